@@ -1,86 +1,249 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"log"
+	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	// Time zones for the stats endpoints, on hosts without a zoneinfo database
+	_ "time/tzdata"
 
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/handlers"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/generate"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/logging"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/metrics"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/server"
+	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	// Parse command line arguments
-	var command string
-	flag.StringVar(&command, "command", "serve", "Command to run (serve, migrate, seed)")
+	var command, dbPath, seedsDir string
+	flag.StringVar(&command, "command", "serve", "Command to run (serve, migrate, seed, generate, cleanup-orphans, rebuild-word-stats, check-word-stats)")
+	flag.StringVar(&dbPath, "db-path", "", "SQLite database file, overriding LANG_PORTAL_DB_PATH")
+	flag.StringVar(&seedsDir, "seeds", "seeds", "Directory of the seed packs")
 	flag.Parse()
 
 	// If no command provided in args, use the first argument
 	if flag.NArg() > 0 {
 		command = flag.Arg(0)
 	}
+	args := commandArgs{
+		seed:     seedArgs{source: os.DirFS(seedsDir)},
+		generate: generate.DefaultOptions(),
+	}
+	if flag.NArg() > 1 {
+		var err error
+		switch command {
+		case "seed":
+			args.seed.opts, err = parseSeedFlags(flag.Args()[1:])
+		case "generate":
+			args.generate, err = parseGenerateFlags(flag.Args()[1:])
+		}
+		if err != nil {
+			fatal("invalid "+command+" arguments", err)
+		}
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("failed to load configuration", err)
+	}
+	if dbPath != "" {
+		cfg.Database.Path = dbPath
+	}
+
+	// Configure logging. The standard library logger is redirected too.
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fatal("failed to configure logging", err)
+	}
+	slog.SetDefault(logger)
+	configureGin(cfg.Log.Level)
+
+	if err := run(command, cfg, args); err != nil {
+		fatal("command failed", err)
+	}
+}
+
+// commandArgs holds the arguments following the command
+type commandArgs struct {
+	seed     seedArgs
+	generate generate.Options
+}
+
+// seedArgs holds what the seed command loads
+type seedArgs struct {
+	source fs.FS
+	opts   database.SeedOptions
+}
+
+// parseSeedFlags parses the flags following the seed command, e.g.
+// "seed --pack pt-a1-basics --sample-sessions"
+func parseSeedFlags(args []string) (database.SeedOptions, error) {
+	var opts database.SeedOptions
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.Func("pack", "Seed pack to load with its dependencies; repeat or separate with commas for several (default: every pack)", func(value string) error {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Packs = append(opts.Packs, name)
+			}
+		}
+		return nil
+	})
+	flags.BoolVar(&opts.SampleSessions, "sample-sessions", false, "Add a sample study session to the first group of each pack")
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+	if flags.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+	return opts, nil
+}
+
+// parseGenerateFlags parses the flags following the generate command, e.g.
+// "generate --users 500 --months 6 --seed 7"
+func parseGenerateFlags(args []string) (generate.Options, error) {
+	opts := generate.DefaultOptions()
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.Int64Var(&opts.Seed, "seed", opts.Seed, "Seed of the random choices; the same flags generate the same data")
+	flags.IntVar(&opts.Users, "users", opts.Users, "Number of students")
+	flags.IntVar(&opts.Groups, "groups", opts.Groups, "Number of word groups")
+	flags.IntVar(&opts.WordsPerGroup, "words-per-group", opts.WordsPerGroup, "Number of words in each group")
+	flags.IntVar(&opts.Months, "months", opts.Months, "Months of study history")
+	flags.Func("until", "Day the history ends, as YYYY-MM-DD (default: today, UTC)", func(value string) error {
+		until, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return err
+		}
+		opts.Until = until
+		return nil
+	})
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+	if flags.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+	return opts, opts.Validate()
+}
 
-	// Initialize database
-	db, err := database.InitDB()
+// run runs command. It returns rather than exiting so that the database is
+// always closed.
+func run(command string, cfg *config.Config, args commandArgs) error {
+	store, err := database.Open(cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer database.CloseDB()
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("failed to close database", "error", err)
+			return
+		}
+		slog.Info("database closed")
+	}()
 
 	// Handle different commands
 	switch command {
 	case "migrate":
-		if err := database.RunMigrations(); err != nil {
-			log.Fatalf("Failed to run migrations: %v", err)
+		if err := database.RunMigrations(store); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
 		}
-		log.Println("Migrations completed successfully")
-		os.Exit(0)
+		slog.Info("migrations completed")
 
 	case "seed":
-		if err := database.RunSeed(); err != nil {
-			log.Fatalf("Failed to seed database: %v", err)
+		if err := database.RunSeed(store, args.seed.source, args.seed.opts); err != nil {
+			return fmt.Errorf("failed to seed database: %w", err)
 		}
-		log.Println("Database seeded successfully")
-		os.Exit(0)
+		slog.Info("database seeded")
 
-	case "close-db":
-		database.CloseDB()
-		log.Println("Database connections closed")
-		os.Exit(0)
+	case "generate":
+		summary, err := generate.Run(context.Background(), api.NewUnitOfWork(store), args.generate)
+		if err != nil {
+			return fmt.Errorf("failed to generate data: %w", err)
+		}
+		slog.Info("data generated", "seed", args.generate.Seed, "users", summary.Users, "groups", summary.Groups,
+			"words", summary.Words, "sessions", summary.Sessions, "reviews", summary.Reviews)
 
-	case "serve":
-		// Initialize repositories
-		wordRepo := repository.NewWordRepository(db)
-		groupRepo := repository.NewGroupRepository(db)
-		studyActivityRepo := repository.NewStudyActivityRepository(db)
-		studySessionRepo := repository.NewStudySessionRepository(db)
-
-		// Initialize services
-		dashboardService := service.NewDashboardService(studySessionRepo, wordRepo, groupRepo)
-		studyActivityService := service.NewStudyActivityService(studyActivityRepo, studySessionRepo)
-		wordService := service.NewWordService(wordRepo)
-		groupService := service.NewGroupService(groupRepo)
-
-		// Initialize handlers
-		dashboardHandler := handlers.NewDashboardHandler(dashboardService)
-		studyActivityHandler := handlers.NewStudyActivityHandler(studyActivityService)
-		wordHandler := handlers.NewWordHandler(wordService)
-		groupHandler := handlers.NewGroupHandler(groupService)
-
-		// Setup router
-		router := api.SetupRouter(dashboardHandler, studyActivityHandler, wordHandler, groupHandler)
-
-		// Start server
-		// TODO: Make port configurable
-		log.Println("Starting server on :3000")
-		if err := router.Run(":3000"); err != nil {
-			log.Fatalf("Failed to start server: %v", err)
+	case "cleanup-orphans":
+		deleted, err := database.DeleteOrphans(context.Background(), store.DB)
+		if err != nil {
+			return fmt.Errorf("failed to delete orphaned rows: %w", err)
 		}
+		slog.Info("orphaned rows deleted", "words_groups", deleted.WordsGroups, "word_stats", deleted.WordStats, "word_review_items", deleted.WordReviewItems)
+
+	case "rebuild-word-stats":
+		words, err := database.RebuildWordStats(context.Background(), store.DB)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild word stats: %w", err)
+		}
+		slog.Info("word stats rebuilt", "words", words)
+
+	case "check-word-stats":
+		mismatched, err := database.CheckWordStats(context.Background(), store.DB)
+		if err != nil {
+			return err
+		}
+		if len(mismatched) > 0 {
+			return fmt.Errorf("word stats differ from the reviews of %d words, starting with word %d; run rebuild-word-stats to recount them",
+				len(mismatched), mismatched[0])
+		}
+		slog.Info("word stats match the reviews")
+
+	case "serve":
+		return serve(cfg, store)
 
 	default:
-		log.Fatalf("Unknown command: %s", command)
+		return fmt.Errorf("unknown command %q", command)
 	}
+	return nil
+}
+
+// serve runs the API server until SIGINT or SIGTERM, then drains in-flight
+// requests and stops background jobs before the database is closed
+func serve(cfg *config.Config, store *database.Store) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var m *metrics.Portal
+	if cfg.Metrics.Enabled {
+		m = metrics.NewPortal()
+		slog.Info("serving metrics", "path", cfg.Metrics.Path)
+	}
+
+	h := api.NewHandlers(cfg, store, m)
+	srv := server.New(cfg.Server, api.SetupRouter(cfg, h), slog.Default())
+	srv.Go("leaderboards", h.RefreshLeaderboards)
+	if err := srv.Run(ctx); err != nil {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// configureGin silences gin's own console output unless debug logging is
+// enabled, in which case route registrations go through slog
+func configureGin(level slog.Level) {
+	if level > slog.LevelDebug {
+		gin.SetMode(gin.ReleaseMode)
+		return
+	}
+	gin.DebugPrintRouteFunc = func(method, path, handler string, handlers int) {
+		slog.Debug("route registered", "method", method, "path", path, "handler", handler)
+	}
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/handlers"
+	v2 "github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/v2"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/dialect"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/metrics"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+)
+
+// Handlers holds the handlers mounted by SetupRouter. The v1 handlers serve
+// the legacy response shapes; V2 serves /api/v2 over the same services.
+type Handlers struct {
+	Dashboard     *handlers.DashboardHandler
+	StudyActivity *handlers.StudyActivityHandler
+	Word          *handlers.WordHandler
+	Group         *handlers.GroupHandler
+	User          *handlers.UserHandler
+	Classroom     *handlers.ClassroomHandler
+	Stats         *handlers.StatsHandler
+	Gamification  *handlers.GamificationHandler
+	Leaderboard   *handlers.LeaderboardHandler
+	V2            *v2.Handler
+	// Metrics is nil when metrics are disabled
+	Metrics *metrics.Portal
+
+	// db is checked by the readiness and version endpoints
+	db *sql.DB
+	// leaderboards are refreshed every leaderboardRefresh by RefreshLeaderboards
+	leaderboards       *service.LeaderboardService
+	leaderboardRefresh time.Duration
+}
+
+// NewHandlers wires repositories, services and handlers configured by cfg on
+// top of store, in its dialect: the queries made outside transactions run on
+// its read pool and everything else on its writer. When m is not nil, queries, study
+// activity and the connection pools are reported to it; m must not be
+// shared with another set of handlers.
+func NewHandlers(cfg *config.Config, store *database.Store, m *metrics.Portal) *Handlers {
+	db := store.DB
+	var conn repository.DB = db
+	if store.Read != nil && store.Read != db {
+		conn = repository.ReadWrite(store.Read, db)
+	}
+	unobserved := repository.WithDialect(conn, dialect.Of(db))
+	repoDB := unobserved
+	var events service.Events
+	if m != nil {
+		repoDB = repository.Instrument(repoDB, m.ObserveQuery)
+		events = m
+	}
+
+	// Initialize repositories
+	repos := newRepositories(repoDB)
+	uow := unitOfWork{db: repoDB}
+
+	if m != nil {
+		m.RegisterDBStats(store.DB, store.Read)
+		// Scrapes aren't attributed to a repository method in the query timings
+		m.RegisterActiveLearners(repository.NewStudySessionRepository(unobserved).CountActiveLearners)
+	}
+
+	// Initialize services
+	dashboardService := service.NewDashboardService(repos.StudySessions, repos.Words, repos.Groups, repos.Gamification)
+	studyActivityService := service.NewStudyActivityService(repos.StudyActivities, repos.StudySessions, repos.Words, uow, events)
+	wordService := service.NewWordService(repos.Words)
+	groupService := service.NewGroupService(repos.Groups, repos.Words, repos.StudyActivities, uow, models.MasteryThresholds{
+		MasteredStreak: cfg.Mastery.Streak,
+		LapsedAfter:    cfg.Mastery.LapsedAfter,
+	})
+	userService := service.NewUserService(repos.Users)
+	classroomService := service.NewClassroomService(repos.Classrooms, repos.Groups, repos.Users, repos.StudyActivities)
+	statsService := service.NewStatsService(repos.StudySessions, repos.Gamification, repos.Users)
+	analyticsService := service.NewAnalyticsService(repos.StudySessions, repos.Words, repos.Groups)
+	gamificationService := service.NewGamificationService(repos.Gamification, repos.StudySessions, repos.Users)
+	pseudonymSecret := []byte(cfg.Leaderboard.PseudonymSecret)
+	if len(pseudonymSecret) == 0 {
+		pseudonymSecret = make([]byte, 32)
+		if _, err := rand.Read(pseudonymSecret); err != nil {
+			panic(err)
+		}
+		slog.Warn("LANG_PORTAL_PSEUDONYM_SECRET is not set, leaderboard pseudonyms will change when the server restarts")
+	}
+	leaderboardService := service.NewLeaderboardService(repos.Leaderboards, repos.Classrooms, repos.Users, cfg.Mastery.Streak, pseudonymSecret)
+
+	return &Handlers{
+		Dashboard:     handlers.NewDashboardHandler(dashboardService),
+		StudyActivity: handlers.NewStudyActivityHandler(studyActivityService),
+		Word:          handlers.NewWordHandler(wordService),
+		Group:         handlers.NewGroupHandler(groupService),
+		User:          handlers.NewUserHandler(userService, classroomService),
+		Classroom:     handlers.NewClassroomHandler(classroomService),
+		Stats:         handlers.NewStatsHandler(statsService, analyticsService),
+		Gamification:  handlers.NewGamificationHandler(gamificationService),
+		Leaderboard:   handlers.NewLeaderboardHandler(leaderboardService),
+		V2: v2.NewHandler(v2.Services{
+			Dashboard:     dashboardService,
+			StudyActivity: studyActivityService,
+			Word:          wordService,
+			Group:         groupService,
+			Classroom:     classroomService,
+		}),
+		Metrics:            m,
+		db:                 db,
+		leaderboards:       leaderboardService,
+		leaderboardRefresh: cfg.Leaderboard.RefreshInterval,
+	}
+}
+
+// RefreshLeaderboards recomputes the leaderboard snapshots of every
+// classroom at once and then at the configured interval, until ctx is
+// canceled. The server runs it as a background job.
+func (h *Handlers) RefreshLeaderboards(ctx context.Context) {
+	ticker := time.NewTicker(h.leaderboardRefresh)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		err := h.leaderboards.RefreshLeaderboards(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			slog.Error("failed to refresh leaderboards", "error", err)
+		default:
+			slog.Debug("leaderboards refreshed", "duration", time.Since(start))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newRepositories builds every repository on db
+func newRepositories(db repository.DB) service.Repositories {
+	return service.Repositories{
+		Words:           repository.NewWordRepository(db),
+		Groups:          repository.NewGroupRepository(db),
+		StudyActivities: repository.NewStudyActivityRepository(db),
+		StudySessions:   repository.NewStudySessionRepository(db),
+		Users:           repository.NewUserRepository(db),
+		Classrooms:      repository.NewClassroomRepository(db),
+		Gamification:    repository.NewGamificationRepository(db),
+		Leaderboards:    repository.NewLeaderboardRepository(db),
+	}
+}
+
+// NewUnitOfWork returns a UnitOfWork writing to store in its dialect, for
+// the commands that write through the repositories outside the server
+func NewUnitOfWork(store *database.Store) service.UnitOfWork {
+	return unitOfWork{db: repository.WithDialect(store.DB, dialect.Of(store.DB))}
+}
+
+// unitOfWork runs service callbacks in database transactions
+type unitOfWork struct {
+	db repository.DB
+}
+
+func (u unitOfWork) WithTx(ctx context.Context, fn func(repos service.Repositories) error) error {
+	return repository.WithTx(ctx, u.db, func(tx repository.DB) error {
+		return fn(newRepositories(tx))
+	})
+}
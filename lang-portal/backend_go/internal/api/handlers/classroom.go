@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ClassroomHandler struct {
+	classroomService *service.ClassroomService
+}
+
+func NewClassroomHandler(classroomService *service.ClassroomService) *ClassroomHandler {
+	return &ClassroomHandler{classroomService: classroomService}
+}
+
+type CreateClassroomRequest struct {
+	Name      string `json:"name" binding:"required"`
+	TeacherID int64  `json:"teacher_id" binding:"required"`
+}
+
+func (h *ClassroomHandler) CreateClassroom(c *gin.Context) {
+	var req CreateClassroomRequest
+	if !request.BindJSON(c, &req) {
+		return
+	}
+
+	classroom, err := h.classroomService.CreateClassroom(c.Request.Context(), &models.Classroom{
+		Name:      req.Name,
+		TeacherID: req.TeacherID,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusCreated, classroom)
+}
+
+func (h *ClassroomHandler) GetClassroom(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	classroom, err := h.classroomService.GetClassroom(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, classroom)
+}
+
+type AddMembersRequest struct {
+	UserIDs []int64 `json:"user_ids" binding:"required,min=1"`
+}
+
+func (h *ClassroomHandler) AddMembers(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AddMembersRequest
+	if !request.BindJSON(c, &req) {
+		return
+	}
+
+	if err := h.classroomService.AddMembers(c.Request.Context(), id, req.UserIDs); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ClassroomHandler) RemoveMember(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, ok := request.ParseID(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.classroomService.RemoveMember(c.Request.Context(), id, userID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type CreateAssignmentRequest struct {
+	GroupID         int64     `json:"group_id" binding:"required"`
+	StudyActivityID *int64    `json:"study_activity_id"`
+	DueAt           time.Time `json:"due_at" binding:"required"`
+	TargetAccuracy  *float64  `json:"target_accuracy" binding:"omitempty,min=0,max=100"`
+}
+
+// defaultTargetAccuracy is the accuracy required when the teacher doesn't set one
+const defaultTargetAccuracy = 80
+
+func (h *ClassroomHandler) CreateAssignment(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CreateAssignmentRequest
+	if !request.BindJSON(c, &req) {
+		return
+	}
+
+	targetAccuracy := float64(defaultTargetAccuracy)
+	if req.TargetAccuracy != nil {
+		targetAccuracy = *req.TargetAccuracy
+	}
+
+	assignment, err := h.classroomService.CreateAssignment(c.Request.Context(), &models.Assignment{
+		ClassroomID:     id,
+		GroupID:         req.GroupID,
+		StudyActivityID: req.StudyActivityID,
+		DueAt:           req.DueAt,
+		TargetAccuracy:  targetAccuracy,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusCreated, assignment)
+}
+
+// ListAssignments returns the teacher view of a classroom's assignments
+func (h *ClassroomHandler) ListAssignments(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	assignments, err := h.classroomService.ListAssignments(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, assignments)
+}
+
+// GetAssignment returns an assignment with a progress report per student
+func (h *ClassroomHandler) GetAssignment(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	assignmentID, ok := request.ParseID(c, "assignment_id")
+	if !ok {
+		return
+	}
+
+	report, err := h.classroomService.GetAssignmentReport(c.Request.Context(), id, assignmentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, report)
+}
@@ -0,0 +1,495 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// ClassroomHandlerTestSuite is a test suite for the classroom and user handlers
+type ClassroomHandlerTestSuite struct {
+	suite.Suite
+	router       *gin.Engine
+	db           *database.TestDB
+	teacher      models.User
+	students     []models.User
+	classroom    models.Classroom
+	testGroup    *models.Group
+	testWords    []*models.Word
+	testActivity *models.StudyActivity
+}
+
+// SetupSuite sets up the test suite
+func (suite *ClassroomHandlerTestSuite) SetupSuite() {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create a temporary test database
+	var err error
+	suite.db, err = database.NewTestDB()
+	if err != nil {
+		suite.T().Fatalf("Failed to create test database: %v", err)
+	}
+
+	// Setup router
+	suite.router = api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), suite.db.Store, nil))
+}
+
+// TearDownSuite tears down the test suite
+func (suite *ClassroomHandlerTestSuite) TearDownSuite() {
+	// Close and remove the test database
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// SetupTest sets up each test
+func (suite *ClassroomHandlerTestSuite) SetupTest() {
+	// Clear any existing test data
+	suite.clearTestData()
+
+	// Seed test data for this test
+	suite.seedTestData()
+}
+
+// TearDownTest cleans up after each test
+func (suite *ClassroomHandlerTestSuite) TearDownTest() {
+	// Clear test data
+	suite.clearTestData()
+}
+
+// clearTestData removes all test data from the database
+func (suite *ClassroomHandlerTestSuite) clearTestData() {
+	suite.students = nil
+	suite.testWords = nil
+
+	// Delete children before parents since foreign keys are not enforced
+	tables := []string{
+		"leaderboard_standings",
+		"leaderboard_snapshots",
+		"leaderboard_profiles",
+		"word_review_items",
+		"study_sessions",
+		"assignments",
+		"classroom_members",
+		"classrooms",
+		"users",
+		"words_groups",
+		"words",
+		"groups",
+		"study_activities",
+	}
+	for _, table := range tables {
+		if _, err := suite.db.DB.Exec("DELETE FROM " + table); err != nil {
+			suite.T().Fatalf("Failed to clear %s data: %v", table, err)
+		}
+	}
+}
+
+// seedTestData seeds the test database with test data
+func (suite *ClassroomHandlerTestSuite) seedTestData() {
+	// Create a group with two words
+	result, err := suite.db.DB.Exec("INSERT INTO groups (name) VALUES (?)", "Greetings")
+	if err != nil {
+		suite.T().Fatalf("Failed to insert test group: %v", err)
+	}
+	groupID, _ := result.LastInsertId()
+	suite.testGroup = &models.Group{ID: groupID, Name: "Greetings"}
+
+	for _, word := range []models.Word{
+		{Portuguese: "olá", English: "hello"},
+		{Portuguese: "adeus", English: "goodbye"},
+	} {
+		result, err := suite.db.DB.Exec("INSERT INTO words (portuguese, english) VALUES (?, ?)", word.Portuguese, word.English)
+		if err != nil {
+			suite.T().Fatalf("Failed to insert test word: %v", err)
+		}
+		id, _ := result.LastInsertId()
+		if _, err := suite.db.DB.Exec("INSERT INTO words_groups (word_id, group_id) VALUES (?, ?)", id, groupID); err != nil {
+			suite.T().Fatalf("Failed to add word to group: %v", err)
+		}
+		suite.testWords = append(suite.testWords, &models.Word{ID: id, Portuguese: word.Portuguese, English: word.English})
+	}
+
+	// Create a study activity
+	result, err = suite.db.DB.Exec(
+		"INSERT INTO study_activities (name, thumbnail_url, description) VALUES (?, ?, ?)",
+		"Flashcards", "https://example.com/flashcards.jpg", "Practice with flashcards",
+	)
+	if err != nil {
+		suite.T().Fatalf("Failed to insert test study activity: %v", err)
+	}
+	activityID, _ := result.LastInsertId()
+	suite.testActivity = &models.StudyActivity{ID: activityID, Name: "Flashcards"}
+
+	// Create a teacher and two students through the API
+	suite.teacher = suite.createUser("Ms. Silva", models.RoleTeacher)
+	suite.students = []models.User{
+		suite.createUser("Ana", ""),
+		suite.createUser("Bruno", models.RoleStudent),
+	}
+
+	// Create a classroom and enroll the students
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/classrooms", map[string]interface{}{
+		"name":       "Portuguese A1",
+		"teacher_id": suite.teacher.ID,
+	})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusCreated)
+	testutil.ParseResponse(suite.T(), w, &suite.classroom)
+
+	w = testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"POST",
+		fmt.Sprintf("/api/classrooms/%d/members", suite.classroom.ID),
+		map[string][]int64{"user_ids": {suite.students[0].ID, suite.students[1].ID}},
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNoContent)
+}
+
+// createUser creates a user through the API
+func (suite *ClassroomHandlerTestSuite) createUser(name, role string) models.User {
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/users", map[string]string{
+		"name": name,
+		"role": role,
+	})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusCreated)
+
+	var user models.User
+	testutil.ParseResponse(suite.T(), w, &user)
+	return user
+}
+
+// createAssignment creates an assignment for the test group through the API
+func (suite *ClassroomHandlerTestSuite) createAssignment(dueAt time.Time) models.Assignment {
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"POST",
+		fmt.Sprintf("/api/classrooms/%d/assignments", suite.classroom.ID),
+		map[string]interface{}{
+			"group_id":          suite.testGroup.ID,
+			"study_activity_id": suite.testActivity.ID,
+			"due_at":            dueAt,
+			"target_accuracy":   50,
+		},
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusCreated)
+
+	var assignment models.Assignment
+	testutil.ParseResponse(suite.T(), w, &assignment)
+	return assignment
+}
+
+// studyAllWords starts a study session for a student and reviews every test word
+func (suite *ClassroomHandlerTestSuite) studyAllWords(userID int64, correct bool) {
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/study_activities", map[string]int64{
+		"group_id":          suite.testGroup.ID,
+		"study_activity_id": suite.testActivity.ID,
+		"user_id":           userID,
+	})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusCreated)
+
+	var session models.StudySession
+	testutil.ParseResponse(suite.T(), w, &session)
+
+	for _, word := range suite.testWords {
+		_, err := suite.db.DB.Exec(
+			"INSERT INTO word_review_items (word_id, study_session_id, correct, created_at) VALUES (?, ?, ?, ?)",
+			word.ID, session.ID, correct, time.Now(),
+		)
+		if err != nil {
+			suite.T().Fatalf("Failed to insert test review: %v", err)
+		}
+	}
+}
+
+// TestGetClassroom tests the GetClassroom endpoint
+func (suite *ClassroomHandlerTestSuite) TestGetClassroom() {
+	w := testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/classrooms/%d", suite.classroom.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var response models.ClassroomDetail
+	testutil.ParseResponse(suite.T(), w, &response)
+
+	assert.Equal(suite.T(), "Portuguese A1", response.Name)
+	assert.Equal(suite.T(), suite.teacher.ID, response.TeacherID)
+	assert.Len(suite.T(), response.Members, 2)
+	assert.Equal(suite.T(), "Ana", response.Members[0].Name)
+	assert.Equal(suite.T(), models.RoleStudent, response.Members[0].Role)
+}
+
+// TestRemoveMember tests the RemoveMember endpoint
+func (suite *ClassroomHandlerTestSuite) TestRemoveMember() {
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"DELETE",
+		fmt.Sprintf("/api/classrooms/%d/members/%d", suite.classroom.ID, suite.students[1].ID),
+		nil,
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNoContent)
+
+	var count int
+	err := suite.db.DB.QueryRow("SELECT COUNT(*) FROM classroom_members WHERE classroom_id = ?", suite.classroom.ID).Scan(&count)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+
+	// Bruno is no longer a member, and there is no classroom 9999
+	w = testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"DELETE",
+		fmt.Sprintf("/api/classrooms/%d/members/%d", suite.classroom.ID, suite.students[1].ID),
+		nil,
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+	w = testutil.PerformRequest(suite.T(), suite.router, "DELETE", fmt.Sprintf("/api/classrooms/9999/members/%d", suite.students[0].ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+}
+
+// TestAssignmentProgress tests the teacher report of an assignment
+func (suite *ClassroomHandlerTestSuite) TestAssignmentProgress() {
+	assignment := suite.createAssignment(time.Now().Add(7 * 24 * time.Hour))
+	assert.Equal(suite.T(), suite.testGroup.Name, assignment.GroupName)
+	assert.Equal(suite.T(), 50.0, assignment.TargetAccuracy)
+
+	// Ana reviews every word correctly, Bruno doesn't study
+	suite.studyAllWords(suite.students[0].ID, true)
+
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"GET",
+		fmt.Sprintf("/api/classrooms/%d/assignments/%d", suite.classroom.ID, assignment.ID),
+		nil,
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var report models.AssignmentReport
+	testutil.ParseResponse(suite.T(), w, &report)
+
+	assert.Equal(suite.T(), assignment.ID, report.ID)
+	assert.Len(suite.T(), report.Progress, 2)
+
+	ana := report.Progress[0]
+	assert.Equal(suite.T(), suite.students[0].ID, ana.UserID)
+	assert.Equal(suite.T(), 2, ana.WordsReviewed)
+	assert.Equal(suite.T(), 2, ana.TotalWords)
+	assert.Equal(suite.T(), 100.0, ana.Accuracy)
+	assert.NotNil(suite.T(), ana.LastReviewedAt)
+	assert.Equal(suite.T(), models.AssignmentCompleted, ana.Status)
+
+	bruno := report.Progress[1]
+	assert.Equal(suite.T(), suite.students[1].ID, bruno.UserID)
+	assert.Equal(suite.T(), 0, bruno.WordsReviewed)
+	assert.Nil(suite.T(), bruno.LastReviewedAt)
+	assert.Equal(suite.T(), models.AssignmentNotStarted, bruno.Status)
+
+	// The teacher list summarizes completion
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/classrooms/%d/assignments", suite.classroom.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var summaries []models.AssignmentSummary
+	testutil.ParseResponse(suite.T(), w, &summaries)
+
+	assert.Len(suite.T(), summaries, 1)
+	assert.Equal(suite.T(), 2, summaries[0].MemberCount)
+	assert.Equal(suite.T(), 1, summaries[0].CompletedCount)
+}
+
+// TestAssignmentBelowTargetAccuracy tests that reviewing every word isn't enough without the target accuracy
+func (suite *ClassroomHandlerTestSuite) TestAssignmentBelowTargetAccuracy() {
+	assignment := suite.createAssignment(time.Now().Add(24 * time.Hour))
+
+	suite.studyAllWords(suite.students[1].ID, false)
+
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"GET",
+		fmt.Sprintf("/api/classrooms/%d/assignments/%d", suite.classroom.ID, assignment.ID),
+		nil,
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var report models.AssignmentReport
+	testutil.ParseResponse(suite.T(), w, &report)
+
+	bruno := report.Progress[1]
+	assert.Equal(suite.T(), 2, bruno.WordsReviewed)
+	assert.Equal(suite.T(), 2, bruno.WrongCount)
+	assert.Equal(suite.T(), 0.0, bruno.Accuracy)
+	assert.Equal(suite.T(), models.AssignmentInProgress, bruno.Status)
+}
+
+// TestStudentAssignments tests the student view of assignments
+func (suite *ClassroomHandlerTestSuite) TestStudentAssignments() {
+	suite.createAssignment(time.Now().Add(-time.Hour))
+	suite.studyAllWords(suite.students[0].ID, true)
+
+	// Ana finished, late work still counts as completed
+	w := testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/users/%d/assignments", suite.students[0].ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var assignments []models.StudentAssignment
+	testutil.ParseResponse(suite.T(), w, &assignments)
+
+	assert.Len(suite.T(), assignments, 1)
+	assert.Equal(suite.T(), suite.classroom.Name, assignments[0].ClassroomName)
+	assert.Equal(suite.T(), suite.students[0].ID, assignments[0].Progress.UserID)
+	assert.Equal(suite.T(), models.AssignmentCompleted, assignments[0].Progress.Status)
+
+	// Bruno never studied and the due date has passed
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/users/%d/assignments", suite.students[1].ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &assignments)
+
+	assert.Len(suite.T(), assignments, 1)
+	assert.Equal(suite.T(), models.AssignmentOverdue, assignments[0].Progress.Status)
+
+	// The teacher is not a member, so they have no assignments of their own
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/users/%d/assignments", suite.teacher.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &assignments)
+	assert.Empty(suite.T(), assignments)
+}
+
+// TestGetAssignmentNotFound tests the assignment endpoints with non-existent
+// assignments, classrooms and users
+func (suite *ClassroomHandlerTestSuite) TestGetAssignmentNotFound() {
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"GET",
+		fmt.Sprintf("/api/classrooms/%d/assignments/9999", suite.classroom.ID),
+		nil,
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/classrooms/9999/assignments", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/users/9999/assignments", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+}
+
+// TestInvalidReferences tests that classrooms, members and assignments
+// referencing missing records, or users in the wrong role, are rejected
+func (suite *ClassroomHandlerTestSuite) TestInvalidReferences() {
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/classrooms", map[string]interface{}{
+		"name":       "Portuguese A2",
+		"teacher_id": suite.students[0].ID,
+	})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+
+	members := fmt.Sprintf("/api/classrooms/%d/members", suite.classroom.ID)
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", members, map[string][]int64{"user_ids": {suite.teacher.ID, 9999}})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), []service.FieldError{
+		{Field: "user_ids[0]", Message: "must be a student"},
+		{Field: "user_ids[1]", Message: "does not exist"},
+	}, response.Fields)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/classrooms/9999/members", map[string][]int64{"user_ids": {suite.students[0].ID}})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", fmt.Sprintf("/api/classrooms/%d/assignments", suite.classroom.ID), map[string]interface{}{
+		"group_id": 9999,
+		"due_at":   time.Now().Add(24 * time.Hour),
+	})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+}
+
+// TestCreateAssignmentInvalidPayload tests the CreateAssignment endpoint with invalid payload
+func (suite *ClassroomHandlerTestSuite) TestCreateAssignmentInvalidPayload() {
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"POST",
+		fmt.Sprintf("/api/classrooms/%d/assignments", suite.classroom.ID),
+		map[string]interface{}{
+			"group_id":        suite.testGroup.ID,
+			"target_accuracy": 150,
+		},
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+}
+
+// TestLeaderboard tests the classroom leaderboard and the leaderboard
+// profiles that name or hide learners on it
+func (suite *ClassroomHandlerTestSuite) TestLeaderboard() {
+	// Ana answers every word right and Bruno every word wrong
+	suite.studyAllWords(suite.students[0].ID, true)
+	suite.studyAllWords(suite.students[1].ID, false)
+	if _, err := suite.db.DB.Exec("UPDATE word_review_items SET xp = 10 WHERE correct"); err != nil {
+		suite.T().Fatalf("Failed to award test XP: %v", err)
+	}
+
+	leaderboard := fmt.Sprintf("/api/classrooms/%d/leaderboard", suite.classroom.ID)
+	w := testutil.PerformRequest(suite.T(), suite.router, "GET", leaderboard, nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var board service.Leaderboard
+	testutil.ParseResponse(suite.T(), w, &board)
+	assert.Equal(suite.T(), models.LeaderboardXP, board.Metric)
+	assert.Equal(suite.T(), models.PeriodWeekly, board.Period)
+	assert.Len(suite.T(), board.Entries, 1)
+	assert.Equal(suite.T(), 1, board.Entries[0].Rank)
+	assert.Equal(suite.T(), 20.0, board.Entries[0].Value)
+	assert.NotEqual(suite.T(), "Ana", board.Entries[0].DisplayName, "learners are pseudonymous by default")
+
+	// Profiles apply to the leaderboard at once
+	profile := fmt.Sprintf("/api/users/%d/leaderboard_profile", suite.students[0].ID)
+	w = testutil.PerformRequest(suite.T(), suite.router, "PUT", profile, map[string]interface{}{"display_name": "Ana S."})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var response models.LeaderboardProfile
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), models.LeaderboardProfile{UserID: suite.students[0].ID, DisplayName: "Ana S."}, response)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/v2/classrooms/%d/leaderboard?period=daily", suite.classroom.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &board)
+	assert.Equal(suite.T(), models.PeriodDaily, board.Period)
+	assert.Len(suite.T(), board.Entries, 1)
+	assert.Equal(suite.T(), "Ana S.", board.Entries[0].DisplayName)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "PUT", profile, map[string]interface{}{"display_name": "Ana S.", "opt_out": true})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", leaderboard, nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &board)
+	assert.Empty(suite.T(), board.Entries)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/users/%d/leaderboard_profile", suite.students[1].ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.NotEmpty(suite.T(), response.DisplayName)
+	assert.False(suite.T(), response.OptOut)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", leaderboard+"?metric=streak", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/classrooms/9999/leaderboard", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+	w = testutil.PerformRequest(suite.T(), suite.router, "PUT", "/api/users/9999/leaderboard_profile", map[string]interface{}{"opt_out": true})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+}
+
+// TestClassroomHandlerSuite runs the test suite
+func TestClassroomHandlerSuite(t *testing.T) {
+	suite.Run(t, new(ClassroomHandlerTestSuite))
+}
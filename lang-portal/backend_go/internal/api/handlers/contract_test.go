@@ -0,0 +1,222 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// V1ContractTestSuite pins the JSON shapes of the v1 API that the existing
+// frontend (frontend/src/types/index.ts) depends on. v1 is frozen: keys may
+// be added, but if one is renamed, removed or changes meaning, the change
+// belongs in /api/v2 instead.
+type V1ContractTestSuite struct {
+	suite.Suite
+	router     *gin.Engine
+	db         *database.TestDB
+	wordID     int64
+	groupID    int64
+	activityID int64
+}
+
+// SetupSuite creates the database and a small data set touching every table
+func (suite *V1ContractTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+
+	var err error
+	suite.db, err = database.NewTestDB()
+	if err != nil {
+		suite.T().Fatalf("Failed to create test database: %v", err)
+	}
+	suite.router = api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), suite.db.Store, nil))
+
+	suite.wordID = suite.insert("INSERT INTO words (portuguese, english) VALUES (?, ?)", "olá", "hello")
+	suite.groupID = suite.insert("INSERT INTO groups (name) VALUES (?)", "Greetings")
+	suite.insert("INSERT INTO words_groups (word_id, group_id) VALUES (?, ?)", suite.wordID, suite.groupID)
+	suite.activityID = suite.insert("INSERT INTO study_activities (name, thumbnail_url, description) VALUES (?, ?, ?)",
+		"Flashcards", "/flashcards.png", "Practice with flashcards")
+	sessionID := suite.insert("INSERT INTO study_sessions (group_id, study_activity_id) VALUES (?, ?)", suite.groupID, suite.activityID)
+	suite.insert("INSERT INTO word_review_items (word_id, study_session_id, correct, created_at) VALUES (?, ?, ?, ?)",
+		suite.wordID, sessionID, true, time.Now())
+}
+
+// TearDownSuite closes the test database
+func (suite *V1ContractTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+func (suite *V1ContractTestSuite) insert(query string, args ...interface{}) int64 {
+	result, err := suite.db.DB.Exec(query, args...)
+	if err != nil {
+		suite.T().Fatalf("Failed to seed contract data: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	return id
+}
+
+// get performs a GET request and decodes the JSON body
+func (suite *V1ContractTestSuite) get(path string) interface{} {
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, path, nil)
+	require.Equal(suite.T(), http.StatusOK, w.Code, "GET %s: %s", path, w.Body.String())
+
+	var body interface{}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &body))
+	return body
+}
+
+// keys returns the sorted keys of a JSON object
+func keys(t *testing.T, v interface{}) []string {
+	object, ok := v.(map[string]interface{})
+	require.True(t, ok, "expected a JSON object, got %T", v)
+
+	result := make([]string, 0, len(object))
+	for k := range object {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// firstItem returns the first element of a JSON array
+func firstItem(t *testing.T, v interface{}) interface{} {
+	items, ok := v.([]interface{})
+	require.True(t, ok, "expected a JSON array, got %T", v)
+	require.NotEmpty(t, items)
+	return items[0]
+}
+
+var v1PaginationKeys = []string{"current_page", "items_per_page", "total_items", "total_pages"}
+
+// TestWords pins the word list and detail shapes
+func (suite *V1ContractTestSuite) TestWords() {
+	t := suite.T()
+
+	page := suite.get("/api/words")
+	assert.Equal(t, []string{"items", "pagination"}, keys(t, page))
+	assert.Equal(t, v1PaginationKeys, keys(t, page.(map[string]interface{})["pagination"]))
+	item := firstItem(t, page.(map[string]interface{})["items"])
+	assert.Equal(t, []string{"correct_count", "created_at", "english", "id", "portuguese", "wrong_count"}, keys(t, item))
+
+	detail := suite.get(fmt.Sprintf("/api/words/%d", suite.wordID)).(map[string]interface{})
+	assert.Equal(t, []string{"english", "groups", "id", "portuguese", "stats"}, keys(t, detail))
+	assert.Equal(t, []string{"correct_count", "wrong_count"}, keys(t, detail["stats"]))
+	assert.Equal(t, []string{"id", "name"}, keys(t, firstItem(t, detail["groups"])))
+}
+
+// TestGroups pins the group list, detail and word list shapes
+func (suite *V1ContractTestSuite) TestGroups() {
+	t := suite.T()
+
+	page := suite.get("/api/groups").(map[string]interface{})
+	assert.Equal(t, v1PaginationKeys, keys(t, page["pagination"]))
+	assert.Equal(t, []string{
+		"created_at", "id", "lapsed_count", "last_studied_at", "learning_count", "mastered_count",
+		"name", "new_count", "next_activity", "percent_complete", "word_count",
+	}, keys(t, firstItem(t, page["items"])))
+
+	detail := suite.get(fmt.Sprintf("/api/groups/%d", suite.groupID)).(map[string]interface{})
+	assert.Equal(t, []string{"id", "name", "stats"}, keys(t, detail))
+	assert.Equal(t, []string{
+		"lapsed_count", "last_studied_at", "learning_count", "mastered_count",
+		"new_count", "next_activity", "percent_complete", "total_word_count",
+	}, keys(t, detail["stats"]))
+	assert.Equal(t, []string{"focus", "name", "study_activity_id"}, keys(t, detail["stats"].(map[string]interface{})["next_activity"]))
+
+	words := suite.get(fmt.Sprintf("/api/groups/%d/words", suite.groupID)).(map[string]interface{})
+	assert.Equal(t, v1PaginationKeys, keys(t, words["pagination"]))
+	assert.Equal(t, []string{"correct_count", "created_at", "english", "id", "portuguese", "wrong_count"}, keys(t, firstItem(t, words["items"])))
+}
+
+// TestStudyActivities pins the activity and session shapes, including the
+// launch response
+func (suite *V1ContractTestSuite) TestStudyActivities() {
+	t := suite.T()
+
+	activities := suite.get("/api/study_activities")
+	assert.Equal(t, []string{"created_at", "description", "difficulty", "id", "name", "thumbnail_url"}, keys(t, firstItem(t, activities)))
+
+	activity := suite.get(fmt.Sprintf("/api/study_activities/%d", suite.activityID))
+	assert.Equal(t, []string{"created_at", "description", "difficulty", "id", "name", "thumbnail_url"}, keys(t, activity))
+
+	// end_time is only present once a session has reviews, as it has here
+	sessionKeys := []string{"activity_name", "end_time", "group_name", "id", "review_items_count", "start_time"}
+	sessions := suite.get(fmt.Sprintf("/api/study_activities/%d/study_sessions", suite.activityID)).(map[string]interface{})
+	assert.Equal(t, v1PaginationKeys, keys(t, sessions["pagination"]))
+	assert.Equal(t, sessionKeys, keys(t, firstItem(t, sessions["items"])))
+
+	all := suite.get("/api/study_sessions").(map[string]interface{})
+	assert.Equal(t, v1PaginationKeys, keys(t, all["pagination"]))
+	assert.Equal(t, sessionKeys, keys(t, firstItem(t, all["items"])))
+
+	w := testutil.PerformRequest(t, suite.router, http.MethodPost, "/api/study_activities", map[string]interface{}{
+		"group_id":          suite.groupID,
+		"study_activity_id": suite.activityID,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var launched interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &launched))
+	assert.Subset(t, keys(t, launched), []string{"group_id", "id"})
+
+	// Keep the seeded session the most recent one for TestDashboard
+	_, err := suite.db.DB.Exec("DELETE FROM study_sessions WHERE id = ?", launched.(map[string]interface{})["id"])
+	require.NoError(t, err)
+}
+
+// TestDashboard pins the dashboard shapes
+func (suite *V1ContractTestSuite) TestDashboard() {
+	t := suite.T()
+
+	last := suite.get("/api/dashboard/last_study_session")
+	assert.Equal(t, []string{"activity_name", "end_time", "group_name", "id", "review_items_count", "start_time"}, keys(t, last))
+
+	progress := suite.get("/api/dashboard/study_progress")
+	assert.Equal(t, []string{"total_available_words", "total_words_studied"}, keys(t, progress))
+
+	stats := suite.get("/api/dashboard/quick-stats")
+	assert.Equal(t, []string{"study_streak_days", "success_rate", "total_active_groups", "total_study_sessions"}, keys(t, stats))
+}
+
+// TestDeprecationHeaders checks that v1 responses announce their deprecation
+// and v2 and documentation responses don't
+func (suite *V1ContractTestSuite) TestDeprecationHeaders() {
+	t := suite.T()
+	cfg := config.Default()
+
+	w := testutil.PerformRequest(t, suite.router, http.MethodGet, "/api/words", nil)
+	assert.Equal(t, fmt.Sprintf("@%d", cfg.API.V1DeprecatedAt.Unix()), w.Header().Get("Deprecation"))
+	assert.Equal(t, cfg.API.V1Sunset.Format(http.TimeFormat), w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v2>; rel="successor-version"`, w.Header().Get("Link"))
+
+	// Errors are deprecated too
+	w = testutil.PerformRequest(t, suite.router, http.MethodGet, "/api/words/999999", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Deprecation"))
+
+	for _, path := range []string{"/api/v2/words", "/api/openapi.json"} {
+		w = httptest.NewRecorder()
+		suite.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Empty(t, w.Header().Get("Deprecation"), path)
+		assert.Empty(t, w.Header().Get("Sunset"), path)
+	}
+}
+
+// TestV1ContractTestSuite runs the test suite
+func TestV1ContractTestSuite(t *testing.T) {
+	suite.Run(t, new(V1ContractTestSuite))
+}
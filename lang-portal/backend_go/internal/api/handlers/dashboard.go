@@ -19,9 +19,9 @@ func NewDashboardHandler(dashboardService *service.DashboardService) *DashboardH
 }
 
 func (h *DashboardHandler) GetLastStudySession(c *gin.Context) {
-	session, err := h.dashboardService.GetLastStudySession()
+	session, err := h.dashboardService.GetLastStudySession(c.Request.Context())
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -36,19 +36,21 @@ func (h *DashboardHandler) GetLastStudySession(c *gin.Context) {
 }
 
 func (h *DashboardHandler) GetStudyProgress(c *gin.Context) {
-	progress, err := h.dashboardService.GetStudyProgress()
+	progress, err := h.dashboardService.GetStudyProgress(c.Request.Context())
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	utils.RespondWithJSON(c, http.StatusOK, progress)
 }
 
+// GetQuickStats counts the guest's study streak, the dashboard being theirs,
+// in the time zone named by the tz query parameter or of their daily goal
 func (h *DashboardHandler) GetQuickStats(c *gin.Context) {
-	stats, err := h.dashboardService.GetQuickStats()
+	stats, err := h.dashboardService.GetQuickStats(c.Request.Context(), 0, c.Query("tz"))
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
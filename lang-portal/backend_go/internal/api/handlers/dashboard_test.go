@@ -1,19 +1,20 @@
 package handlers_test
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/handlers"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/testutil"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -22,7 +23,6 @@ type DashboardHandlerTestSuite struct {
 	suite.Suite
 	router              *gin.Engine
 	db                  *database.TestDB
-	dashboardHandler    *handlers.DashboardHandler
 	testWords           []*models.Word
 	testGroups          []*models.Group
 	testStudyActivities []*models.StudyActivity
@@ -42,31 +42,8 @@ func (suite *DashboardHandlerTestSuite) SetupSuite() {
 		suite.T().Fatalf("Failed to create test database: %v", err)
 	}
 
-	// Initialize repositories
-	wordRepo := repository.NewWordRepository(suite.db.DB)
-	groupRepo := repository.NewGroupRepository(suite.db.DB)
-	studySessionRepo := repository.NewStudySessionRepository(suite.db.DB)
-	studyActivityRepo := repository.NewStudyActivityRepository(suite.db.DB)
-
-	// Initialize services
-	wordService := service.NewWordService(wordRepo)
-	groupService := service.NewGroupService(groupRepo)
-	studyActivityService := service.NewStudyActivityService(studyActivityRepo, studySessionRepo)
-	dashboardService := service.NewDashboardService(studySessionRepo, wordRepo, groupRepo)
-
-	// Initialize handlers
-	wordHandler := handlers.NewWordHandler(wordService)
-	groupHandler := handlers.NewGroupHandler(groupService)
-	studyActivityHandler := handlers.NewStudyActivityHandler(studyActivityService)
-	suite.dashboardHandler = handlers.NewDashboardHandler(dashboardService)
-
 	// Setup router
-	suite.router = api.SetupRouter(
-		suite.dashboardHandler,
-		studyActivityHandler,
-		wordHandler,
-		groupHandler,
-	)
+	suite.router = api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), suite.db.Store, nil))
 }
 
 // TearDownSuite tears down the test suite
@@ -110,7 +87,7 @@ func (suite *DashboardHandlerTestSuite) clearTestData() {
 
 	// Check if the word_review_items table exists
 	var tableExists int
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='word_review_items'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='word_review_items'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if word_review_items table exists: %v", err)
 	}
@@ -124,7 +101,7 @@ func (suite *DashboardHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the study_sessions table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='study_sessions'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='study_sessions'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if study_sessions table exists: %v", err)
 	}
@@ -138,7 +115,7 @@ func (suite *DashboardHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the study_activities table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='study_activities'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='study_activities'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if study_activities table exists: %v", err)
 	}
@@ -152,7 +129,7 @@ func (suite *DashboardHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the words_groups table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='words_groups'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='words_groups'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if words_groups table exists: %v", err)
 	}
@@ -166,7 +143,7 @@ func (suite *DashboardHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the groups table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='groups'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='groups'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if groups table exists: %v", err)
 	}
@@ -180,7 +157,7 @@ func (suite *DashboardHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the words table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='words'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='words'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if words table exists: %v", err)
 	}
@@ -501,6 +478,166 @@ func (suite *DashboardHandlerTestSuite) TestGetLastStudySessionEmpty() {
 	assert.Equal(suite.T(), "No study sessions found", response["message"])
 }
 
+// TestGetActivity tests the study activity endpoint
+func (suite *DashboardHandlerTestSuite) TestGetActivity() {
+	today := time.Now().UTC()
+	path := "/api/stats/activity?tz=UTC&from=" + today.AddDate(0, 0, -2).Format(time.DateOnly) + "&to=" + today.Format(time.DateOnly)
+	w := testutil.PerformRequest(suite.T(), suite.router, "GET", path, nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var response service.ActivityHistory
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), "UTC", response.TimeZone)
+	assert.Len(suite.T(), response.Days, 3)
+	sessions, reviews := 0, 0
+	for _, day := range response.Days {
+		sessions += day.Sessions
+		reviews += day.Reviews
+	}
+	assert.Equal(suite.T(), len(suite.testStudySessions), sessions)
+	assert.Equal(suite.T(), len(suite.testWordReviewItems), reviews)
+}
+
+// TestGetActivityInvalidTimeZone tests that unknown time zones are rejected
+func (suite *DashboardHandlerTestSuite) TestGetActivityInvalidTimeZone() {
+	w := testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/stats/activity?tz=Brazil/Nowhere", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+}
+
+// TestGetStreak tests the study streak endpoint
+func (suite *DashboardHandlerTestSuite) TestGetStreak() {
+	w := testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/stats/streak?tz=UTC", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	// The sessions were started yesterday and today
+	var response service.StudyStreak
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), "UTC", response.TimeZone)
+	assert.Equal(suite.T(), 2, response.Current)
+	assert.Equal(suite.T(), 2, response.Longest)
+	assert.True(suite.T(), response.StudiedToday)
+	assert.Empty(suite.T(), response.FrozenDays)
+
+	// The sessions are the guest's, so users have no streak of their own yet
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/users", map[string]string{"name": "Ana", "role": "student"})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusCreated)
+	var user models.User
+	testutil.ParseResponse(suite.T(), w, &user)
+	defer suite.db.DB.Exec("DELETE FROM users WHERE id = ?", user.ID)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/v2/users/%d/streak", user.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Zero(suite.T(), response.Longest)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/users/999999/streak", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/dashboard/quick-stats?tz=Nowhere", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+}
+
+// TestGetRetention tests the retention, group stats and word history endpoints
+func (suite *DashboardHandlerTestSuite) TestGetRetention() {
+	w := testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/stats/retention", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var report service.RetentionReport
+	testutil.ParseResponse(suite.T(), w, &report)
+	require.Len(suite.T(), report.Rates, 3)
+	assert.Equal(suite.T(), []int{1, 7, 30}, []int{report.Rates[0].Days, report.Rates[1].Days, report.Rates[2].Days})
+	assert.NotNil(suite.T(), report.Leeches)
+
+	group := suite.testGroups[0]
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/groups/%d/stats", group.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var stats service.GroupAnalytics
+	testutil.ParseResponse(suite.T(), w, &stats)
+	assert.Equal(suite.T(), group.ID, stats.GroupID)
+	assert.Equal(suite.T(), 2, stats.TotalWordCount)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/groups/%d/stats", group.ID+100), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+
+	// The first word was reviewed in both sessions, right both times
+	word := suite.testWords[0]
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/words/%d/history", word.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var history service.WordHistory
+	testutil.ParseResponse(suite.T(), w, &history)
+	assert.Equal(suite.T(), word.ID, history.WordID)
+	assert.Equal(suite.T(), 2, history.CorrectCount)
+	require.Len(suite.T(), history.Reviews, 2)
+	assert.Nil(suite.T(), history.Reviews[0].IntervalDays)
+	assert.NotNil(suite.T(), history.Reviews[1].IntervalDays)
+}
+
+// TestDailyGoalAndAchievements tests the daily goal and achievements endpoints
+func (suite *DashboardHandlerTestSuite) TestDailyGoalAndAchievements() {
+	defer suite.db.DB.Exec("DELETE FROM daily_goals")
+	defer suite.db.DB.Exec("DELETE FROM achievements")
+
+	w := testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/dashboard/daily_goal", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var goal service.DailyGoalStatus
+	testutil.ParseResponse(suite.T(), w, &goal)
+	assert.Equal(suite.T(), models.GoalReviews, goal.Kind)
+	assert.Equal(suite.T(), 20, goal.Target)
+
+	body := map[string]interface{}{"kind": "minutes", "target": 15, "time_zone": "Europe/Lisbon"}
+	w = testutil.PerformRequest(suite.T(), suite.router, "PUT", "/api/v2/dashboard/daily_goal", body)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &goal)
+	assert.Equal(suite.T(), models.GoalMinutes, goal.Kind)
+	assert.Equal(suite.T(), "Europe/Lisbon", goal.TimeZone)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "PUT", "/api/dashboard/daily_goal", map[string]interface{}{"kind": "pages", "target": 15})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+	// Users have their own goal under /users/:id
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/users", map[string]string{"name": "Ana", "role": "student"})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusCreated)
+	var user models.User
+	testutil.ParseResponse(suite.T(), w, &user)
+	defer suite.db.DB.Exec("DELETE FROM users WHERE id = ?", user.ID)
+	w = testutil.PerformRequest(suite.T(), suite.router, "PUT", fmt.Sprintf("/api/users/%d/daily_goal", user.ID), map[string]interface{}{"kind": "reviews", "target": 40})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/v2/users/%d/daily_goal", user.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &goal)
+	assert.Equal(suite.T(), 40, goal.Target)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/dashboard/daily_goal", nil)
+	testutil.ParseResponse(suite.T(), w, &goal)
+	assert.Equal(suite.T(), 15, goal.Target, "the guest's goal is their own")
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/users/abc/daily_goal", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/users/999999/daily_goal", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/users/999999/achievements", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+
+	// The guest's reviews were recorded before the rules ran, so their first
+	// steps are earned but not unlocked: listing achievements only reads them
+	for i := 0; i < 2; i++ {
+		w = testutil.PerformRequest(suite.T(), suite.router, "GET", "/api/dashboard/achievements", nil)
+		testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+		var report service.AchievementReport
+		testutil.ParseResponse(suite.T(), w, &report)
+		assert.Empty(suite.T(), report.Unlocked)
+		require.NotEmpty(suite.T(), report.InProgress)
+		assert.Equal(suite.T(), "first_review", report.InProgress[0].Key)
+		assert.Equal(suite.T(), 1, report.InProgress[0].Progress)
+		assert.Nil(suite.T(), report.InProgress[0].UnlockedAt)
+	}
+	var unlocked int
+	require.NoError(suite.T(), suite.db.DB.QueryRow("SELECT COUNT(*) FROM achievements").Scan(&unlocked))
+	assert.Zero(suite.T(), unlocked)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "GET", fmt.Sprintf("/api/users/%d/achievements", user.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var report service.AchievementReport
+	testutil.ParseResponse(suite.T(), w, &report)
+	assert.Zero(suite.T(), report.XP)
+	assert.Empty(suite.T(), report.Unlocked)
+	assert.Zero(suite.T(), report.InProgress[0].Progress)
+}
+
 // TestMain runs the test suite
 func TestDashboardHandlerSuite(t *testing.T) {
 	suite.Run(t, new(DashboardHandlerTestSuite))
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GamificationHandler serves the daily goal and achievements of a user on
+// the /users/:id routes, and of the guest on the dashboard's
+type GamificationHandler struct {
+	gamificationService *service.GamificationService
+}
+
+func NewGamificationHandler(gamificationService *service.GamificationService) *GamificationHandler {
+	return &GamificationHandler{
+		gamificationService: gamificationService,
+	}
+}
+
+// learnerID returns the learner a request is for: the user named by the id
+// path parameter, or the guest on the routes without one
+func learnerID(c *gin.Context) (int64, bool) {
+	if c.Param("id") == "" {
+		return 0, true
+	}
+	return request.ParseID(c, "id")
+}
+
+// DailyGoalRequest is the body for setting a daily goal
+type DailyGoalRequest struct {
+	Kind     models.GoalKind `json:"kind" binding:"required"`
+	Target   int             `json:"target" binding:"required"`
+	TimeZone string          `json:"time_zone"`
+}
+
+// GetDailyGoal returns the progress towards the daily goal today
+func (h *GamificationHandler) GetDailyGoal(c *gin.Context) {
+	userID, ok := learnerID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.gamificationService.GetDailyGoal(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, status)
+}
+
+// SetDailyGoal sets the daily goal and returns the progress towards it today
+func (h *GamificationHandler) SetDailyGoal(c *gin.Context) {
+	userID, ok := learnerID(c)
+	if !ok {
+		return
+	}
+	var req DailyGoalRequest
+	if !request.BindJSON(c, &req) {
+		return
+	}
+
+	status, err := h.gamificationService.SetDailyGoal(c.Request.Context(), userID, service.DailyGoalInput{
+		Kind:     req.Kind,
+		Target:   req.Target,
+		TimeZone: req.TimeZone,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, status)
+}
+
+// GetAchievements returns the XP and the unlocked and in progress
+// achievements
+func (h *GamificationHandler) GetAchievements(c *gin.Context) {
+	userID, ok := learnerID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.gamificationService.GetAchievements(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, report)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
 	"github.com/gin-gonic/gin"
@@ -31,9 +32,9 @@ func (h *GroupHandler) ListGroups(c *gin.Context) {
 	}
 
 	// Get paginated groups
-	groups, totalCount, err := h.groupService.ListGroupsPaginated(page, pageSize)
+	groups, totalCount, err := h.groupService.ListGroupsPaginated(c.Request.Context(), page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -55,24 +56,22 @@ func (h *GroupHandler) ListGroups(c *gin.Context) {
 }
 
 func (h *GroupHandler) GetGroup(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
-	group, err := h.groupService.GetGroup(id)
+	group, err := h.groupService.GetGroup(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, group)
 }
 
 func (h *GroupHandler) GetGroupWords(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
@@ -88,9 +87,9 @@ func (h *GroupHandler) GetGroupWords(c *gin.Context) {
 	}
 
 	// Get paginated group words with stats
-	words, totalCount, err := h.groupService.GetGroupWordsPaginated(id, page, pageSize)
+	words, totalCount, err := h.groupService.GetGroupWordsPaginated(c.Request.Context(), id, page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -112,91 +111,82 @@ func (h *GroupHandler) GetGroupWords(c *gin.Context) {
 }
 
 func (h *GroupHandler) CreateGroup(c *gin.Context) {
-	var group models.Group
-	if err := c.ShouldBindJSON(&group); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.GroupInput
+	if !request.BindJSON(c, &input) {
 		return
 	}
 
-	createdGroup, err := h.groupService.CreateGroup(&group)
+	createdGroup, err := h.groupService.CreateGroup(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, createdGroup)
 }
 
 func (h *GroupHandler) UpdateGroup(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
-	var group models.Group
-	if err := c.ShouldBindJSON(&group); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.GroupInput
+	if !request.BindJSON(c, &input) {
 		return
 	}
-	group.ID = id
 
-	updatedGroup, err := h.groupService.UpdateGroup(&group)
+	updatedGroup, err := h.groupService.UpdateGroup(c.Request.Context(), id, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, updatedGroup)
 }
 
 func (h *GroupHandler) DeleteGroup(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.groupService.DeleteGroup(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.groupService.DeleteGroup(c.Request.Context(), id); err != nil {
+		c.Error(err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
 
 func (h *GroupHandler) AddWordsToGroup(c *gin.Context) {
-	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	groupID, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var wordIDs []int64
-	if err := c.ShouldBindJSON(&wordIDs); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !request.BindJSON(c, &wordIDs) {
 		return
 	}
 
-	if err := h.groupService.AddWordsToGroup(groupID, wordIDs); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.groupService.AddWordsToGroup(c.Request.Context(), groupID, wordIDs); err != nil {
+		c.Error(err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
 
 func (h *GroupHandler) RemoveWordFromGroup(c *gin.Context) {
-	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	groupID, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
-	wordID, err := strconv.ParseInt(c.Param("word_id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid word ID"})
+	wordID, ok := request.ParseID(c, "word_id")
+	if !ok {
 		return
 	}
 
-	if err := h.groupService.RemoveWordFromGroup(groupID, wordID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.groupService.RemoveWordFromGroup(c.Request.Context(), groupID, wordID); err != nil {
+		c.Error(err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -204,9 +194,8 @@ func (h *GroupHandler) RemoveWordFromGroup(c *gin.Context) {
 
 // GetGroupStudySessions returns a paginated list of study sessions for a group
 func (h *GroupHandler) GetGroupStudySessions(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
@@ -4,13 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/handlers"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/testutil"
 	"github.com/gin-gonic/gin"
@@ -21,11 +22,10 @@ import (
 // GroupHandlerTestSuite is a test suite for the group handlers
 type GroupHandlerTestSuite struct {
 	suite.Suite
-	router       *gin.Engine
-	db           *database.TestDB
-	groupHandler *handlers.GroupHandler
-	testGroups   []*models.Group
-	testWords    []*models.Word
+	router     *gin.Engine
+	db         *database.TestDB
+	testGroups []*models.Group
+	testWords  []*models.Word
 }
 
 // SetupSuite sets up the test suite
@@ -40,31 +40,8 @@ func (suite *GroupHandlerTestSuite) SetupSuite() {
 		suite.T().Fatalf("Failed to create test database: %v", err)
 	}
 
-	// Initialize repositories
-	wordRepo := repository.NewWordRepository(suite.db.DB)
-	groupRepo := repository.NewGroupRepository(suite.db.DB)
-	studySessionRepo := repository.NewStudySessionRepository(suite.db.DB)
-	studyActivityRepo := repository.NewStudyActivityRepository(suite.db.DB)
-
-	// Initialize services
-	wordService := service.NewWordService(wordRepo)
-	groupService := service.NewGroupService(groupRepo)
-	studyActivityService := service.NewStudyActivityService(studyActivityRepo, studySessionRepo)
-	dashboardService := service.NewDashboardService(studySessionRepo, wordRepo, groupRepo)
-
-	// Initialize handlers
-	wordHandler := handlers.NewWordHandler(wordService)
-	suite.groupHandler = handlers.NewGroupHandler(groupService)
-	studyActivityHandler := handlers.NewStudyActivityHandler(studyActivityService)
-	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
-
 	// Setup router
-	suite.router = api.SetupRouter(
-		dashboardHandler,
-		studyActivityHandler,
-		wordHandler,
-		suite.groupHandler,
-	)
+	suite.router = api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), suite.db.Store, nil))
 }
 
 // TearDownSuite tears down the test suite
@@ -105,7 +82,7 @@ func (suite *GroupHandlerTestSuite) clearTestData() {
 
 	// Check if the words_groups table exists
 	var tableExists int
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='words_groups'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='words_groups'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if words_groups table exists: %v", err)
 	}
@@ -119,7 +96,7 @@ func (suite *GroupHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the groups table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='groups'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='groups'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if groups table exists: %v", err)
 	}
@@ -133,7 +110,7 @@ func (suite *GroupHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the words table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='words'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='words'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if words table exists: %v", err)
 	}
@@ -288,6 +265,78 @@ func (suite *GroupHandlerTestSuite) TestGetGroupNotFound() {
 
 	// Check the status code - should be 404 for non-existent group
 	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+
+	// Parse the response
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+
+	// Verify the error body
+	assert.Equal(suite.T(), service.CodeNotFound, response.Code)
+	assert.Equal(suite.T(), "group not found", response.Message)
+	assert.NotEmpty(suite.T(), response.RequestID)
+}
+
+// TestAddWordsToGroupDuplicate tests that adding a word twice to a group is a conflict
+func (suite *GroupHandlerTestSuite) TestAddWordsToGroupDuplicate() {
+	// The first test word is already in the first test group
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"POST",
+		fmt.Sprintf("/api/groups/%d/words", suite.testGroups[0].ID),
+		[]int64{suite.testWords[0].ID},
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusConflict)
+
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), service.CodeConflict, response.Code)
+	assert.NotContains(suite.T(), response.Message, "UNIQUE")
+}
+
+// TestAddWordsToGroupUnknownWord tests that nonexistent word IDs are rejected
+// before anything is written
+func (suite *GroupHandlerTestSuite) TestAddWordsToGroupUnknownWord() {
+	group := suite.testGroups[1]
+
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"POST",
+		fmt.Sprintf("/api/groups/%d/words", group.ID),
+		[]int64{suite.testWords[2].ID, 9999},
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), service.CodeValidation, response.Code)
+	assert.Equal(suite.T(), []service.FieldError{{Field: "word_ids[1]", Message: "does not exist"}}, response.Fields)
+
+	// The valid word was not added either
+	var count int
+	err := suite.db.DB.QueryRow("SELECT COUNT(*) FROM words_groups WHERE group_id = ? AND word_id = ?",
+		group.ID, suite.testWords[2].ID).Scan(&count)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, count)
+}
+
+// TestAddWordsToGroupInvalidIDs tests validation of the word ID list itself
+func (suite *GroupHandlerTestSuite) TestAddWordsToGroupInvalidIDs() {
+	path := fmt.Sprintf("/api/groups/%d/words", suite.testGroups[1].ID)
+
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", path, []int64{})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), []service.FieldError{{Field: "word_ids", Message: "must not be empty"}}, response.Fields)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", path, []int64{suite.testWords[2].ID, suite.testWords[2].ID})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), []service.FieldError{{Field: "word_ids[1]", Message: "is a duplicate"}}, response.Fields)
 }
 
 // TestGetGroupWords tests the GetGroupWords endpoint
@@ -359,6 +408,55 @@ func (suite *GroupHandlerTestSuite) TestCreateGroup() {
 	assert.Equal(suite.T(), 1, count)
 }
 
+// TestCreateGroupValidation tests that a blank group name is rejected
+func (suite *GroupHandlerTestSuite) TestCreateGroupValidation() {
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/groups", map[string]string{"name": " \t "})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+
+	assert.Equal(suite.T(), service.CodeValidation, response.Code)
+	assert.Equal(suite.T(), []service.FieldError{{Field: "name", Message: "must not be empty"}}, response.Fields)
+}
+
+// TestCreateGroupDuplicateName tests that group names are unique, ignoring case
+func (suite *GroupHandlerTestSuite) TestCreateGroupDuplicateName() {
+	name := strings.ToUpper(suite.testGroups[0].Name)
+
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/groups", map[string]string{"name": name})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusConflict)
+
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+
+	assert.Equal(suite.T(), service.CodeConflict, response.Code)
+	assert.Equal(suite.T(), []service.FieldError{{Field: "name", Message: "is already taken"}}, response.Fields)
+}
+
+// TestUpdateGroupDuplicateName tests that a group can't be renamed to another group's name
+func (suite *GroupHandlerTestSuite) TestUpdateGroupDuplicateName() {
+	// Renaming a group to another group's name is rejected
+	w := testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"PUT",
+		fmt.Sprintf("/api/groups/%d", suite.testGroups[0].ID),
+		map[string]string{"name": suite.testGroups[1].Name},
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusConflict)
+
+	// Keeping its own name is fine
+	w = testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"PUT",
+		fmt.Sprintf("/api/groups/%d", suite.testGroups[0].ID),
+		map[string]string{"name": suite.testGroups[0].Name},
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+}
+
 // TestUpdateGroup tests the UpdateGroup endpoint
 func (suite *GroupHandlerTestSuite) TestUpdateGroup() {
 	// Update the first test group
@@ -508,6 +606,16 @@ func (suite *GroupHandlerTestSuite) TestRemoveWordFromGroup() {
 
 	// Verify that the remaining word is the second word
 	assert.Equal(suite.T(), suite.testWords[1].ID, words[0].ID)
+
+	// The word is no longer in the group
+	w = testutil.PerformRequest(
+		suite.T(),
+		suite.router,
+		"DELETE",
+		fmt.Sprintf("/api/groups/%d/words/%d", group.ID, word.ID),
+		nil,
+	)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
 }
 
 // TestMain runs the test suite
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LeaderboardHandler serves the classroom leaderboards and the leaderboard
+// profiles of users
+type LeaderboardHandler struct {
+	leaderboardService *service.LeaderboardService
+}
+
+func NewLeaderboardHandler(leaderboardService *service.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{
+		leaderboardService: leaderboardService,
+	}
+}
+
+// LeaderboardProfileRequest is the body for setting a leaderboard profile
+type LeaderboardProfileRequest struct {
+	DisplayName string `json:"display_name"`
+	OptOut      bool   `json:"opt_out"`
+}
+
+// GetLeaderboard returns the leaderboard of a classroom by the metric and
+// period query parameters
+func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	board, err := h.leaderboardService.GetLeaderboard(c.Request.Context(), id,
+		models.LeaderboardMetric(c.Query("metric")), models.LeaderboardPeriod(c.Query("period")))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, board)
+}
+
+// GetProfile returns how a user appears on leaderboards
+func (h *LeaderboardHandler) GetProfile(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	profile, err := h.leaderboardService.GetLeaderboardProfile(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, profile)
+}
+
+// SetProfile sets how a user appears on leaderboards
+func (h *LeaderboardHandler) SetProfile(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	var req LeaderboardProfileRequest
+	if !request.BindJSON(c, &req) {
+		return
+	}
+
+	profile, err := h.leaderboardService.SetLeaderboardProfile(c.Request.Context(), id, service.LeaderboardProfileInput{
+		DisplayName: req.DisplayName,
+		OptOut:      req.OptOut,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, profile)
+}
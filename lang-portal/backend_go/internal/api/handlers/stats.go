@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type StatsHandler struct {
+	statsService     *service.StatsService
+	analyticsService *service.AnalyticsService
+}
+
+func NewStatsHandler(statsService *service.StatsService, analyticsService *service.AnalyticsService) *StatsHandler {
+	return &StatsHandler{
+		statsService:     statsService,
+		analyticsService: analyticsService,
+	}
+}
+
+// GetActivity returns the study activity of each day between the from and
+// to query parameters, in the time zone named by tz
+func (h *StatsHandler) GetActivity(c *gin.Context) {
+	activity, err := h.statsService.GetActivity(c.Request.Context(), service.ActivityRange{
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		TimeZone: c.Query("tz"),
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, activity)
+}
+
+// GetStreak returns the study streak of a user on /users/:id/streak, or of
+// the guest, in the time zone named by the tz query parameter or of their
+// daily goal
+func (h *StatsHandler) GetStreak(c *gin.Context) {
+	userID, ok := learnerID(c)
+	if !ok {
+		return
+	}
+
+	streak, err := h.statsService.GetStreak(c.Request.Context(), userID, c.Query("tz"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, streak)
+}
+
+// GetRetention returns the retention of all words and the leeches
+func (h *StatsHandler) GetRetention(c *gin.Context) {
+	report, err := h.analyticsService.GetRetention(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, report)
+}
+
+// GetGroupStats returns the retention of the words of a group and its leeches
+func (h *StatsHandler) GetGroupStats(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	stats, err := h.analyticsService.GetGroupStats(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, stats)
+}
+
+// GetWordHistory returns the reviews of a word and its retention
+func (h *StatsHandler) GetWordHistory(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	history, err := h.analyticsService.GetWordHistory(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, history)
+}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/pkg/utils"
@@ -21,20 +23,14 @@ func NewStudyActivityHandler(studyActivityService *service.StudyActivityService)
 }
 
 func (h *StudyActivityHandler) GetStudyActivity(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		utils.RespondWithError(c, http.StatusBadRequest, "Invalid activity ID")
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
-	activity, err := h.studyActivityService.GetStudyActivity(id)
+	activity, err := h.studyActivityService.GetStudyActivity(c.Request.Context(), id)
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	if activity == nil {
-		utils.RespondWithError(c, http.StatusNotFound, "Activity not found")
+		c.Error(err)
 		return
 	}
 
@@ -42,16 +38,15 @@ func (h *StudyActivityHandler) GetStudyActivity(c *gin.Context) {
 }
 
 func (h *StudyActivityHandler) GetStudyActivitySessions(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		utils.RespondWithError(c, http.StatusBadRequest, "Invalid activity ID")
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
 	page, perPage := utils.GetPaginationFromContext(c, 100)
-	sessions, total, err := h.studyActivityService.GetStudyActivitySessions(id, page, perPage)
+	sessions, total, err := h.studyActivityService.GetStudyActivitySessions(c.Request.Context(), id, page, perPage)
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -60,31 +55,95 @@ func (h *StudyActivityHandler) GetStudyActivitySessions(c *gin.Context) {
 }
 
 type CreateStudySessionRequest struct {
-	GroupID         int64 `json:"group_id" binding:"required"`
-	StudyActivityID int64 `json:"study_activity_id" binding:"required"`
+	GroupID         int64  `json:"group_id" binding:"required"`
+	StudyActivityID int64  `json:"study_activity_id" binding:"required"`
+	UserID          *int64 `json:"user_id"`
 }
 
 func (h *StudyActivityHandler) CreateStudySession(c *gin.Context) {
 	var req CreateStudySessionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	if !request.BindJSON(c, &req) {
 		return
 	}
 
-	session, err := h.studyActivityService.CreateStudySession(req.GroupID, req.StudyActivityID)
+	session, err := h.studyActivityService.CreateStudySession(c.Request.Context(), req.GroupID, req.StudyActivityID, req.UserID)
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	utils.RespondWithJSON(c, http.StatusCreated, session)
 }
 
+// ReviewRequest is the body for recording a review. Correct is a pointer so
+// that a missing value is rejected rather than read as false.
+type ReviewRequest struct {
+	Correct *bool `json:"correct" binding:"required"`
+}
+
+// ReviewResponse is returned once a review is recorded
+type ReviewResponse struct {
+	Success        bool      `json:"success"`
+	WordID         int64     `json:"word_id"`
+	StudySessionID int64     `json:"study_session_id"`
+	Correct        bool      `json:"correct"`
+	XP             int       `json:"xp"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RecordReview records whether a word was answered correctly in a study session
+func (h *StudyActivityHandler) RecordReview(c *gin.Context) {
+	sessionID, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	wordID, ok := request.ParseID(c, "word_id")
+	if !ok {
+		return
+	}
+	var req ReviewRequest
+	if !request.BindJSON(c, &req) {
+		return
+	}
+
+	review, err := h.studyActivityService.RecordReview(c.Request.Context(), sessionID, wordID, *req.Correct)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, ReviewResponse{
+		Success:        true,
+		WordID:         review.WordID,
+		StudySessionID: review.StudySessionID,
+		Correct:        review.Correct,
+		XP:             review.XP,
+		CreatedAt:      review.CreatedAt,
+	})
+}
+
+// EndStudySession ends a study session and lists the achievements it
+// unlocked
+func (h *StudyActivityHandler) EndStudySession(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	end, err := h.studyActivityService.EndStudySession(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, end)
+}
+
 // ListStudyActivities returns a list of all study activities
 func (h *StudyActivityHandler) ListStudyActivities(c *gin.Context) {
-	activities, err := h.studyActivityService.ListStudyActivities()
+	activities, err := h.studyActivityService.ListStudyActivities(c.Request.Context())
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch study activities")
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, activities)
@@ -107,18 +166,16 @@ func (h *StudyActivityHandler) ListStudySessions(c *gin.Context) {
 	offset := (page - 1) * pageSize
 
 	// Fetch study sessions
-	sessions, err := h.studyActivityService.ListStudySessions(offset, pageSize)
+	sessions, err := h.studyActivityService.ListStudySessions(c.Request.Context(), offset, pageSize)
 	if err != nil {
-		// Log the error for debugging
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch study sessions: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
 	// Count total study sessions for pagination
-	total, err := h.studyActivityService.CountStudySessions()
+	total, err := h.studyActivityService.CountStudySessions(c.Request.Context())
 	if err != nil {
-		// Log the error for debugging
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count study sessions: " + err.Error()})
+		c.Error(err)
 		return
 	}
 
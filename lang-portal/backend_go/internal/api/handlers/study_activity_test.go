@@ -8,9 +8,10 @@ import (
 
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/handlers"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/testutil"
 	"github.com/gin-gonic/gin"
@@ -21,12 +22,11 @@ import (
 // StudyActivityHandlerTestSuite is a test suite for the study activity handlers
 type StudyActivityHandlerTestSuite struct {
 	suite.Suite
-	router               *gin.Engine
-	db                   *database.TestDB
-	studyActivityHandler *handlers.StudyActivityHandler
-	testStudyActivities  []*models.StudyActivity
-	testGroups           []*models.Group
-	testStudySessions    []*models.StudySession
+	router              *gin.Engine
+	db                  *database.TestDB
+	testStudyActivities []*models.StudyActivity
+	testGroups          []*models.Group
+	testStudySessions   []*models.StudySession
 }
 
 // SetupSuite sets up the test suite
@@ -41,31 +41,8 @@ func (suite *StudyActivityHandlerTestSuite) SetupSuite() {
 		suite.T().Fatalf("Failed to create test database: %v", err)
 	}
 
-	// Initialize repositories
-	wordRepo := repository.NewWordRepository(suite.db.DB)
-	groupRepo := repository.NewGroupRepository(suite.db.DB)
-	studySessionRepo := repository.NewStudySessionRepository(suite.db.DB)
-	studyActivityRepo := repository.NewStudyActivityRepository(suite.db.DB)
-
-	// Initialize services
-	wordService := service.NewWordService(wordRepo)
-	groupService := service.NewGroupService(groupRepo)
-	studyActivityService := service.NewStudyActivityService(studyActivityRepo, studySessionRepo)
-	dashboardService := service.NewDashboardService(studySessionRepo, wordRepo, groupRepo)
-
-	// Initialize handlers
-	wordHandler := handlers.NewWordHandler(wordService)
-	groupHandler := handlers.NewGroupHandler(groupService)
-	suite.studyActivityHandler = handlers.NewStudyActivityHandler(studyActivityService)
-	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
-
 	// Setup router
-	suite.router = api.SetupRouter(
-		dashboardHandler,
-		suite.studyActivityHandler,
-		wordHandler,
-		groupHandler,
-	)
+	suite.router = api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), suite.db.Store, nil))
 }
 
 // TearDownSuite tears down the test suite
@@ -107,7 +84,7 @@ func (suite *StudyActivityHandlerTestSuite) clearTestData() {
 
 	// Check if the study_sessions table exists
 	var tableExists int
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='study_sessions'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='study_sessions'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if study_sessions table exists: %v", err)
 	}
@@ -121,7 +98,7 @@ func (suite *StudyActivityHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the study_activities table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='study_activities'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='study_activities'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if study_activities table exists: %v", err)
 	}
@@ -135,7 +112,7 @@ func (suite *StudyActivityHandlerTestSuite) clearTestData() {
 	}
 
 	// Check if the groups table exists
-	err = suite.db.DB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='groups'").Scan(&tableExists)
+	err = tx.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='groups'").Scan(&tableExists)
 	if err != nil {
 		suite.T().Fatalf("Failed to check if groups table exists: %v", err)
 	}
@@ -279,6 +256,14 @@ func (suite *StudyActivityHandlerTestSuite) TestGetStudyActivityNotFound() {
 
 	// Check the status code - should be 404 for non-existent activity
 	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+
+	// Parse the response
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+
+	// Verify the error body
+	assert.Equal(suite.T(), service.CodeNotFound, response.Code)
+	assert.Equal(suite.T(), "study activity not found", response.Message)
 }
 
 // TestGetStudyActivitySessions tests the GetStudyActivitySessions endpoint
@@ -377,6 +362,93 @@ func (suite *StudyActivityHandlerTestSuite) TestCreateStudySessionInvalidPayload
 
 	// Check the status code - should be 400 for invalid payload
 	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+
+	// Parse the response
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+
+	// Verify the missing field is reported
+	assert.Equal(suite.T(), service.CodeValidation, response.Code)
+	assert.Equal(suite.T(), []service.FieldError{{Field: "study_activity_id", Message: "is required"}}, response.Fields)
+}
+
+// TestCreateStudySessionMissingReferences tests that sessions on a group or
+// study activity that doesn't exist are not found rather than failing
+func (suite *StudyActivityHandlerTestSuite) TestCreateStudySessionMissingReferences() {
+	for _, payload := range []map[string]int64{
+		{"group_id": 999999, "study_activity_id": suite.testStudyActivities[0].ID},
+		{"group_id": suite.testGroups[0].ID, "study_activity_id": 999999},
+	} {
+		w := testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/v2/study_sessions", payload)
+		testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+	}
+}
+
+// TestRecordReview tests recording reviews and the errors for unknown sessions and words
+func (suite *StudyActivityHandlerTestSuite) TestRecordReview() {
+	result, err := suite.db.DB.Exec("INSERT INTO words (portuguese, english) VALUES ('olá', 'hello')")
+	suite.Require().NoError(err)
+	wordID, _ := result.LastInsertId()
+	defer suite.db.DB.Exec("DELETE FROM words")
+	defer suite.db.DB.Exec("DELETE FROM word_review_items")
+
+	sessionID := suite.testStudySessions[0].ID
+	path := fmt.Sprintf("/api/study_sessions/%d/words/%d/review", sessionID, wordID)
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", path, map[string]bool{"correct": false})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+
+	var response handlers.ReviewResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.True(suite.T(), response.Success)
+	assert.Equal(suite.T(), wordID, response.WordID)
+	assert.Equal(suite.T(), sessionID, response.StudySessionID)
+	assert.False(suite.T(), response.Correct)
+	assert.False(suite.T(), response.CreatedAt.IsZero())
+
+	var correct bool
+	err = suite.db.DB.QueryRow("SELECT correct FROM word_review_items WHERE study_session_id = ? AND word_id = ?", sessionID, wordID).Scan(&correct)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), correct)
+
+	// correct is required, so an empty body isn't read as a wrong answer
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", path, map[string]bool{})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", fmt.Sprintf("/api/study_sessions/999999/words/%d/review", wordID), map[string]bool{"correct": true})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", fmt.Sprintf("/api/study_sessions/%d/words/999999/review", sessionID), map[string]bool{"correct": true})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+}
+
+// TestEndStudySession tests ending a study session, after which it takes no reviews
+func (suite *StudyActivityHandlerTestSuite) TestEndStudySession() {
+	result, err := suite.db.DB.Exec("INSERT INTO words (portuguese, english) VALUES ('olá', 'hello')")
+	suite.Require().NoError(err)
+	wordID, _ := result.LastInsertId()
+	defer suite.db.DB.Exec("DELETE FROM words")
+	defer suite.db.DB.Exec("DELETE FROM word_review_items")
+	defer suite.db.DB.Exec("DELETE FROM achievements")
+
+	sessionID := suite.testStudySessions[0].ID
+	review := fmt.Sprintf("/api/study_sessions/%d/words/%d/review", sessionID, wordID)
+	w := testutil.PerformRequest(suite.T(), suite.router, "POST", review, map[string]bool{"correct": true})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var reviewed handlers.ReviewResponse
+	testutil.ParseResponse(suite.T(), w, &reviewed)
+	assert.Equal(suite.T(), models.XPPerReview, reviewed.XP)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", fmt.Sprintf("/api/v2/study_sessions/%d/end", sessionID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var end service.SessionEnd
+	testutil.ParseResponse(suite.T(), w, &end)
+	assert.Equal(suite.T(), sessionID, end.StudySessionID)
+	assert.False(suite.T(), end.EndedAt.IsZero())
+	assert.NotNil(suite.T(), end.Unlocked)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", review, map[string]bool{"correct": true})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusConflict)
+	w = testutil.PerformRequest(suite.T(), suite.router, "POST", "/api/study_sessions/999999/end", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
 }
 
 // TestMain runs the test suite
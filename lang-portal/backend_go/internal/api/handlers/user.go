@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	userService      *service.UserService
+	classroomService *service.ClassroomService
+}
+
+func NewUserHandler(userService *service.UserService, classroomService *service.ClassroomService) *UserHandler {
+	return &UserHandler{
+		userService:      userService,
+		classroomService: classroomService,
+	}
+}
+
+type CreateUserRequest struct {
+	Name string `json:"name" binding:"required"`
+	Role string `json:"role" binding:"omitempty,oneof=student teacher"`
+}
+
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if !request.BindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.userService.CreateUser(c.Request.Context(), &models.User{Name: req.Name, Role: req.Role})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusCreated, user)
+}
+
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUser(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, user)
+}
+
+// ListUserAssignments returns the student view of their assignments and progress
+func (h *UserHandler) ListUserAssignments(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	assignments, err := h.classroomService.ListStudentAssignments(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, assignments)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
 	"github.com/gin-gonic/gin"
@@ -31,9 +32,9 @@ func (h *WordHandler) ListWords(c *gin.Context) {
 	}
 
 	// Get paginated words with stats
-	words, totalCount, err := h.wordService.ListWordsWithStatsPaginated(page, pageSize)
+	words, totalCount, err := h.wordService.ListWordsWithStatsPaginated(c.Request.Context(), page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -55,67 +56,61 @@ func (h *WordHandler) ListWords(c *gin.Context) {
 }
 
 func (h *WordHandler) GetWord(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid word ID"})
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
 	// Get word with stats and groups
-	word, err := h.wordService.GetWordDetail(id)
+	word, err := h.wordService.GetWordDetail(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "word not found"})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, word)
 }
 
 func (h *WordHandler) CreateWord(c *gin.Context) {
-	var word models.Word
-	if err := c.ShouldBindJSON(&word); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.WordInput
+	if !request.BindJSON(c, &input) {
 		return
 	}
 
-	createdWord, err := h.wordService.CreateWord(&word)
+	createdWord, err := h.wordService.CreateWord(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, createdWord)
 }
 
 func (h *WordHandler) UpdateWord(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid word ID"})
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
-	var word models.Word
-	if err := c.ShouldBindJSON(&word); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input models.WordInput
+	if !request.BindJSON(c, &input) {
 		return
 	}
-	word.ID = id
 
-	updatedWord, err := h.wordService.UpdateWord(&word)
+	updatedWord, err := h.wordService.UpdateWord(c.Request.Context(), id, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, updatedWord)
 }
 
 func (h *WordHandler) DeleteWord(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid word ID"})
+	id, ok := request.ParseID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.wordService.DeleteWord(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.wordService.DeleteWord(c.Request.Context(), id); err != nil {
+		c.Error(err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/buildinfo"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds the checks of one readiness probe
+const readinessTimeout = 2 * time.Second
+
+// Statuses reported by the health endpoints
+const (
+	statusOK          = "ok"
+	statusFailing     = "failing"
+	statusUnavailable = "unavailable"
+)
+
+// HealthStatus is the body of /healthz
+type HealthStatus struct {
+	Status string `json:"status"`
+}
+
+// CheckResult is the outcome of one readiness check
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ReadinessReport is the body of /readyz. Status is "ok" only when every
+// check passed.
+type ReadinessReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// VersionInfo describes the running build and the database it serves
+type VersionInfo struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit"`
+	SchemaVersion string `json:"schema_version"`
+	GoVersion     string `json:"go_version"`
+}
+
+// serveHealthz reports that the process is up. It doesn't touch the
+// database, so a slow database doesn't get the process restarted.
+func serveHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthStatus{Status: statusOK})
+}
+
+// serveReadyz checks that db can serve requests: it answers, its schema is
+// up to date and its directory is writable. It responds 503 when any check
+// fails.
+func serveReadyz(db *sql.DB) gin.HandlerFunc {
+	checks := []struct {
+		name  string
+		check func(ctx context.Context) error
+	}{
+		{"database", db.PingContext},
+		{"migrations", func(ctx context.Context) error {
+			pending, err := database.PendingMigrations(ctx, db)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending: %s", len(pending), strings.Join(pending, ", "))
+			}
+			return nil
+		}},
+		{"disk", func(ctx context.Context) error {
+			dir, err := database.Dir(ctx, db)
+			if err != nil || dir == "" {
+				return err
+			}
+			return database.CheckWritable(dir)
+		}},
+	}
+
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		report := ReadinessReport{Status: statusOK, Checks: make(map[string]CheckResult, len(checks))}
+		for _, check := range checks {
+			if err := check.check(ctx); err != nil {
+				report.Status = statusUnavailable
+				report.Checks[check.name] = CheckResult{Status: statusFailing, Error: err.Error()}
+				continue
+			}
+			report.Checks[check.name] = CheckResult{Status: statusOK}
+		}
+
+		status := http.StatusOK
+		if report.Status != statusOK {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
+
+// serveVersion reports the build and schema versions
+func serveVersion(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schema, err := database.SchemaVersion(c.Request.Context(), db)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, VersionInfo{
+			Version:       buildinfo.Version,
+			Commit:        buildinfo.GitCommit(),
+			SchemaVersion: schema,
+			GoVersion:     buildinfo.GoVersion(),
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/buildinfo"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/dialect"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDBRouter returns a router over a fresh, migrated database
+func newTestDBRouter(t *testing.T) (*gin.Engine, *database.TestDB) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewTestDB()
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	return api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), db.Store, nil)), db
+}
+
+func get(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+// TestHealthz tests the liveness probe
+func TestHealthz(t *testing.T) {
+	w := get(newRouter(), "/healthz")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+// TestReadyz tests that each readiness check can fail the probe
+func TestReadyz(t *testing.T) {
+	router, db := newTestDBRouter(t)
+
+	var report api.ReadinessReport
+	w := get(router, "/readyz")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, "ok", report.Status)
+	assert.Equal(t, map[string]api.CheckResult{
+		"database":   {Status: "ok"},
+		"migrations": {Status: "ok"},
+		"disk":       {Status: "ok"},
+	}, report.Checks)
+
+	// Forget the last migration, as if the binary were newer than the schema
+	migrations := database.Migrations(dialect.SQLite)
+	last := migrations[len(migrations)-1]
+	_, err := db.DB.Exec("DELETE FROM migrations WHERE name = ?", last)
+	require.NoError(t, err)
+
+	report = api.ReadinessReport{}
+	w = get(router, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, "unavailable", report.Status)
+	assert.Equal(t, "failing", report.Checks["migrations"].Status)
+	assert.Contains(t, report.Checks["migrations"].Error, last)
+	assert.Equal(t, "ok", report.Checks["database"].Status)
+
+	db.DB.Close()
+	report = api.ReadinessReport{}
+	w = get(router, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, "failing", report.Checks["database"].Status)
+}
+
+// TestVersion tests the build and schema versions
+func TestVersion(t *testing.T) {
+	router, _ := newTestDBRouter(t)
+
+	w := get(router, "/api/version")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var info api.VersionInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+
+	migrations := database.Migrations(dialect.SQLite)
+	assert.Equal(t, buildinfo.Version, info.Version)
+	assert.NotEmpty(t, info.Commit)
+	assert.Equal(t, strings.TrimSuffix(migrations[len(migrations)-1], ".sql"), info.SchemaVersion)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+
+	// The version endpoint isn't part of the deprecated v1 API
+	assert.Empty(t, w.Header().Get("Deprecation"))
+}
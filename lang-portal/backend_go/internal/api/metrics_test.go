@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/metrics"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetrics tests that requests, queries and study activity show up in a scrape
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewTestDB()
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	result, err := db.DB.Exec("INSERT INTO groups (name) VALUES ('Greetings')")
+	require.NoError(t, err)
+	groupID, _ := result.LastInsertId()
+	result, err = db.DB.Exec("INSERT INTO study_activities (name, thumbnail_url, description) VALUES ('Flashcards', '', '')")
+	require.NoError(t, err)
+	activityID, _ := result.LastInsertId()
+	result, err = db.DB.Exec("INSERT INTO words (portuguese, english) VALUES ('olá', 'hello')")
+	require.NoError(t, err)
+	wordID, _ := result.LastInsertId()
+	result, err = db.DB.Exec("INSERT INTO users (name) VALUES ('Ana')")
+	require.NoError(t, err)
+	userID, _ := result.LastInsertId()
+
+	router := api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), db.Store, metrics.NewPortal()))
+
+	w := testutil.PerformRequest(t, router, http.MethodPost, "/api/v2/study_sessions", map[string]interface{}{
+		"group_id": groupID, "study_activity_id": activityID, "user_id": userID,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var session struct {
+		ID int64 `json:"id"`
+	}
+	testutil.ParseResponse(t, w, &session)
+
+	reviews := fmt.Sprintf("/api/v2/study_sessions/%d/reviews", session.ID)
+	for _, correct := range []bool{true, true, false} {
+		w = testutil.PerformRequest(t, router, http.MethodPost, reviews, map[string]interface{}{"word_id": wordID, "correct": correct})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+	testutil.PerformRequest(t, router, http.MethodGet, "/api/words/999999", nil)
+	testutil.PerformRequest(t, router, http.MethodGet, "/nope", nil)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, metrics.ContentType, w.Header().Get("Content-Type"))
+	body := w.Body.String()
+
+	// Requests are labelled with their route template, not their path
+	assert.Contains(t, body, `lang_portal_http_requests_total{method="POST",route="/api/v2/study_sessions/:id/reviews",status="201"} 3`)
+	assert.Contains(t, body, `lang_portal_http_requests_total{method="GET",route="/api/words/:id",status="404"} 1`)
+	assert.Contains(t, body, `lang_portal_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `lang_portal_http_request_duration_seconds_count{method="POST",route="/api/v2/study_sessions"} 1`)
+	assert.NotContains(t, body, "/999999")
+
+	assert.Contains(t, body, `lang_portal_db_query_duration_seconds_count{repository="study_session",method="CreateReview"} 3`)
+	assert.Contains(t, body, `lang_portal_db_query_duration_seconds_count{repository="word",method="GetWord"}`)
+	assert.Contains(t, body, "lang_portal_db_open_connections ")
+	assert.Contains(t, body, "lang_portal_db_wait_count_total ")
+
+	assert.Contains(t, body, "lang_portal_study_sessions_started_total 1\n")
+	assert.Contains(t, body, `lang_portal_reviews_recorded_total{result="correct"} 2`)
+	assert.Contains(t, body, `lang_portal_reviews_recorded_total{result="incorrect"} 1`)
+	assert.Contains(t, body, `lang_portal_active_learners{window="1d"} 1`)
+	assert.Contains(t, body, `lang_portal_active_learners{window="30d"} 1`)
+}
+
+// TestMetricsDisabled tests that nothing is served when metrics are off
+func TestMetricsDisabled(t *testing.T) {
+	router := newRouter()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
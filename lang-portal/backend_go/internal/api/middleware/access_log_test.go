@@ -0,0 +1,117 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newLoggedRouter returns a router with the logging middleware writing JSON
+// records to buf
+func newLoggedRouter(t *testing.T, buf *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger, err := logging.New(buf, slog.LevelDebug, logging.FormatJSON)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.User(), middleware.AccessLog(logger), middleware.Recovery(logger), middleware.Errors(logger))
+	router.GET("/words/:id", func(c *gin.Context) {
+		// Handlers see the request ID through the request's context
+		assert.Equal(t, "req-1", logging.RequestID(c.Request.Context()))
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return router
+}
+
+// records decodes the JSON log records in buf
+func records(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		result = append(result, record)
+	}
+	return result
+}
+
+// TestAccessLog tests the fields of the access log record
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	router := newLoggedRouter(t, &buf)
+
+	req := httptest.NewRequest(http.MethodGet, "/words/42", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	req.Header.Set(middleware.UserHeader, "7")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	logged := records(t, &buf)
+	require.Len(t, logged, 1)
+	record := logged[0]
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, http.MethodGet, record["method"])
+	assert.Equal(t, "/words/:id", record["route"])
+	assert.Equal(t, "/words/42", record["path"])
+	assert.Equal(t, float64(http.StatusOK), record["status"])
+	assert.Contains(t, record, "latency_ms")
+	assert.Equal(t, "req-1", record[logging.RequestIDKey])
+	assert.Equal(t, "7", record[logging.UserKey])
+}
+
+// TestAccessLogUnmatched tests that unknown routes are logged without their
+// path as the route, and invalid user IDs are ignored
+func TestAccessLogUnmatched(t *testing.T) {
+	var buf bytes.Buffer
+	router := newLoggedRouter(t, &buf)
+
+	req := httptest.NewRequest(http.MethodGet, "/nope", nil)
+	req.Header.Set(middleware.UserHeader, "not-a-number")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	record := records(t, &buf)[0]
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "unmatched", record["route"])
+	assert.Equal(t, float64(http.StatusNotFound), record["status"])
+	assert.NotContains(t, record, logging.UserKey)
+	assert.NotEmpty(t, record[logging.RequestIDKey])
+}
+
+// TestRecovery tests that a panic becomes a logged internal error
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	router := newLoggedRouter(t, &buf)
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var body middleware.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "req-2", body.RequestID)
+
+	logged := records(t, &buf)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "panic serving request", logged[0]["msg"])
+	assert.Equal(t, "boom", logged[0]["panic"])
+	assert.Equal(t, "req-2", logged[0][logging.RequestIDKey])
+	assert.Equal(t, "ERROR", logged[1]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), logged[1]["status"])
+}
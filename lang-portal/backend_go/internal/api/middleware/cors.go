@@ -1,22 +1,199 @@
 package middleware
 
 import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
-func CORS() gin.HandlerFunc {
+// CORSConfig describes a cross-origin resource sharing policy
+type CORSConfig struct {
+	// AllowedOrigins lists exact origins ("https://app.example.com") or
+	// wildcard subdomain patterns ("https://*.example.com"). A single "*"
+	// allows any origin; credentials are never sent in that case.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// CORSOverride applies a different policy to every path under PathPrefix
+type CORSOverride struct {
+	PathPrefix string
+	Config     CORSConfig
+}
+
+// corsPolicy is a CORSConfig with its header values precomputed
+type corsPolicy struct {
+	allowAny         bool
+	exactOrigins     map[string]bool
+	patterns         []originPattern
+	allowedMethods   string
+	allowedHeaders   string
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+// originPattern matches origins of the form <prefix><subdomain><suffix>
+type originPattern struct {
+	prefix string
+	suffix string
+}
+
+type corsOverride struct {
+	pathPrefix string
+	policy     *corsPolicy
+}
+
+// CORS returns a middleware that applies cfg to every request, unless the
+// request path falls under one of the overrides. When several overrides
+// match, the one with the longest path prefix wins.
+func CORS(cfg CORSConfig, overrides ...CORSOverride) gin.HandlerFunc {
+	defaultPolicy := newCORSPolicy(cfg)
+
+	compiled := make([]corsOverride, 0, len(overrides))
+	for _, override := range overrides {
+		compiled = append(compiled, corsOverride{
+			pathPrefix: override.PathPrefix,
+			policy:     newCORSPolicy(override.Config),
+		})
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return len(compiled[i].pathPrefix) > len(compiled[j].pathPrefix)
+	})
+
 	return func(c *gin.Context) {
-		// NOTE: this would be not safe in a production environment
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+		policy := defaultPolicy
+		for _, override := range compiled {
+			if strings.HasPrefix(c.Request.URL.Path, override.pathPrefix) {
+				policy = override.policy
+				break
+			}
+		}
+		policy.handle(c)
+	}
+}
+
+func newCORSPolicy(cfg CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		exactOrigins:     make(map[string]bool),
+		allowedMethods:   strings.Join(cfg.AllowedMethods, ", "),
+		allowedHeaders:   strings.Join(cfg.AllowedHeaders, ", "),
+		exposedHeaders:   strings.Join(cfg.ExposedHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials,
+	}
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge / time.Second))
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == "*":
+			p.allowAny = true
+		case strings.Contains(origin, "*"):
+			parts := strings.SplitN(origin, "*", 2)
+			p.patterns = append(p.patterns, originPattern{prefix: parts[0], suffix: parts[1]})
+		default:
+			p.exactOrigins[origin] = true
+		}
+	}
+
+	// Browsers reject a wildcard origin combined with credentials
+	if p.allowAny {
+		p.allowCredentials = false
+	}
+	return p
+}
+
+func (p *corsPolicy) allows(origin string) bool {
+	if p.allowAny {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.exactOrigins[origin] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if pattern.matches(origin) {
+			return true
 		}
+	}
+	return false
+}
+
+// matches reports whether origin has a non-empty subdomain in place of the wildcard
+func (o originPattern) matches(origin string) bool {
+	if len(origin) <= len(o.prefix)+len(o.suffix) {
+		return false
+	}
+	if !strings.HasPrefix(origin, o.prefix) || !strings.HasSuffix(origin, o.suffix) {
+		return false
+	}
+	subdomain := origin[len(o.prefix) : len(origin)-len(o.suffix)]
+	return !strings.ContainsAny(subdomain, "/:@") && !strings.HasPrefix(subdomain, ".")
+}
+
+func (p *corsPolicy) handle(c *gin.Context) {
+	header := c.Writer.Header()
+	origin := c.GetHeader("Origin")
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+	// Unless every origin gets the same answer, the response depends on the
+	// Origin header and caches must key on it
+	if !p.allowAny {
+		header.Add("Vary", "Origin")
+	}
+	if preflight {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
 
+	if origin == "" {
 		c.Next()
+		return
+	}
+
+	if !p.allows(origin) {
+		if preflight {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		// Serve the request without CORS headers; the browser will block it
+		c.Next()
+		return
+	}
+
+	if p.allowAny {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if preflight {
+		header.Set("Access-Control-Allow-Methods", p.allowedMethods)
+		header.Set("Access-Control-Allow-Headers", p.allowedHeaders)
+		if p.maxAge != "" {
+			header.Set("Access-Control-Max-Age", p.maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	if p.exposedHeaders != "" {
+		header.Set("Access-Control-Expose-Headers", p.exposedHeaders)
 	}
+	c.Next()
 }
@@ -0,0 +1,185 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCORSRouter returns a router with a single GET /api/words route behind the CORS middleware
+func newCORSRouter(cfg middleware.CORSConfig, overrides ...middleware.CORSOverride) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CORS(cfg, overrides...))
+	router.GET("/api/words", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []string{}})
+	})
+	router.GET("/api/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"openapi": "3.0.3"})
+	})
+	return router
+}
+
+func testCORSConfig() middleware.CORSConfig {
+	return middleware.CORSConfig{
+		AllowedOrigins:   []string{"http://localhost:8080", "https://*.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+}
+
+func performCORSRequest(router *gin.Engine, method, path, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestCORSOriginMatching tests which origins are allowed by the policy
+func TestCORSOriginMatching(t *testing.T) {
+	router := newCORSRouter(testCORSConfig())
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{"exact origin", "http://localhost:8080", true},
+		{"exact origin is case-insensitive", "HTTP://LOCALHOST:8080", true},
+		{"different port", "http://localhost:3000", false},
+		{"different scheme", "https://localhost:8080", false},
+		{"wildcard subdomain", "https://app.example.com", true},
+		{"nested wildcard subdomain", "https://eu.app.example.com", true},
+		{"wildcard does not match the apex domain", "https://example.com", false},
+		{"wildcard does not match a suffix attack", "https://evilexample.com", false},
+		{"wildcard does not match another scheme", "http://app.example.com", false},
+		{"wildcard does not match a port", "https://app.example.com:8443", false},
+		{"wildcard does not match userinfo", "https://user@app.example.com", false},
+		{"unknown origin", "https://attacker.test", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performCORSRequest(router, http.MethodGet, "/api/words", tt.origin, nil)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Header().Values("Vary"), "Origin")
+			if tt.allowed {
+				assert.Equal(t, tt.origin, w.Header().Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+				assert.Equal(t, "X-Request-ID", w.Header().Get("Access-Control-Expose-Headers"))
+			} else {
+				assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+				assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+			}
+		})
+	}
+}
+
+// TestCORSPreflight tests preflight handling for allowed and rejected origins
+func TestCORSPreflight(t *testing.T) {
+	router := newCORSRouter(testCORSConfig())
+	preflightHeaders := map[string]string{
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "Content-Type",
+	}
+
+	t.Run("allowed origin", func(t *testing.T) {
+		w := performCORSRequest(router, http.MethodOptions, "/api/words", "https://app.example.com", preflightHeaders)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+		assert.ElementsMatch(t,
+			[]string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+			w.Header().Values("Vary"),
+		)
+		// Exposed headers only apply to actual responses
+		assert.Empty(t, w.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("rejected origin", func(t *testing.T) {
+		w := performCORSRequest(router, http.MethodOptions, "/api/words", "https://attacker.test", preflightHeaders)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Contains(t, w.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("plain OPTIONS request is not a preflight", func(t *testing.T) {
+		w := performCORSRequest(router, http.MethodOptions, "/api/words", "https://app.example.com", nil)
+
+		assert.NotEqual(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+	})
+}
+
+// TestCORSWithoutOrigin tests that same-origin requests still get Vary: Origin
+func TestCORSWithoutOrigin(t *testing.T) {
+	router := newCORSRouter(testCORSConfig())
+
+	w := performCORSRequest(router, http.MethodGet, "/api/words", "", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Origin"}, w.Header().Values("Vary"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// TestCORSWildcardNeverSendsCredentials tests that "*" is never combined with credentials
+func TestCORSWildcardNeverSendsCredentials(t *testing.T) {
+	cfg := testCORSConfig()
+	cfg.AllowedOrigins = []string{"*"}
+	router := newCORSRouter(cfg)
+
+	w := performCORSRequest(router, http.MethodGet, "/api/words", "https://anywhere.test", nil)
+
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, w.Header().Values("Vary"))
+}
+
+// TestCORSRouteOverride tests that a path override replaces the default policy
+func TestCORSRouteOverride(t *testing.T) {
+	router := newCORSRouter(testCORSConfig(), middleware.CORSOverride{
+		PathPrefix: "/api/openapi.json",
+		Config: middleware.CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET"},
+		},
+	})
+
+	// The overridden path is readable from any origin
+	w := performCORSRequest(router, http.MethodGet, "/api/openapi.json", "https://docs.test", nil)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	// Other paths keep the default policy
+	w = performCORSRequest(router, http.MethodGet, "/api/words", "https://docs.test", nil)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Preflight responses use the override too
+	w = performCORSRequest(router, http.MethodOptions, "/api/openapi.json", "https://docs.test", map[string]string{
+		"Access-Control-Request-Method": "GET",
+	})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation marks every response as coming from a deprecated API version.
+// It sets the Deprecation header (RFC 9745) to deprecatedAt, the Sunset
+// header (RFC 8594) to the date the version stops being served, and links
+// the successor version.
+func Deprecation(deprecatedAt, sunset time.Time, successor string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	link := fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+
+	return func(c *gin.Context) {
+		// Set before the handler runs so error responses carry them too
+		header := c.Writer.Header()
+		header.Set("Deprecation", deprecation)
+		header.Set("Sunset", sunsetDate)
+		header.Add("Link", link)
+		c.Next()
+	}
+}
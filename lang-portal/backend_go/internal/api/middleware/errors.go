@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse is the body of every API error response
+type ErrorResponse struct {
+	Code      string               `json:"code"`
+	Message   string               `json:"message"`
+	Fields    []service.FieldError `json:"fields,omitempty"`
+	RequestID string               `json:"request_id,omitempty"`
+}
+
+// Errors translates the last error recorded with c.Error into an
+// ErrorResponse. Domain errors keep their message; anything else is logged
+// to logger and reported as an internal error so driver details never reach
+// clients.
+func Errors(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		status, body := translateError(c.Request.Context(), c.Errors.Last().Err)
+		body.RequestID = GetRequestID(c)
+		if status == http.StatusInternalServerError || status == http.StatusServiceUnavailable {
+			logger.ErrorContext(c.Request.Context(), "request failed",
+				"method", c.Request.Method,
+				"route", c.FullPath(),
+				"error", c.Errors.Last().Err,
+			)
+		}
+		c.AbortWithStatusJSON(status, body)
+	}
+}
+
+// StatusClientClosedRequest is reported when the client disconnected before
+// the response was ready. Nobody reads it, but it shows up in access logs.
+const StatusClientClosedRequest = 499
+
+func translateError(ctx context.Context, err error) (int, ErrorResponse) {
+	// The driver reports interrupted queries in its own words, so check the
+	// request's context rather than the error
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable, ErrorResponse{
+			Code:    service.CodeTimeout,
+			Message: "the request took too long",
+		}
+	case errors.Is(ctx.Err(), context.Canceled) || errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest, ErrorResponse{
+			Code:    service.CodeCanceled,
+			Message: "the request was canceled",
+		}
+	}
+
+	var domainErr *service.Error
+	if !errors.As(err, &domainErr) {
+		return http.StatusInternalServerError, ErrorResponse{
+			Code:    service.CodeInternal,
+			Message: "internal server error",
+		}
+	}
+
+	body := ErrorResponse{
+		Code:    domainErr.Code,
+		Message: domainErr.Message,
+		Fields:  domainErr.Fields,
+	}
+
+	switch {
+	case errors.Is(domainErr, service.ErrNotFound):
+		return http.StatusNotFound, body
+	case errors.Is(domainErr, service.ErrConflict):
+		return http.StatusConflict, body
+	case errors.Is(domainErr, service.ErrValidation):
+		return http.StatusBadRequest, body
+	case errors.Is(domainErr, service.ErrForbidden):
+		return http.StatusForbidden, body
+	default:
+		return http.StatusInternalServerError, body
+	}
+}
@@ -0,0 +1,75 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestErrorsTranslation tests how errors recorded by handlers become responses
+func TestErrorsTranslation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"not found", service.NotFound("group"), http.StatusNotFound, service.CodeNotFound, "group not found"},
+		{"conflict", service.Conflict("word is already in the group", errors.New("UNIQUE constraint failed")), http.StatusConflict, service.CodeConflict, "word is already in the group"},
+		{"validation", service.Validation("invalid request body"), http.StatusBadRequest, service.CodeValidation, "invalid request body"},
+		{"forbidden", service.Forbidden("not a member of this classroom"), http.StatusForbidden, service.CodeForbidden, "not a member of this classroom"},
+		{"wrapped domain error", fmt.Errorf("loading group: %w", service.NotFound("group")), http.StatusNotFound, service.CodeNotFound, "group not found"},
+		{"driver error is not leaked", errors.New("no such table: groups"), http.StatusInternalServerError, service.CodeInternal, "internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(middleware.RequestID(), middleware.Errors(discardLogger))
+			router.GET("/fail", func(c *gin.Context) {
+				c.Error(tt.err)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			req.Header.Set(middleware.RequestIDHeader, "test-request-id")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+
+			var body middleware.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.code, body.Code)
+			assert.Equal(t, tt.message, body.Message)
+			assert.Equal(t, "test-request-id", body.RequestID)
+		})
+	}
+}
+
+// TestRequestIDGenerated tests that a request ID is generated when the client sends none
+func TestRequestIDGenerated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, middleware.GetRequestID(c))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	id := w.Header().Get(middleware.RequestIDHeader)
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, w.Body.String())
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a handler into an internal error response and
+// logs it with its stack trace
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// The client went away; let net/http handle it
+				panic(recovered)
+			}
+
+			logger.ErrorContext(c.Request.Context(), "panic serving request",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{
+				Code:      service.CodeInternal,
+				Message:   "internal server error",
+				RequestID: GetRequestID(c),
+			})
+		}()
+		c.Next()
+	}
+}
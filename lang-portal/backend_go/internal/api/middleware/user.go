@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// UserHeader carries the ID of the user making the request. The API has no
+// authentication yet, so the value is only used to attribute log records.
+const UserHeader = "X-User-ID"
+
+// User stores the user ID sent in X-User-ID in the request's context.
+// Values that aren't positive integers are ignored.
+func User() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if raw := c.GetHeader(UserHeader); raw != "" {
+			if id, err := strconv.ParseInt(raw, 10, 64); err == nil && id > 0 {
+				c.Request = c.Request.WithContext(logging.WithUser(c.Request.Context(), raw))
+			}
+		}
+		c.Next()
+	}
+}
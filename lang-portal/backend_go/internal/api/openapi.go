@@ -0,0 +1,864 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/handlers"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/openapi"
+	v2 "github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/v2"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Query parameters shared by the paginated and stats endpoints
+var (
+	pageParam     = openapi.QueryInt("page", "Page number", 1)
+	pageSizeParam = openapi.QueryInt("page_size", "Items per page, at most 100", 10)
+	perPageParam  = openapi.QueryInt("per_page", "Items per page", 100)
+
+	v2PerPageParam = openapi.QueryInt("per_page", "Items per page, at most 100", 20)
+	v2PageQuery    = []openapi.Parameter{pageParam, v2PerPageParam}
+
+	tzParam       = openapi.QueryString("tz", "IANA time zone the days are in, such as America/Sao_Paulo (default UTC)", "")
+	tzQuery       = []openapi.Parameter{openapi.QueryString("tz", "IANA time zone the days are in, such as America/Sao_Paulo (default that of the learner's daily goal)", "")}
+	activityQuery = []openapi.Parameter{
+		openapi.QueryString("from", "First day (default a year before to)", "date"),
+		openapi.QueryString("to", "Last day (default today)", "date"),
+		tzParam,
+	}
+	leaderboardQuery = []openapi.Parameter{
+		openapi.QueryEnum("metric", "What learners are ranked by (default xp)", "xp", "accuracy", "words_mastered"),
+		openapi.QueryEnum("period", "Rolling window of reviews ranked (default weekly)", "daily", "weekly", "all_time"),
+	}
+)
+
+// deleteGroupDescription warns that deleting a group destroys the study
+// history on it
+const deleteGroupDescription = "Also deletes the assignments given on the group and every study session on it, with their reviews. " +
+	"This destroys that study history for every learner: the XP, streak days, word statistics and leaderboard standings it counted for are gone. " +
+	"The words themselves are kept."
+
+// startSessionDescription explains the errors for references to missing
+// records when starting a study session
+const startSessionDescription = "Returns 404 when the group, study activity or user doesn't exist."
+
+// wordConflictDescription explains the conflict for a word that exists
+const wordConflictDescription = "Returns 409 when another word has the same text and translation."
+
+// Descriptions of the gamification endpoints
+const (
+	streakDescription = "Days run from midnight to midnight in tz, by default the time zone of the learner's daily goal. A streak stays alive until the end of the day after the last one studied. " +
+		"A freeze is earned every 7 days of a streak, at most 2 are held, and each covers a missed day without lengthening the streak. " + learnerDescription
+	learnerDescription   = "The /users/:id routes are for that user, the dashboard's for the guest: the sessions started without a user."
+	dailyGoalDescription = "The goal counts the reviews, or the minutes from the start of each session to its last review, of the sessions started today in the goal's time zone. " +
+		"Learners who haven't set a goal have one of 20 reviews a day in UTC. " + learnerDescription
+	achievementsDescription = "Each correct review earns 10 XP, doubled from the 6th answer right in a row in a session and tripled from the 11th. " +
+		"Achievements are unlocked after every review and when a session ends, when the streak is counted in the time zone of the daily goal; listing them unlocks none, so one earned before it was introduced is unlocked when the learner next ends a session. " +
+		"A perfect session is one ended with at least 10 reviews, all correct. " + learnerDescription
+)
+
+// Descriptions of the leaderboard endpoints
+const (
+	leaderboardDescription = "Ranks the current members of a classroom by the reviews they made in the last 24 hours, 7 days or ever. " +
+		"Accuracy needs at least 10 reviews in the period, and a word counts as mastered when answered right 3 times in a row (LANG_PORTAL_MASTERY_STREAK) during it. " +
+		"Members who opted out or have nothing to show are left out, and equal values share a rank. " +
+		"Leaderboards are recomputed every 5 minutes (LANG_PORTAL_LEADERBOARD_REFRESH), as of computed_at; profile changes show at once."
+	leaderboardProfileDescription = "Learners appear under their display name, or else a pseudonym such as \"Swift Otter 42\", which can't be traced back to them. " +
+		"An empty display_name goes back to the pseudonym, and opt_out hides the learner from every leaderboard."
+)
+
+// masteryDescription explains the mastery of the words of a group
+const masteryDescription = "Words are new until reviewed, mastered once answered right 3 times in a row (LANG_PORTAL_MASTERY_STREAK), " +
+	"and lapsed if then not reviewed for 30 days (LANG_PORTAL_MASTERY_LAPSED_AFTER); the others are being learned. " +
+	"The next activity focuses on the lapsed words first, then those being learned, then new ones."
+
+// OpenAPISpec returns the OpenAPI document describing every route registered
+// by SetupRouter. TestOpenAPICoversRoutes keeps the two in sync.
+func OpenAPISpec() *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:       "Language Learning Portal API",
+		Description: "Backend for the Portuguese language learning portal.",
+		Version:     "2.0.0",
+	}, models.Pagination{}, middleware.ErrorResponse{})
+
+	b.AddTag("dashboard", "Learner overview")
+	b.AddTag("stats", "Study history")
+	b.AddTag("study activities", "Study activities and the sessions launched from them")
+	b.AddTag("study sessions", "Study sessions")
+	b.AddTag("words", "Vocabulary")
+	b.AddTag("groups", "Thematic groups of words")
+	b.AddTag("users", "Students and teachers")
+	b.AddTag("classrooms", "Classrooms, members and assignments")
+	b.AddTag("docs", "API documentation")
+	b.AddTag("operations", "Health checks and build information")
+
+	// v1 routes go first so their types keep the unprefixed component names
+	for _, route := range v1Routes() {
+		route.Deprecated = true
+		b.Add(route)
+	}
+	for _, route := range v2Routes() {
+		b.Add(route)
+	}
+	for _, route := range docsRoutes() {
+		b.Add(route)
+	}
+	for _, route := range operationsRoutes() {
+		b.Add(route)
+	}
+
+	// Limits enforced by Validate methods rather than binding tags
+	maxWord, maxName, maxIDs, minOne := models.MaxWordLength, models.MaxGroupNameLength, models.MaxGroupWordsPerRequest, 1
+	word := b.Schema("WordInput")
+	for _, field := range []string{"portuguese", "english"} {
+		word.Properties[field].MinLength = &minOne
+		word.Properties[field].MaxLength = &maxWord
+	}
+	group := b.Schema("GroupInput")
+	group.Properties["name"].MinLength = &minOne
+	group.Properties["name"].MaxLength = &maxName
+	wordIDs := b.Document().Operation(http.MethodPost, "/api/groups/{id}/words").RequestBody.Content["application/json"].Schema
+	wordIDs.MinItems = &minOne
+	wordIDs.MaxItems = &maxIDs
+
+	v2Word := b.Schema("V2WordInput")
+	for _, field := range []string{"term", "translation"} {
+		v2Word.Properties[field].MinLength = &minOne
+		v2Word.Properties[field].MaxLength = &maxWord
+	}
+	v2Group := b.Schema("V2GroupInput")
+	v2Group.Properties["name"].MinLength = &minOne
+	v2Group.Properties["name"].MaxLength = &maxName
+	v2WordIDs := b.Schema("GroupWordsInput").Properties["word_ids"]
+	v2WordIDs.MinItems = &minOne
+	v2WordIDs.MaxItems = &maxIDs
+
+	return b.Document()
+}
+
+// v1Routes documents the deprecated v1 API served at /api
+func v1Routes() []openapi.Route {
+	return []openapi.Route{
+		// Dashboard
+		{
+			Method: http.MethodGet, Path: "/api/dashboard/last_study_session", Tag: "dashboard",
+			Summary:     "Get the most recent study session",
+			Description: "Returns {\"message\": \"No study sessions found\"} when there are none.",
+			Response:    models.StudySessionDetail{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/dashboard/study_progress", Tag: "dashboard",
+			Summary:  "Get the number of words studied out of all words",
+			Response: service.StudyProgress{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/dashboard/quick-stats", Tag: "dashboard",
+			Summary:     "Get success rate, session count, active groups and streak",
+			Description: "The streak is the guest's, counted as by the stats streak endpoint.",
+			Query:       tzQuery,
+			Response:    service.QuickStats{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/dashboard/daily_goal", Tag: "dashboard",
+			Summary:     "Get the guest's daily goal and their progress towards it today",
+			Description: dailyGoalDescription,
+			Response:    service.DailyGoalStatus{},
+		},
+		{
+			Method: http.MethodPut, Path: "/api/dashboard/daily_goal", Tag: "dashboard",
+			Summary:     "Set the guest's daily goal, in reviews or minutes",
+			Description: dailyGoalDescription,
+			Request:     handlers.DailyGoalRequest{},
+			Response:    service.DailyGoalStatus{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/dashboard/achievements", Tag: "dashboard",
+			Summary:     "Get the XP the guest earned and their unlocked and in progress achievements",
+			Description: achievementsDescription,
+			Response:    service.AchievementReport{},
+		},
+
+		// Stats
+		{
+			Method: http.MethodGet, Path: "/api/stats/activity", Tag: "stats",
+			Summary:     "Get the sessions, reviews, accuracy and minutes studied of each day",
+			Description: "Days run from midnight to midnight in tz. Every day of the range is listed, including those without sessions; sessions count on the day they started. At most 367 days are returned.",
+			Query:       activityQuery,
+			Response:    service.ActivityHistory{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/stats/streak", Tag: "stats",
+			Summary:     "Get the guest's current and longest study streaks and their streak freezes",
+			Description: streakDescription,
+			Query:       tzQuery,
+			Response:    service.StudyStreak{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/stats/retention", Tag: "stats",
+			Summary:     "Get the retention of all words, the average recall interval and the leeches",
+			Description: "Retention is the percentage of correct reviews among those made at least 1, 7 or 30 days after the learner's previous review of the same word, and before the next interval. Reviews made sooner than a day after the previous one aren't counted. Leeches are words answered wrong at least 4 times, and more often than right, that haven't since been answered right 3 times in a row.",
+			Response:    service.RetentionReport{},
+		},
+
+		// Study activities
+		{
+			Method: http.MethodGet, Path: "/api/study_activities", Tag: "study activities",
+			Summary:  "List study activities",
+			Response: openapi.ListOf(models.StudyActivity{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/study_activities/:id", Tag: "study activities",
+			Summary:  "Get a study activity",
+			Response: models.StudyActivity{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/study_activities/:id/study_sessions", Tag: "study activities",
+			Summary:  "List the study sessions of an activity",
+			Query:    []openapi.Parameter{pageParam, perPageParam},
+			Response: openapi.PageOf(models.StudySessionDetail{}),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/study_activities", Tag: "study activities",
+			Summary:     "Start a study session",
+			Description: startSessionDescription,
+			Request:     handlers.CreateStudySessionRequest{},
+			Response:    models.StudySession{},
+			Status:      http.StatusCreated,
+		},
+
+		// Study sessions
+		{
+			Method: http.MethodGet, Path: "/api/study_sessions", Tag: "study sessions",
+			Summary:  "List study sessions",
+			Query:    []openapi.Parameter{pageParam, pageSizeParam},
+			Response: openapi.PageOf(models.StudySessionDetail{}),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/study_sessions/:id/words/:word_id/review", Tag: "study sessions",
+			Summary:  "Record the review of a word in a study session",
+			Request:  handlers.ReviewRequest{},
+			Response: handlers.ReviewResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/study_sessions/:id/end", Tag: "study sessions",
+			Summary:     "End a study session and unlock the achievements it earned",
+			Description: "An ended session takes no more reviews. Ending it again returns when it ended, with no achievements.",
+			Response:    service.SessionEnd{},
+		},
+
+		// Words
+		{
+			Method: http.MethodGet, Path: "/api/words", Tag: "words",
+			Summary:  "List words with review counts",
+			Query:    []openapi.Parameter{pageParam, pageSizeParam},
+			Response: openapi.PageOf(models.WordWithStats{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/words/:id", Tag: "words",
+			Summary:  "Get a word with its stats and groups",
+			Response: models.WordDetail{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/words/:id/history", Tag: "words",
+			Summary:     "Get every review of a word, oldest first, with its retention",
+			Description: "interval_days is the time since the previous review by the same learner, null for their first. See the retention endpoint for how retention and leeches are measured.",
+			Response:    service.WordHistory{},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/words", Tag: "words",
+			Summary:     "Create a word",
+			Description: wordConflictDescription,
+			Request:     models.WordInput{},
+			Response:    models.Word{},
+			Status:      http.StatusCreated,
+		},
+		{
+			Method: http.MethodPut, Path: "/api/words/:id", Tag: "words",
+			Summary:     "Update a word",
+			Description: wordConflictDescription,
+			Request:     models.WordInput{},
+			Response:    models.Word{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/words/:id", Tag: "words",
+			Summary: "Delete a word",
+			Status:  http.StatusNoContent,
+		},
+
+		// Groups
+		{
+			Method: http.MethodGet, Path: "/api/groups", Tag: "groups",
+			Summary:     "List groups with their word counts and mastery",
+			Description: masteryDescription,
+			Query:       []openapi.Parameter{pageParam, pageSizeParam},
+			Response:    openapi.PageOf(models.GroupWithStats{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/groups/:id", Tag: "groups",
+			Summary:     "Get a group with its stats and mastery",
+			Description: masteryDescription,
+			Response:    models.GroupDetail{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/groups/:id/words", Tag: "groups",
+			Summary:  "List the words in a group with review counts",
+			Query:    []openapi.Parameter{pageParam, pageSizeParam},
+			Response: openapi.PageOf(models.WordWithStats{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/groups/:id/stats", Tag: "groups",
+			Summary:     "Get the retention of the words of a group and its leeches",
+			Description: "See the retention endpoint for how retention and leeches are measured.",
+			Response:    service.GroupAnalytics{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/groups/:id/study_sessions", Tag: "groups",
+			Summary:  "List the study sessions of a group",
+			Query:    []openapi.Parameter{pageParam, pageSizeParam},
+			Response: openapi.PageOf(models.StudySession{}),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/groups", Tag: "groups",
+			Summary:  "Create a group",
+			Request:  models.GroupInput{},
+			Response: models.Group{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodPut, Path: "/api/groups/:id", Tag: "groups",
+			Summary:  "Rename a group",
+			Request:  models.GroupInput{},
+			Response: models.Group{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/groups/:id", Tag: "groups",
+			Summary:     "Delete a group",
+			Description: deleteGroupDescription,
+			Status:      http.StatusNoContent,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/groups/:id/words", Tag: "groups",
+			Summary:     "Add words to a group",
+			Description: "The body is a JSON array of distinct, existing word IDs.",
+			Request:     []int64{},
+			Status:      http.StatusNoContent,
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/groups/:id/words/:word_id", Tag: "groups",
+			Summary: "Remove a word from a group",
+			Status:  http.StatusNoContent,
+		},
+
+		// Users
+		{
+			Method: http.MethodPost, Path: "/api/users", Tag: "users",
+			Summary:  "Create a user",
+			Request:  handlers.CreateUserRequest{},
+			Response: models.User{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/users/:id", Tag: "users",
+			Summary:  "Get a user",
+			Response: models.User{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/users/:id/assignments", Tag: "users",
+			Summary:  "List a student's assignments with their progress",
+			Response: openapi.ListOf(models.StudentAssignment{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/users/:id/daily_goal", Tag: "users",
+			Summary:     "Get a user's daily goal and their progress towards it today",
+			Description: dailyGoalDescription,
+			Response:    service.DailyGoalStatus{},
+		},
+		{
+			Method: http.MethodPut, Path: "/api/users/:id/daily_goal", Tag: "users",
+			Summary:     "Set a user's daily goal, in reviews or minutes",
+			Description: dailyGoalDescription,
+			Request:     handlers.DailyGoalRequest{},
+			Response:    service.DailyGoalStatus{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/users/:id/achievements", Tag: "users",
+			Summary:     "Get the XP a user earned and their unlocked and in progress achievements",
+			Description: achievementsDescription,
+			Response:    service.AchievementReport{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/users/:id/streak", Tag: "users",
+			Summary:     "Get a user's current and longest study streaks and their streak freezes",
+			Description: streakDescription,
+			Query:       tzQuery,
+			Response:    service.StudyStreak{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/users/:id/leaderboard_profile", Tag: "users",
+			Summary:     "Get how a user appears on leaderboards",
+			Description: leaderboardProfileDescription,
+			Response:    models.LeaderboardProfile{},
+		},
+		{
+			Method: http.MethodPut, Path: "/api/users/:id/leaderboard_profile", Tag: "users",
+			Summary:     "Set a user's leaderboard display name or opt out",
+			Description: leaderboardProfileDescription,
+			Request:     handlers.LeaderboardProfileRequest{},
+			Response:    models.LeaderboardProfile{},
+		},
+
+		// Classrooms
+		{
+			Method: http.MethodPost, Path: "/api/classrooms", Tag: "classrooms",
+			Summary:  "Create a classroom",
+			Request:  handlers.CreateClassroomRequest{},
+			Response: models.Classroom{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/classrooms/:id", Tag: "classrooms",
+			Summary:  "Get a classroom with its members",
+			Response: models.ClassroomDetail{},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/classrooms/:id/members", Tag: "classrooms",
+			Summary: "Add students to a classroom",
+			Request: handlers.AddMembersRequest{},
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/classrooms/:id/members/:user_id", Tag: "classrooms",
+			Summary: "Remove a student from a classroom",
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/classrooms/:id/assignments", Tag: "classrooms",
+			Summary:  "List a classroom's assignments with completion counts",
+			Response: openapi.ListOf(models.AssignmentSummary{}),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/classrooms/:id/assignments", Tag: "classrooms",
+			Summary:  "Assign a group to a classroom",
+			Request:  handlers.CreateAssignmentRequest{},
+			Response: models.Assignment{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/classrooms/:id/assignments/:assignment_id", Tag: "classrooms",
+			Summary:  "Get an assignment with per-student progress",
+			Response: models.AssignmentReport{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/classrooms/:id/leaderboard", Tag: "classrooms",
+			Summary:     "Get a classroom's leaderboard",
+			Description: leaderboardDescription,
+			Query:       leaderboardQuery,
+			Response:    service.Leaderboard{},
+		},
+	}
+}
+
+// v2Routes documents the v2 API served at /api/v2
+func v2Routes() []openapi.Route {
+	return []openapi.Route{
+		// Dashboard
+		{
+			Method: http.MethodGet, Path: "/api/v2/dashboard/last_study_session", Tag: "dashboard",
+			Summary:     "Get the most recent study session",
+			Description: "Responds 404 when there are no study sessions.",
+			Response:    v2.StudySession{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/dashboard/study_progress", Tag: "dashboard",
+			Summary:  "Get the number of words studied out of all words",
+			Response: service.StudyProgress{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/dashboard/quick_stats", Tag: "dashboard",
+			Summary:     "Get success rate, session count, active groups and streak",
+			Description: "The streak is the guest's, counted as by the stats streak endpoint.",
+			Query:       tzQuery,
+			Response:    service.QuickStats{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/dashboard/daily_goal", Tag: "dashboard",
+			Summary:     "Get the guest's daily goal and their progress towards it today",
+			Description: dailyGoalDescription,
+			Response:    service.DailyGoalStatus{},
+		},
+		{
+			Method: http.MethodPut, Path: "/api/v2/dashboard/daily_goal", Tag: "dashboard",
+			Summary:     "Set the guest's daily goal, in reviews or minutes",
+			Description: dailyGoalDescription,
+			Request:     handlers.DailyGoalRequest{},
+			Response:    service.DailyGoalStatus{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/dashboard/achievements", Tag: "dashboard",
+			Summary:     "Get the XP the guest earned and their unlocked and in progress achievements",
+			Description: achievementsDescription,
+			Response:    service.AchievementReport{},
+		},
+
+		// Stats
+		{
+			Method: http.MethodGet, Path: "/api/v2/stats/activity", Tag: "stats",
+			Summary:     "Get the sessions, reviews, accuracy and minutes studied of each day",
+			Description: "Days run from midnight to midnight in tz. Every day of the range is listed, including those without sessions; sessions count on the day they started. At most 367 days are returned.",
+			Query:       activityQuery,
+			Response:    service.ActivityHistory{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/stats/streak", Tag: "stats",
+			Summary:     "Get the guest's current and longest study streaks and their streak freezes",
+			Description: streakDescription,
+			Query:       tzQuery,
+			Response:    service.StudyStreak{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/stats/retention", Tag: "stats",
+			Summary:     "Get the retention of all words, the average recall interval and the leeches",
+			Description: "Retention is the percentage of correct reviews among those made at least 1, 7 or 30 days after the learner's previous review of the same word, and before the next interval. Reviews made sooner than a day after the previous one aren't counted. Leeches are words answered wrong at least 4 times, and more often than right, that haven't since been answered right 3 times in a row.",
+			Response:    service.RetentionReport{},
+		},
+
+		// Study activities
+		{
+			Method: http.MethodGet, Path: "/api/v2/study_activities", Tag: "study activities",
+			Summary:  "List study activities",
+			Query:    v2PageQuery,
+			Response: v2Page(v2.StudyActivity{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/study_activities/:id", Tag: "study activities",
+			Summary:  "Get a study activity",
+			Response: v2.StudyActivity{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/study_activities/:id/study_sessions", Tag: "study activities",
+			Summary:  "List the study sessions of an activity",
+			Query:    v2PageQuery,
+			Response: v2Page(v2.StudySession{}),
+		},
+
+		// Study sessions
+		{
+			Method: http.MethodGet, Path: "/api/v2/study_sessions", Tag: "study sessions",
+			Summary:  "List study sessions",
+			Query:    v2PageQuery,
+			Response: v2Page(v2.StudySession{}),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/study_sessions", Tag: "study sessions",
+			Summary:     "Start a study session",
+			Description: startSessionDescription,
+			Request:     v2.StudySessionInput{},
+			Response:    v2.StudySession{},
+			Status:      http.StatusCreated,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/study_sessions/:id/reviews", Tag: "study sessions",
+			Summary:  "Record the review of a word in a study session",
+			Request:  v2.ReviewInput{},
+			Response: v2.Review{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/study_sessions/:id/reviews/batch", Tag: "study sessions",
+			Summary:     "Record several reviews in a study session",
+			Description: "Either every review is recorded or, if a word doesn't exist, none is.",
+			Request:     v2.ReviewBatchInput{},
+			Response:    []v2.Review{},
+			Status:      http.StatusCreated,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/study_sessions/:id/end", Tag: "study sessions",
+			Summary:     "End a study session and unlock the achievements it earned",
+			Description: "An ended session takes no more reviews. Ending it again returns when it ended, with no achievements.",
+			Response:    service.SessionEnd{},
+		},
+
+		// Words
+		{
+			Method: http.MethodGet, Path: "/api/v2/words", Tag: "words",
+			Summary:  "List words with review counts",
+			Query:    v2PageQuery,
+			Response: v2Page(v2.Word{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/words/:id", Tag: "words",
+			Summary:  "Get a word with its stats and groups",
+			Response: v2.WordDetail{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/words/:id/history", Tag: "words",
+			Summary:     "Get every review of a word, oldest first, with its retention",
+			Description: "interval_days is the time since the previous review by the same learner, null for their first. See the retention endpoint for how retention and leeches are measured.",
+			Response:    service.WordHistory{},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/words", Tag: "words",
+			Summary:     "Create a word",
+			Description: wordConflictDescription,
+			Request:     v2.WordInput{},
+			Response:    v2.Word{},
+			Status:      http.StatusCreated,
+		},
+		{
+			Method: http.MethodPut, Path: "/api/v2/words/:id", Tag: "words",
+			Summary:     "Update a word",
+			Description: wordConflictDescription,
+			Request:     v2.WordInput{},
+			Response:    v2.Word{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/v2/words/:id", Tag: "words",
+			Summary: "Delete a word",
+			Status:  http.StatusNoContent,
+		},
+
+		// Groups
+		{
+			Method: http.MethodGet, Path: "/api/v2/groups", Tag: "groups",
+			Summary:     "List groups with their word counts and mastery",
+			Description: masteryDescription,
+			Query:       v2PageQuery,
+			Response:    v2Page(v2.Group{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/groups/:id", Tag: "groups",
+			Summary:     "Get a group with its word count and mastery",
+			Description: masteryDescription,
+			Response:    v2.Group{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/groups/:id/words", Tag: "groups",
+			Summary:  "List the words in a group with review counts",
+			Query:    v2PageQuery,
+			Response: v2Page(v2.Word{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/groups/:id/stats", Tag: "groups",
+			Summary:     "Get the retention of the words of a group and its leeches",
+			Description: "See the retention endpoint for how retention and leeches are measured.",
+			Response:    service.GroupAnalytics{},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/groups", Tag: "groups",
+			Summary:  "Create a group",
+			Request:  v2.GroupInput{},
+			Response: v2.Group{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/groups/import", Tag: "groups",
+			Summary:     "Create a group together with its words",
+			Description: "Words that already exist are added to the group rather than created again, and a word listed twice is rejected. The group and its words are created together or not at all.",
+			Request:     v2.GroupImportInput{},
+			Response:    v2.Group{},
+			Status:      http.StatusCreated,
+		},
+		{
+			Method: http.MethodPut, Path: "/api/v2/groups/:id", Tag: "groups",
+			Summary:  "Rename a group",
+			Request:  v2.GroupInput{},
+			Response: v2.Group{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/v2/groups/:id", Tag: "groups",
+			Summary:     "Delete a group",
+			Description: deleteGroupDescription,
+			Status:      http.StatusNoContent,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/groups/:id/words", Tag: "groups",
+			Summary:     "Add words to a group",
+			Description: "word_ids must be distinct, existing word IDs.",
+			Request:     v2.GroupWordsInput{},
+			Status:      http.StatusNoContent,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/groups/:id/words/move", Tag: "groups",
+			Summary:     "Move words to another group",
+			Description: "Every word must be in the group and none in the target group; otherwise nothing moves.",
+			Request:     v2.MoveWordsInput{},
+			Status:      http.StatusNoContent,
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/v2/groups/:id/words/:word_id", Tag: "groups",
+			Summary: "Remove a word from a group",
+			Status:  http.StatusNoContent,
+		},
+
+		// Users
+		{
+			Method: http.MethodPost, Path: "/api/v2/users", Tag: "users",
+			Summary:  "Create a user",
+			Request:  handlers.CreateUserRequest{},
+			Response: models.User{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/users/:id", Tag: "users",
+			Summary:  "Get a user",
+			Response: models.User{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/users/:id/assignments", Tag: "users",
+			Summary:  "List a student's assignments with their progress",
+			Query:    v2PageQuery,
+			Response: v2Page(models.StudentAssignment{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/users/:id/daily_goal", Tag: "users",
+			Summary:     "Get a user's daily goal and their progress towards it today",
+			Description: dailyGoalDescription,
+			Response:    service.DailyGoalStatus{},
+		},
+		{
+			Method: http.MethodPut, Path: "/api/v2/users/:id/daily_goal", Tag: "users",
+			Summary:     "Set a user's daily goal, in reviews or minutes",
+			Description: dailyGoalDescription,
+			Request:     handlers.DailyGoalRequest{},
+			Response:    service.DailyGoalStatus{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/users/:id/achievements", Tag: "users",
+			Summary:     "Get the XP a user earned and their unlocked and in progress achievements",
+			Description: achievementsDescription,
+			Response:    service.AchievementReport{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/users/:id/streak", Tag: "users",
+			Summary:     "Get a user's current and longest study streaks and their streak freezes",
+			Description: streakDescription,
+			Query:       tzQuery,
+			Response:    service.StudyStreak{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/users/:id/leaderboard_profile", Tag: "users",
+			Summary:     "Get how a user appears on leaderboards",
+			Description: leaderboardProfileDescription,
+			Response:    models.LeaderboardProfile{},
+		},
+		{
+			Method: http.MethodPut, Path: "/api/v2/users/:id/leaderboard_profile", Tag: "users",
+			Summary:     "Set a user's leaderboard display name or opt out",
+			Description: leaderboardProfileDescription,
+			Request:     handlers.LeaderboardProfileRequest{},
+			Response:    models.LeaderboardProfile{},
+		},
+
+		// Classrooms
+		{
+			Method: http.MethodPost, Path: "/api/v2/classrooms", Tag: "classrooms",
+			Summary:  "Create a classroom",
+			Request:  handlers.CreateClassroomRequest{},
+			Response: models.Classroom{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/classrooms/:id", Tag: "classrooms",
+			Summary:  "Get a classroom with its members",
+			Response: models.ClassroomDetail{},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/classrooms/:id/members", Tag: "classrooms",
+			Summary: "Add students to a classroom",
+			Request: handlers.AddMembersRequest{},
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/v2/classrooms/:id/members/:user_id", Tag: "classrooms",
+			Summary: "Remove a student from a classroom",
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/classrooms/:id/assignments", Tag: "classrooms",
+			Summary:  "List a classroom's assignments with completion counts",
+			Query:    v2PageQuery,
+			Response: v2Page(models.AssignmentSummary{}),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v2/classrooms/:id/assignments", Tag: "classrooms",
+			Summary:  "Assign a group to a classroom",
+			Request:  handlers.CreateAssignmentRequest{},
+			Response: models.Assignment{},
+			Status:   http.StatusCreated,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/classrooms/:id/assignments/:assignment_id", Tag: "classrooms",
+			Summary:  "Get an assignment with per-student progress",
+			Response: models.AssignmentReport{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v2/classrooms/:id/leaderboard", Tag: "classrooms",
+			Summary:     "Get a classroom's leaderboard",
+			Description: leaderboardDescription,
+			Query:       leaderboardQuery,
+			Response:    service.Leaderboard{},
+		},
+	}
+}
+
+// docsRoutes documents the unversioned documentation routes
+func docsRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/api/openapi.json", Tag: "docs",
+			Summary:  "Get this OpenAPI document",
+			Response: map[string]interface{}{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/docs", Tag: "docs",
+			Summary:     "Browse the API documentation",
+			Response:    "",
+			ContentType: "text/html",
+		},
+	}
+}
+
+// operationsRoutes documents the probes and build information
+func operationsRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/healthz", Tag: "operations",
+			Summary:  "Check that the server is running",
+			Response: HealthStatus{},
+		},
+		{
+			Method: http.MethodGet, Path: "/readyz", Tag: "operations",
+			Summary:  "Check that the server can serve requests; 503 when a check fails",
+			Response: ReadinessReport{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/version", Tag: "operations",
+			Summary:  "Get the build and database schema versions",
+			Response: VersionInfo{},
+		},
+	}
+}
+
+// v2Page describes a v2 list response with items of the same type as item
+func v2Page(item interface{}) interface{} {
+	return openapi.PageWith(item, v2.Pagination{})
+}
+
+// serveOpenAPI serves the OpenAPI document, encoded once
+func serveOpenAPI(doc *openapi.Document) gin.HandlerFunc {
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic("api: encoding OpenAPI document: " + err.Error())
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}
+}
+
+// serveDocs serves the embedded documentation page
+func serveDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage)
+}
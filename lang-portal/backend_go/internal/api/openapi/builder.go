@@ -0,0 +1,192 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Route documents one registered route. Path uses gin syntax, e.g. /api/words/:id.
+type Route struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	// Query lists the query parameters the handler reads
+	Query []Parameter
+	// Request is a value of the JSON request body type, if any
+	Request interface{}
+	// Response is a value of the JSON response body type, if any. Use PageOf
+	// and ListOf for paginated and array responses.
+	Response interface{}
+	// Status is the success status code; it defaults to 200
+	Status int
+	// ContentType overrides the response media type, which defaults to JSON
+	ContentType string
+	// Deprecated marks routes of a deprecated API version
+	Deprecated bool
+}
+
+// Builder collects routes into a Document
+type Builder struct {
+	doc         *Document
+	schemas     *schemas
+	errorSchema *Schema
+}
+
+// NewBuilder returns a builder for a document. Paginated responses use the
+// pagination type for their pagination field, and every operation documents
+// errorBody as its error response.
+func NewBuilder(info Info, pagination, errorBody interface{}) *Builder {
+	s := newSchemas(pagination)
+	return &Builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   make(map[string]map[string]*Operation),
+		},
+		schemas:     s,
+		errorSchema: s.of(errorBody),
+	}
+}
+
+// AddTag adds a tag description to the document
+func (b *Builder) AddTag(name, description string) {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+}
+
+// Add documents a route
+func (b *Builder) Add(route Route) {
+	path, params := convertPath(route.Path)
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	op := &Operation{
+		OperationID: operationID(route.Method, path),
+		Summary:     route.Summary,
+		Description: route.Description,
+		Deprecated:  route.Deprecated,
+		Responses:   make(map[string]*Response),
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	for _, name := range params {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "integer", Format: "int64", Minimum: float(1)},
+		})
+	}
+	for _, param := range route.Query {
+		param.In = "query"
+		op.Parameters = append(op.Parameters, param)
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(b.schemas.of(route.Request)),
+		}
+	}
+
+	success := &Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		contentType := route.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		success.Content = map[string]MediaType{contentType: {Schema: b.schemas.of(route.Response)}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errorResponse := &Response{Description: "Error", Content: jsonContent(b.errorSchema)}
+	op.Responses["default"] = errorResponse
+
+	if b.doc.Paths[path] == nil {
+		b.doc.Paths[path] = make(map[string]*Operation)
+	}
+	b.doc.Paths[path][lowerMethod(route.Method)] = op
+}
+
+// Schema returns a registered component schema so callers can add
+// constraints that can't be derived from struct tags
+func (b *Builder) Schema(name string) *Schema {
+	return b.schemas.components[name]
+}
+
+// Document returns the built document
+func (b *Builder) Document() *Document {
+	b.doc.Components.Schemas = b.schemas.components
+	return b.doc
+}
+
+// QueryInt describes an optional integer query parameter
+func QueryInt(name, description string, defaultValue int) Parameter {
+	return Parameter{
+		Name:        name,
+		Description: description + " (default " + strconv.Itoa(defaultValue) + ")",
+		Schema:      &Schema{Type: "integer", Minimum: float(1)},
+	}
+}
+
+// QueryString describes an optional string query parameter. format is an
+// OpenAPI string format, such as "date", or empty.
+func QueryString(name, description, format string) Parameter {
+	return Parameter{
+		Name:        name,
+		Description: description,
+		Schema:      &Schema{Type: "string", Format: format},
+	}
+}
+
+// QueryEnum describes an optional string query parameter taking one of
+// values
+func QueryEnum(name, description string, values ...string) Parameter {
+	return Parameter{
+		Name:        name,
+		Description: description,
+		Schema:      &Schema{Type: "string", Enum: values},
+	}
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// ConvertPath turns a gin path into an OpenAPI path, e.g. /words/:id becomes /words/{id}
+func ConvertPath(path string) string {
+	converted, _ := convertPath(path)
+	return converted
+}
+
+func convertPath(path string) (string, []string) {
+	var params []string
+	converted := ginParam.ReplaceAllStringFunc(path, func(match string) string {
+		params = append(params, match[1:])
+		return "{" + match[1:] + "}"
+	})
+	return converted, params
+}
+
+// operationID derives a stable ID such as get_api_words_id
+func operationID(method, path string) string {
+	replacer := strings.NewReplacer("/", "_", "{", "", "}", "", "-", "_", ".", "_")
+	return lowerMethod(method) + replacer.Replace(path)
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+func lowerMethod(method string) string {
+	return strings.ToLower(method)
+}
+
+func float(n float64) *float64 {
+	return &n
+}
@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// page marks a paginated response whose items have the wrapped type
+type page struct {
+	item       interface{}
+	pagination interface{}
+}
+
+// PageOf describes a paginated response with items of the same type as item
+// and the builder's pagination type
+func PageOf(item interface{}) interface{} {
+	return page{item: item}
+}
+
+// PageWith is like PageOf but with its own pagination type
+func PageWith(item, pagination interface{}) interface{} {
+	return page{item: item, pagination: pagination}
+}
+
+// ListOf describes a JSON array with elements of the same type as item
+func ListOf(item interface{}) interface{} {
+	return reflect.New(reflect.SliceOf(reflect.TypeOf(item))).Elem().Interface()
+}
+
+// schemas generates schemas for Go types, registering named structs as
+// reusable components
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	pagination interface{}
+}
+
+func newSchemas(pagination interface{}) *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+		pagination: pagination,
+	}
+}
+
+// of returns the schema for the type of v
+func (s *schemas) of(v interface{}) *Schema {
+	if p, ok := v.(page); ok {
+		pagination := p.pagination
+		if pagination == nil {
+			pagination = s.pagination
+		}
+		return &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"items":      {Type: "array", Items: s.of(p.item)},
+				"pagination": s.of(pagination),
+			},
+			Required: []string{"items", "pagination"},
+		}
+	}
+	return s.forType(reflect.TypeOf(v))
+}
+
+func (s *schemas) forType(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.forType(t.Elem())
+		if schema.Ref != "" {
+			// $ref siblings are ignored by OpenAPI 3.0 tools, so pointers to
+			// components stay plain references
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.forType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.forType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.register(t)}
+	default:
+		// interface{} and anything else accepts any value
+		return &Schema{}
+	}
+}
+
+// register adds a named struct to the components and returns its name
+func (s *schemas) register(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		// Same type name in another package
+		name = exportedName(pathBase(t.PkgPath())) + name
+	}
+	s.names[t] = name
+
+	// Reserve the name before recursing so self-references terminate
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+	return name
+}
+
+// object builds an inline object schema from a struct's JSON fields.
+// Embedded structs are flattened the way encoding/json does.
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		property := s.forType(field.Type)
+		required := applyBindingRules(property, field.Tag.Get("binding"))
+		schema.Properties[name] = property
+
+		if required || (!omitempty && field.Type.Kind() != reflect.Ptr && field.Tag.Get("binding") == "") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// jsonName returns a field's JSON name and whether it is omitted when empty
+// or always skipped
+func jsonName(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// applyBindingRules copies gin binding rules onto a property schema and
+// reports whether the field is required. Fields with binding tags are only
+// required when the tags say so.
+func applyBindingRules(schema *Schema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(schema, name == "min", n)
+		}
+	}
+	return required
+}
+
+func applyBound(schema *Schema, lower bool, n float64) {
+	count := int(n)
+	switch schema.Type {
+	case "array":
+		if lower {
+			schema.MinItems = &count
+		} else {
+			schema.MaxItems = &count
+		}
+	case "string":
+		if lower {
+			schema.MinLength = &count
+		} else {
+			schema.MaxLength = &count
+		}
+	default:
+		if lower {
+			schema.Minimum = &n
+		} else {
+			schema.Maximum = &n
+		}
+	}
+}
+
+func pathBase(pkgPath string) string {
+	if i := strings.LastIndex(pkgPath, "/"); i >= 0 {
+		return pkgPath[i+1:]
+	}
+	return pkgPath
+}
+
+func exportedName(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,100 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/openapi"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRouter returns the real router without a database, since the tests
+// below never reach the repositories
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), &database.Store{}, nil))
+}
+
+// TestOpenAPICoversRoutes fails when a route is registered without being
+// documented, or documented without being registered
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc := api.OpenAPISpec()
+
+	registered := make(map[string]bool)
+	for _, route := range newRouter().Routes() {
+		path := openapi.ConvertPath(route.Path)
+		registered[route.Method+" "+path] = true
+		assert.NotNil(t, doc.Operation(route.Method, path), "%s %s is missing from the OpenAPI spec", route.Method, route.Path)
+	}
+
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			key := strings.ToUpper(method) + " " + path
+			assert.True(t, registered[key], "%s is documented but not registered", key)
+		}
+	}
+}
+
+// TestOpenAPISchemas spot-checks schemas derived from models and handler requests
+func TestOpenAPISchemas(t *testing.T) {
+	doc := api.OpenAPISpec()
+	schemas := doc.Components.Schemas
+
+	// Embedded structs are flattened
+	require.Contains(t, schemas, "WordWithStats")
+	assert.Contains(t, schemas["WordWithStats"].Properties, "portuguese")
+	assert.Contains(t, schemas["WordWithStats"].Properties, "correct_count")
+
+	// Request DTOs don't expose server-assigned fields
+	require.Contains(t, schemas, "WordInput")
+	assert.NotContains(t, schemas["WordInput"].Properties, "id")
+	assert.NotContains(t, schemas["WordInput"].Properties, "created_at")
+	assert.Equal(t, 100, *schemas["WordInput"].Properties["english"].MaxLength)
+
+	// Binding rules become constraints
+	require.Contains(t, schemas, "CreateUserRequest")
+	assert.Equal(t, []string{"name"}, schemas["CreateUserRequest"].Required)
+	assert.Equal(t, []string{"student", "teacher"}, schemas["CreateUserRequest"].Properties["role"].Enum)
+
+	// Paginated responses wrap the item schema
+	op := doc.Operation(http.MethodGet, "/api/words/{id}")
+	require.NotNil(t, op)
+	assert.Equal(t, "id", op.Parameters[0].Name)
+	assert.Equal(t, "path", op.Parameters[0].In)
+
+	list := doc.Operation(http.MethodGet, "/api/words").Responses["200"].Content["application/json"].Schema
+	assert.Equal(t, "#/components/schemas/WordWithStats", list.Properties["items"].Items.Ref)
+	assert.Equal(t, "#/components/schemas/Pagination", list.Properties["pagination"].Ref)
+
+	// Errors share one schema
+	errorBody := op.Responses["default"].Content["application/json"].Schema
+	assert.Equal(t, "#/components/schemas/ErrorResponse", errorBody.Ref)
+}
+
+// TestServeOpenAPI tests the document and docs UI endpoints
+func TestServeOpenAPI(t *testing.T) {
+	router := newRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.NotEmpty(t, doc.Paths)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "openapi.json")
+}
@@ -0,0 +1,112 @@
+// Package request binds and validates request input for the HTTP handlers,
+// recording failures as validation errors.
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var registerFieldNames sync.Once
+
+// BindJSON binds the request body into obj. On failure it records a
+// validation error with one entry per rejected field and returns false.
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	registerFieldNames.Do(useJSONFieldNames)
+
+	if err := c.ShouldBindJSON(obj); err != nil {
+		c.Error(bindingError(err))
+		return false
+	}
+	return true
+}
+
+// ParseID parses an int64 path parameter, recording a validation error on failure
+func ParseID(c *gin.Context, param string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil || id < 1 {
+		c.Error(service.Validation("invalid path parameter", service.FieldError{
+			Field:   param,
+			Message: "must be a positive integer",
+		}))
+		return 0, false
+	}
+	return id, true
+}
+
+// bindingError converts errors from gin's binding into a validation error
+func bindingError(err error) *service.Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]service.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, service.FieldError{
+				Field:   fieldPath(fe),
+				Message: validationMessage(fe),
+			})
+		}
+		return service.Validation("invalid request body", fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return service.Validation("invalid request body", service.FieldError{
+			Field:   typeErr.Field,
+			Message: "must be of type " + typeErr.Type.String(),
+		})
+	}
+
+	return service.Validation("invalid request body")
+}
+
+// fieldPath returns the JSON path of a field, without the top-level struct name
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
+
+// useJSONFieldNames makes validation errors report JSON field names
+func useJSONFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
@@ -1,72 +1,271 @@
 package api
 
 import (
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/handlers"
+	"log/slog"
+
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(
-	dashboardHandler *handlers.DashboardHandler,
-	studyActivityHandler *handlers.StudyActivityHandler,
-	wordHandler *handlers.WordHandler,
-	groupHandler *handlers.GroupHandler,
-) *gin.Engine {
-	router := gin.Default()
-
-	// Apply global middleware
-	router.Use(middleware.CORS())
-
-	// API routes
-	api := router.Group("/api")
-	{
-		// Dashboard routes
-		dashboard := api.Group("/dashboard")
-		{
-			dashboard.GET("/last_study_session", dashboardHandler.GetLastStudySession)
-			dashboard.GET("/study_progress", dashboardHandler.GetStudyProgress)
-			dashboard.GET("/quick-stats", dashboardHandler.GetQuickStats)
-		}
-
-		// Study activities routes
-		activities := api.Group("/study_activities")
-		{
-			activities.GET("", studyActivityHandler.ListStudyActivities)
-			activities.GET("/:id", studyActivityHandler.GetStudyActivity)
-			activities.GET("/:id/study_sessions", studyActivityHandler.GetStudyActivitySessions)
-			activities.POST("", studyActivityHandler.CreateStudySession)
-		}
-
-		// Study sessions routes
-		studySessions := api.Group("/study_sessions")
-		{
-			studySessions.GET("", studyActivityHandler.ListStudySessions)
-		}
-
-		// Words routes
-		words := api.Group("/words")
-		{
-			words.GET("", wordHandler.ListWords)
-			words.GET("/:id", wordHandler.GetWord)
-			words.POST("", wordHandler.CreateWord)
-			words.PUT("/:id", wordHandler.UpdateWord)
-			words.DELETE("/:id", wordHandler.DeleteWord)
-		}
-
-		// Groups routes
-		groups := api.Group("/groups")
-		{
-			groups.GET("", groupHandler.ListGroups)
-			groups.GET("/:id", groupHandler.GetGroup)
-			groups.GET("/:id/words", groupHandler.GetGroupWords)
-			groups.GET("/:id/study_sessions", groupHandler.GetGroupStudySessions)
-			groups.POST("", groupHandler.CreateGroup)
-			groups.PUT("/:id", groupHandler.UpdateGroup)
-			groups.DELETE("/:id", groupHandler.DeleteGroup)
-			groups.POST("/:id/words", groupHandler.AddWordsToGroup)
-			groups.DELETE("/:id/words/:word_id", groupHandler.RemoveWordFromGroup)
-		}
+// SetupRouter mounts every API version. v1 is served at /api for the
+// existing frontend and is deprecated in favour of /api/v2. Requests and
+// errors are logged to slog's default logger.
+func SetupRouter(cfg *config.Config, h *Handlers) *gin.Engine {
+	logger := slog.Default()
+	router := gin.New()
+
+	// Apply global middleware. The access log wraps recovery so panics are
+	// logged with their 500 status, and the query timeout wraps error
+	// translation so its deadline is still in force when errors are reported.
+	router.Use(middleware.RequestID())
+	router.Use(middleware.User())
+	router.Use(middleware.AccessLog(logger))
+	if h.Metrics != nil {
+		router.Use(middleware.Metrics(h.Metrics))
 	}
+	router.Use(middleware.Recovery(logger))
+	router.Use(middleware.QueryTimeout(cfg.Database.QueryTimeout))
+	router.Use(corsMiddleware(cfg.CORS))
+	router.Use(middleware.Errors(logger))
+
+	if h.Metrics != nil {
+		router.GET(cfg.Metrics.Path, gin.WrapH(h.Metrics.Registry))
+	}
+
+	// Probes for the process supervisor
+	router.GET("/healthz", serveHealthz)
+	router.GET("/readyz", serveReadyz(h.db))
+
+	// Documentation and build routes, outside any version
+	docs := router.Group("/api")
+	docs.GET("/openapi.json", serveOpenAPI(OpenAPISpec()))
+	docs.GET("/docs", serveDocs)
+	docs.GET("/version", serveVersion(h.db))
+
+	v1 := router.Group("/api", middleware.Deprecation(cfg.API.V1DeprecatedAt, cfg.API.V1Sunset, "/api/v2"))
+	mountV1(v1, h)
+
+	mountV2(router.Group("/api/v2"), h)
 
 	return router
 }
+
+// mountV1 registers the v1 routes. Their response shapes are pinned by the
+// contract tests in handlers/contract_test.go.
+func mountV1(api *gin.RouterGroup, h *Handlers) {
+	// Dashboard routes
+	dashboard := api.Group("/dashboard")
+	{
+		dashboard.GET("/last_study_session", h.Dashboard.GetLastStudySession)
+		dashboard.GET("/study_progress", h.Dashboard.GetStudyProgress)
+		dashboard.GET("/quick-stats", h.Dashboard.GetQuickStats)
+		dashboard.GET("/daily_goal", h.Gamification.GetDailyGoal)
+		dashboard.PUT("/daily_goal", h.Gamification.SetDailyGoal)
+		dashboard.GET("/achievements", h.Gamification.GetAchievements)
+	}
+
+	// Stats routes
+	stats := api.Group("/stats")
+	{
+		stats.GET("/activity", h.Stats.GetActivity)
+		stats.GET("/streak", h.Stats.GetStreak)
+		stats.GET("/retention", h.Stats.GetRetention)
+	}
+
+	// Study activities routes
+	activities := api.Group("/study_activities")
+	{
+		activities.GET("", h.StudyActivity.ListStudyActivities)
+		activities.GET("/:id", h.StudyActivity.GetStudyActivity)
+		activities.GET("/:id/study_sessions", h.StudyActivity.GetStudyActivitySessions)
+		activities.POST("", h.StudyActivity.CreateStudySession)
+	}
+
+	// Study sessions routes
+	studySessions := api.Group("/study_sessions")
+	{
+		studySessions.GET("", h.StudyActivity.ListStudySessions)
+		studySessions.POST("/:id/words/:word_id/review", h.StudyActivity.RecordReview)
+		studySessions.POST("/:id/end", h.StudyActivity.EndStudySession)
+	}
+
+	// Words routes
+	words := api.Group("/words")
+	{
+		words.GET("", h.Word.ListWords)
+		words.GET("/:id", h.Word.GetWord)
+		words.GET("/:id/history", h.Stats.GetWordHistory)
+		words.POST("", h.Word.CreateWord)
+		words.PUT("/:id", h.Word.UpdateWord)
+		words.DELETE("/:id", h.Word.DeleteWord)
+	}
+
+	// Groups routes
+	groups := api.Group("/groups")
+	{
+		groups.GET("", h.Group.ListGroups)
+		groups.GET("/:id", h.Group.GetGroup)
+		groups.GET("/:id/words", h.Group.GetGroupWords)
+		groups.GET("/:id/stats", h.Stats.GetGroupStats)
+		groups.GET("/:id/study_sessions", h.Group.GetGroupStudySessions)
+		groups.POST("", h.Group.CreateGroup)
+		groups.PUT("/:id", h.Group.UpdateGroup)
+		groups.DELETE("/:id", h.Group.DeleteGroup)
+		groups.POST("/:id/words", h.Group.AddWordsToGroup)
+		groups.DELETE("/:id/words/:word_id", h.Group.RemoveWordFromGroup)
+	}
+
+	// Users routes
+	users := api.Group("/users")
+	{
+		users.POST("", h.User.CreateUser)
+		users.GET("/:id", h.User.GetUser)
+		users.GET("/:id/assignments", h.User.ListUserAssignments)
+		users.GET("/:id/daily_goal", h.Gamification.GetDailyGoal)
+		users.PUT("/:id/daily_goal", h.Gamification.SetDailyGoal)
+		users.GET("/:id/achievements", h.Gamification.GetAchievements)
+		users.GET("/:id/streak", h.Stats.GetStreak)
+		users.GET("/:id/leaderboard_profile", h.Leaderboard.GetProfile)
+		users.PUT("/:id/leaderboard_profile", h.Leaderboard.SetProfile)
+	}
+
+	// Classrooms routes
+	classrooms := api.Group("/classrooms")
+	{
+		classrooms.POST("", h.Classroom.CreateClassroom)
+		classrooms.GET("/:id", h.Classroom.GetClassroom)
+		classrooms.POST("/:id/members", h.Classroom.AddMembers)
+		classrooms.DELETE("/:id/members/:user_id", h.Classroom.RemoveMember)
+		classrooms.GET("/:id/assignments", h.Classroom.ListAssignments)
+		classrooms.POST("/:id/assignments", h.Classroom.CreateAssignment)
+		classrooms.GET("/:id/assignments/:assignment_id", h.Classroom.GetAssignment)
+		classrooms.GET("/:id/leaderboard", h.Leaderboard.GetLeaderboard)
+	}
+}
+
+// mountV2 registers the v2 routes. Endpoints whose resources didn't change
+// reuse the v1 handlers.
+func mountV2(api *gin.RouterGroup, h *Handlers) {
+	v2 := h.V2
+
+	// Dashboard routes
+	dashboard := api.Group("/dashboard")
+	{
+		dashboard.GET("/last_study_session", v2.GetLastStudySession)
+		dashboard.GET("/study_progress", v2.GetStudyProgress)
+		dashboard.GET("/quick_stats", v2.GetQuickStats)
+		dashboard.GET("/daily_goal", h.Gamification.GetDailyGoal)
+		dashboard.PUT("/daily_goal", h.Gamification.SetDailyGoal)
+		dashboard.GET("/achievements", h.Gamification.GetAchievements)
+	}
+
+	// Stats routes
+	stats := api.Group("/stats")
+	{
+		stats.GET("/activity", h.Stats.GetActivity)
+		stats.GET("/streak", h.Stats.GetStreak)
+		stats.GET("/retention", h.Stats.GetRetention)
+	}
+
+	// Study activities routes
+	activities := api.Group("/study_activities")
+	{
+		activities.GET("", v2.ListStudyActivities)
+		activities.GET("/:id", v2.GetStudyActivity)
+		activities.GET("/:id/study_sessions", v2.ListStudyActivitySessions)
+	}
+
+	// Study sessions routes
+	studySessions := api.Group("/study_sessions")
+	{
+		studySessions.GET("", v2.ListStudySessions)
+		studySessions.POST("", v2.CreateStudySession)
+		studySessions.POST("/:id/reviews", v2.RecordReview)
+		studySessions.POST("/:id/reviews/batch", v2.RecordReviews)
+		studySessions.POST("/:id/end", h.StudyActivity.EndStudySession)
+	}
+
+	// Words routes
+	words := api.Group("/words")
+	{
+		words.GET("", v2.ListWords)
+		words.GET("/:id", v2.GetWord)
+		words.GET("/:id/history", h.Stats.GetWordHistory)
+		words.POST("", v2.CreateWord)
+		words.PUT("/:id", v2.UpdateWord)
+		words.DELETE("/:id", v2.DeleteWord)
+	}
+
+	// Groups routes
+	groups := api.Group("/groups")
+	{
+		groups.GET("", v2.ListGroups)
+		groups.GET("/:id", v2.GetGroup)
+		groups.GET("/:id/words", v2.ListGroupWords)
+		groups.GET("/:id/stats", h.Stats.GetGroupStats)
+		groups.POST("", v2.CreateGroup)
+		groups.POST("/import", v2.ImportGroup)
+		groups.PUT("/:id", v2.UpdateGroup)
+		groups.DELETE("/:id", v2.DeleteGroup)
+		groups.POST("/:id/words", v2.AddGroupWords)
+		groups.POST("/:id/words/move", v2.MoveGroupWords)
+		groups.DELETE("/:id/words/:word_id", v2.RemoveGroupWord)
+	}
+
+	// Users routes
+	users := api.Group("/users")
+	{
+		users.POST("", h.User.CreateUser)
+		users.GET("/:id", h.User.GetUser)
+		users.GET("/:id/assignments", v2.ListUserAssignments)
+		users.GET("/:id/daily_goal", h.Gamification.GetDailyGoal)
+		users.PUT("/:id/daily_goal", h.Gamification.SetDailyGoal)
+		users.GET("/:id/achievements", h.Gamification.GetAchievements)
+		users.GET("/:id/streak", h.Stats.GetStreak)
+		users.GET("/:id/leaderboard_profile", h.Leaderboard.GetProfile)
+		users.PUT("/:id/leaderboard_profile", h.Leaderboard.SetProfile)
+	}
+
+	// Classrooms routes
+	classrooms := api.Group("/classrooms")
+	{
+		classrooms.POST("", h.Classroom.CreateClassroom)
+		classrooms.GET("/:id", h.Classroom.GetClassroom)
+		classrooms.POST("/:id/members", h.Classroom.AddMembers)
+		classrooms.DELETE("/:id/members/:user_id", h.Classroom.RemoveMember)
+		classrooms.GET("/:id/assignments", v2.ListClassroomAssignments)
+		classrooms.POST("/:id/assignments", h.Classroom.CreateAssignment)
+		classrooms.GET("/:id/assignments/:assignment_id", h.Classroom.GetAssignment)
+		classrooms.GET("/:id/leaderboard", h.Leaderboard.GetLeaderboard)
+	}
+}
+
+// corsMiddleware builds the CORS policy from configuration. Public paths get
+// an override that allows any origin for read-only requests.
+func corsMiddleware(cfg config.CORS) gin.HandlerFunc {
+	policy := middleware.CORSConfig{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+
+	overrides := make([]middleware.CORSOverride, 0, len(cfg.PublicPaths))
+	for _, path := range cfg.PublicPaths {
+		overrides = append(overrides, middleware.CORSOverride{
+			PathPrefix: path,
+			Config: middleware.CORSConfig{
+				AllowedOrigins: []string{"*"},
+				AllowedMethods: []string{"GET", "HEAD", "OPTIONS"},
+				AllowedHeaders: cfg.AllowedHeaders,
+				ExposedHeaders: cfg.ExposedHeaders,
+				MaxAge:         cfg.MaxAge,
+			},
+		})
+	}
+
+	return middleware.CORS(policy, overrides...)
+}
@@ -0,0 +1,64 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlowRouter returns a router over a database whose study session
+// aggregations run for seconds
+func newSlowRouter(t *testing.T, queryTimeout time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewTestDB()
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	require.NoError(t, db.SlowStudySessions())
+
+	cfg := config.Default()
+	cfg.Database.QueryTimeout = queryTimeout
+	return api.SetupRouter(cfg, api.NewHandlers(cfg, db.Store, nil))
+}
+
+// TestQueryTimeout tests that a slow request is cut off at the configured timeout
+func TestQueryTimeout(t *testing.T) {
+	router := newSlowRouter(t, 100*time.Millisecond)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/dashboard/quick-stats", nil))
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var body middleware.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, service.CodeTimeout, body.Code)
+}
+
+// TestClientCancelAbortsRequest tests that a client disconnecting stops the
+// queries of its request
+func TestClientCancelAbortsRequest(t *testing.T) {
+	router := newSlowRouter(t, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/dashboard/quick_stats", nil).WithContext(ctx)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, middleware.StatusClientClosedRequest, w.Code)
+}
@@ -0,0 +1,47 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/gin-gonic/gin"
+)
+
+// The classroom resources already have their v2 shapes, so only the list
+// endpoints differ from v1: they are paginated like every other v2 list.
+
+func (h *Handler) ListClassroomAssignments(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	params, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	assignments, err := h.classroomService.ListAssignments(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newPage(paginate(assignments, params), params, len(assignments)))
+}
+
+func (h *Handler) ListUserAssignments(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	params, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	assignments, err := h.classroomService.ListStudentAssignments(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newPage(paginate(assignments, params), params, len(assignments)))
+}
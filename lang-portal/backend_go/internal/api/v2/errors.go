@@ -0,0 +1,26 @@
+package v2
+
+import (
+	"errors"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+)
+
+// renameFields reports service field errors under their v2 names. The
+// service error is copied so the original is left untouched.
+func renameFields(err error, names map[string]string) error {
+	var svcErr *service.Error
+	if !errors.As(err, &svcErr) || len(svcErr.Fields) == 0 {
+		return err
+	}
+
+	renamed := *svcErr
+	renamed.Fields = make([]service.FieldError, len(svcErr.Fields))
+	for i, field := range svcErr.Fields {
+		if name, ok := names[field.Field]; ok {
+			field.Field = name
+		}
+		renamed.Fields[i] = field
+	}
+	return &renamed
+}
@@ -0,0 +1,181 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListGroups(c *gin.Context) {
+	params, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	groups, total, err := h.groupService.ListGroupsPaginated(c.Request.Context(), params.page, params.perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	items := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		items = append(items, newGroupWithStats(g))
+	}
+	c.JSON(http.StatusOK, newPage(items, params, total))
+}
+
+func (h *Handler) GetGroup(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	group, err := h.groupService.GetGroupWithStats(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newGroupWithStats(group))
+}
+
+func (h *Handler) ListGroupWords(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	params, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	// Unlike v1, an unknown group is a 404 rather than an empty page
+	if _, err := h.groupService.GetGroup(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	words, total, err := h.groupService.GetGroupWordsPaginated(c.Request.Context(), id, params.page, params.perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newPage(newWords(words), params, total))
+}
+
+func (h *Handler) CreateGroup(c *gin.Context) {
+	var input GroupInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(c.Request.Context(), models.GroupInput{Name: input.Name})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, newGroup(group))
+}
+
+func (h *Handler) UpdateGroup(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var input GroupInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	group, err := h.groupService.UpdateGroup(c.Request.Context(), id, models.GroupInput{Name: input.Name})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newGroup(group))
+}
+
+func (h *Handler) DeleteGroup(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.DeleteGroup(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) AddGroupWords(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var input GroupWordsInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	if err := h.groupService.AddWordsToGroup(c.Request.Context(), id, input.WordIDs); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MoveGroupWords moves words to another group, all or none
+func (h *Handler) MoveGroupWords(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var input MoveWordsInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	if err := h.groupService.MoveWords(c.Request.Context(), id, input.ToGroupID, input.WordIDs); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ImportGroup creates a group and its words, all or none
+func (h *Handler) ImportGroup(c *gin.Context) {
+	var input GroupImportInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	group, err := h.groupService.ImportGroup(c.Request.Context(), input.toModel())
+	if err != nil {
+		c.Error(renameFields(err, input.fieldNames()))
+		return
+	}
+	c.JSON(http.StatusCreated, newGroupWithStats(group))
+}
+
+func (h *Handler) RemoveGroupWord(c *gin.Context) {
+	groupID, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	wordID, ok := request.ParseID(c, "word_id")
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.RemoveWordFromGroup(c.Request.Context(), groupID, wordID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
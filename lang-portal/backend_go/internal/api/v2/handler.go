@@ -0,0 +1,39 @@
+// Package v2 serves version 2 of the HTTP API under /api/v2.
+//
+// Compared to v1, words use language-agnostic fields (term and translation,
+// with their language codes), every list endpoint is paginated with the same
+// page and per_page parameters and the same envelope, and invalid pagination
+// parameters are rejected instead of silently replaced.
+package v2
+
+import (
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+)
+
+// Services are the services the v2 handlers are built on
+type Services struct {
+	Dashboard     *service.DashboardService
+	StudyActivity *service.StudyActivityService
+	Word          *service.WordService
+	Group         *service.GroupService
+	Classroom     *service.ClassroomService
+}
+
+// Handler serves the v2 endpoints
+type Handler struct {
+	dashboardService     *service.DashboardService
+	studyActivityService *service.StudyActivityService
+	wordService          *service.WordService
+	groupService         *service.GroupService
+	classroomService     *service.ClassroomService
+}
+
+func NewHandler(services Services) *Handler {
+	return &Handler{
+		dashboardService:     services.Dashboard,
+		studyActivityService: services.StudyActivity,
+		wordService:          services.Word,
+		groupService:         services.Group,
+		classroomService:     services.Classroom,
+	}
+}
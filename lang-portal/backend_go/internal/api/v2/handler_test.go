@@ -0,0 +1,357 @@
+package v2_test
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/middleware"
+	v2 "github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/v2"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// HandlerTestSuite is a test suite for the v2 handlers
+type HandlerTestSuite struct {
+	suite.Suite
+	router *gin.Engine
+	db     *database.TestDB
+}
+
+// SetupSuite sets up the test suite
+func (suite *HandlerTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+
+	var err error
+	suite.db, err = database.NewTestDB()
+	if err != nil {
+		suite.T().Fatalf("Failed to create test database: %v", err)
+	}
+	suite.router = api.SetupRouter(config.Default(), api.NewHandlers(config.Default(), suite.db.Store, nil))
+}
+
+// TearDownSuite tears down the test suite
+func (suite *HandlerTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// SetupTest clears the data left by the previous test
+func (suite *HandlerTestSuite) SetupTest() {
+	for _, table := range []string{"word_review_items", "study_sessions", "study_activities", "words_groups", "groups", "words"} {
+		if _, err := suite.db.DB.Exec("DELETE FROM " + table); err != nil {
+			suite.T().Fatalf("Failed to clear %s: %v", table, err)
+		}
+	}
+}
+
+func (suite *HandlerTestSuite) createWord(term, translation string) v2.Word {
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/words", v2.WordInput{Term: term, Translation: translation})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+
+	var word v2.Word
+	testutil.ParseResponse(suite.T(), w, &word)
+	return word
+}
+
+// TestWordShape tests that words use language-neutral field names
+func (suite *HandlerTestSuite) TestWordShape() {
+	created := suite.createWord("olá", "hello")
+	assert.Equal(suite.T(), "olá", created.Term)
+	assert.Equal(suite.T(), "hello", created.Translation)
+	assert.Equal(suite.T(), v2.TermLanguage, created.TermLanguage)
+	assert.Equal(suite.T(), v2.TranslationLanguage, created.TranslationLanguage)
+	assert.Nil(suite.T(), created.Stats)
+
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, fmt.Sprintf("/api/v2/words/%d", created.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var detail v2.WordDetail
+	testutil.ParseResponse(suite.T(), w, &detail)
+	assert.Equal(suite.T(), created.ID, detail.ID)
+	require.NotNil(suite.T(), detail.Stats)
+	assert.Equal(suite.T(), 0, detail.Stats.CorrectCount)
+	assert.Nil(suite.T(), detail.Stats.LastReviewedAt)
+	assert.Zero(suite.T(), detail.Stats.Streak)
+	assert.NotNil(suite.T(), detail.Groups)
+}
+
+// TestWordValidationFields tests that validation errors use v2 field names
+func (suite *HandlerTestSuite) TestWordValidationFields() {
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/words", v2.WordInput{Term: " ", Translation: "hello"})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+	require.Len(suite.T(), response.Fields, 1)
+	assert.Equal(suite.T(), "term", response.Fields[0].Field)
+}
+
+// TestPagination tests the list envelope and strict query validation
+func (suite *HandlerTestSuite) TestPagination() {
+	for _, term := range []string{"um", "dois", "três"} {
+		suite.createWord(term, term)
+	}
+
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, "/api/v2/words?page=2&per_page=2", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var page struct {
+		Items      []v2.Word     `json:"items"`
+		Pagination v2.Pagination `json:"pagination"`
+	}
+	testutil.ParseResponse(suite.T(), w, &page)
+	assert.Len(suite.T(), page.Items, 1)
+	assert.Equal(suite.T(), v2.Pagination{Page: 2, PerPage: 2, TotalItems: 3, TotalPages: 2}, page.Pagination)
+
+	for _, query := range []string{"page=0", "page=abc", "per_page=0", "per_page=101"} {
+		w := testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, "/api/v2/words?"+query, nil)
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, query)
+	}
+
+	// Empty pages encode as an empty array
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, "/api/v2/groups", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	assert.Contains(suite.T(), w.Body.String(), `"items":[]`)
+}
+
+// TestPaginationPageOverflow tests that a page whose offset doesn't fit in
+// an int is rejected rather than wrapping around, and that the last page
+// that fits is empty
+func (suite *HandlerTestSuite) TestPaginationPageOverflow() {
+	suite.createWord("um", "one")
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/users", map[string]string{"name": "Teresa", "role": "teacher"})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var teacher struct {
+		ID int64 `json:"id"`
+	}
+	testutil.ParseResponse(suite.T(), w, &teacher)
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/classrooms", map[string]interface{}{"name": "A1", "teacher_id": teacher.ID})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var classroom struct {
+		ID int64 `json:"id"`
+	}
+	testutil.ParseResponse(suite.T(), w, &classroom)
+
+	maxPage := math.MaxInt / 20
+	for _, path := range []string{"/api/v2/words", fmt.Sprintf("/api/v2/classrooms/%d/assignments", classroom.ID), fmt.Sprintf("/api/v2/users/%d/assignments", teacher.ID)} {
+		w := testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, path+"?page=922337203685477581", nil)
+		testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+		var response middleware.ErrorResponse
+		testutil.ParseResponse(suite.T(), w, &response)
+		assert.Equal(suite.T(), []service.FieldError{{Field: "page", Message: fmt.Sprintf("must be at most %d", maxPage)}}, response.Fields, path)
+
+		w = testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, fmt.Sprintf("%s?page=%d", path, maxPage), nil)
+		testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+		assert.Contains(suite.T(), w.Body.String(), `"items":[]`, path)
+	}
+}
+
+// TestGroupWords tests adding words with an object body and listing them
+func (suite *HandlerTestSuite) TestGroupWords() {
+	word := suite.createWord("olá", "hello")
+
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/groups", v2.GroupInput{Name: "Greetings"})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var group v2.Group
+	testutil.ParseResponse(suite.T(), w, &group)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, fmt.Sprintf("/api/v2/groups/%d/words", group.ID), v2.GroupWordsInput{WordIDs: []int64{word.ID}})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNoContent)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, fmt.Sprintf("/api/v2/groups/%d", group.ID), nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	testutil.ParseResponse(suite.T(), w, &group)
+	require.NotNil(suite.T(), group.WordCount)
+	assert.Equal(suite.T(), 1, *group.WordCount)
+	require.NotNil(suite.T(), group.Mastery)
+	assert.Equal(suite.T(), 1, group.Mastery.New)
+	assert.Nil(suite.T(), group.Mastery.LastStudiedAt)
+
+	// Unlike v1, listing the words of an unknown group is a 404
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, "/api/v2/groups/999999/words", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+}
+
+// TestStudySessions tests starting a session and reading it back from the dashboard
+func (suite *HandlerTestSuite) TestStudySessions() {
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, "/api/v2/dashboard/last_study_session", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNotFound)
+
+	result, err := suite.db.DB.Exec("INSERT INTO groups (name) VALUES ('Greetings')")
+	require.NoError(suite.T(), err)
+	groupID, _ := result.LastInsertId()
+	result, err = suite.db.DB.Exec("INSERT INTO study_activities (name, thumbnail_url, description) VALUES ('Flashcards', '', '')")
+	require.NoError(suite.T(), err)
+	activityID, _ := result.LastInsertId()
+
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/study_sessions", v2.StudySessionInput{GroupID: groupID, StudyActivityID: activityID})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var created v2.StudySession
+	testutil.ParseResponse(suite.T(), w, &created)
+	assert.False(suite.T(), created.StartedAt.IsZero())
+
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, "/api/v2/dashboard/last_study_session", nil)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusOK)
+	var last v2.StudySession
+	testutil.ParseResponse(suite.T(), w, &last)
+	assert.Equal(suite.T(), created.ID, last.ID)
+	assert.Equal(suite.T(), "Greetings", last.GroupName)
+	assert.Equal(suite.T(), "Flashcards", last.ActivityName)
+
+	word := suite.createWord("olá", "hello")
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, fmt.Sprintf("/api/v2/study_sessions/%d/reviews", created.ID), map[string]interface{}{"word_id": word.ID, "correct": true})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var review v2.Review
+	testutil.ParseResponse(suite.T(), w, &review)
+	assert.NotZero(suite.T(), review.ID)
+	assert.Equal(suite.T(), created.ID, review.StudySessionID)
+	assert.True(suite.T(), review.Correct)
+
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, fmt.Sprintf("/api/v2/study_sessions/%d/reviews", created.ID), map[string]interface{}{"word_id": word.ID})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+}
+
+func (suite *HandlerTestSuite) createGroup(name string, wordIDs ...int64) v2.Group {
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/groups", v2.GroupInput{Name: name})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var group v2.Group
+	testutil.ParseResponse(suite.T(), w, &group)
+
+	if len(wordIDs) > 0 {
+		w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, fmt.Sprintf("/api/v2/groups/%d/words", group.ID), v2.GroupWordsInput{WordIDs: wordIDs})
+		require.Equal(suite.T(), http.StatusNoContent, w.Code, w.Body.String())
+	}
+	return group
+}
+
+func (suite *HandlerTestSuite) countRows(table string) int {
+	var count int
+	require.NoError(suite.T(), suite.db.DB.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+	return count
+}
+
+// TestRecordReviews tests that a batch of reviews is recorded all or nothing
+func (suite *HandlerTestSuite) TestRecordReviews() {
+	hello, bye := suite.createWord("olá", "hello"), suite.createWord("tchau", "bye")
+	group := suite.createGroup("Greetings", hello.ID, bye.ID)
+	result, err := suite.db.DB.Exec("INSERT INTO study_activities (name, thumbnail_url, description) VALUES ('Flashcards', '', '')")
+	require.NoError(suite.T(), err)
+	activityID, _ := result.LastInsertId()
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/study_sessions", v2.StudySessionInput{GroupID: group.ID, StudyActivityID: activityID})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var session v2.StudySession
+	testutil.ParseResponse(suite.T(), w, &session)
+	path := fmt.Sprintf("/api/v2/study_sessions/%d/reviews/batch", session.ID)
+
+	// An unknown word rejects the whole batch
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, path, map[string]interface{}{
+		"reviews": []map[string]interface{}{
+			{"word_id": hello.ID, "correct": true},
+			{"word_id": 999999, "correct": false},
+		},
+	})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+	require.Len(suite.T(), response.Fields, 1)
+	assert.Equal(suite.T(), "reviews[1].word_id", response.Fields[0].Field)
+	assert.Zero(suite.T(), suite.countRows("word_review_items"))
+
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, path, map[string]interface{}{
+		"reviews": []map[string]interface{}{
+			{"word_id": hello.ID, "correct": true},
+			{"word_id": bye.ID, "correct": false},
+		},
+	})
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var reviews []v2.Review
+	testutil.ParseResponse(suite.T(), w, &reviews)
+	require.Len(suite.T(), reviews, 2)
+	assert.Equal(suite.T(), hello.ID, reviews[0].WordID)
+	assert.True(suite.T(), reviews[0].Correct)
+	assert.Equal(suite.T(), bye.ID, reviews[1].WordID)
+	assert.False(suite.T(), reviews[1].Correct)
+	assert.Equal(suite.T(), 2, suite.countRows("word_review_items"))
+}
+
+// TestMoveGroupWords tests moving words between groups, all or nothing
+func (suite *HandlerTestSuite) TestMoveGroupWords() {
+	hello, bye := suite.createWord("olá", "hello"), suite.createWord("tchau", "bye")
+	from := suite.createGroup("Greetings", hello.ID, bye.ID)
+	to := suite.createGroup("Farewells", bye.ID)
+	path := fmt.Sprintf("/api/v2/groups/%d/words/move", from.ID)
+
+	// bye is already in the target, so hello stays where it was too
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, path, v2.MoveWordsInput{WordIDs: []int64{hello.ID, bye.ID}, ToGroupID: to.ID})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusConflict)
+	assert.Equal(suite.T(), 3, suite.countRows("words_groups"))
+
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, path, v2.MoveWordsInput{WordIDs: []int64{hello.ID}, ToGroupID: to.ID})
+	testutil.AssertStatusCode(suite.T(), w, http.StatusNoContent)
+
+	var group v2.Group
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, fmt.Sprintf("/api/v2/groups/%d", to.ID), nil)
+	testutil.ParseResponse(suite.T(), w, &group)
+	require.NotNil(suite.T(), group.WordCount)
+	assert.Equal(suite.T(), 2, *group.WordCount)
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodGet, fmt.Sprintf("/api/v2/groups/%d", from.ID), nil)
+	testutil.ParseResponse(suite.T(), w, &group)
+	require.NotNil(suite.T(), group.WordCount)
+	assert.Equal(suite.T(), 1, *group.WordCount)
+}
+
+// TestImportGroup tests creating a group with its words in one request
+func (suite *HandlerTestSuite) TestImportGroup() {
+	input := v2.GroupImportInput{
+		Name:  "Greetings",
+		Words: []v2.WordInput{{Term: "olá", Translation: "hello"}, {Term: "tchau", Translation: "bye"}},
+	}
+	w := testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/groups/import", input)
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	var group v2.Group
+	testutil.ParseResponse(suite.T(), w, &group)
+	assert.Equal(suite.T(), "Greetings", group.Name)
+	require.NotNil(suite.T(), group.WordCount)
+	assert.Equal(suite.T(), 2, *group.WordCount)
+
+	// The name is taken: no words are created either
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/groups/import", input)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusConflict)
+	assert.Equal(suite.T(), 2, suite.countRows("words"))
+
+	input = v2.GroupImportInput{Name: "Numbers", Words: []v2.WordInput{{Term: "um", Translation: "one"}, {Term: " ", Translation: "two"}}}
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/groups/import", input)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+	var response middleware.ErrorResponse
+	testutil.ParseResponse(suite.T(), w, &response)
+	require.Len(suite.T(), response.Fields, 1)
+	assert.Equal(suite.T(), "words[1].term", response.Fields[0].Field)
+
+	input = v2.GroupImportInput{Name: "Numbers", Words: []v2.WordInput{{Term: "um", Translation: "one"}, {Term: "um ", Translation: "one"}}}
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/groups/import", input)
+	testutil.AssertStatusCode(suite.T(), w, http.StatusBadRequest)
+	testutil.ParseResponse(suite.T(), w, &response)
+	assert.Equal(suite.T(), []service.FieldError{{Field: "words[1]", Message: "is a duplicate"}}, response.Fields)
+
+	// Words that already exist are added to the group instead of created
+	input = v2.GroupImportInput{Name: "Polite", Words: []v2.WordInput{{Term: "olá", Translation: "hello"}, {Term: "obrigado", Translation: "thanks"}}}
+	w = testutil.PerformRequest(suite.T(), suite.router, http.MethodPost, "/api/v2/groups/import", input)
+	require.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+	testutil.ParseResponse(suite.T(), w, &group)
+	require.NotNil(suite.T(), group.WordCount)
+	assert.Equal(suite.T(), 2, *group.WordCount)
+	assert.Equal(suite.T(), 3, suite.countRows("words"))
+}
+
+// TestHandlerTestSuite runs the test suite
+func TestHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HandlerTestSuite))
+}
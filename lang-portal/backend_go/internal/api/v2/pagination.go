@@ -0,0 +1,105 @@
+package v2
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// Pagination describes the page returned by a list endpoint
+type Pagination struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	TotalItems int `json:"total_items"`
+	TotalPages int `json:"total_pages"`
+}
+
+// Page is the envelope of every v2 list response
+type Page struct {
+	Items      interface{} `json:"items"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// pageParams holds the parsed page and per_page query parameters
+type pageParams struct {
+	page    int
+	perPage int
+}
+
+func (p pageParams) offset() int {
+	return (p.page - 1) * p.perPage
+}
+
+// parsePage reads page and per_page, recording a validation error if either is invalid
+func parsePage(c *gin.Context) (pageParams, bool) {
+	params := pageParams{page: 1, perPage: defaultPerPage}
+	var fields []service.FieldError
+
+	if raw, ok := c.GetQuery("page"); ok {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			fields = append(fields, service.FieldError{Field: "page", Message: "must be a positive integer"})
+		}
+		params.page = page
+	}
+
+	if raw, ok := c.GetQuery("per_page"); ok {
+		perPage, err := strconv.Atoi(raw)
+		switch {
+		case err != nil || perPage < 1:
+			fields = append(fields, service.FieldError{Field: "per_page", Message: "must be a positive integer"})
+		case perPage > maxPerPage:
+			fields = append(fields, service.FieldError{Field: "per_page", Message: fmt.Sprintf("must be at most %d", maxPerPage)})
+		}
+		params.perPage = perPage
+	}
+
+	// The offset of the page, and of its end, must fit in an int
+	if len(fields) == 0 {
+		if maxPage := math.MaxInt / params.perPage; params.page > maxPage {
+			fields = append(fields, service.FieldError{Field: "page", Message: fmt.Sprintf("must be at most %d", maxPage)})
+		}
+	}
+
+	if len(fields) > 0 {
+		c.Error(service.Validation("invalid query parameters", fields...))
+		return pageParams{}, false
+	}
+	return params, true
+}
+
+// newPage wraps a page of items. Items must be a non-nil slice so empty
+// pages encode as [].
+func newPage(items interface{}, params pageParams, total int) Page {
+	return Page{
+		Items: items,
+		Pagination: Pagination{
+			Page:       params.page,
+			PerPage:    params.perPage,
+			TotalItems: total,
+			TotalPages: (total + params.perPage - 1) / params.perPage,
+		},
+	}
+}
+
+// paginate returns the part of items on the requested page, for lists that
+// are built in memory
+func paginate[T any](items []T, params pageParams) []T {
+	start := params.offset()
+	if start < 0 || start >= len(items) {
+		return []T{}
+	}
+	end := start + params.perPage
+	if end < start || end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
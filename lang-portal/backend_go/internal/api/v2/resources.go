@@ -0,0 +1,310 @@
+package v2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+// Language codes of the two sides of a word
+const (
+	TermLanguage        = "pt"
+	TranslationLanguage = "en"
+)
+
+// WordStats holds the review statistics of a word
+type WordStats struct {
+	CorrectCount   int        `json:"correct_count"`
+	WrongCount     int        `json:"wrong_count"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+	// Streak is the number of correct answers since the last wrong one
+	Streak int `json:"streak"`
+}
+
+// Word is a vocabulary entry. Term is in TermLanguage and Translation in
+// TranslationLanguage.
+type Word struct {
+	ID                  int64      `json:"id"`
+	Term                string     `json:"term"`
+	Translation         string     `json:"translation"`
+	TermLanguage        string     `json:"term_language"`
+	TranslationLanguage string     `json:"translation_language"`
+	Stats               *WordStats `json:"stats,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// GroupRef identifies a group a word belongs to
+type GroupRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// WordDetail is a word with the groups it belongs to
+type WordDetail struct {
+	Word
+	Groups []GroupRef `json:"groups"`
+}
+
+// WordInput is the body for creating or updating a word
+type WordInput struct {
+	Term        string `json:"term"`
+	Translation string `json:"translation"`
+}
+
+// wordInputFields maps service field names to v2 field names
+var wordInputFields = map[string]string{
+	"portuguese": "term",
+	"english":    "translation",
+}
+
+func (in WordInput) toModel() models.WordInput {
+	return models.WordInput{Portuguese: in.Term, English: in.Translation}
+}
+
+// Group is a thematic group of words. WordCount and Mastery are omitted
+// where they aren't computed.
+type Group struct {
+	ID        int64         `json:"id"`
+	Name      string        `json:"name"`
+	WordCount *int          `json:"word_count,omitempty"`
+	Mastery   *GroupMastery `json:"mastery,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// GroupMastery counts the words of a group by state: new, learning,
+// mastered or lapsed
+type GroupMastery struct {
+	New      int `json:"new"`
+	Learning int `json:"learning"`
+	Mastered int `json:"mastered"`
+	Lapsed   int `json:"lapsed"`
+	// PercentComplete is the percentage of the words mastered
+	PercentComplete float64       `json:"percent_complete"`
+	LastStudiedAt   *time.Time    `json:"last_studied_at,omitempty"`
+	NextActivity    *NextActivity `json:"next_activity,omitempty"`
+}
+
+// NextActivity is the study activity recommended next for a group, and the
+// state of the words it should focus on
+type NextActivity struct {
+	StudyActivityID int64  `json:"study_activity_id"`
+	Name            string `json:"name"`
+	Focus           string `json:"focus"`
+}
+
+// GroupInput is the body for creating or renaming a group
+type GroupInput struct {
+	Name string `json:"name"`
+}
+
+// GroupWordsInput is the body for adding words to a group
+type GroupWordsInput struct {
+	WordIDs []int64 `json:"word_ids"`
+}
+
+// MoveWordsInput is the body for moving words from a group to another
+type MoveWordsInput struct {
+	WordIDs   []int64 `json:"word_ids"`
+	ToGroupID int64   `json:"to_group_id" binding:"required"`
+}
+
+// GroupImportInput is the body for creating a group together with its words
+type GroupImportInput struct {
+	Name  string      `json:"name"`
+	Words []WordInput `json:"words"`
+}
+
+func (in GroupImportInput) toModel() models.GroupImport {
+	words := make([]models.WordInput, len(in.Words))
+	for i, word := range in.Words {
+		words[i] = word.toModel()
+	}
+	return models.GroupImport{Name: in.Name, Words: words}
+}
+
+// fieldNames maps the service field names of an import to v2 names
+func (in GroupImportInput) fieldNames() map[string]string {
+	names := make(map[string]string, 2*len(in.Words))
+	for i := range in.Words {
+		for service, v2 := range wordInputFields {
+			names[fmt.Sprintf("words[%d].%s", i, service)] = fmt.Sprintf("words[%d].%s", i, v2)
+		}
+	}
+	return names
+}
+
+// StudyActivity is a kind of exercise a study session runs
+type StudyActivity struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	Description  string    `json:"description"`
+	Difficulty   int       `json:"difficulty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// StudySession is one run of a study activity on a group
+type StudySession struct {
+	ID              int64      `json:"id"`
+	StudyActivityID int64      `json:"study_activity_id"`
+	GroupID         int64      `json:"group_id"`
+	UserID          *int64     `json:"user_id,omitempty"`
+	ActivityName    string     `json:"activity_name,omitempty"`
+	GroupName       string     `json:"group_name,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	LastReviewedAt  *time.Time `json:"last_reviewed_at,omitempty"`
+	// EndedAt is set once the session has ended and takes no more reviews
+	EndedAt          *time.Time `json:"ended_at,omitempty"`
+	ReviewItemsCount int        `json:"review_items_count"`
+}
+
+// StudySessionInput is the body for starting a study session
+type StudySessionInput struct {
+	GroupID         int64  `json:"group_id" binding:"required"`
+	StudyActivityID int64  `json:"study_activity_id" binding:"required"`
+	UserID          *int64 `json:"user_id"`
+}
+
+// Review is the answer given for a word during a study session
+type Review struct {
+	ID             int64 `json:"id"`
+	StudySessionID int64 `json:"study_session_id"`
+	WordID         int64 `json:"word_id"`
+	Correct        bool  `json:"correct"`
+	// XP is what the review earned, more for answers right in a row
+	XP        int       `json:"xp"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewInput is the body for recording a review. Correct is a pointer so
+// that a missing value is rejected rather than read as false.
+type ReviewInput struct {
+	WordID  int64 `json:"word_id" binding:"required"`
+	Correct *bool `json:"correct" binding:"required"`
+}
+
+// ReviewBatchInput is the body for recording several reviews at once
+type ReviewBatchInput struct {
+	Reviews []ReviewBatchItem `json:"reviews" binding:"dive"`
+}
+
+// ReviewBatchItem is one review of a batch. Correct is a pointer so that a
+// missing value is rejected rather than read as false.
+type ReviewBatchItem struct {
+	WordID  int64 `json:"word_id"`
+	Correct *bool `json:"correct" binding:"required"`
+}
+
+func (in ReviewBatchInput) toModel() []models.ReviewInput {
+	reviews := make([]models.ReviewInput, len(in.Reviews))
+	for i, review := range in.Reviews {
+		reviews[i] = models.ReviewInput{WordID: review.WordID, Correct: *review.Correct}
+	}
+	return reviews
+}
+
+func newReview(r *models.WordReviewItem) Review {
+	return Review{
+		ID:             r.ID,
+		StudySessionID: r.StudySessionID,
+		WordID:         r.WordID,
+		Correct:        r.Correct,
+		XP:             r.XP,
+		CreatedAt:      r.CreatedAt,
+	}
+}
+
+func newWord(w *models.WordWithStats) Word {
+	return Word{
+		ID:                  w.ID,
+		Term:                w.Portuguese,
+		Translation:         w.English,
+		TermLanguage:        TermLanguage,
+		TranslationLanguage: TranslationLanguage,
+		Stats: &WordStats{
+			CorrectCount:   w.CorrectCount,
+			WrongCount:     w.WrongCount,
+			LastReviewedAt: w.LastReviewedAt,
+			Streak:         w.Streak,
+		},
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+func newPlainWord(w *models.Word) Word {
+	return Word{
+		ID:                  w.ID,
+		Term:                w.Portuguese,
+		Translation:         w.English,
+		TermLanguage:        TermLanguage,
+		TranslationLanguage: TranslationLanguage,
+		CreatedAt:           w.CreatedAt,
+	}
+}
+
+func newWords(words []*models.WordWithStats) []Word {
+	result := make([]Word, 0, len(words))
+	for _, w := range words {
+		result = append(result, newWord(w))
+	}
+	return result
+}
+
+func newGroup(g *models.Group) Group {
+	return Group{ID: g.ID, Name: g.Name, CreatedAt: g.CreatedAt}
+}
+
+func newGroupWithStats(g *models.GroupWithStats) Group {
+	group := newGroup(&g.Group)
+	wordCount := g.WordCount
+	group.WordCount = &wordCount
+	if m := g.GroupMastery; m != nil {
+		group.Mastery = &GroupMastery{
+			New:             m.NewCount,
+			Learning:        m.LearningCount,
+			Mastered:        m.MasteredCount,
+			Lapsed:          m.LapsedCount,
+			PercentComplete: m.PercentComplete,
+			LastStudiedAt:   m.LastStudiedAt,
+		}
+		if next := m.NextActivity; next != nil {
+			group.Mastery.NextActivity = &NextActivity{StudyActivityID: next.StudyActivityID, Name: next.Name, Focus: string(next.Focus)}
+		}
+	}
+	return group
+}
+
+func newStudyActivity(a models.StudyActivity) StudyActivity {
+	return StudyActivity{
+		ID:           a.ID,
+		Name:         a.Name,
+		ThumbnailURL: a.ThumbnailURL,
+		Description:  a.Description,
+		Difficulty:   a.Difficulty,
+		CreatedAt:    a.CreatedAt,
+	}
+}
+
+func newStudySession(s models.StudySessionDetail) StudySession {
+	return StudySession{
+		ID:               s.ID,
+		StudyActivityID:  s.StudyActivityID,
+		GroupID:          s.GroupID,
+		UserID:           s.UserID,
+		ActivityName:     s.ActivityName,
+		GroupName:        s.GroupName,
+		StartedAt:        s.CreatedAt,
+		LastReviewedAt:   s.EndTime,
+		EndedAt:          s.EndedAt,
+		ReviewItemsCount: s.ReviewItemsCount,
+	}
+}
+
+func newStudySessions(sessions []models.StudySessionDetail) []StudySession {
+	result := make([]StudySession, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, newStudySession(s))
+	}
+	return result
+}
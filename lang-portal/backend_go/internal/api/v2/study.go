@@ -0,0 +1,184 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListStudyActivities(c *gin.Context) {
+	params, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	activities, total, err := h.studyActivityService.ListStudyActivitiesPaginated(c.Request.Context(), params.page, params.perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	items := make([]StudyActivity, 0, len(activities))
+	for _, a := range activities {
+		items = append(items, newStudyActivity(a))
+	}
+	c.JSON(http.StatusOK, newPage(items, params, total))
+}
+
+func (h *Handler) GetStudyActivity(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	activity, err := h.studyActivityService.GetStudyActivity(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newStudyActivity(*activity))
+}
+
+func (h *Handler) ListStudyActivitySessions(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	params, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.studyActivityService.GetStudyActivity(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	sessions, total, err := h.studyActivityService.GetStudyActivitySessions(c.Request.Context(), id, params.page, params.perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newPage(newStudySessions(sessions), params, total))
+}
+
+func (h *Handler) ListStudySessions(c *gin.Context) {
+	params, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.studyActivityService.ListStudySessions(c.Request.Context(), params.offset(), params.perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	total, err := h.studyActivityService.CountStudySessions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newPage(newStudySessions(sessions), params, total))
+}
+
+// CreateStudySession starts a study session. In v1 this is
+// POST /api/study_activities.
+func (h *Handler) CreateStudySession(c *gin.Context) {
+	var input StudySessionInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	session, err := h.studyActivityService.CreateStudySession(c.Request.Context(), input.GroupID, input.StudyActivityID, input.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, StudySession{
+		ID:              session.ID,
+		StudyActivityID: session.StudyActivityID,
+		GroupID:         session.GroupID,
+		UserID:          session.UserID,
+		StartedAt:       session.CreatedAt,
+	})
+}
+
+// RecordReview records whether a word was answered correctly in a study
+// session. In v1 this is POST /api/study_sessions/:id/words/:word_id/review.
+func (h *Handler) RecordReview(c *gin.Context) {
+	sessionID, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	var input ReviewInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	review, err := h.studyActivityService.RecordReview(c.Request.Context(), sessionID, input.WordID, *input.Correct)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, newReview(review))
+}
+
+// RecordReviews records a batch of reviews in a study session, all or none
+func (h *Handler) RecordReviews(c *gin.Context) {
+	sessionID, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+	var input ReviewBatchInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	reviews, err := h.studyActivityService.RecordReviews(c.Request.Context(), sessionID, input.toModel())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	items := make([]Review, 0, len(reviews))
+	for _, review := range reviews {
+		items = append(items, newReview(review))
+	}
+	c.JSON(http.StatusCreated, items)
+}
+
+// GetLastStudySession returns the most recent study session, or 404 when
+// there are none
+func (h *Handler) GetLastStudySession(c *gin.Context) {
+	session, err := h.dashboardService.GetLastStudySession(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if session == nil {
+		c.Error(service.NotFound("study session"))
+		return
+	}
+	c.JSON(http.StatusOK, newStudySession(*session))
+}
+
+func (h *Handler) GetStudyProgress(c *gin.Context) {
+	progress, err := h.dashboardService.GetStudyProgress(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetQuickStats counts the guest's study streak, the dashboard being theirs,
+// in the time zone named by the tz query parameter or of their daily goal
+func (h *Handler) GetQuickStats(c *gin.Context) {
+	stats, err := h.dashboardService.GetQuickStats(c.Request.Context(), 0, c.Query("tz"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
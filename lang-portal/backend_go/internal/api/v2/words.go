@@ -0,0 +1,99 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/api/request"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListWords(c *gin.Context) {
+	params, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	words, total, err := h.wordService.ListWordsWithStatsPaginated(c.Request.Context(), params.page, params.perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newPage(newWords(words), params, total))
+}
+
+func (h *Handler) GetWord(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	detail, err := h.wordService.GetWordDetail(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	word, err := h.wordService.GetWord(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := WordDetail{
+		Word:   newPlainWord(word),
+		Groups: make([]GroupRef, 0, len(detail.Groups)),
+	}
+	response.Stats = &WordStats{
+		CorrectCount: detail.Stats.CorrectCount,
+		WrongCount:   detail.Stats.WrongCount,
+	}
+	for _, g := range detail.Groups {
+		response.Groups = append(response.Groups, GroupRef{ID: g.ID, Name: g.Name})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) CreateWord(c *gin.Context) {
+	var input WordInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	word, err := h.wordService.CreateWord(c.Request.Context(), input.toModel())
+	if err != nil {
+		c.Error(renameFields(err, wordInputFields))
+		return
+	}
+	c.JSON(http.StatusCreated, newPlainWord(word))
+}
+
+func (h *Handler) UpdateWord(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var input WordInput
+	if !request.BindJSON(c, &input) {
+		return
+	}
+
+	word, err := h.wordService.UpdateWord(c.Request.Context(), id, input.toModel())
+	if err != nil {
+		c.Error(renameFields(err, wordInputFields))
+		return
+	}
+	c.JSON(http.StatusOK, newPlainWord(word))
+}
+
+func (h *Handler) DeleteWord(c *gin.Context) {
+	id, ok := request.ParseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.wordService.DeleteWord(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
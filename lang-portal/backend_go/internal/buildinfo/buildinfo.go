@@ -0,0 +1,39 @@
+// Package buildinfo describes the running binary. Version and Commit are set
+// at build time by the Build mage target:
+//
+//	go build -ldflags "-X github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/buildinfo.Version=v1.2.0 ..."
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	// Version is the release the binary was built from
+	Version = "dev"
+	// Commit is the git commit the binary was built from. When it isn't set
+	// with -ldflags, the revision recorded by the go tool is used.
+	Commit = ""
+)
+
+// GitCommit returns Commit, falling back to the VCS revision stamped by
+// `go build`, or "unknown"
+func GitCommit() string {
+	if Commit != "" {
+		return Commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
+
+// GoVersion returns the version of Go the binary was built with
+func GoVersion() string {
+	return runtime.Version()
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/dialect"
+)
+
+// Store is an open database: the connection pools the repositories, the
+// migrations and the seeds run on. Stores are independent of each other, so
+// a process can open several, e.g. one per test.
+type Store struct {
+	// DB runs migrations, seeds, writes and transactions. For SQLite it is
+	// a single connection, as SQLite allows one writer at a time.
+	DB *sql.DB
+	// Read runs the queries made outside transactions. For SQLite it is a
+	// pool of read-only connections; for Postgres it is DB.
+	Read *sql.DB
+}
+
+// Open connects to the database described by cfg: a SQLite file, which is
+// created along with its directory if needed, or a Postgres server
+func Open(cfg config.Database) (*Store, error) {
+	d, err := dialect.Parse(cfg.Driver)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []any{"driver", d.Name()}
+	var store *Store
+	if d == dialect.SQLite {
+		attrs = append(attrs, "path", cfg.Path, "journal_mode", cfg.SQLite.JournalMode, "readers", cfg.SQLite.Readers)
+		store, err = openSQLite(cfg.Path, cfg.SQLite)
+	} else {
+		var db *sql.DB
+		db, err = open(d.DriverName(), cfg.URL)
+		store = &Store{DB: db, Read: db}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("database connection established", attrs...)
+	return store, nil
+}
+
+// Close closes both pools
+func (s *Store) Close() error {
+	if s.Read != nil && s.Read != s.DB {
+		if err := s.Read.Close(); err != nil {
+			s.DB.Close()
+			return err
+		}
+	}
+	return s.DB.Close()
+}
+
+// openSQLite opens the writer to the SQLite database at path, creating it
+// and its directory if needed, then the readers. The writer goes first so
+// that the journal mode is set before the readers connect.
+func openSQLite(path string, cfg config.SQLite) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating database directory: %v", err)
+	}
+
+	write, err := open(dialect.SQLite.DriverName(), sqliteDSN(path, cfg, false))
+	if err != nil {
+		return nil, err
+	}
+	write.SetMaxOpenConns(1)
+
+	read, err := open(dialect.SQLite.DriverName(), sqliteDSN(path, cfg, true))
+	if err != nil {
+		write.Close()
+		return nil, err
+	}
+	read.SetMaxOpenConns(cfg.Readers)
+	read.SetMaxIdleConns(cfg.Readers)
+	return &Store{DB: write, Read: read}, nil
+}
+
+// sqliteDSN returns the data source name opening the SQLite database at
+// path with cfg's pragmas. Read-only connections refuse to write. Writers
+// take the write lock when their transaction begins rather than at its
+// first write, so that a transaction waits for the lock for up to the busy
+// timeout instead of failing when another writer holds it.
+func sqliteDSN(path string, cfg config.SQLite, readOnly bool) string {
+	params := url.Values{}
+	params.Set("_journal_mode", cfg.JournalMode)
+	params.Set("_foreign_keys", strconv.FormatBool(cfg.ForeignKeys))
+	params.Set("_busy_timeout", strconv.FormatInt(cfg.BusyTimeout.Milliseconds(), 10))
+	params.Set("_synchronous", cfg.Synchronous)
+	if readOnly {
+		params.Set("_query_only", "true")
+	} else {
+		params.Set("_txlock", "immediate")
+	}
+	return path + "?" + params.Encode()
+}
+
+// open opens a pool and checks that the database answers
+func open(driver, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %v", err)
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to the database: %v", err)
+	}
+	return db, nil
+}
+
+// Dir returns the directory holding db's main database file, or "" for an
+// in-memory SQLite database or a Postgres server
+func Dir(ctx context.Context, db *sql.DB) (string, error) {
+	if dialect.Of(db) != dialect.SQLite {
+		return "", nil
+	}
+	rows, err := db.QueryContext(ctx, "PRAGMA database_list")
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seq int
+		var name, file string
+		if err := rows.Scan(&seq, &name, &file); err != nil {
+			return "", err
+		}
+		if name == "main" {
+			if file == "" {
+				return "", nil
+			}
+			return filepath.Dir(file), nil
+		}
+	}
+	return "", rows.Err()
+}
+
+// CheckWritable reports whether files can be created in dir, which SQLite
+// needs for its journal
+func CheckWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSQLitePragmas tests that the configured pragmas are set on the
+// connections of both pools and that the readers can't write
+func TestSQLitePragmas(t *testing.T) {
+	t.Parallel()
+	cfg := config.Default().Database
+	cfg.Path = filepath.Join(t.TempDir(), "data", "learning.db")
+	store, err := Open(cfg)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for name, db := range map[string]*sql.DB{"writer": store.DB, "reader": store.Read} {
+		var journalMode string
+		var foreignKeys, busyTimeout, synchronous int
+		require.NoError(t, db.QueryRow("PRAGMA journal_mode").Scan(&journalMode), name)
+		require.NoError(t, db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys), name)
+		require.NoError(t, db.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout), name)
+		require.NoError(t, db.QueryRow("PRAGMA synchronous").Scan(&synchronous), name)
+		assert.Equal(t, "wal", journalMode, name)
+		assert.Equal(t, 1, foreignKeys, name)
+		assert.Equal(t, 5000, busyTimeout, name)
+		assert.Equal(t, 1, synchronous, name, "NORMAL")
+	}
+	assert.Equal(t, 1, store.DB.Stats().MaxOpenConnections)
+	assert.Equal(t, cfg.SQLite.Readers, store.Read.Stats().MaxOpenConnections)
+
+	require.NoError(t, RunMigrations(store))
+	_, err = store.Read.Exec("INSERT INTO groups (name) VALUES ('Greetings')")
+	assert.Error(t, err, "readers are read-only")
+}
+
+// TestStoresAreIsolated tests that a process can open several databases,
+// each migrated and seeded on its own
+func TestStoresAreIsolated(t *testing.T) {
+	t.Parallel()
+	seeded, err := NewTestDB()
+	require.NoError(t, err)
+	defer seeded.Close()
+	empty, err := NewTestDB()
+	require.NoError(t, err)
+	defer empty.Close()
+
+	require.NoError(t, RunSeed(seeded.Store, fstest.MapFS{
+		"greetings/manifest.json":         {Data: []byte(`{"name": "greetings", "version": "1.0.0"}`)},
+		"greetings/words_and_groups.json": {Data: []byte(`{"groups": [{"name": "Greetings", "words": [{"portuguese": "olá", "english": "hello"}]}]}`)},
+	}, SeedOptions{}))
+
+	count := func(store *Store) (words int) {
+		require.NoError(t, store.Read.QueryRow("SELECT COUNT(*) FROM words").Scan(&words))
+		return words
+	}
+	assert.Equal(t, 1, count(seeded.Store))
+	assert.Zero(t, count(empty.Store))
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestXPBackfillMatchesReviewXP tests that the migration awarding XP to the
+// reviews recorded before it applies the rules of models.ReviewXP
+func TestXPBackfillMatchesReviewXP(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+
+	migration, err := migrationFiles.ReadFile("migrations/sqlite/12_gamification.sql")
+	require.NoError(t, err)
+	backfill := string(migration)
+	i := strings.Index(backfill, "UPDATE word_review_items SET xp")
+	j := strings.Index(backfill, "-- Keep the totals of each learner")
+	require.True(t, 0 <= i && i < j)
+	backfill = backfill[i:j]
+
+	_, err = tdb.DB.Exec(`
+		INSERT INTO words (id, portuguese, english) VALUES (1, 'olá', 'hello'), (2, 'adeus', 'goodbye');
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings');
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES (1, 'flashcards', '', '');
+		INSERT INTO study_sessions (id, group_id, study_activity_id) VALUES (1, 1, 1), (2, 1, 1), (3, 1, 1);
+	`)
+	require.NoError(t, err)
+
+	// Sessions are interleaved, and mostly answered right so that combos grow
+	rng := rand.New(rand.NewSource(1))
+	combos := map[int64]int{}
+	want := map[int64]int{}
+	for n := 0; n < 300; n++ {
+		sessionID := int64(1 + rng.Intn(3))
+		correct := rng.Float64() < 0.85
+		result, err := tdb.DB.Exec("INSERT INTO word_review_items (word_id, study_session_id, correct) VALUES (?, ?, ?)",
+			1+rng.Intn(2), sessionID, correct)
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+
+		combos[sessionID]++
+		if !correct {
+			combos[sessionID] = 0
+		}
+		want[id] = models.ReviewXP(combos[sessionID])
+	}
+
+	_, err = tdb.DB.Exec(backfill)
+	require.NoError(t, err)
+	rows, err := tdb.DB.Query("SELECT id, xp FROM word_review_items")
+	require.NoError(t, err)
+	defer rows.Close()
+	got := map[int64]int{}
+	for rows.Next() {
+		var id int64
+		var xp int
+		require.NoError(t, rows.Scan(&id, &xp))
+		got[id] = xp
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, want, got)
+	tripled := 0
+	for _, xp := range got {
+		if xp == 3*models.XPPerReview {
+			tripled++
+		}
+	}
+	assert.NotZero(t, tripled, "combos should reach the highest multiplier")
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLearnerStatsTriggersMatchRecount tests that the triggers keep
+// learner_stats in step with the reviews of each learner as reviews,
+// sessions and users are deleted, and that the migration fills it the same
+func TestLearnerStatsTriggersMatchRecount(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+
+	_, err = tdb.DB.Exec(`
+		INSERT INTO words (id, portuguese, english) VALUES (1, 'olá', 'hello'), (2, 'adeus', 'goodbye'), (3, 'sim', 'yes');
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings');
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES (1, 'flashcards', '', '');
+		INSERT INTO users (id, name, role) VALUES (1, 'Ana', 'student'), (2, 'Rui', 'student');
+		INSERT INTO study_sessions (id, group_id, study_activity_id, user_id) VALUES
+			(1, 1, 1, NULL), (2, 1, 1, 1), (3, 1, 1, 1), (4, 1, 1, 2), (5, 1, 1, 2);
+	`)
+	require.NoError(t, err)
+
+	type totals struct{ reviews, xp, learned int }
+	stored := func() map[int64]totals {
+		t.Helper()
+		rows, err := tdb.DB.Query("SELECT learner_id, reviews, xp, words_learned FROM learner_stats WHERE reviews > 0")
+		require.NoError(t, err)
+		defer rows.Close()
+		all := map[int64]totals{}
+		for rows.Next() {
+			var id int64
+			var s totals
+			require.NoError(t, rows.Scan(&id, &s.reviews, &s.xp, &s.learned))
+			all[id] = s
+		}
+		require.NoError(t, rows.Err())
+		return all
+	}
+	recount := func() map[int64]totals {
+		t.Helper()
+		rows, err := tdb.DB.Query(`
+			SELECT COALESCE(ss.user_id, 0), COUNT(*), SUM(wri.xp), COUNT(DISTINCT CASE WHEN wri.correct THEN wri.word_id END)
+			FROM word_review_items wri
+			JOIN study_sessions ss ON ss.id = wri.study_session_id
+			GROUP BY COALESCE(ss.user_id, 0)
+		`)
+		require.NoError(t, err)
+		defer rows.Close()
+		all := map[int64]totals{}
+		for rows.Next() {
+			var id int64
+			var s totals
+			require.NoError(t, rows.Scan(&id, &s.reviews, &s.xp, &s.learned))
+			all[id] = s
+		}
+		require.NoError(t, rows.Err())
+		return all
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	var ids []int64
+	for n := 0; n < 200; n++ {
+		result, err := tdb.DB.Exec("INSERT INTO word_review_items (word_id, study_session_id, correct, xp) VALUES (?, ?, ?, ?)",
+			1+rng.Intn(3), 1+rng.Intn(5), rng.Float64() < 0.7, 10*rng.Intn(4))
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	assert.Equal(t, recount(), stored())
+
+	rng.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	for _, id := range ids[:150] {
+		_, err = tdb.DB.Exec("DELETE FROM word_review_items WHERE id = ?", id)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, recount(), stored(), "after deleting reviews")
+
+	_, err = tdb.DB.Exec("DELETE FROM study_sessions WHERE id = 2")
+	require.NoError(t, err)
+	assert.Equal(t, recount(), stored(), "after deleting a session")
+
+	_, err = tdb.DB.Exec("DELETE FROM users WHERE id = 2")
+	require.NoError(t, err)
+	assert.Equal(t, recount(), stored(), "after deleting a user, whose reviews become the guest's")
+	assert.NotContains(t, stored(), int64(2))
+
+	_, err = tdb.DB.Exec("DELETE FROM words WHERE id = 1")
+	require.NoError(t, err)
+	assert.Equal(t, recount(), stored(), "after deleting a word")
+
+	migration, err := migrationFiles.ReadFile("migrations/sqlite/12_gamification.sql")
+	require.NoError(t, err)
+	backfill := string(migration)
+	i := strings.Index(backfill, "-- Fill them")
+	require.GreaterOrEqual(t, i, 0)
+	_, err = tdb.DB.Exec("DELETE FROM learner_stats; DELETE FROM learner_words;" + backfill[i:])
+	require.NoError(t, err)
+	assert.Equal(t, recount(), stored(), "after filling them again")
+}
@@ -1,66 +1,83 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"embed"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"path/filepath"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/dialect"
 )
 
-func RunMigrations() error {
-	db, err := InitDB()
-	if err != nil {
-		return fmt.Errorf("failed to initialize database: %v", err)
-	}
+// migrationFiles holds the schema migrations of every dialect, each in a
+// directory named after it and applied in file name order. The dialects'
+// migrations have the same names, so a schema version means the same thing
+// on both. They are embedded so the binary doesn't depend on its working
+// directory.
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
 
-	// Create migrations table if it doesn't exist
-	_, err = db.Exec(`
+// migrationsTable creates the table recording the applied migrations
+var migrationsTable = map[dialect.Dialect]string{
+	dialect.SQLite: `
 		CREATE TABLE IF NOT EXISTS migrations (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			name TEXT NOT NULL UNIQUE,
 			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %v", err)
-	}
+	`,
+	dialect.Postgres: `
+		CREATE TABLE IF NOT EXISTS migrations (
+			id BIGSERIAL PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			applied_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+		)
+	`,
+}
 
-	// Get list of migration files
-	files, err := filepath.Glob("internal/database/migrations/*.sql")
-	if err != nil {
-		return fmt.Errorf("failed to list migration files: %v", err)
+// migrationsTableExists counts the tables named migrations, in the schema
+// queries run in
+var migrationsTableExists = map[dialect.Dialect]string{
+	dialect.SQLite:   "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'migrations'",
+	dialect.Postgres: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'migrations'",
+}
+
+// RunMigrations applies the migrations of store's dialect that it doesn't
+// have yet, then reports orphaned rows
+func RunMigrations(store *Store) error {
+	if err := applyMigrations(store.DB); err != nil {
+		return err
 	}
+	return reportOrphans(context.Background(), store.DB)
+}
 
-	sort.Strings(files)
+// applyMigrations applies the migrations that db doesn't have yet
+func applyMigrations(db *sql.DB) error {
+	d := dialect.Of(db)
 
-	// Get applied migrations
-	rows, err := db.Query("SELECT name FROM migrations")
+	// Create migrations table if it doesn't exist
+	_, err := db.Exec(migrationsTable[d])
 	if err != nil {
-		return fmt.Errorf("failed to query migrations: %v", err)
+		return fmt.Errorf("failed to create migrations table: %v", err)
 	}
-	defer rows.Close()
 
-	applied := make(map[string]bool)
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			return fmt.Errorf("failed to scan migration row: %v", err)
-		}
-		applied[name] = true
+	pending, err := PendingMigrations(context.Background(), db)
+	if err != nil {
+		return err
 	}
 
 	// Apply new migrations
-	for _, file := range files {
-		name := filepath.Base(file)
-		if applied[name] {
-			continue
-		}
-
-		log.Printf("Applying migration: %s", name)
+	for _, name := range pending {
+		slog.Info("applying migration", "name", name)
 
-		content, err := ioutil.ReadFile(file)
+		content, err := migrationFiles.ReadFile(path.Join("migrations", d.Name(), name))
 		if err != nil {
 			return fmt.Errorf("failed to read migration file %s: %v", name, err)
 		}
@@ -70,14 +87,7 @@ func RunMigrations() error {
 			return fmt.Errorf("failed to begin transaction: %v", err)
 		}
 
-		// Split the file into individual statements
-		statements := strings.Split(string(content), ";")
-		for _, stmt := range statements {
-			stmt = strings.TrimSpace(stmt)
-			if stmt == "" {
-				continue
-			}
-
+		for _, stmt := range splitStatements(string(content)) {
 			if _, err := tx.Exec(stmt); err != nil {
 				tx.Rollback()
 				return fmt.Errorf("failed to execute migration %s: %v", name, err)
@@ -85,7 +95,7 @@ func RunMigrations() error {
 		}
 
 		// Record the migration
-		if _, err := tx.Exec("INSERT INTO migrations (name) VALUES (?)", name); err != nil {
+		if _, err := tx.Exec(d.Rebind("INSERT INTO migrations (name) VALUES (?)"), name); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("failed to record migration %s: %v", name, err)
 		}
@@ -94,8 +104,153 @@ func RunMigrations() error {
 			return fmt.Errorf("failed to commit migration %s: %v", name, err)
 		}
 
-		log.Printf("Successfully applied migration: %s", name)
+		slog.Info("applied migration", "name", name)
 	}
 
 	return nil
 }
+
+// Migrations returns the names of the migrations of d built into the
+// binary, in the order they are applied
+func Migrations(d dialect.Dialect) []string {
+	names, err := fs.Glob(migrationFiles, path.Join("migrations", d.Name(), "*.sql"))
+	if err != nil {
+		// The pattern is well formed, so this can't happen
+		panic(err)
+	}
+	for i, name := range names {
+		names[i] = path.Base(name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AppliedMigrations returns the names of the migrations applied to db, in
+// the order they are applied. It is empty for a database that was never
+// migrated.
+func AppliedMigrations(ctx context.Context, db *sql.DB) ([]string, error) {
+	var exists int
+	err := db.QueryRowContext(ctx, migrationsTableExists[dialect.Of(db)]).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for migrations table: %w", err)
+	}
+	if exists == 0 {
+		return []string{}, nil
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT name FROM migrations ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan migration row: %w", err)
+		}
+		applied = append(applied, name)
+	}
+	return applied, rows.Err()
+}
+
+// PendingMigrations returns the names of the built-in migrations of db's
+// dialect not yet applied to it
+func PendingMigrations(ctx context.Context, db *sql.DB) ([]string, error) {
+	applied, err := AppliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(applied))
+	for _, name := range applied {
+		done[name] = true
+	}
+
+	pending := []string{}
+	for _, name := range Migrations(dialect.Of(db)) {
+		if !done[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+// SchemaVersion returns the last migration applied to db without its
+// extension, e.g. "02_classrooms", or "" if none were applied
+func SchemaVersion(ctx context.Context, db *sql.DB) (string, error) {
+	applied, err := AppliedMigrations(ctx, db)
+	if err != nil || len(applied) == 0 {
+		return "", err
+	}
+	return strings.TrimSuffix(applied[len(applied)-1], ".sql"), nil
+}
+
+// splitStatements splits a migration file into its statements, at the
+// semicolons that aren't in a comment, a quoted string, a dollar-quoted
+// Postgres function body or the BEGIN ... END block of a SQLite trigger
+func splitStatements(content string) []string {
+	var statements []string
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch {
+		case strings.HasPrefix(content[i:], "--"):
+			if end := strings.IndexByte(content[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(content)
+			}
+		case content[i] == '\'':
+			if end := strings.IndexByte(content[i+1:], '\''); end >= 0 {
+				i += end + 1
+			}
+		case content[i] == '$':
+			// A tag is $$ or $name$
+			if tag := dollarTag.FindString(content[i:]); tag != "" {
+				if end := strings.Index(content[i+len(tag):], tag); end >= 0 {
+					i += len(tag) + end + len(tag) - 1
+				}
+			}
+		case content[i] == ';':
+			code := stripComments(content[start:i])
+			if createTrigger.MatchString(code) && !blocksClosed(code) {
+				// The semicolon ends a statement of the trigger's body
+				continue
+			}
+			if strings.TrimSpace(code) != "" {
+				statements = append(statements, strings.TrimSpace(content[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	if strings.TrimSpace(stripComments(content[start:])) != "" {
+		statements = append(statements, strings.TrimSpace(content[start:]))
+	}
+	return statements
+}
+
+var (
+	dollarTag     = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+	createTrigger = regexp.MustCompile(`(?i)^\s*CREATE\s+(TEMP\s+|TEMPORARY\s+)?TRIGGER\b`)
+	blockKeyword  = regexp.MustCompile(`(?i)\b(BEGIN|CASE|END)\b`)
+	lineComment   = regexp.MustCompile(`--[^\n]*`)
+)
+
+// stripComments removes the line comments of a statement
+func stripComments(stmt string) string {
+	return lineComment.ReplaceAllString(stmt, "")
+}
+
+// blocksClosed reports whether every BEGIN and CASE of a trigger statement
+// has its END
+func blocksClosed(stmt string) bool {
+	depth := 0
+	for _, keyword := range blockKeyword.FindAllString(stmt, -1) {
+		if strings.EqualFold(keyword, "END") {
+			depth--
+		} else {
+			depth++
+		}
+	}
+	return depth <= 0
+}
@@ -0,0 +1,61 @@
+-- The Postgres schema follows the SQLite one, migration for migration. The
+-- foreign keys are declared in 04_foreign_keys.sql, when SQLite started
+-- enforcing them.
+
+-- Create words table
+CREATE TABLE IF NOT EXISTS words (
+    id BIGSERIAL PRIMARY KEY,
+    portuguese TEXT NOT NULL,
+    english TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create groups table
+CREATE TABLE IF NOT EXISTS groups (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create words_groups table
+CREATE TABLE IF NOT EXISTS words_groups (
+    id BIGSERIAL PRIMARY KEY,
+    word_id BIGINT NOT NULL,
+    group_id BIGINT NOT NULL,
+    UNIQUE(word_id, group_id)
+);
+
+-- Create study_activities table
+CREATE TABLE IF NOT EXISTS study_activities (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    thumbnail_url TEXT NOT NULL,
+    description TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create study_sessions table
+CREATE TABLE IF NOT EXISTS study_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL,
+    study_activity_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create word_review_items table
+CREATE TABLE IF NOT EXISTS word_review_items (
+    id BIGSERIAL PRIMARY KEY,
+    word_id BIGINT NOT NULL,
+    study_session_id BIGINT NOT NULL,
+    correct BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_words_portuguese ON words(portuguese);
+CREATE INDEX IF NOT EXISTS idx_words_english ON words(english);
+CREATE INDEX IF NOT EXISTS idx_groups_name ON groups(name);
+CREATE INDEX IF NOT EXISTS idx_word_review_items_word_id ON word_review_items(word_id);
+CREATE INDEX IF NOT EXISTS idx_word_review_items_study_session_id ON word_review_items(study_session_id);
+CREATE INDEX IF NOT EXISTS idx_study_sessions_group_id ON study_sessions(group_id);
+CREATE INDEX IF NOT EXISTS idx_study_sessions_study_activity_id ON study_sessions(study_activity_id);
@@ -0,0 +1,44 @@
+-- Create users table
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'student',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create classrooms table
+CREATE TABLE IF NOT EXISTS classrooms (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    teacher_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create classroom_members table
+CREATE TABLE IF NOT EXISTS classroom_members (
+    id BIGSERIAL PRIMARY KEY,
+    classroom_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    joined_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(classroom_id, user_id)
+);
+
+-- Create assignments table
+CREATE TABLE IF NOT EXISTS assignments (
+    id BIGSERIAL PRIMARY KEY,
+    classroom_id BIGINT NOT NULL,
+    group_id BIGINT NOT NULL,
+    study_activity_id BIGINT,
+    due_at TIMESTAMPTZ NOT NULL,
+    target_accuracy DOUBLE PRECISION NOT NULL DEFAULT 80,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Track which learner ran a study session
+ALTER TABLE study_sessions ADD COLUMN user_id BIGINT;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_classrooms_teacher_id ON classrooms(teacher_id);
+CREATE INDEX IF NOT EXISTS idx_classroom_members_user_id ON classroom_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_assignments_classroom_id ON assignments(classroom_id);
+CREATE INDEX IF NOT EXISTS idx_study_sessions_user_id ON study_sessions(user_id);
@@ -0,0 +1,9 @@
+-- Make group names unique regardless of case, so that two requests creating
+-- or renaming groups at once can't both take a name. Groups named like an
+-- older one are renamed first, after their ID, e.g. "greetings (7)".
+UPDATE groups SET name = name || ' (' || id || ')'
+WHERE EXISTS (
+    SELECT 1 FROM groups g WHERE lower(g.name) = lower(groups.name) AND g.id < groups.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_name_unique ON groups(lower(name));
@@ -0,0 +1,33 @@
+-- Declare the foreign keys of the SQLite schema, which SQLite now enforces.
+-- They are NOT VALID so that rows already orphaned don't fail the migration:
+-- the cleanup-orphans command deletes them.
+ALTER TABLE words_groups
+    ADD CONSTRAINT words_groups_word_id_fkey FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE NOT VALID,
+    ADD CONSTRAINT words_groups_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE NOT VALID;
+
+ALTER TABLE study_sessions
+    ADD CONSTRAINT study_sessions_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE NOT VALID,
+    ADD CONSTRAINT study_sessions_study_activity_id_fkey FOREIGN KEY (study_activity_id) REFERENCES study_activities(id) ON DELETE CASCADE NOT VALID,
+    ADD CONSTRAINT study_sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL NOT VALID;
+
+ALTER TABLE word_review_items
+    ADD CONSTRAINT word_review_items_word_id_fkey FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE NOT VALID,
+    ADD CONSTRAINT word_review_items_study_session_id_fkey FOREIGN KEY (study_session_id) REFERENCES study_sessions(id) ON DELETE CASCADE NOT VALID;
+
+ALTER TABLE classrooms
+    ADD CONSTRAINT classrooms_teacher_id_fkey FOREIGN KEY (teacher_id) REFERENCES users(id) ON DELETE CASCADE NOT VALID;
+
+ALTER TABLE classroom_members
+    ADD CONSTRAINT classroom_members_classroom_id_fkey FOREIGN KEY (classroom_id) REFERENCES classrooms(id) ON DELETE CASCADE NOT VALID,
+    ADD CONSTRAINT classroom_members_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE NOT VALID;
+
+ALTER TABLE assignments
+    ADD CONSTRAINT assignments_classroom_id_fkey FOREIGN KEY (classroom_id) REFERENCES classrooms(id) ON DELETE CASCADE NOT VALID,
+    ADD CONSTRAINT assignments_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE NOT VALID,
+    ADD CONSTRAINT assignments_study_activity_id_fkey FOREIGN KEY (study_activity_id) REFERENCES study_activities(id) ON DELETE SET NULL NOT VALID;
+
+-- Index the referencing columns that aren't yet, so that the cascades don't
+-- scan their tables
+CREATE INDEX IF NOT EXISTS idx_words_groups_group_id ON words_groups(group_id);
+CREATE INDEX IF NOT EXISTS idx_assignments_group_id ON assignments(group_id);
+CREATE INDEX IF NOT EXISTS idx_assignments_study_activity_id ON assignments(study_activity_id);
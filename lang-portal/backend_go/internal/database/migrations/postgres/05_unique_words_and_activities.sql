@@ -0,0 +1,51 @@
+-- Make words unique by their Portuguese and English text, and study
+-- activities by their name, so that concurrent seeding can't create them
+-- twice. Duplicates are merged into the oldest first: their groups, reviews,
+-- sessions and assignments move to it.
+CREATE TEMP TABLE merged_words ON COMMIT DROP AS
+SELECT w.id, k.keeper
+FROM words w
+JOIN (
+    SELECT portuguese, english, MIN(id) AS keeper
+    FROM words
+    GROUP BY portuguese, english
+    HAVING COUNT(*) > 1
+) k ON k.portuguese = w.portuguese AND k.english = w.english
+WHERE w.id <> k.keeper;
+
+INSERT INTO words_groups (word_id, group_id)
+SELECT m.keeper, wg.group_id
+FROM words_groups wg
+JOIN merged_words m ON m.id = wg.word_id
+ON CONFLICT (word_id, group_id) DO NOTHING;
+
+UPDATE word_review_items wri SET word_id = m.keeper
+FROM merged_words m
+WHERE m.id = wri.word_id;
+
+DELETE FROM words WHERE id IN (SELECT id FROM merged_words);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_words_portuguese_english ON words(portuguese, english);
+
+CREATE TEMP TABLE merged_activities ON COMMIT DROP AS
+SELECT a.id, k.keeper
+FROM study_activities a
+JOIN (
+    SELECT name, MIN(id) AS keeper
+    FROM study_activities
+    GROUP BY name
+    HAVING COUNT(*) > 1
+) k ON k.name = a.name
+WHERE a.id <> k.keeper;
+
+UPDATE study_sessions ss SET study_activity_id = m.keeper
+FROM merged_activities m
+WHERE m.id = ss.study_activity_id;
+
+UPDATE assignments a SET study_activity_id = m.keeper
+FROM merged_activities m
+WHERE m.id = a.study_activity_id;
+
+DELETE FROM study_activities WHERE id IN (SELECT id FROM merged_activities);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_study_activities_name ON study_activities(name);
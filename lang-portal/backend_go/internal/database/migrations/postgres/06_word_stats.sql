@@ -0,0 +1,119 @@
+-- Keep the review statistics of each word in word_stats, so that listing
+-- words doesn't aggregate every review. Triggers update it in the
+-- transaction that writes the reviews, including the deletes cascading from
+-- words, groups and study sessions. The rebuild-word-stats command
+-- recomputes it and check-word-stats compares it with the reviews.
+CREATE TABLE IF NOT EXISTS word_stats (
+    word_id BIGINT PRIMARY KEY REFERENCES words(id) ON DELETE CASCADE,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    wrong_count INTEGER NOT NULL DEFAULT 0,
+    last_reviewed_at TIMESTAMPTZ,
+    -- streak counts the correct answers since the last wrong one, answered
+    -- at last_wrong_at
+    streak INTEGER NOT NULL DEFAULT 0,
+    last_wrong_at TIMESTAMPTZ
+);
+
+-- word_stats_count returns the statistics of a word counted from its reviews
+CREATE OR REPLACE FUNCTION word_stats_count(target BIGINT)
+RETURNS TABLE (correct_count INTEGER, wrong_count INTEGER, last_reviewed_at TIMESTAMPTZ, streak INTEGER, last_wrong_at TIMESTAMPTZ)
+LANGUAGE sql STABLE AS $$
+    SELECT
+        COUNT(*) FILTER (WHERE r.correct)::INTEGER,
+        COUNT(*) FILTER (WHERE NOT r.correct)::INTEGER,
+        MAX(r.created_at),
+        COUNT(*) FILTER (WHERE r.wrong_since = 0)::INTEGER,
+        MAX(r.created_at) FILTER (WHERE NOT r.correct)
+    FROM (
+        SELECT correct, created_at,
+            SUM(CASE WHEN correct THEN 0 ELSE 1 END)
+                OVER (ORDER BY created_at DESC, id DESC) AS wrong_since
+        FROM word_review_items
+        WHERE word_id = target
+    ) r
+    HAVING COUNT(*) > 0
+$$;
+
+-- Reviews are usually answered after the last one, but may be recorded out
+-- of order, as when importing past sessions. A correct answer extends the
+-- streak if it comes after the last wrong one; a wrong answer ends it, and
+-- the correct answers after it start the new one.
+CREATE OR REPLACE FUNCTION word_stats_review_inserted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO word_stats (word_id) VALUES (NEW.word_id) ON CONFLICT (word_id) DO NOTHING;
+    UPDATE word_stats ws SET
+        correct_count = ws.correct_count + (CASE WHEN NEW.correct THEN 1 ELSE 0 END),
+        wrong_count = ws.wrong_count + (CASE WHEN NEW.correct THEN 0 ELSE 1 END),
+        streak = CASE
+            WHEN NEW.correct THEN
+                (CASE WHEN ws.last_wrong_at IS NULL OR NEW.created_at >= ws.last_wrong_at THEN ws.streak + 1 ELSE ws.streak END)
+            WHEN ws.last_reviewed_at IS NULL OR NEW.created_at >= ws.last_reviewed_at THEN 0
+            WHEN ws.last_wrong_at IS NULL OR NEW.created_at >= ws.last_wrong_at THEN (
+                SELECT COUNT(*) FROM word_review_items wri
+                WHERE wri.word_id = NEW.word_id AND wri.created_at > NEW.created_at
+            )
+            ELSE ws.streak
+        END,
+        last_wrong_at = CASE
+            WHEN NOT NEW.correct AND (ws.last_wrong_at IS NULL OR NEW.created_at >= ws.last_wrong_at) THEN NEW.created_at
+            ELSE ws.last_wrong_at
+        END,
+        last_reviewed_at = GREATEST(ws.last_reviewed_at, NEW.created_at)
+    WHERE ws.word_id = NEW.word_id;
+    RETURN NULL;
+END
+$$;
+
+-- Deleting a review answered before the last wrong one leaves the streak
+-- as it is. Deleting a later one recounts the word, unless the word itself
+-- is being deleted.
+CREATE OR REPLACE FUNCTION word_stats_review_deleted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE word_stats SET
+        correct_count = correct_count - (CASE WHEN OLD.correct THEN 1 ELSE 0 END),
+        wrong_count = wrong_count - (CASE WHEN OLD.correct THEN 0 ELSE 1 END)
+    WHERE word_id = OLD.word_id AND OLD.created_at < last_wrong_at;
+    IF FOUND THEN
+        RETURN NULL;
+    END IF;
+    DELETE FROM word_stats WHERE word_id = OLD.word_id;
+    INSERT INTO word_stats (word_id, correct_count, wrong_count, last_reviewed_at, streak, last_wrong_at)
+    SELECT OLD.word_id, c.correct_count, c.wrong_count, c.last_reviewed_at, c.streak, c.last_wrong_at
+    FROM word_stats_count(OLD.word_id) c
+    WHERE EXISTS (SELECT 1 FROM words WHERE id = OLD.word_id);
+    RETURN NULL;
+END
+$$;
+
+-- Index the reviews of a word by time, for counting the streak after a wrong
+-- answer recorded out of order
+CREATE INDEX IF NOT EXISTS idx_word_review_items_word_id_created_at ON word_review_items(word_id, created_at);
+
+DROP TRIGGER IF EXISTS word_stats_review_inserted ON word_review_items;
+CREATE TRIGGER word_stats_review_inserted AFTER INSERT ON word_review_items
+    FOR EACH ROW EXECUTE FUNCTION word_stats_review_inserted();
+
+DROP TRIGGER IF EXISTS word_stats_review_deleted ON word_review_items;
+CREATE TRIGGER word_stats_review_deleted AFTER DELETE ON word_review_items
+    FOR EACH ROW EXECUTE FUNCTION word_stats_review_deleted();
+
+-- Fill it from the reviews already recorded
+INSERT INTO word_stats (word_id, correct_count, wrong_count, last_reviewed_at, streak, last_wrong_at)
+SELECT
+    r.word_id,
+    COUNT(*) FILTER (WHERE r.correct),
+    COUNT(*) FILTER (WHERE NOT r.correct),
+    MAX(r.created_at),
+    COUNT(*) FILTER (WHERE r.wrong_since = 0),
+    MAX(r.created_at) FILTER (WHERE NOT r.correct)
+FROM (
+    SELECT wri.word_id, wri.correct, wri.created_at,
+        SUM(CASE WHEN wri.correct THEN 0 ELSE 1 END)
+            OVER (PARTITION BY wri.word_id ORDER BY wri.created_at DESC, wri.id DESC) AS wrong_since
+    FROM word_review_items wri
+    JOIN words w ON w.id = wri.word_id
+) r
+GROUP BY r.word_id
+ON CONFLICT (word_id) DO NOTHING;
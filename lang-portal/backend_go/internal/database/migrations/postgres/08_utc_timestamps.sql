@@ -0,0 +1,3 @@
+-- SQLite rewrites the times stamped with a local offset in UTC. Postgres
+-- stores them as instants, which compare correctly whatever their zone, so
+-- there is nothing to do.
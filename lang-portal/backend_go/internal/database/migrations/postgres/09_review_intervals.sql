@@ -0,0 +1,97 @@
+-- Keep on each review the time since the previous review of the same word
+-- by the same learner, in whole seconds, so that measuring retention doesn't
+-- compare every review with the one before. Another learner reviewing a word
+-- says nothing about how well this one remembers it. The learner of a review
+-- is the user of its session, all guests being one learner, as for the daily
+-- goals and streaks. It is NULL for the first review of a word by a learner.
+-- Reviews are ordered by time, then by ID, and may be recorded out of order,
+-- which changes the interval of the review after.
+ALTER TABLE word_review_items ADD COLUMN IF NOT EXISTS seconds_since_previous BIGINT;
+
+-- review_learner returns the learner of the reviews of a session: its user,
+-- or 0 for the guest. It is NULL once the session is deleted.
+CREATE OR REPLACE FUNCTION review_learner(session_id BIGINT)
+RETURNS BIGINT
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(user_id, 0) FROM study_sessions WHERE id = session_id
+$$;
+
+-- review_interval_seconds returns the seconds from the review of a word by a
+-- learner before the given time and ID until then, NULL if there is none
+CREATE OR REPLACE FUNCTION review_interval_seconds(target BIGINT, learner BIGINT, at TIMESTAMPTZ, before_id BIGINT)
+RETURNS BIGINT
+LANGUAGE sql STABLE AS $$
+    SELECT CAST(floor(extract(epoch FROM at)) AS BIGINT) - CAST(floor(extract(epoch FROM p.created_at)) AS BIGINT)
+    FROM word_review_items p
+    JOIN study_sessions ps ON ps.id = p.study_session_id
+    WHERE p.word_id = target AND COALESCE(ps.user_id, 0) = learner AND (p.created_at, p.id) < (at, before_id)
+    ORDER BY p.created_at DESC, p.id DESC
+    LIMIT 1
+$$;
+
+CREATE OR REPLACE FUNCTION review_interval_inserted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    learner BIGINT := review_learner(NEW.study_session_id);
+BEGIN
+    UPDATE word_review_items
+    SET seconds_since_previous = review_interval_seconds(NEW.word_id, learner, NEW.created_at, NEW.id)
+    WHERE id = NEW.id;
+    UPDATE word_review_items
+    SET seconds_since_previous = review_interval_seconds(word_id, learner, created_at, id)
+    WHERE id = (
+        SELECT n.id
+        FROM word_review_items n
+        JOIN study_sessions ns ON ns.id = n.study_session_id
+        WHERE n.word_id = NEW.word_id AND COALESCE(ns.user_id, 0) = learner
+            AND (n.created_at, n.id) > (NEW.created_at, NEW.id)
+        ORDER BY n.created_at, n.id
+        LIMIT 1
+    );
+    RETURN NULL;
+END
+$$;
+
+-- Deleting a review makes the one after it follow the one before. When the
+-- review is deleted with its session, the session is already gone, so the
+-- learner isn't known: the first review after it of every learner is
+-- measured again, and the reviews of the deleted session are left out.
+CREATE OR REPLACE FUNCTION review_interval_deleted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE word_review_items
+    SET seconds_since_previous = review_interval_seconds(word_id, review_learner(study_session_id), created_at, id)
+    WHERE id IN (
+        SELECT DISTINCT ON (COALESCE(ns.user_id, 0)) n.id
+        FROM word_review_items n
+        JOIN study_sessions ns ON ns.id = n.study_session_id
+        WHERE n.word_id = OLD.word_id AND (n.created_at, n.id) > (OLD.created_at, OLD.id)
+        ORDER BY COALESCE(ns.user_id, 0), n.created_at, n.id
+    );
+    RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS review_interval_inserted ON word_review_items;
+CREATE TRIGGER review_interval_inserted AFTER INSERT ON word_review_items
+    FOR EACH ROW EXECUTE FUNCTION review_interval_inserted();
+
+DROP TRIGGER IF EXISTS review_interval_deleted ON word_review_items;
+CREATE TRIGGER review_interval_deleted AFTER DELETE ON word_review_items
+    FOR EACH ROW EXECUTE FUNCTION review_interval_deleted();
+
+-- Fill it for the reviews already recorded
+UPDATE word_review_items wri SET seconds_since_previous = intervals.seconds
+FROM (
+    SELECT r.id,
+        CAST(floor(extract(epoch FROM r.created_at)) AS BIGINT)
+            - LAG(CAST(floor(extract(epoch FROM r.created_at)) AS BIGINT)) OVER (
+                PARTITION BY r.word_id, COALESCE(ss.user_id, 0) ORDER BY r.created_at, r.id
+            ) AS seconds
+    FROM word_review_items r
+    JOIN study_sessions ss ON ss.id = r.study_session_id
+) intervals
+WHERE intervals.id = wri.id AND intervals.seconds IS NOT NULL;
+
+-- Index the reviews by interval, for the retention after each interval
+CREATE INDEX IF NOT EXISTS idx_word_review_items_seconds_since_previous ON word_review_items(seconds_since_previous);
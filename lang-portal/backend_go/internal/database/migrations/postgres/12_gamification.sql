@@ -0,0 +1,186 @@
+-- Keep the XP awarded for each review. It is set when the review is
+-- recorded, from the answers right in a row before it in its session: see
+-- models.ReviewXP.
+ALTER TABLE word_review_items ADD COLUMN xp INTEGER NOT NULL DEFAULT 0;
+
+-- Sessions can be ended, after which they take no more reviews
+ALTER TABLE study_sessions ADD COLUMN ended_at TIMESTAMPTZ;
+
+-- The daily goal of each learner. user_id is NULL for the guest, who
+-- studies without a user, hence the unique index on COALESCE(user_id, 0).
+CREATE TABLE IF NOT EXISTS daily_goals (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    target INTEGER NOT NULL,
+    time_zone TEXT NOT NULL DEFAULT 'UTC',
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_goals_learner ON daily_goals((COALESCE(user_id, 0)));
+
+-- The achievements each learner has unlocked, once each
+CREATE TABLE IF NOT EXISTS achievements (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    achievement TEXT NOT NULL,
+    unlocked_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_achievements_learner ON achievements((COALESCE(user_id, 0)), achievement);
+
+-- Award the reviews already recorded the XP they would have earned: 10 per
+-- correct answer, doubled from the 6th right in a row in a session and
+-- tripled from the 11th. Reviews are taken in the order they were recorded.
+UPDATE word_review_items SET xp = CASE WHEN combos.combo > 10 THEN 30 WHEN combos.combo > 5 THEN 20 ELSE 10 END
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY study_session_id, misses ORDER BY id) AS combo
+    FROM (
+        SELECT id, study_session_id, correct,
+            SUM(CASE WHEN correct THEN 0 ELSE 1 END) OVER (PARTITION BY study_session_id ORDER BY id) AS misses
+        FROM word_review_items
+    ) answers
+    WHERE correct
+) combos
+WHERE combos.id = word_review_items.id;
+
+-- Keep the totals of each learner in learner_stats, so that the rules engine
+-- doesn't sum up their whole history on every review. The learner of a
+-- review is the user of its session, or 0 for the guest. learner_words
+-- counts the correct answers of each learner to each word, the words
+-- learned being those with any. Triggers update both in the transaction
+-- that writes the reviews, as for word_stats.
+CREATE TABLE IF NOT EXISTS learner_stats (
+    learner_id BIGINT PRIMARY KEY,
+    reviews INTEGER NOT NULL DEFAULT 0,
+    xp INTEGER NOT NULL DEFAULT 0,
+    words_learned INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS learner_words (
+    learner_id BIGINT NOT NULL,
+    word_id BIGINT NOT NULL,
+    correct_count INTEGER NOT NULL,
+    PRIMARY KEY (learner_id, word_id)
+);
+
+-- learner_stats_add adds reviews, XP and correct answers to a word to the
+-- totals of a learner, or takes them away if negative
+CREATE OR REPLACE FUNCTION learner_stats_add(learner BIGINT, target BIGINT, reviews_added INTEGER, xp_added INTEGER, correct_added INTEGER)
+RETURNS void
+LANGUAGE plpgsql AS $$
+DECLARE
+    correct_before INTEGER;
+    correct_after INTEGER;
+BEGIN
+    SELECT correct_count INTO correct_before FROM learner_words WHERE learner_id = learner AND word_id = target;
+    correct_before := COALESCE(correct_before, 0);
+    correct_after := correct_before + correct_added;
+    IF correct_after > 0 THEN
+        INSERT INTO learner_words (learner_id, word_id, correct_count) VALUES (learner, target, correct_after)
+        ON CONFLICT (learner_id, word_id) DO UPDATE SET correct_count = EXCLUDED.correct_count;
+    ELSE
+        DELETE FROM learner_words WHERE learner_id = learner AND word_id = target;
+    END IF;
+
+    INSERT INTO learner_stats (learner_id) VALUES (learner) ON CONFLICT (learner_id) DO NOTHING;
+    UPDATE learner_stats SET
+        reviews = reviews + reviews_added,
+        xp = xp + xp_added,
+        words_learned = words_learned
+            + (CASE WHEN correct_before = 0 AND correct_after > 0 THEN 1 ELSE 0 END)
+            - (CASE WHEN correct_before > 0 AND correct_after <= 0 THEN 1 ELSE 0 END)
+    WHERE learner_id = learner;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION learner_stats_review_inserted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    learner BIGINT := review_learner(NEW.study_session_id);
+BEGIN
+    IF learner IS NOT NULL THEN
+        PERFORM learner_stats_add(learner, NEW.word_id, 1, NEW.xp, CASE WHEN NEW.correct THEN 1 ELSE 0 END);
+    END IF;
+    RETURN NULL;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION learner_stats_review_deleted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    learner BIGINT := review_learner(OLD.study_session_id);
+BEGIN
+    IF learner IS NOT NULL THEN
+        PERFORM learner_stats_add(learner, OLD.word_id, -1, -OLD.xp, CASE WHEN OLD.correct THEN -1 ELSE 0 END);
+    END IF;
+    RETURN NULL;
+END
+$$;
+
+-- The reviews of a session are deleted with it, but the foreign key deletes
+-- them once the session is gone, when their learner is no longer known.
+-- Delete them first.
+CREATE OR REPLACE FUNCTION learner_stats_session_deleted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM word_review_items WHERE study_session_id = OLD.id;
+    RETURN OLD;
+END
+$$;
+
+-- A session changes learner when its user is deleted, its reviews becoming
+-- the guest's: move them from one learner's totals to the other's
+CREATE OR REPLACE FUNCTION learner_stats_session_moved() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    moved RECORD;
+BEGIN
+    FOR moved IN
+        SELECT word_id, COUNT(*) AS reviews, SUM(xp) AS xp, COUNT(*) FILTER (WHERE correct) AS correct
+        FROM word_review_items
+        WHERE study_session_id = NEW.id
+        GROUP BY word_id
+    LOOP
+        PERFORM learner_stats_add(COALESCE(OLD.user_id, 0), moved.word_id, -moved.reviews::INTEGER, -moved.xp::INTEGER, -moved.correct::INTEGER);
+        PERFORM learner_stats_add(COALESCE(NEW.user_id, 0), moved.word_id, moved.reviews::INTEGER, moved.xp::INTEGER, moved.correct::INTEGER);
+    END LOOP;
+    RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS learner_stats_review_inserted ON word_review_items;
+CREATE TRIGGER learner_stats_review_inserted AFTER INSERT ON word_review_items
+    FOR EACH ROW EXECUTE FUNCTION learner_stats_review_inserted();
+
+DROP TRIGGER IF EXISTS learner_stats_review_deleted ON word_review_items;
+CREATE TRIGGER learner_stats_review_deleted AFTER DELETE ON word_review_items
+    FOR EACH ROW EXECUTE FUNCTION learner_stats_review_deleted();
+
+DROP TRIGGER IF EXISTS learner_stats_session_deleted ON study_sessions;
+CREATE TRIGGER learner_stats_session_deleted BEFORE DELETE ON study_sessions
+    FOR EACH ROW EXECUTE FUNCTION learner_stats_session_deleted();
+
+DROP TRIGGER IF EXISTS learner_stats_session_moved ON study_sessions;
+CREATE TRIGGER learner_stats_session_moved AFTER UPDATE OF user_id ON study_sessions
+    FOR EACH ROW
+    WHEN (COALESCE(OLD.user_id, 0) <> COALESCE(NEW.user_id, 0))
+    EXECUTE FUNCTION learner_stats_session_moved();
+
+-- Fill them from the reviews already recorded
+INSERT INTO learner_words (learner_id, word_id, correct_count)
+SELECT COALESCE(ss.user_id, 0), wri.word_id, COUNT(*)
+FROM word_review_items wri
+JOIN study_sessions ss ON ss.id = wri.study_session_id
+WHERE wri.correct
+GROUP BY COALESCE(ss.user_id, 0), wri.word_id
+ON CONFLICT (learner_id, word_id) DO NOTHING;
+
+INSERT INTO learner_stats (learner_id, reviews, xp, words_learned)
+SELECT
+    COALESCE(ss.user_id, 0),
+    COUNT(*),
+    SUM(wri.xp),
+    (SELECT COUNT(*) FROM learner_words lw WHERE lw.learner_id = COALESCE(ss.user_id, 0))
+FROM word_review_items wri
+JOIN study_sessions ss ON ss.id = wri.study_session_id
+GROUP BY COALESCE(ss.user_id, 0)
+ON CONFLICT (learner_id) DO NOTHING;
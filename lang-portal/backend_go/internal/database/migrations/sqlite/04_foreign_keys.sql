@@ -0,0 +1,6 @@
+-- Foreign keys are enforced from now on, so deleting a group, study activity
+-- or classroom cascades. Index the referencing columns that aren't yet, so
+-- that the cascades don't scan their tables.
+CREATE INDEX IF NOT EXISTS idx_words_groups_group_id ON words_groups(group_id);
+CREATE INDEX IF NOT EXISTS idx_assignments_group_id ON assignments(group_id);
+CREATE INDEX IF NOT EXISTS idx_assignments_study_activity_id ON assignments(study_activity_id);
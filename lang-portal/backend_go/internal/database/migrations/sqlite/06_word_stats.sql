@@ -0,0 +1,100 @@
+-- Keep the review statistics of each word in word_stats, so that listing
+-- words doesn't aggregate every review. Triggers update it in the
+-- transaction that writes the reviews, including the deletes cascading from
+-- words, groups and study sessions. The rebuild-word-stats command
+-- recomputes it and check-word-stats compares it with the reviews.
+CREATE TABLE IF NOT EXISTS word_stats (
+    word_id INTEGER PRIMARY KEY,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    wrong_count INTEGER NOT NULL DEFAULT 0,
+    last_reviewed_at DATETIME,
+    -- streak counts the correct answers since the last wrong one, answered
+    -- at last_wrong_at
+    streak INTEGER NOT NULL DEFAULT 0,
+    last_wrong_at DATETIME,
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE
+);
+
+-- Reviews are usually answered after the last one, but may be recorded out
+-- of order, as when importing past sessions. A correct answer extends the
+-- streak if it comes after the last wrong one; a wrong answer ends it, and
+-- the correct answers after it start the new one.
+CREATE TRIGGER IF NOT EXISTS word_stats_review_inserted AFTER INSERT ON word_review_items
+BEGIN
+    INSERT OR IGNORE INTO word_stats (word_id) VALUES (NEW.word_id);
+    UPDATE word_stats SET
+        correct_count = correct_count + (CASE WHEN NEW.correct THEN 1 ELSE 0 END),
+        wrong_count = wrong_count + (CASE WHEN NEW.correct THEN 0 ELSE 1 END),
+        streak = CASE
+            WHEN NEW.correct THEN
+                (CASE WHEN last_wrong_at IS NULL OR NEW.created_at >= last_wrong_at THEN streak + 1 ELSE streak END)
+            WHEN last_reviewed_at IS NULL OR NEW.created_at >= last_reviewed_at THEN 0
+            WHEN last_wrong_at IS NULL OR NEW.created_at >= last_wrong_at THEN (
+                SELECT COUNT(*) FROM word_review_items
+                WHERE word_id = NEW.word_id AND created_at > NEW.created_at
+            )
+            ELSE streak
+        END,
+        last_wrong_at = CASE
+            WHEN NOT NEW.correct AND (last_wrong_at IS NULL OR NEW.created_at >= last_wrong_at) THEN NEW.created_at
+            ELSE last_wrong_at
+        END,
+        last_reviewed_at = CASE
+            WHEN last_reviewed_at IS NULL OR NEW.created_at >= last_reviewed_at THEN NEW.created_at
+            ELSE last_reviewed_at
+        END
+    WHERE word_id = NEW.word_id;
+END;
+
+-- Index the reviews of a word by time, for counting the streak after a wrong
+-- answer recorded out of order
+CREATE INDEX IF NOT EXISTS idx_word_review_items_word_id_created_at ON word_review_items(word_id, created_at);
+
+-- Deleting a review answered before the last wrong one leaves the streak
+-- as it is. Deleting a later one recounts the word, unless the word itself
+-- is being deleted.
+CREATE TRIGGER IF NOT EXISTS word_stats_review_deleted AFTER DELETE ON word_review_items
+BEGIN
+    UPDATE word_stats SET
+        correct_count = correct_count - (CASE WHEN OLD.correct THEN 1 ELSE 0 END),
+        wrong_count = wrong_count - (CASE WHEN OLD.correct THEN 0 ELSE 1 END)
+    WHERE word_id = OLD.word_id AND OLD.created_at < last_wrong_at;
+    DELETE FROM word_stats
+    WHERE word_id = OLD.word_id AND (last_wrong_at IS NULL OR OLD.created_at >= last_wrong_at);
+    INSERT INTO word_stats (word_id, correct_count, wrong_count, last_reviewed_at, streak, last_wrong_at)
+    SELECT
+        word_id,
+        SUM(CASE WHEN correct THEN 1 ELSE 0 END),
+        SUM(CASE WHEN correct THEN 0 ELSE 1 END),
+        MAX(created_at),
+        SUM(CASE WHEN wrong_since = 0 THEN 1 ELSE 0 END),
+        MAX(CASE WHEN correct THEN NULL ELSE created_at END)
+    FROM (
+        SELECT word_id, correct, created_at,
+            SUM(CASE WHEN correct THEN 0 ELSE 1 END)
+                OVER (ORDER BY created_at DESC, id DESC) AS wrong_since
+        FROM word_review_items
+        WHERE word_id = OLD.word_id
+    )
+    WHERE NOT EXISTS (SELECT 1 FROM word_stats WHERE word_id = OLD.word_id)
+        AND EXISTS (SELECT 1 FROM words WHERE id = OLD.word_id)
+    GROUP BY word_id;
+END;
+
+-- Fill it from the reviews already recorded
+INSERT INTO word_stats (word_id, correct_count, wrong_count, last_reviewed_at, streak, last_wrong_at)
+SELECT
+    word_id,
+    SUM(CASE WHEN correct THEN 1 ELSE 0 END),
+    SUM(CASE WHEN correct THEN 0 ELSE 1 END),
+    MAX(created_at),
+    SUM(CASE WHEN wrong_since = 0 THEN 1 ELSE 0 END),
+    MAX(CASE WHEN correct THEN NULL ELSE created_at END)
+FROM (
+    SELECT wri.word_id, wri.correct, wri.created_at,
+        SUM(CASE WHEN wri.correct THEN 0 ELSE 1 END)
+            OVER (PARTITION BY wri.word_id ORDER BY wri.created_at DESC, wri.id DESC) AS wrong_since
+    FROM word_review_items wri
+    JOIN words w ON w.id = wri.word_id
+)
+GROUP BY word_id;
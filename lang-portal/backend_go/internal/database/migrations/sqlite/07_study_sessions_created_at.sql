@@ -0,0 +1,3 @@
+-- Index the sessions by start time, for the activity history of a range of
+-- days
+CREATE INDEX IF NOT EXISTS idx_study_sessions_created_at ON study_sessions(created_at);
@@ -0,0 +1,45 @@
+-- Rows used to be stamped with the server's local time, so a server outside
+-- UTC stored times such as 2025-03-10 23:35:00-03:00. SQLite compares
+-- timestamps as text, so such a time sorted before 2025-03-11 00:00:00+00:00
+-- although it is 02:35 UTC that day. Rewrite the times with an offset in
+-- UTC, as every write now is, to the millisecond.
+UPDATE words SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at) || '+00:00'
+WHERE length(created_at) > 19 AND substr(created_at, -3, 1) = ':' AND substr(created_at, -6, 1) IN ('+', '-') AND substr(created_at, -6) <> '+00:00';
+UPDATE groups SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at) || '+00:00'
+WHERE length(created_at) > 19 AND substr(created_at, -3, 1) = ':' AND substr(created_at, -6, 1) IN ('+', '-') AND substr(created_at, -6) <> '+00:00';
+UPDATE study_activities SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at) || '+00:00'
+WHERE length(created_at) > 19 AND substr(created_at, -3, 1) = ':' AND substr(created_at, -6, 1) IN ('+', '-') AND substr(created_at, -6) <> '+00:00';
+UPDATE study_sessions SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at) || '+00:00'
+WHERE length(created_at) > 19 AND substr(created_at, -3, 1) = ':' AND substr(created_at, -6, 1) IN ('+', '-') AND substr(created_at, -6) <> '+00:00';
+UPDATE word_review_items SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at) || '+00:00'
+WHERE length(created_at) > 19 AND substr(created_at, -3, 1) = ':' AND substr(created_at, -6, 1) IN ('+', '-') AND substr(created_at, -6) <> '+00:00';
+UPDATE users SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at) || '+00:00'
+WHERE length(created_at) > 19 AND substr(created_at, -3, 1) = ':' AND substr(created_at, -6, 1) IN ('+', '-') AND substr(created_at, -6) <> '+00:00';
+UPDATE classrooms SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at) || '+00:00'
+WHERE length(created_at) > 19 AND substr(created_at, -3, 1) = ':' AND substr(created_at, -6, 1) IN ('+', '-') AND substr(created_at, -6) <> '+00:00';
+UPDATE classroom_members SET joined_at = strftime('%Y-%m-%d %H:%M:%f', joined_at) || '+00:00'
+WHERE length(joined_at) > 19 AND substr(joined_at, -3, 1) = ':' AND substr(joined_at, -6, 1) IN ('+', '-') AND substr(joined_at, -6) <> '+00:00';
+UPDATE assignments SET due_at = strftime('%Y-%m-%d %H:%M:%f', due_at) || '+00:00'
+WHERE length(due_at) > 19 AND substr(due_at, -3, 1) = ':' AND substr(due_at, -6, 1) IN ('+', '-') AND substr(due_at, -6) <> '+00:00';
+UPDATE assignments SET created_at = strftime('%Y-%m-%d %H:%M:%f', created_at) || '+00:00'
+WHERE length(created_at) > 19 AND substr(created_at, -3, 1) = ':' AND substr(created_at, -6, 1) IN ('+', '-') AND substr(created_at, -6) <> '+00:00';
+
+-- The word statistics were kept in the order of the times as text: recount
+-- them, as 06_word_stats does
+DELETE FROM word_stats;
+INSERT INTO word_stats (word_id, correct_count, wrong_count, last_reviewed_at, streak, last_wrong_at)
+SELECT
+    word_id,
+    SUM(CASE WHEN correct THEN 1 ELSE 0 END),
+    SUM(CASE WHEN correct THEN 0 ELSE 1 END),
+    MAX(created_at),
+    SUM(CASE WHEN wrong_since = 0 THEN 1 ELSE 0 END),
+    MAX(CASE WHEN correct THEN NULL ELSE created_at END)
+FROM (
+    SELECT wri.word_id, wri.correct, wri.created_at,
+        SUM(CASE WHEN wri.correct THEN 0 ELSE 1 END)
+            OVER (PARTITION BY wri.word_id ORDER BY wri.created_at DESC, wri.id DESC) AS wrong_since
+    FROM word_review_items wri
+    JOIN words w ON w.id = wri.word_id
+)
+GROUP BY word_id;
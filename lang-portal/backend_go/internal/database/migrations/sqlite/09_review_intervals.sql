@@ -0,0 +1,81 @@
+-- Keep on each review the time since the previous review of the same word
+-- by the same learner, in whole seconds, so that measuring retention doesn't
+-- compare every review with the one before. Another learner reviewing a word
+-- says nothing about how well this one remembers it. The learner of a review
+-- is the user of its session, all guests being one learner, as for the daily
+-- goals and streaks. It is NULL for the first review of a word by a learner.
+-- Reviews are ordered by time, then by ID, and may be recorded out of order,
+-- which changes the interval of the review after.
+ALTER TABLE word_review_items ADD COLUMN seconds_since_previous INTEGER;
+
+CREATE TRIGGER IF NOT EXISTS review_interval_inserted AFTER INSERT ON word_review_items
+BEGIN
+    UPDATE word_review_items SET seconds_since_previous = CAST(strftime('%s', NEW.created_at) AS INTEGER) - (
+        SELECT CAST(strftime('%s', p.created_at) AS INTEGER)
+        FROM word_review_items p
+        JOIN study_sessions ps ON ps.id = p.study_session_id
+        JOIN study_sessions s ON s.id = NEW.study_session_id
+        WHERE p.word_id = NEW.word_id AND COALESCE(ps.user_id, 0) = COALESCE(s.user_id, 0)
+            AND (p.created_at, p.id) < (NEW.created_at, NEW.id)
+        ORDER BY p.created_at DESC, p.id DESC
+        LIMIT 1
+    )
+    WHERE id = NEW.id;
+    UPDATE word_review_items
+    SET seconds_since_previous = CAST(strftime('%s', created_at) AS INTEGER) - CAST(strftime('%s', NEW.created_at) AS INTEGER)
+    WHERE id = (
+        SELECT n.id
+        FROM word_review_items n
+        JOIN study_sessions ns ON ns.id = n.study_session_id
+        JOIN study_sessions s ON s.id = NEW.study_session_id
+        WHERE n.word_id = NEW.word_id AND COALESCE(ns.user_id, 0) = COALESCE(s.user_id, 0)
+            AND (n.created_at, n.id) > (NEW.created_at, NEW.id)
+        ORDER BY n.created_at, n.id
+        LIMIT 1
+    );
+END;
+
+-- Deleting a review makes the one after it follow the one before. When the
+-- review is deleted with its session, the session is already gone, so the
+-- learner isn't known: the first review after it of every learner is
+-- measured again, and the reviews of the deleted session are left out.
+CREATE TRIGGER IF NOT EXISTS review_interval_deleted AFTER DELETE ON word_review_items
+BEGIN
+    UPDATE word_review_items SET seconds_since_previous = CAST(strftime('%s', created_at) AS INTEGER) - (
+        SELECT CAST(strftime('%s', p.created_at) AS INTEGER)
+        FROM word_review_items p
+        JOIN study_sessions ps ON ps.id = p.study_session_id
+        JOIN study_sessions s ON s.id = word_review_items.study_session_id
+        WHERE p.word_id = word_review_items.word_id AND COALESCE(ps.user_id, 0) = COALESCE(s.user_id, 0)
+            AND (p.created_at, p.id) < (word_review_items.created_at, word_review_items.id)
+        ORDER BY p.created_at DESC, p.id DESC
+        LIMIT 1
+    )
+    WHERE id IN (
+        SELECT id
+        FROM (
+            SELECT n.id,
+                ROW_NUMBER() OVER (PARTITION BY COALESCE(ns.user_id, 0) ORDER BY n.created_at, n.id) AS position
+            FROM word_review_items n
+            JOIN study_sessions ns ON ns.id = n.study_session_id
+            WHERE n.word_id = OLD.word_id AND (n.created_at, n.id) > (OLD.created_at, OLD.id)
+        )
+        WHERE position = 1
+    );
+END;
+
+-- Fill it for the reviews already recorded
+UPDATE word_review_items SET seconds_since_previous = intervals.seconds
+FROM (
+    SELECT wri.id,
+        CAST(strftime('%s', wri.created_at) AS INTEGER)
+            - LAG(CAST(strftime('%s', wri.created_at) AS INTEGER)) OVER (
+                PARTITION BY wri.word_id, COALESCE(ss.user_id, 0) ORDER BY wri.created_at, wri.id
+            ) AS seconds
+    FROM word_review_items wri
+    JOIN study_sessions ss ON ss.id = wri.study_session_id
+) intervals
+WHERE intervals.id = word_review_items.id AND intervals.seconds IS NOT NULL;
+
+-- Index the reviews by interval, for the retention after each interval
+CREATE INDEX IF NOT EXISTS idx_word_review_items_seconds_since_previous ON word_review_items(seconds_since_previous);
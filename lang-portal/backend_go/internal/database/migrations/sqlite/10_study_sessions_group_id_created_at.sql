@@ -0,0 +1,4 @@
+-- Index the sessions of a group by start time, for when the group was last
+-- studied. It replaces the index on group_id alone, which it covers.
+CREATE INDEX IF NOT EXISTS idx_study_sessions_group_id_created_at ON study_sessions(group_id, created_at);
+DROP INDEX IF EXISTS idx_study_sessions_group_id;
@@ -0,0 +1,7 @@
+-- Rank study activities from the easiest to the hardest, for the activity
+-- recommended next in a group. The activities already created are ranked in
+-- the order of their IDs; seed packs set the difficulty of theirs.
+ALTER TABLE study_activities ADD COLUMN difficulty INTEGER NOT NULL DEFAULT 0;
+
+UPDATE study_activities
+SET difficulty = (SELECT COUNT(*) FROM study_activities a WHERE a.id <= study_activities.id);
@@ -0,0 +1,167 @@
+-- Keep the XP awarded for each review. It is set when the review is
+-- recorded, from the answers right in a row before it in its session: see
+-- models.ReviewXP.
+ALTER TABLE word_review_items ADD COLUMN xp INTEGER NOT NULL DEFAULT 0;
+
+-- Sessions can be ended, after which they take no more reviews
+ALTER TABLE study_sessions ADD COLUMN ended_at DATETIME;
+
+-- The daily goal of each learner. user_id is NULL for the guest, who
+-- studies without a user, hence the unique index on COALESCE(user_id, 0).
+CREATE TABLE IF NOT EXISTS daily_goals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    target INTEGER NOT NULL,
+    time_zone TEXT NOT NULL DEFAULT 'UTC',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_goals_learner ON daily_goals(COALESCE(user_id, 0));
+
+-- The achievements each learner has unlocked, once each
+CREATE TABLE IF NOT EXISTS achievements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    achievement TEXT NOT NULL,
+    unlocked_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_achievements_learner ON achievements(COALESCE(user_id, 0), achievement);
+
+-- Award the reviews already recorded the XP they would have earned: 10 per
+-- correct answer, doubled from the 6th right in a row in a session and
+-- tripled from the 11th. Reviews are taken in the order they were recorded.
+UPDATE word_review_items SET xp = CASE WHEN combos.combo > 10 THEN 30 WHEN combos.combo > 5 THEN 20 ELSE 10 END
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY study_session_id, misses ORDER BY id) AS combo
+    FROM (
+        SELECT id, study_session_id, correct,
+            SUM(CASE WHEN correct THEN 0 ELSE 1 END) OVER (PARTITION BY study_session_id ORDER BY id) AS misses
+        FROM word_review_items
+    ) answers
+    WHERE correct
+) combos
+WHERE combos.id = word_review_items.id;
+
+-- Keep the totals of each learner in learner_stats, so that the rules engine
+-- doesn't sum up their whole history on every review. The learner of a
+-- review is the user of its session, or 0 for the guest. learner_words
+-- counts the correct answers of each learner to each word, the words
+-- learned being those with any. Triggers update both in the transaction
+-- that writes the reviews, as for word_stats.
+CREATE TABLE IF NOT EXISTS learner_stats (
+    learner_id INTEGER PRIMARY KEY,
+    reviews INTEGER NOT NULL DEFAULT 0,
+    xp INTEGER NOT NULL DEFAULT 0,
+    words_learned INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS learner_words (
+    learner_id INTEGER NOT NULL,
+    word_id INTEGER NOT NULL,
+    correct_count INTEGER NOT NULL,
+    PRIMARY KEY (learner_id, word_id)
+);
+
+CREATE TRIGGER IF NOT EXISTS learner_stats_review_inserted AFTER INSERT ON word_review_items
+BEGIN
+    INSERT INTO learner_stats (learner_id)
+    SELECT COALESCE(user_id, 0) FROM study_sessions WHERE id = NEW.study_session_id
+    ON CONFLICT (learner_id) DO NOTHING;
+    UPDATE learner_stats SET
+        reviews = reviews + 1,
+        xp = xp + NEW.xp,
+        words_learned = words_learned + (CASE WHEN NEW.correct AND NOT EXISTS (
+            SELECT 1 FROM learner_words lw
+            WHERE lw.learner_id = learner_stats.learner_id AND lw.word_id = NEW.word_id
+        ) THEN 1 ELSE 0 END)
+    WHERE learner_id = (SELECT COALESCE(user_id, 0) FROM study_sessions WHERE id = NEW.study_session_id);
+    INSERT INTO learner_words (learner_id, word_id, correct_count)
+    SELECT COALESCE(user_id, 0), NEW.word_id, 1 FROM study_sessions WHERE id = NEW.study_session_id AND NEW.correct
+    ON CONFLICT (learner_id, word_id) DO UPDATE SET correct_count = correct_count + 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS learner_stats_review_deleted AFTER DELETE ON word_review_items
+BEGIN
+    UPDATE learner_words SET correct_count = correct_count - 1
+    WHERE OLD.correct AND word_id = OLD.word_id
+        AND learner_id = (SELECT COALESCE(user_id, 0) FROM study_sessions WHERE id = OLD.study_session_id);
+    UPDATE learner_stats SET
+        reviews = reviews - 1,
+        xp = xp - OLD.xp,
+        words_learned = words_learned - (
+            SELECT COUNT(*) FROM learner_words lw
+            WHERE lw.learner_id = learner_stats.learner_id AND lw.word_id = OLD.word_id AND lw.correct_count = 0
+        )
+    WHERE learner_id = (SELECT COALESCE(user_id, 0) FROM study_sessions WHERE id = OLD.study_session_id);
+    DELETE FROM learner_words
+    WHERE word_id = OLD.word_id AND correct_count = 0
+        AND learner_id = (SELECT COALESCE(user_id, 0) FROM study_sessions WHERE id = OLD.study_session_id);
+END;
+
+-- The reviews of a session are deleted with it, but the foreign key deletes
+-- them once the session is gone, when their learner is no longer known.
+-- Delete them first.
+CREATE TRIGGER IF NOT EXISTS learner_stats_session_deleted BEFORE DELETE ON study_sessions
+BEGIN
+    DELETE FROM word_review_items WHERE study_session_id = OLD.id;
+END;
+
+-- A session changes learner when its user is deleted, its reviews becoming
+-- the guest's: move them from one learner's totals to the other's
+CREATE TRIGGER IF NOT EXISTS learner_stats_session_moved AFTER UPDATE OF user_id ON study_sessions
+WHEN COALESCE(OLD.user_id, 0) <> COALESCE(NEW.user_id, 0)
+BEGIN
+    UPDATE learner_words SET correct_count = correct_count - (
+        SELECT COUNT(*) FROM word_review_items wri
+        WHERE wri.study_session_id = NEW.id AND wri.correct AND wri.word_id = learner_words.word_id
+    )
+    WHERE learner_id = COALESCE(OLD.user_id, 0)
+        AND word_id IN (SELECT word_id FROM word_review_items WHERE study_session_id = NEW.id AND correct);
+    UPDATE learner_stats SET
+        reviews = reviews - (SELECT COUNT(*) FROM word_review_items WHERE study_session_id = NEW.id),
+        xp = xp - (SELECT COALESCE(SUM(xp), 0) FROM word_review_items WHERE study_session_id = NEW.id),
+        words_learned = words_learned - (
+            SELECT COUNT(*) FROM learner_words lw
+            WHERE lw.learner_id = learner_stats.learner_id AND lw.correct_count = 0
+        )
+    WHERE learner_id = COALESCE(OLD.user_id, 0);
+    DELETE FROM learner_words WHERE learner_id = COALESCE(OLD.user_id, 0) AND correct_count = 0;
+
+    INSERT INTO learner_stats (learner_id) VALUES (COALESCE(NEW.user_id, 0))
+    ON CONFLICT (learner_id) DO NOTHING;
+    UPDATE learner_stats SET
+        reviews = reviews + (SELECT COUNT(*) FROM word_review_items WHERE study_session_id = NEW.id),
+        xp = xp + (SELECT COALESCE(SUM(xp), 0) FROM word_review_items WHERE study_session_id = NEW.id),
+        words_learned = words_learned + (
+            SELECT COUNT(DISTINCT wri.word_id) FROM word_review_items wri
+            WHERE wri.study_session_id = NEW.id AND wri.correct AND NOT EXISTS (
+                SELECT 1 FROM learner_words lw
+                WHERE lw.learner_id = learner_stats.learner_id AND lw.word_id = wri.word_id
+            )
+        )
+    WHERE learner_id = COALESCE(NEW.user_id, 0);
+    INSERT INTO learner_words (learner_id, word_id, correct_count)
+    SELECT COALESCE(NEW.user_id, 0), word_id, COUNT(*)
+    FROM word_review_items
+    WHERE study_session_id = NEW.id AND correct
+    GROUP BY word_id
+    ON CONFLICT (learner_id, word_id) DO UPDATE SET correct_count = correct_count + excluded.correct_count;
+END;
+
+-- Fill them from the reviews already recorded
+INSERT INTO learner_words (learner_id, word_id, correct_count)
+SELECT COALESCE(ss.user_id, 0), wri.word_id, COUNT(*)
+FROM word_review_items wri
+JOIN study_sessions ss ON ss.id = wri.study_session_id
+WHERE wri.correct
+GROUP BY COALESCE(ss.user_id, 0), wri.word_id;
+
+INSERT INTO learner_stats (learner_id, reviews, xp, words_learned)
+SELECT
+    COALESCE(ss.user_id, 0),
+    COUNT(*),
+    SUM(wri.xp),
+    (SELECT COUNT(*) FROM learner_words lw WHERE lw.learner_id = COALESCE(ss.user_id, 0))
+FROM word_review_items wri
+JOIN study_sessions ss ON ss.id = wri.study_session_id
+GROUP BY COALESCE(ss.user_id, 0);
@@ -0,0 +1,29 @@
+-- How each learner appears on the leaderboards of their classrooms. Learners
+-- without a row, or without a display name, appear under a pseudonym.
+CREATE TABLE IF NOT EXISTS leaderboard_profiles (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    display_name TEXT,
+    opt_out BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The standings of the members of each classroom over each period, as last
+-- computed by the leaderboard refresh job
+CREATE TABLE IF NOT EXISTS leaderboard_snapshots (
+    classroom_id INTEGER NOT NULL REFERENCES classrooms(id) ON DELETE CASCADE,
+    period TEXT NOT NULL,
+    computed_at DATETIME NOT NULL,
+    PRIMARY KEY (classroom_id, period)
+);
+
+CREATE TABLE IF NOT EXISTS leaderboard_standings (
+    classroom_id INTEGER NOT NULL,
+    period TEXT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    xp INTEGER NOT NULL,
+    reviews INTEGER NOT NULL,
+    correct_count INTEGER NOT NULL,
+    words_mastered INTEGER NOT NULL,
+    PRIMARY KEY (classroom_id, period, user_id),
+    FOREIGN KEY (classroom_id, period) REFERENCES leaderboard_snapshots(classroom_id, period) ON DELETE CASCADE
+);
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "statements",
+			content: "CREATE TABLE a (id INTEGER);\n\nCREATE INDEX idx ON a(id);\n",
+			want:    []string{"CREATE TABLE a (id INTEGER)", "CREATE INDEX idx ON a(id)"},
+		},
+		{
+			name:    "semicolons in strings and comments",
+			content: "-- one; two\nINSERT INTO a (s) VALUES ('x;y');\n-- trailing; comment\n",
+			want:    []string{"-- one; two\nINSERT INTO a (s) VALUES ('x;y')"},
+		},
+		{
+			name: "sqlite trigger",
+			content: `CREATE TRIGGER t AFTER INSERT ON a
+BEGIN
+    UPDATE b SET n = CASE WHEN NEW.ok THEN n + 1 ELSE 0 END; -- the end;
+    DELETE FROM c;
+END;
+SELECT 1;`,
+			want: []string{
+				"CREATE TRIGGER t AFTER INSERT ON a\nBEGIN\n    UPDATE b SET n = CASE WHEN NEW.ok THEN n + 1 ELSE 0 END; -- the end;\n    DELETE FROM c;\nEND",
+				"SELECT 1",
+			},
+		},
+		{
+			name: "postgres function",
+			content: `CREATE FUNCTION f() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM c;
+    RETURN NULL;
+END
+$$;
+CREATE FUNCTION g() RETURNS int LANGUAGE sql AS $body$ SELECT 1; $body$;`,
+			want: []string{
+				"CREATE FUNCTION f() RETURNS trigger LANGUAGE plpgsql AS $$\nBEGIN\n    DELETE FROM c;\n    RETURN NULL;\nEND\n$$",
+				"CREATE FUNCTION g() RETURNS int LANGUAGE sql AS $body$ SELECT 1; $body$",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, splitStatements(tt.content))
+		})
+	}
+}
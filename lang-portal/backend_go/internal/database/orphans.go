@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// Orphans counts the rows referencing a word, group or study session that
+// is gone. SQLite databases created before foreign keys were enforced can
+// have them, as deletes didn't cascade.
+type Orphans struct {
+	WordsGroups     int
+	WordStats       int
+	WordReviewItems int
+}
+
+// Total is the number of orphaned rows
+func (o Orphans) Total() int {
+	return o.WordsGroups + o.WordStats + o.WordReviewItems
+}
+
+// orphanedRows selects the orphans of each table, by the same condition for
+// counting and deleting. word_stats comes before word_review_items, as
+// deleting a review of a missing word may delete its statistics too, which
+// would then go uncounted.
+var orphanedRows = []struct {
+	table     string
+	condition string
+	count     func(o *Orphans) *int
+}{
+	{
+		table: "words_groups",
+		condition: `NOT EXISTS (SELECT 1 FROM words w WHERE w.id = words_groups.word_id)
+			OR NOT EXISTS (SELECT 1 FROM groups g WHERE g.id = words_groups.group_id)`,
+		count: func(o *Orphans) *int { return &o.WordsGroups },
+	},
+	{
+		table:     "word_stats",
+		condition: `NOT EXISTS (SELECT 1 FROM words w WHERE w.id = word_stats.word_id)`,
+		count:     func(o *Orphans) *int { return &o.WordStats },
+	},
+	{
+		table: "word_review_items",
+		condition: `NOT EXISTS (SELECT 1 FROM words w WHERE w.id = word_review_items.word_id)
+			OR NOT EXISTS (SELECT 1 FROM study_sessions ss WHERE ss.id = word_review_items.study_session_id)`,
+		count: func(o *Orphans) *int { return &o.WordReviewItems },
+	},
+}
+
+// FindOrphans counts the orphaned rows of db
+func FindOrphans(ctx context.Context, db *sql.DB) (Orphans, error) {
+	var o Orphans
+	for _, rows := range orphanedRows {
+		query := "SELECT COUNT(*) FROM " + rows.table + " WHERE " + rows.condition
+		if err := db.QueryRowContext(ctx, query).Scan(rows.count(&o)); err != nil {
+			return Orphans{}, fmt.Errorf("failed to count orphaned %s: %w", rows.table, err)
+		}
+	}
+	return o, nil
+}
+
+// DeleteOrphans deletes the orphaned rows of db, all or none, and returns
+// how many there were
+func DeleteOrphans(ctx context.Context, db *sql.DB) (Orphans, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Orphans{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var o Orphans
+	for _, rows := range orphanedRows {
+		result, err := tx.ExecContext(ctx, "DELETE FROM "+rows.table+" WHERE "+rows.condition)
+		if err != nil {
+			return Orphans{}, fmt.Errorf("failed to delete orphaned %s: %w", rows.table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return Orphans{}, err
+		}
+		*rows.count(&o) = int(n)
+	}
+	return o, tx.Commit()
+}
+
+// reportOrphans warns about the orphaned rows of db, which the
+// cleanup-orphans command deletes
+func reportOrphans(ctx context.Context, db *sql.DB) error {
+	o, err := FindOrphans(ctx, db)
+	if err != nil || o.Total() == 0 {
+		return err
+	}
+	slog.Warn("found orphaned rows, run the cleanup-orphans command to delete them",
+		"words_groups", o.WordsGroups, "word_stats", o.WordStats, "word_review_items", o.WordReviewItems)
+	return nil
+}
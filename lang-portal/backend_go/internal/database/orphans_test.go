@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOrphans tests that the rows left behind by deletes made without
+// foreign keys are found and deleted
+func TestOrphans(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+	ctx := context.Background()
+
+	// Connections opened without the pragmas don't enforce foreign keys
+	legacy, err := sql.Open("sqlite3", tdb.Path)
+	require.NoError(t, err)
+	defer legacy.Close()
+	_, err = legacy.Exec(`
+		INSERT INTO words (id, portuguese, english) VALUES (1, 'olá', 'hello'), (2, 'adeus', 'goodbye');
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings');
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES (1, 'flashcards', '', '');
+		INSERT INTO study_sessions (id, group_id, study_activity_id) VALUES (1, 1, 1);
+		INSERT INTO words_groups (word_id, group_id) VALUES (1, 1), (2, 1), (1, 2);
+		INSERT INTO word_review_items (word_id, study_session_id, correct) VALUES (1, 1, 1), (2, 1, 0), (1, 2, 1);
+		DELETE FROM words WHERE id = 2;
+	`)
+	require.NoError(t, err)
+
+	orphans, err := FindOrphans(ctx, tdb.DB)
+	require.NoError(t, err)
+	assert.Equal(t, Orphans{WordsGroups: 2, WordStats: 1, WordReviewItems: 2}, orphans)
+
+	deleted, err := DeleteOrphans(ctx, tdb.DB)
+	require.NoError(t, err)
+	assert.Equal(t, orphans, deleted)
+
+	orphans, err = FindOrphans(ctx, tdb.DB)
+	require.NoError(t, err)
+	assert.Zero(t, orphans.Total())
+	var reviews, stats int
+	require.NoError(t, tdb.DB.QueryRow("SELECT COUNT(*) FROM word_review_items").Scan(&reviews))
+	assert.Equal(t, 1, reviews)
+	require.NoError(t, tdb.DB.QueryRow("SELECT correct_count FROM word_stats WHERE word_id = 1").Scan(&stats))
+	assert.Equal(t, 1, stats, "the statistics of the words kept are recounted")
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReviewIntervalsMigration tests that the migration measuring the
+// intervals between the reviews of each learner measures those already
+// recorded
+func TestReviewIntervalsMigration(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+
+	migration, err := migrationFiles.ReadFile("migrations/sqlite/09_review_intervals.sql")
+	require.NoError(t, err)
+
+	_, err = tdb.DB.Exec(`
+		DROP TRIGGER review_interval_inserted;
+		DROP TRIGGER review_interval_deleted;
+		DROP INDEX idx_word_review_items_seconds_since_previous;
+		ALTER TABLE word_review_items DROP COLUMN seconds_since_previous;
+		INSERT INTO words (id, portuguese, english) VALUES (1, 'olá', 'hello');
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings');
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES (1, 'flashcards', '', '');
+		INSERT INTO users (id, name) VALUES (1, 'Ana'), (2, 'Bruno');
+		INSERT INTO study_sessions (id, group_id, study_activity_id, user_id) VALUES (1, 1, 1, 1), (2, 1, 1, 2), (3, 1, 1, NULL);
+		INSERT INTO word_review_items (id, word_id, study_session_id, correct, created_at) VALUES
+			(1, 1, 1, TRUE, '2025-03-01 09:00:00+00:00'),
+			(2, 1, 2, TRUE, '2025-03-02 09:00:00+00:00'),
+			(3, 1, 3, TRUE, '2025-03-03 09:00:00+00:00'),
+			(4, 1, 1, TRUE, '2025-03-04 09:00:00+00:00'),
+			(5, 1, 3, TRUE, '2025-03-04 10:00:00+00:00');
+	`)
+	require.NoError(t, err)
+
+	_, err = tdb.DB.Exec(string(migration))
+	require.NoError(t, err)
+
+	rows, err := tdb.DB.Query("SELECT COALESCE(seconds_since_previous, -1) FROM word_review_items ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close()
+	var intervals []int64
+	for rows.Next() {
+		var seconds int64
+		require.NoError(t, rows.Scan(&seconds))
+		intervals = append(intervals, seconds)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []int64{-1, -1, -1, 3 * 86400, 86400 + 3600}, intervals)
+}
@@ -1,11 +1,18 @@
 package database
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
 	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/dialect"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 )
 
 type seedWord struct {
@@ -26,164 +33,351 @@ type studyActivity struct {
 	Name         string `json:"name"`
 	ThumbnailURL string `json:"thumbnail_url"`
 	Description  string `json:"description"`
+	Difficulty   int    `json:"difficulty"`
 }
 
 type studyActivitiesSeed struct {
 	StudyActivities []studyActivity `json:"study_activities"`
 }
 
-func RunSeed() error {
-	db, err := InitDB()
-	if err != nil {
-		return fmt.Errorf("failed to initialize database: %v", err)
+// validate applies the same rules as the API to the seed data, trimming
+// names in place
+func (s *wordsAndGroupsSeed) validate() error {
+	seen := make(map[string]bool, len(s.Groups))
+	for i := range s.Groups {
+		group := &s.Groups[i]
+
+		groupInput := models.GroupInput{Name: group.Name}
+		if err := groupInput.Validate(); err != nil {
+			return fmt.Errorf("group %d: %v", i, err)
+		}
+		group.Name = groupInput.Name
+
+		key := strings.ToLower(group.Name)
+		if seen[key] {
+			return fmt.Errorf("group %d: name %q is a duplicate", i, group.Name)
+		}
+		seen[key] = true
+
+		for j := range group.Words {
+			word := &group.Words[j]
+			wordInput := models.WordInput{Portuguese: word.Portuguese, English: word.English}
+			if err := wordInput.Validate(); err != nil {
+				return fmt.Errorf("group %q, word %d: %v", group.Name, j, err)
+			}
+			word.Portuguese, word.English = wordInput.Portuguese, wordInput.English
+		}
 	}
+	return nil
+}
 
-	// Read and parse words and groups
-	wordsData, err := ioutil.ReadFile("seeds/words_and_groups.json")
+// The data files a seed pack may hold
+const (
+	wordsAndGroupsFile  = "words_and_groups.json"
+	studyActivitiesFile = "study_activities.json"
+)
+
+// SeedOptions selects what RunSeed loads
+type SeedOptions struct {
+	// Packs names the seed packs to load, along with their dependencies.
+	// Every pack of the source is loaded if it is empty.
+	Packs []string
+	// SampleSessions adds a study session with a few reviews to the first
+	// group of each pack, unless the group was already studied
+	SampleSessions bool
+}
+
+// seedCounts tallies the rows a pack created or updated
+type seedCounts struct {
+	activitiesCreated, activitiesUpdated int
+	groupsCreated, wordsCreated          int
+	wordsLinked, sampleSessions          int
+}
+
+// RunSeed loads seed packs from source, a directory of packs such as
+// os.DirFS("seeds"), into store, in one transaction. Seeding is idempotent:
+// study activities, groups and words are matched on their natural keys (the
+// activity name, the group name regardless of case, and the word pair),
+// which unique indexes enforce, and only the missing ones are created, so a
+// pack can be loaded again after it gained data, even by two seeds at once.
+func RunSeed(store *Store, source fs.FS, opts SeedOptions) error {
+	available, err := ListSeedPacks(source)
+	if err != nil {
+		return err
+	}
+	packs, err := resolveSeedPacks(available, opts.Packs)
 	if err != nil {
-		return fmt.Errorf("failed to read words and groups seed file: %v", err)
+		return err
+	}
+
+	// Read every pack before writing anything
+	type packData struct {
+		pack           *SeedPack
+		activities     studyActivitiesSeed
+		wordsAndGroups wordsAndGroupsSeed
+	}
+	data := make([]packData, len(packs))
+	for i, pack := range packs {
+		data[i].pack = pack
+		if err := readSeedFile(source, pack, studyActivitiesFile, &data[i].activities); err != nil {
+			return err
+		}
+		if err := readSeedFile(source, pack, wordsAndGroupsFile, &data[i].wordsAndGroups); err != nil {
+			return err
+		}
+		if err := data[i].wordsAndGroups.validate(); err != nil {
+			return fmt.Errorf("invalid words and groups of seed pack %s: %v", pack.Name, err)
+		}
 	}
 
-	var wordsAndGroups wordsAndGroupsSeed
-	if err := json.Unmarshal(wordsData, &wordsAndGroups); err != nil {
-		return fmt.Errorf("failed to parse words and groups seed data: %v", err)
+	db := store.DB
+	s := seeder{q: dialect.Of(db).Rebind}
+	if s.tx, err = db.Begin(); err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
+	defer s.tx.Rollback()
 
-	// Read and parse study activities
-	activitiesData, err := ioutil.ReadFile("seeds/study_activities.json")
-	if err != nil {
-		return fmt.Errorf("failed to read study activities seed file: %v", err)
+	for _, d := range data {
+		var counts seedCounts
+		if err := s.seedActivities(d.activities.StudyActivities, &counts); err != nil {
+			return fmt.Errorf("seed pack %s: %v", d.pack.Name, err)
+		}
+		groupIDs, err := s.seedGroups(d.wordsAndGroups.Groups, &counts)
+		if err != nil {
+			return fmt.Errorf("seed pack %s: %v", d.pack.Name, err)
+		}
+		if opts.SampleSessions && len(groupIDs) > 0 {
+			if err := s.seedSampleSession(groupIDs[0], &counts); err != nil {
+				return fmt.Errorf("seed pack %s: %v", d.pack.Name, err)
+			}
+		}
+		slog.Info("seeded pack", "pack", d.pack.Name, "version", d.pack.Version,
+			"activities_created", counts.activitiesCreated, "activities_updated", counts.activitiesUpdated,
+			"groups_created", counts.groupsCreated, "words_created", counts.wordsCreated,
+			"words_linked", counts.wordsLinked, "sample_sessions", counts.sampleSessions)
 	}
 
-	var activities studyActivitiesSeed
-	if err := json.Unmarshal(activitiesData, &activities); err != nil {
-		return fmt.Errorf("failed to parse study activities seed data: %v", err)
+	if err := s.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
 	}
+	return nil
+}
 
-	// Begin transaction
-	tx, err := db.Begin()
+// readSeedFile parses the data file name of pack into v, leaving v empty if
+// the pack doesn't have one
+func readSeedFile(source fs.FS, pack *SeedPack, name string, v any) error {
+	data, err := fs.ReadFile(source, path.Join(pack.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to read %s of seed pack %s: %v", name, pack.Name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s of seed pack %s: %v", name, pack.Name, err)
+	}
+	return nil
+}
+
+// seeder upserts seed data in a transaction
+type seeder struct {
+	tx *sql.Tx
+	q  func(string) string
+}
+
+// findID returns the id selected by query, or 0 if there is none
+func (s seeder) findID(query string, args ...any) (int64, error) {
+	var id int64
+	err := s.tx.QueryRow(s.q(query), args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
 	}
-	defer tx.Rollback()
+	return id, err
+}
 
-	// Insert study activities
-	log.Println("Seeding study activities...")
-	for _, activity := range activities.StudyActivities {
-		_, err := tx.Exec(`
-			INSERT INTO study_activities (name, thumbnail_url, description, created_at)
-			VALUES (?, ?, ?, ?)
-		`, activity.Name, activity.ThumbnailURL, activity.Description, time.Now())
+// insert runs an INSERT ... RETURNING id statement
+func (s seeder) insert(query string, args ...any) (int64, error) {
+	var id int64
+	err := s.tx.QueryRow(s.q(query), args...).Scan(&id)
+	return id, err
+}
+
+// insertOrFind runs insert, an INSERT ... ON CONFLICT DO NOTHING RETURNING
+// id statement, and selects the row it conflicted with using find if it
+// didn't insert one. It reports whether the row was created. Unlike looking
+// the row up first, this can't fail or create a duplicate when another seed
+// inserts the same row meanwhile.
+func (s seeder) insertOrFind(insert string, insertArgs []any, find string, findArgs ...any) (int64, bool, error) {
+	id, err := s.insert(insert, insertArgs...)
+	if err == nil {
+		return id, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, false, err
+	}
+	id, err = s.findID(find, findArgs...)
+	if err == nil && id == 0 {
+		err = errors.New("the conflicting row is gone")
+	}
+	return id, false, err
+}
+
+// seedActivities creates the missing activities and updates the thumbnail,
+// description and difficulty of the others
+func (s seeder) seedActivities(activities []studyActivity, counts *seedCounts) error {
+	for _, activity := range activities {
+		id, created, err := s.insertOrFind(`
+			INSERT INTO study_activities (name, thumbnail_url, description, difficulty, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+			RETURNING id
+		`, []any{activity.Name, activity.ThumbnailURL, activity.Description, activity.Difficulty, time.Now().UTC()},
+			`SELECT id FROM study_activities WHERE name = ?`, activity.Name)
 		if err != nil {
 			return fmt.Errorf("failed to insert study activity: %v", err)
 		}
+		if created {
+			counts.activitiesCreated++
+			continue
+		}
+
+		result, err := s.tx.Exec(s.q(`
+			UPDATE study_activities SET thumbnail_url = ?, description = ?, difficulty = ?
+			WHERE id = ? AND (thumbnail_url <> ? OR description <> ? OR difficulty <> ?)
+		`), activity.ThumbnailURL, activity.Description, activity.Difficulty,
+			id, activity.ThumbnailURL, activity.Description, activity.Difficulty)
+		if err != nil {
+			return fmt.Errorf("failed to update study activity: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			counts.activitiesUpdated++
+		}
 	}
+	return nil
+}
 
-	// Insert groups and words
-	log.Println("Seeding groups and words...")
-	for _, group := range wordsAndGroups.Groups {
-		// Insert group
-		groupResult, err := tx.Exec(`
+// seedGroups creates the missing groups and words and adds the words to
+// their groups. It returns the IDs of the groups, in seed order.
+func (s seeder) seedGroups(groups []seedGroup, counts *seedCounts) ([]int64, error) {
+	groupIDs := make([]int64, 0, len(groups))
+	for _, group := range groups {
+		groupID, created, err := s.insertOrFind(`
 			INSERT INTO groups (name, created_at)
 			VALUES (?, ?)
-		`, group.Name, time.Now())
+			ON CONFLICT DO NOTHING
+			RETURNING id
+		`, []any{group.Name, time.Now().UTC()},
+			`SELECT id FROM groups WHERE lower(name) = lower(?)`, group.Name)
 		if err != nil {
-			return fmt.Errorf("failed to insert group: %v", err)
+			return nil, fmt.Errorf("failed to insert group: %v", err)
 		}
-
-		groupID, err := groupResult.LastInsertId()
-		if err != nil {
-			return fmt.Errorf("failed to get group ID: %v", err)
+		if created {
+			counts.groupsCreated++
 		}
+		groupIDs = append(groupIDs, groupID)
 
-		// Insert words and create word-group associations
 		for _, word := range group.Words {
-			// Insert word
-			wordResult, err := tx.Exec(`
+			wordID, created, err := s.insertOrFind(`
 				INSERT INTO words (portuguese, english, created_at)
 				VALUES (?, ?, ?)
-			`, word.Portuguese, word.English, time.Now())
+				ON CONFLICT DO NOTHING
+				RETURNING id
+			`, []any{word.Portuguese, word.English, time.Now().UTC()},
+				`SELECT id FROM words WHERE portuguese = ? AND english = ?`, word.Portuguese, word.English)
 			if err != nil {
-				return fmt.Errorf("failed to insert word: %v", err)
+				return nil, fmt.Errorf("failed to insert word: %v", err)
 			}
-
-			wordID, err := wordResult.LastInsertId()
-			if err != nil {
-				return fmt.Errorf("failed to get word ID: %v", err)
+			if created {
+				counts.wordsCreated++
 			}
 
-			// Create word-group association
-			_, err = tx.Exec(`
+			result, err := s.tx.Exec(s.q(`
 				INSERT INTO words_groups (word_id, group_id)
 				VALUES (?, ?)
-			`, wordID, groupID)
+				ON CONFLICT (word_id, group_id) DO NOTHING
+			`), wordID, groupID)
 			if err != nil {
-				return fmt.Errorf("failed to insert word-group association: %v", err)
+				return nil, fmt.Errorf("failed to insert word-group association: %v", err)
+			}
+			if n, _ := result.RowsAffected(); n > 0 {
+				counts.wordsLinked++
 			}
 		}
 	}
+	return groupIDs, nil
+}
 
-	// Create some sample study sessions and word reviews
-	log.Println("Creating sample study sessions and reviews...")
-	// Get first group and activity IDs
-	var firstGroupID, firstActivityID int64
-	err = tx.QueryRow("SELECT id FROM groups LIMIT 1").Scan(&firstGroupID)
+// seedSampleSession creates a study session of the first study activity
+// with a correct review of up to five words of the group, unless the group
+// already has a session. The reviews earn the XP of a combo, as if answered
+// in the app.
+func (s seeder) seedSampleSession(groupID int64, counts *seedCounts) error {
+	existing, err := s.findID(`SELECT id FROM study_sessions WHERE group_id = ? ORDER BY id LIMIT 1`, groupID)
 	if err != nil {
-		return fmt.Errorf("failed to get first group ID: %v", err)
+		return fmt.Errorf("failed to look up study sessions: %v", err)
+	}
+	if existing != 0 {
+		return nil
 	}
-	err = tx.QueryRow("SELECT id FROM study_activities LIMIT 1").Scan(&firstActivityID)
+	activityID, err := s.findID("SELECT id FROM study_activities ORDER BY id LIMIT 1")
 	if err != nil {
 		return fmt.Errorf("failed to get first activity ID: %v", err)
 	}
+	if activityID == 0 {
+		return errors.New("a sample study session needs a study activity")
+	}
 
-	// Create a study session
-	sessionResult, err := tx.Exec(`
+	sessionID, err := s.insert(`
 		INSERT INTO study_sessions (group_id, study_activity_id, created_at)
 		VALUES (?, ?, ?)
-	`, firstGroupID, firstActivityID, time.Now())
+		RETURNING id
+	`, groupID, activityID, time.Now().UTC())
 	if err != nil {
 		return fmt.Errorf("failed to create study session: %v", err)
 	}
 
-	sessionID, err := sessionResult.LastInsertId()
+	// The IDs are read first because Postgres can't run the inserts while
+	// the rows are open on the same connection
+	wordIDs, err := sampleWordIDs(s.tx, s.q, groupID)
 	if err != nil {
-		return fmt.Errorf("failed to get session ID: %v", err)
+		return fmt.Errorf("failed to get words for reviews: %v", err)
 	}
+	for i, wordID := range wordIDs {
+		_, err = s.tx.Exec(s.q(`
+			INSERT INTO word_review_items (word_id, study_session_id, correct, xp, created_at)
+			VALUES (?, ?, ?, ?, ?)
+		`), wordID, sessionID, true, models.ReviewXP(i+1), time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to create word review: %v", err)
+		}
+	}
+	counts.sampleSessions++
+	return nil
+}
 
-	// Add some word reviews
-	rows, err := tx.Query(`
+// sampleWordIDs returns up to five words of a group to review
+func sampleWordIDs(tx *sql.Tx, q func(string) string, groupID int64) ([]int64, error) {
+	rows, err := tx.Query(q(`
 		SELECT id FROM words
 		WHERE id IN (
 			SELECT word_id FROM words_groups
 			WHERE group_id = ?
 		)
+		ORDER BY id
 		LIMIT 5
-	`, firstGroupID)
+	`), groupID)
 	if err != nil {
-		return fmt.Errorf("failed to get words for reviews: %v", err)
+		return nil, err
 	}
 	defer rows.Close()
 
+	var ids []int64
 	for rows.Next() {
-		var wordID int64
-		if err := rows.Scan(&wordID); err != nil {
-			return fmt.Errorf("failed to scan word ID: %v", err)
-		}
-
-		_, err = tx.Exec(`
-			INSERT INTO word_review_items (word_id, study_session_id, correct, created_at)
-			VALUES (?, ?, ?, ?)
-		`, wordID, sessionID, true, time.Now())
-		if err != nil {
-			return fmt.Errorf("failed to create word review: %v", err)
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
 		}
+		ids = append(ids, id)
 	}
-
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %v", err)
-	}
-
-	log.Println("Seeding completed successfully")
-	return nil
+	return ids, rows.Err()
 }
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// manifestFile describes a seed pack, in the pack's directory
+const manifestFile = "manifest.json"
+
+// SeedPack is a named, versioned set of seed data: a directory of the seed
+// source holding a manifest and, optionally, study_activities.json and
+// words_and_groups.json
+type SeedPack struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	// Dependencies maps the packs loaded before this one to the version
+	// each must be compatible with: the same major version, and a minor and
+	// patch version at least as recent
+	Dependencies map[string]string `json:"dependencies"`
+
+	// dir is the pack's directory in the seed source
+	dir string
+}
+
+// ListSeedPacks reads the manifests of the packs in source, sorted by name
+func ListSeedPacks(source fs.FS) ([]*SeedPack, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed packs: %v", err)
+	}
+
+	var packs []*SeedPack
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := fs.ReadFile(source, path.Join(entry.Name(), manifestFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest of seed pack %s: %v", entry.Name(), err)
+		}
+
+		pack := &SeedPack{dir: entry.Name()}
+		if err := json.Unmarshal(data, pack); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest of seed pack %s: %v", entry.Name(), err)
+		}
+		if err := pack.validate(); err != nil {
+			return nil, fmt.Errorf("invalid manifest of seed pack %s: %v", entry.Name(), err)
+		}
+		packs = append(packs, pack)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name < packs[j].Name })
+	return packs, nil
+}
+
+// validate checks that the pack is named after its directory and that its
+// versions are well formed
+func (p *SeedPack) validate() error {
+	if p.Name != p.dir {
+		return fmt.Errorf("name %q differs from the directory name", p.Name)
+	}
+	if _, err := parseVersion(p.Version); err != nil {
+		return err
+	}
+	for name, version := range p.Dependencies {
+		if _, err := parseVersion(version); err != nil {
+			return fmt.Errorf("dependency %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// resolveSeedPacks returns the packs called names and the packs they depend
+// on, each after its dependencies. All the packs are returned if names is
+// empty.
+func resolveSeedPacks(packs []*SeedPack, names []string) ([]*SeedPack, error) {
+	byName := make(map[string]*SeedPack, len(packs))
+	for _, pack := range packs {
+		byName[pack.Name] = pack
+	}
+	if len(names) == 0 {
+		for _, pack := range packs {
+			names = append(names, pack.Name)
+		}
+	}
+
+	var ordered []*SeedPack
+	// done is false while a pack's dependencies are being resolved, to
+	// detect cycles, and true once it is ordered
+	done := map[string]bool{}
+	var visit func(name string, chain []string) error
+	visit = func(name string, chain []string) error {
+		chain = append(chain, name)
+		finished, seen := done[name]
+		if finished {
+			return nil
+		}
+		if seen {
+			return fmt.Errorf("seed packs depend on each other: %s", strings.Join(chain, " -> "))
+		}
+		pack, ok := byName[name]
+		if !ok {
+			if len(chain) > 1 {
+				return fmt.Errorf("seed pack %s depends on unknown pack %s", chain[len(chain)-2], name)
+			}
+			return fmt.Errorf("unknown seed pack %s", name)
+		}
+
+		done[name] = false
+		deps := make([]string, 0, len(pack.Dependencies))
+		for dep := range pack.Dependencies {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, chain); err != nil {
+				return err
+			}
+			if !compatible(byName[dep].Version, pack.Dependencies[dep]) {
+				return fmt.Errorf("seed pack %s requires %s %s, which is at version %s",
+					name, dep, pack.Dependencies[dep], byName[dep].Version)
+			}
+		}
+		done[name] = true
+		ordered = append(ordered, pack)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// version is a MAJOR.MINOR.PATCH version
+type version [3]int
+
+func parseVersion(s string) (version, error) {
+	var v version
+	parts := strings.Split(s, ".")
+	if len(parts) != len(v) {
+		return v, fmt.Errorf("version %q is not MAJOR.MINOR.PATCH", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("version %q is not MAJOR.MINOR.PATCH", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// compatible reports whether a pack at version have satisfies a dependency
+// on version want. Both are valid versions.
+func compatible(have, want string) bool {
+	h, _ := parseVersion(have)
+	w, _ := parseVersion(want)
+	if h[0] != w[0] {
+		return false
+	}
+	if h[1] != w[1] {
+		return h[1] > w[1]
+	}
+	return h[2] >= w[2]
+}
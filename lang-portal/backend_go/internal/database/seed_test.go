@@ -0,0 +1,217 @@
+package database
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSeedTestDB(t *testing.T) *TestDB {
+	t.Helper()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	t.Cleanup(tdb.Close)
+	return tdb
+}
+
+func countRows(t *testing.T, tdb *TestDB, table string) int {
+	t.Helper()
+	var count int
+	require.NoError(t, tdb.DB.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+	return count
+}
+
+// TestSeedIsIdempotent tests that the packs shipped in seeds/ load, and that
+// loading them again creates nothing
+func TestSeedIsIdempotent(t *testing.T) {
+	t.Parallel()
+	tdb := newSeedTestDB(t)
+	seeds := os.DirFS("../../seeds")
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, RunSeed(tdb.Store, seeds, SeedOptions{}))
+		assert.Equal(t, 3, countRows(t, tdb, "study_activities"))
+		assert.Equal(t, 3, countRows(t, tdb, "groups"))
+		assert.Equal(t, 15, countRows(t, tdb, "words"))
+		assert.Equal(t, 15, countRows(t, tdb, "words_groups"))
+		assert.Zero(t, countRows(t, tdb, "study_sessions"), "sample sessions are optional")
+	}
+}
+
+// TestSeedSampleSessions tests that a sample session is added to a group
+// only while it has none
+func TestSeedSampleSessions(t *testing.T) {
+	t.Parallel()
+	tdb := newSeedTestDB(t)
+	seeds := os.DirFS("../../seeds")
+
+	opts := SeedOptions{Packs: []string{"pt-a1-basics"}, SampleSessions: true}
+	require.NoError(t, RunSeed(tdb.Store, seeds, opts))
+	require.NoError(t, RunSeed(tdb.Store, seeds, opts))
+	assert.Equal(t, 3, countRows(t, tdb, "study_activities"), "dependencies are loaded")
+	assert.Equal(t, 1, countRows(t, tdb, "study_sessions"))
+	assert.Equal(t, 5, countRows(t, tdb, "word_review_items"))
+
+	var xp int
+	require.NoError(t, tdb.DB.QueryRow("SELECT SUM(xp) FROM word_review_items").Scan(&xp))
+	want := 0
+	for combo := 1; combo <= 5; combo++ {
+		want += models.ReviewXP(combo)
+	}
+	assert.Equal(t, want, xp, "the reviews earn XP as a combo")
+}
+
+// TestSeedUpsertsOnNaturalKeys tests that a pack loaded again after it
+// changed only adds what is new, matching the data already loaded on the
+// activity name, the group name regardless of case and the word pair
+func TestSeedUpsertsOnNaturalKeys(t *testing.T) {
+	t.Parallel()
+	tdb := newSeedTestDB(t)
+	manifest := &fstest.MapFile{Data: []byte(`{"name": "basics", "version": "1.0.0"}`)}
+
+	require.NoError(t, RunSeed(tdb.Store, fstest.MapFS{
+		"basics/manifest.json": manifest,
+		"basics/study_activities.json": {Data: []byte(`{"study_activities": [
+			{"name": "Flashcards", "thumbnail_url": "/old.png", "description": "Old"}
+		]}`)},
+		"basics/words_and_groups.json": {Data: []byte(`{"groups": [
+			{"name": "Greetings", "words": [{"portuguese": "olá", "english": "hello"}]}
+		]}`)},
+	}, SeedOptions{}))
+
+	require.NoError(t, RunSeed(tdb.Store, fstest.MapFS{
+		"basics/manifest.json": manifest,
+		"basics/study_activities.json": {Data: []byte(`{"study_activities": [
+			{"name": "Flashcards", "thumbnail_url": "/new.png", "description": "New", "difficulty": 1}
+		]}`)},
+		"basics/words_and_groups.json": {Data: []byte(`{"groups": [
+			{"name": "greetings", "words": [{"portuguese": "olá", "english": "hello"}, {"portuguese": "oi", "english": "hi"}]},
+			{"name": "Informal", "words": [{"portuguese": "oi", "english": "hi"}]}
+		]}`)},
+	}, SeedOptions{}))
+
+	assert.Equal(t, 1, countRows(t, tdb, "study_activities"))
+	var thumbnail, description string
+	var difficulty int
+	require.NoError(t, tdb.DB.QueryRow("SELECT thumbnail_url, description, difficulty FROM study_activities").
+		Scan(&thumbnail, &description, &difficulty))
+	assert.Equal(t, "/new.png", thumbnail)
+	assert.Equal(t, "New", description)
+	assert.Equal(t, 1, difficulty)
+
+	assert.Equal(t, 2, countRows(t, tdb, "groups"))
+	assert.Equal(t, 2, countRows(t, tdb, "words"), "words shared by groups are created once")
+	assert.Equal(t, 3, countRows(t, tdb, "words_groups"))
+}
+
+// TestSeedRejectsInvalidPacks tests that nothing is loaded when a pack
+// can't be
+func TestSeedRejectsInvalidPacks(t *testing.T) {
+	t.Parallel()
+	tdb := newSeedTestDB(t)
+
+	err := RunSeed(tdb.Store, fstest.MapFS{
+		"good/manifest.json":         {Data: []byte(`{"name": "good", "version": "1.0.0"}`)},
+		"good/words_and_groups.json": {Data: []byte(`{"groups": [{"name": "Greetings", "words": []}]}`)},
+		"bad/manifest.json":          {Data: []byte(`{"name": "bad", "version": "1.0.0"}`)},
+		"bad/words_and_groups.json":  {Data: []byte(`{"groups": [{"name": "", "words": []}]}`)},
+	}, SeedOptions{})
+	assert.ErrorContains(t, err, "seed pack bad")
+	assert.Zero(t, countRows(t, tdb, "groups"))
+
+	err = RunSeed(tdb.Store, fstest.MapFS{
+		"pack/manifest.json": {Data: []byte(`{"name": "other", "version": "1.0.0"}`)},
+	}, SeedOptions{})
+	assert.ErrorContains(t, err, "differs from the directory name")
+}
+
+func TestResolveSeedPacks(t *testing.T) {
+	pack := func(name, version string, deps map[string]string) *SeedPack {
+		return &SeedPack{Name: name, Version: version, Dependencies: deps, dir: name}
+	}
+	names := func(packs []*SeedPack) []string {
+		var names []string
+		for _, p := range packs {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name      string
+		packs     []*SeedPack
+		requested []string
+		want      []string
+		wantErr   string
+	}{
+		{
+			name: "dependencies first",
+			packs: []*SeedPack{
+				pack("activities", "1.2.0", nil),
+				pack("a1", "1.0.0", map[string]string{"activities": "1.1.0", "core": "2.0.0"}),
+				pack("core", "2.0.1", map[string]string{"activities": "1.0.0"}),
+			},
+			requested: []string{"a1"},
+			want:      []string{"activities", "core", "a1"},
+		},
+		{
+			name:  "every pack by default",
+			packs: []*SeedPack{pack("a", "1.0.0", nil), pack("b", "1.0.0", map[string]string{"a": "1.0.0"})},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:      "unknown pack",
+			packs:     []*SeedPack{pack("a", "1.0.0", nil)},
+			requested: []string{"b"},
+			wantErr:   "unknown seed pack b",
+		},
+		{
+			name:      "unknown dependency",
+			packs:     []*SeedPack{pack("a", "1.0.0", map[string]string{"b": "1.0.0"})},
+			requested: []string{"a"},
+			wantErr:   "seed pack a depends on unknown pack b",
+		},
+		{
+			name: "cycle",
+			packs: []*SeedPack{
+				pack("a", "1.0.0", map[string]string{"b": "1.0.0"}),
+				pack("b", "1.0.0", map[string]string{"a": "1.0.0"}),
+			},
+			requested: []string{"a"},
+			wantErr:   "a -> b -> a",
+		},
+		{
+			name: "incompatible major version",
+			packs: []*SeedPack{
+				pack("a", "2.0.0", nil),
+				pack("b", "1.0.0", map[string]string{"a": "1.0.0"}),
+			},
+			requested: []string{"b"},
+			wantErr:   "seed pack b requires a 1.0.0, which is at version 2.0.0",
+		},
+		{
+			name: "too old",
+			packs: []*SeedPack{
+				pack("a", "1.1.9", nil),
+				pack("b", "1.0.0", map[string]string{"a": "1.2.0"}),
+			},
+			requested: []string{"b"},
+			wantErr:   "seed pack b requires a 1.2.0, which is at version 1.1.9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := resolveSeedPacks(tt.packs, tt.requested)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(resolved))
+		})
+	}
+}
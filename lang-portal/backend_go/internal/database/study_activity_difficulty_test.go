@@ -0,0 +1,42 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStudyActivityDifficultyMigration tests that the activities created
+// before the migration are ranked in the order of their IDs
+func TestStudyActivityDifficultyMigration(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+
+	migration, err := migrationFiles.ReadFile("migrations/sqlite/11_study_activity_difficulty.sql")
+	require.NoError(t, err)
+
+	_, err = tdb.DB.Exec(`
+		ALTER TABLE study_activities DROP COLUMN difficulty;
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES
+			(4, 'writing', '', ''), (2, 'flashcards', '', ''), (7, 'matching', '', '');
+	`)
+	require.NoError(t, err)
+
+	_, err = tdb.DB.Exec(string(migration))
+	require.NoError(t, err)
+
+	rows, err := tdb.DB.Query("SELECT difficulty FROM study_activities ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close()
+	var difficulties []int
+	for rows.Next() {
+		var difficulty int
+		require.NoError(t, rows.Scan(&difficulty))
+		difficulties = append(difficulties, difficulty)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []int{1, 2, 3}, difficulties)
+}
@@ -1,42 +1,44 @@
 package database
 
 import (
-	"database/sql"
-	"io/ioutil"
+	"fmt"
 	"os"
-	"path/filepath"
+	"sync/atomic"
 
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// TestDB represents a test database
+// TestDB is a migrated database of its own, so that tests using one can
+// run in parallel
 type TestDB struct {
-	DB   *sql.DB
+	*Store
 	Path string
+
+	// drop removes a Postgres test schema
+	drop func()
 }
 
-// NewTestDB creates a new test database
+// NewTestDB creates a SQLite database in a temporary file, opened as the
+// server opens it
 func NewTestDB() (*TestDB, error) {
 	// Create a temporary file for the test database
-	tempFile, err := ioutil.TempFile("", "test-db-*.sqlite")
+	tempFile, err := os.CreateTemp("", "test-db-*.sqlite")
 	if err != nil {
 		return nil, err
 	}
 	tempFile.Close()
 
-	// Open the database connection
-	db, err := sql.Open("sqlite3", tempFile.Name())
-	if err != nil {
-		os.Remove(tempFile.Name())
+	cfg := config.Default().Database
+	cfg.Path = tempFile.Name()
+	testDB := &TestDB{Path: tempFile.Name()}
+	if testDB.Store, err = Open(cfg); err != nil {
+		testDB.Close()
 		return nil, err
 	}
 
-	// Create the test database
-	testDB := &TestDB{
-		DB:   db,
-		Path: tempFile.Name(),
-	}
-
 	// Initialize the database schema
 	if err := testDB.initSchema(); err != nil {
 		testDB.Close()
@@ -46,76 +48,80 @@ func NewTestDB() (*TestDB, error) {
 	return testDB, nil
 }
 
-// initSchema initializes the database schema
-func (tdb *TestDB) initSchema() error {
-	// Find the project root directory
-	rootDir, err := findProjectRoot()
-	if err != nil {
-		return err
-	}
-
-	// Read the schema file from the migrations directory
-	schemaPath := filepath.Join(rootDir, "internal", "database", "migrations", "01_initial_schema.sql")
-	schema, err := ioutil.ReadFile(schemaPath)
+// NewPostgresTestDB creates a test database on the Postgres server at url,
+// in a schema of its own that Close drops. Tests sharing a server don't see
+// each other's data.
+func NewPostgresTestDB(url string) (*TestDB, error) {
+	cfg, err := pgx.ParseConfig(url)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Execute the schema
-	_, err = tdb.DB.Exec(string(schema))
-	return err
-}
-
-// findProjectRoot attempts to find the project root directory
-func findProjectRoot() (string, error) {
-	// Start with the current working directory
-	dir, err := os.Getwd()
-	if err != nil {
-		return "", err
+	admin := stdlib.OpenDB(*cfg)
+	schema := fmt.Sprintf("test_%d_%d", os.Getpid(), testSchemas.Add(1))
+	for _, stmt := range []string{"DROP SCHEMA IF EXISTS " + schema + " CASCADE", "CREATE SCHEMA " + schema} {
+		if _, err := admin.Exec(stmt); err != nil {
+			admin.Close()
+			return nil, err
+		}
 	}
 
-	// Check if we're already at the project root
-	if isProjectRoot(dir) {
-		return dir, nil
+	cfg.RuntimeParams["search_path"] = schema
+	db := stdlib.OpenDB(*cfg)
+	testDB := &TestDB{
+		Store: &Store{DB: db, Read: db},
+		drop: func() {
+			admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+			admin.Close()
+		},
 	}
-
-	// Walk up the directory tree until we find the project root
-	for {
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			// We've reached the filesystem root without finding the project root
-			return "", os.ErrNotExist
-		}
-		dir = parent
-		if isProjectRoot(dir) {
-			return dir, nil
-		}
+	if err := testDB.initSchema(); err != nil {
+		testDB.Close()
+		return nil, err
 	}
+	return testDB, nil
 }
 
-// isProjectRoot checks if the given directory is the project root
-func isProjectRoot(dir string) bool {
-	// Check for common indicators of the project root
-	indicators := []string{
-		"go.mod",
-		"internal/database/migrations/01_initial_schema.sql",
-	}
+// testSchemas numbers the Postgres test schemas created by this process
+var testSchemas atomic.Int64
 
-	for _, indicator := range indicators {
-		if _, err := os.Stat(filepath.Join(dir, indicator)); err == nil {
-			return true
-		}
-	}
-
-	return false
+// initSchema applies the migrations the server runs
+func (tdb *TestDB) initSchema() error {
+	return applyMigrations(tdb.DB)
 }
 
-// Close closes the database connection and removes the temporary file
+// Close closes the database connections and removes the temporary files
 func (tdb *TestDB) Close() {
-	if tdb.DB != nil {
-		tdb.DB.Close()
+	if tdb.Store != nil {
+		tdb.Store.Close()
 	}
 	if tdb.Path != "" {
-		os.Remove(tdb.Path)
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			os.Remove(tdb.Path + suffix)
+		}
 	}
+	if tdb.drop != nil {
+		tdb.drop()
+	}
+}
+
+// SlowStudySessions replaces the study_sessions table with a view over
+// millions of generated rows, so that queries aggregating sessions run for
+// seconds. Tests use it to check that canceling a request interrupts them.
+// The database can't be written to study_sessions afterwards.
+func (tdb *TestDB) SlowStudySessions() error {
+	_, err := tdb.DB.Exec(`
+		DROP TABLE study_sessions;
+		CREATE VIEW study_sessions AS
+		WITH RECURSIVE n(x) AS (
+			SELECT 1
+			UNION ALL
+			SELECT x + 1 FROM n LIMIT 50000000
+		)
+		SELECT
+			x AS id, 1 AS group_id, 1 AS study_activity_id,
+			datetime('now', '-' || x || ' seconds') AS created_at,
+			NULL AS user_id
+		FROM n;
+	`)
+	return err
 }
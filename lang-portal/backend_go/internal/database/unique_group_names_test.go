@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUniqueGroupNamesMigration tests that the migration making group names
+// unique renames the groups named like an older one before
+func TestUniqueGroupNamesMigration(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+
+	migration, err := migrationFiles.ReadFile("migrations/sqlite/03_unique_group_names.sql")
+	require.NoError(t, err)
+
+	_, err = tdb.DB.Exec(`
+		DROP INDEX idx_groups_name_unique;
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings'), (2, 'Food'), (3, 'greetings'), (4, 'GREETINGS');
+	`)
+	require.NoError(t, err)
+
+	_, err = tdb.DB.Exec(string(migration))
+	require.NoError(t, err)
+
+	rows, err := tdb.DB.Query("SELECT name FROM groups ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"Greetings", "Food", "greetings (3)", "GREETINGS (4)"}, names)
+
+	_, err = tdb.DB.Exec("INSERT INTO groups (name) VALUES ('FOOD')")
+	assert.Error(t, err, "names are unique regardless of case")
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUniqueWordsAndActivitiesMigration tests that the migration making words
+// and study activities unique merges the duplicates into the oldest, keeping
+// their groups, reviews, sessions and assignments
+func TestUniqueWordsAndActivitiesMigration(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+
+	migration, err := migrationFiles.ReadFile("migrations/sqlite/05_unique_words_and_activities.sql")
+	require.NoError(t, err)
+
+	_, err = tdb.DB.Exec(`
+		DROP INDEX idx_words_portuguese_english;
+		DROP INDEX idx_study_activities_name;
+		INSERT INTO words (id, portuguese, english) VALUES (1, 'olá', 'hello'), (2, 'sim', 'yes'), (3, 'olá', 'hello');
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings'), (2, 'Basics');
+		INSERT INTO words_groups (word_id, group_id) VALUES (1, 1), (3, 1), (3, 2), (2, 2);
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES
+			(1, 'flashcards', '', ''), (2, 'quiz', '', ''), (3, 'flashcards', '', '');
+		INSERT INTO users (id, name, role) VALUES (1, 'Teresa', 'teacher'), (2, 'Ana', 'student');
+		INSERT INTO classrooms (id, name, teacher_id) VALUES (1, 'A1', 1);
+		INSERT INTO assignments (classroom_id, group_id, study_activity_id, due_at) VALUES (1, 1, 3, '2025-04-01 00:00:00+00:00');
+		INSERT INTO study_sessions (id, group_id, study_activity_id, user_id) VALUES (1, 1, 1, 2), (2, 1, 3, 2);
+		INSERT INTO word_review_items (id, word_id, study_session_id, correct, created_at) VALUES
+			(1, 1, 1, TRUE, '2025-03-01 09:00:00+00:00'),
+			(2, 3, 2, FALSE, '2025-03-02 09:00:00+00:00'),
+			(3, 1, 1, TRUE, '2025-03-03 09:00:00+00:00');
+	`)
+	require.NoError(t, err)
+
+	_, err = tdb.DB.Exec(string(migration))
+	require.NoError(t, err)
+
+	ints := func(query string) []int64 {
+		t.Helper()
+		rows, err := tdb.DB.Query(query)
+		require.NoError(t, err)
+		defer rows.Close()
+		var values []int64
+		for rows.Next() {
+			var value int64
+			require.NoError(t, rows.Scan(&value))
+			values = append(values, value)
+		}
+		require.NoError(t, rows.Err())
+		return values
+	}
+	assert.Equal(t, []int64{1, 2}, ints("SELECT id FROM words ORDER BY id"))
+	assert.Equal(t, []int64{1, 1, 2}, ints("SELECT word_id FROM words_groups ORDER BY group_id, word_id"))
+	assert.Equal(t, []int64{1, 1, 1}, ints("SELECT word_id FROM word_review_items ORDER BY id"))
+	assert.Equal(t, []int64{1, 2}, ints("SELECT id FROM study_activities ORDER BY id"))
+	assert.Equal(t, []int64{1, 1}, ints("SELECT study_activity_id FROM study_sessions ORDER BY id"))
+	assert.Equal(t, []int64{1}, ints("SELECT study_activity_id FROM assignments"))
+
+	_, err = tdb.DB.Exec("INSERT INTO words (portuguese, english) VALUES ('sim', 'yes')")
+	assert.Error(t, err, "words are unique")
+	_, err = tdb.DB.Exec("INSERT INTO study_activities (name, thumbnail_url, description) VALUES ('quiz', '', '')")
+	assert.Error(t, err, "activity names are unique")
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUTCTimestampsMigration tests that the migration rewriting the times
+// stamped with a local offset in UTC recounts what depended on their order
+func TestUTCTimestampsMigration(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+
+	migration, err := migrationFiles.ReadFile("migrations/sqlite/08_utc_timestamps.sql")
+	require.NoError(t, err)
+
+	// The wrong answer, at 02:50 UTC, sorts as text before the right one, at
+	// 01:00 UTC
+	_, err = tdb.DB.Exec(`
+		INSERT INTO words (id, portuguese, english) VALUES (1, 'olá', 'hello');
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings');
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES (1, 'flashcards', '', '');
+		INSERT INTO study_sessions (id, group_id, study_activity_id, created_at) VALUES
+			(1, 1, 1, '2025-03-10 23:35:00.123456789-03:00'),
+			(2, 1, 1, '2025-03-11 00:55:00+00:00');
+		INSERT INTO word_review_items (id, word_id, study_session_id, correct, created_at) VALUES
+			(1, 1, 1, FALSE, '2025-03-10 23:50:00-03:00'),
+			(2, 1, 2, TRUE, '2025-03-11 01:00:00+00:00');
+	`)
+	require.NoError(t, err)
+	var streak int
+	require.NoError(t, tdb.DB.QueryRow("SELECT streak FROM word_stats WHERE word_id = 1").Scan(&streak))
+	require.Equal(t, 1, streak)
+
+	_, err = tdb.DB.Exec(string(migration))
+	require.NoError(t, err)
+
+	text := func(query string) []string {
+		rows, err := tdb.DB.Query(query)
+		require.NoError(t, err)
+		defer rows.Close()
+		var values []string
+		for rows.Next() {
+			var value string
+			require.NoError(t, rows.Scan(&value))
+			values = append(values, value)
+		}
+		require.NoError(t, rows.Err())
+		return values
+	}
+	assert.Equal(t, []string{"2025-03-11 02:35:00.123+00:00", "2025-03-11 00:55:00+00:00"},
+		text("SELECT CAST(created_at AS TEXT) FROM study_sessions ORDER BY id"))
+	assert.Equal(t, []string{"2025-03-11 02:50:00.000+00:00", "2025-03-11 01:00:00+00:00"},
+		text("SELECT CAST(created_at AS TEXT) FROM word_review_items ORDER BY id"))
+	var correct, wrong int
+	var lastReviewed string
+	require.NoError(t, tdb.DB.QueryRow(`
+		SELECT correct_count, wrong_count, streak, CAST(last_reviewed_at AS TEXT) FROM word_stats WHERE word_id = 1
+	`).Scan(&correct, &wrong, &streak, &lastReviewed))
+	assert.Equal(t, 1, correct)
+	assert.Equal(t, 1, wrong)
+	assert.Zero(t, streak, "the wrong answer came last")
+	assert.Equal(t, "2025-03-11 02:50:00.000+00:00", lastReviewed)
+}
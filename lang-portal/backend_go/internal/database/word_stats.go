@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// countedWordStats counts the statistics of every reviewed word from its
+// reviews, as the word_stats triggers maintain them. The streak is the number
+// of reviews since the last wrong one.
+const countedWordStats = `
+	SELECT
+		r.word_id,
+		SUM(CASE WHEN r.correct THEN 1 ELSE 0 END) AS correct_count,
+		SUM(CASE WHEN r.correct THEN 0 ELSE 1 END) AS wrong_count,
+		MAX(r.created_at) AS last_reviewed_at,
+		SUM(CASE WHEN r.wrong_since = 0 THEN 1 ELSE 0 END) AS streak,
+		MAX(CASE WHEN r.correct THEN NULL ELSE r.created_at END) AS last_wrong_at
+	FROM (
+		SELECT wri.word_id, wri.correct, wri.created_at,
+			SUM(CASE WHEN wri.correct THEN 0 ELSE 1 END)
+				OVER (PARTITION BY wri.word_id ORDER BY wri.created_at DESC, wri.id DESC) AS wrong_since
+		FROM word_review_items wri
+		JOIN words w ON w.id = wri.word_id
+	) r
+	GROUP BY r.word_id`
+
+// RebuildWordStats recounts word_stats from the reviews, all or none, and
+// returns the number of words with statistics
+func RebuildWordStats(ctx context.Context, db *sql.DB) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM word_stats"); err != nil {
+		return 0, fmt.Errorf("failed to clear word stats: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO word_stats (word_id, correct_count, wrong_count, last_reviewed_at, streak, last_wrong_at)
+	`+countedWordStats)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count word stats: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), tx.Commit()
+}
+
+// CheckWordStats compares word_stats with the statistics counted from the
+// reviews, and returns the IDs of the words whose statistics differ
+func CheckWordStats(ctx context.Context, db *sql.DB) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT COALESCE(ws.word_id, c.word_id)
+		FROM word_stats ws
+		FULL OUTER JOIN (`+countedWordStats+`) c ON c.word_id = ws.word_id
+		WHERE ws.word_id IS NULL OR c.word_id IS NULL
+			OR ws.correct_count <> c.correct_count
+			OR ws.wrong_count <> c.wrong_count
+			OR ws.last_reviewed_at IS DISTINCT FROM c.last_reviewed_at
+			OR ws.streak <> c.streak
+			OR ws.last_wrong_at IS DISTINCT FROM c.last_wrong_at
+		ORDER BY 1
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check word stats: %w", err)
+	}
+	defer rows.Close()
+
+	var wordIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		wordIDs = append(wordIDs, id)
+	}
+	return wordIDs, rows.Err()
+}
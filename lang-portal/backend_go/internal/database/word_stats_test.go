@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWordStats tests that the triggers keep word_stats in step with the
+// reviews, and that drift is found and repaired
+func TestWordStats(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+	ctx := context.Background()
+
+	_, err = tdb.DB.Exec(`
+		INSERT INTO words (id, portuguese, english) VALUES (1, 'olá', 'hello'), (2, 'adeus', 'goodbye'), (3, 'sim', 'yes');
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings'), (2, 'Imported');
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES (1, 'flashcards', '', '');
+		INSERT INTO study_sessions (id, group_id, study_activity_id) VALUES (1, 1, 1), (2, 2, 1);
+		INSERT INTO word_review_items (word_id, study_session_id, correct, created_at) VALUES
+			(1, 1, 1, '2025-03-10 09:00:00'), (1, 1, 0, '2025-03-10 09:01:00'),
+			(1, 1, 1, '2025-03-10 09:02:00'), (1, 1, 1, '2025-03-10 09:03:00'),
+			(2, 1, 0, '2025-03-10 09:04:00'),
+			(1, 2, 0, '2025-03-10 08:00:00'), (2, 2, 1, '2025-03-10 09:05:00');
+	`)
+	require.NoError(t, err)
+
+	type stats struct{ correct, wrong, streak int }
+	statsOf := func(wordID int64) stats {
+		t.Helper()
+		var s stats
+		require.NoError(t, tdb.DB.QueryRow("SELECT correct_count, wrong_count, streak FROM word_stats WHERE word_id = ?", wordID).
+			Scan(&s.correct, &s.wrong, &s.streak))
+		return s
+	}
+	assert.Equal(t, stats{3, 2, 2}, statsOf(1))
+	assert.Equal(t, stats{1, 1, 1}, statsOf(2))
+	mismatched, err := CheckWordStats(ctx, tdb.DB)
+	require.NoError(t, err)
+	assert.Empty(t, mismatched)
+
+	// Deleting a session recounts the words reviewed in it
+	_, err = tdb.DB.Exec("DELETE FROM study_sessions WHERE id = 2")
+	require.NoError(t, err)
+	assert.Equal(t, stats{3, 1, 2}, statsOf(1))
+	assert.Equal(t, stats{0, 1, 0}, statsOf(2))
+
+	_, err = tdb.DB.Exec(`
+		UPDATE word_stats SET streak = 5 WHERE word_id = 1;
+		DELETE FROM word_stats WHERE word_id = 2;
+		INSERT INTO word_stats (word_id, correct_count) VALUES (3, 1);
+	`)
+	require.NoError(t, err)
+	mismatched, err = CheckWordStats(ctx, tdb.DB)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, mismatched)
+
+	rebuilt, err := RebuildWordStats(ctx, tdb.DB)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rebuilt)
+	mismatched, err = CheckWordStats(ctx, tdb.DB)
+	require.NoError(t, err)
+	assert.Empty(t, mismatched)
+	assert.Equal(t, stats{3, 1, 2}, statsOf(1))
+}
+
+// TestWordStatsTriggersMatchRecount tests the triggers on reviews recorded
+// and deleted in a random order, with ties, against the recount, and the
+// intervals kept between the reviews of each word by each learner
+func TestWordStatsTriggersMatchRecount(t *testing.T) {
+	t.Parallel()
+	tdb, err := NewTestDB()
+	require.NoError(t, err)
+	defer tdb.Close()
+	ctx := context.Background()
+
+	_, err = tdb.DB.Exec(`
+		INSERT INTO words (id, portuguese, english) VALUES (1, 'olá', 'hello'), (2, 'adeus', 'goodbye');
+		INSERT INTO groups (id, name) VALUES (1, 'Greetings');
+		INSERT INTO study_activities (id, name, thumbnail_url, description) VALUES (1, 'flashcards', '', '');
+		INSERT INTO users (id, name) VALUES (1, 'Ana'), (2, 'Bruno');
+	`)
+	require.NoError(t, err)
+
+	// Sessions of the guest and of two users, replaced as they are deleted
+	rng := rand.New(rand.NewSource(1))
+	learners := []interface{}{nil, 1, 2}
+	var sessions int64
+	newSession := func() {
+		t.Helper()
+		sessions++
+		_, err := tdb.DB.Exec("INSERT INTO study_sessions (id, group_id, study_activity_id, user_id) VALUES (?, 1, 1, ?)",
+			sessions, learners[rng.Intn(len(learners))])
+		require.NoError(t, err)
+	}
+	for i := 0; i < 4; i++ {
+		newSession()
+	}
+
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 300; i++ {
+		switch n := rng.Intn(20); {
+		case n == 0:
+			_, err = tdb.DB.Exec("DELETE FROM study_sessions WHERE id = (SELECT id FROM study_sessions ORDER BY RANDOM() LIMIT 1)")
+			require.NoError(t, err)
+			newSession()
+		case n < 4:
+			_, err = tdb.DB.Exec("DELETE FROM word_review_items WHERE id = (SELECT id FROM word_review_items ORDER BY RANDOM() LIMIT 1)")
+		default:
+			answered := start.Add(time.Duration(rng.Intn(40)) * time.Minute)
+			_, err = tdb.DB.Exec("INSERT INTO word_review_items (word_id, study_session_id, correct, created_at) VALUES (?, (SELECT id FROM study_sessions ORDER BY RANDOM() LIMIT 1), ?, ?)",
+				1+rng.Intn(2), rng.Intn(3) > 0, answered)
+		}
+		require.NoError(t, err)
+		mismatched, err := CheckWordStats(ctx, tdb.DB)
+		require.NoError(t, err)
+		require.Empty(t, mismatched, "after step %d", i)
+
+		var wrongIntervals int
+		require.NoError(t, tdb.DB.QueryRow(`
+			SELECT COUNT(*)
+			FROM (
+				SELECT wri.seconds_since_previous,
+					CAST(strftime('%s', wri.created_at) AS INTEGER)
+						- LAG(CAST(strftime('%s', wri.created_at) AS INTEGER)) OVER (
+							PARTITION BY wri.word_id, COALESCE(ss.user_id, 0) ORDER BY wri.created_at, wri.id
+						) AS seconds
+				FROM word_review_items wri
+				JOIN study_sessions ss ON ss.id = wri.study_session_id
+			)
+			WHERE seconds_since_previous IS NOT seconds
+		`).Scan(&wrongIntervals))
+		require.Zero(t, wrongIntervals, "after step %d", i)
+	}
+}
@@ -0,0 +1,130 @@
+// Package dialect describes the databases the portal can store its data in.
+// The repositories and seeds write their queries for SQLite, with ?
+// placeholders, and a Dialect adapts the few constructs that differ.
+package dialect
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect is the flavour of SQL spoken by a database
+type Dialect interface {
+	// Name identifies the dialect in the configuration, e.g. "postgres"
+	Name() string
+	// DriverName is the database/sql driver connections are opened with
+	DriverName() string
+	// Rebind rewrites the ? placeholders of query into the dialect's own
+	Rebind(query string) string
+	// Epoch returns an expression for the Unix time of the timestamp
+	// expression expr, in whole seconds
+	Epoch(expr string) string
+	// IsUniqueViolation reports whether err is the driver's error for a
+	// violated uniqueness constraint
+	IsUniqueViolation(err error) bool
+	// IsForeignKeyViolation reports whether err is the driver's error for a
+	// write referencing a row that doesn't exist
+	IsForeignKeyViolation(err error) bool
+}
+
+var (
+	SQLite   Dialect = sqlite{}
+	Postgres Dialect = postgres{}
+)
+
+// All lists the supported dialects
+var All = []Dialect{SQLite, Postgres}
+
+// Parse returns the dialect called name
+func Parse(name string) (Dialect, error) {
+	for _, d := range All {
+		if d.Name() == name {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown database driver %q", name)
+}
+
+// Of returns the dialect of the database db is connected to, or SQLite,
+// the default, if db is nil
+func Of(db *sql.DB) Dialect {
+	if db == nil {
+		return SQLite
+	}
+	if _, ok := db.Driver().(*stdlib.Driver); ok {
+		return Postgres
+	}
+	return SQLite
+}
+
+type sqlite struct{}
+
+func (sqlite) Name() string               { return "sqlite" }
+func (sqlite) DriverName() string         { return "sqlite3" }
+func (sqlite) Rebind(query string) string { return query }
+func (sqlite) Epoch(expr string) string   { return "CAST(strftime('%s', " + expr + ") AS INTEGER)" }
+
+func (sqlite) IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+func (sqlite) IsForeignKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
+
+type postgres struct{}
+
+// SQLSTATEs of the constraint violations told apart
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+func (postgres) Name() string       { return "postgres" }
+func (postgres) DriverName() string { return "pgx" }
+
+// Rebind numbers the placeholders $1, $2... skipping those in string literals
+func (postgres) Rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n, quoted := 0, false
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(query[i])
+	}
+	return b.String()
+}
+
+func (postgres) Epoch(expr string) string {
+	return "CAST(floor(extract(epoch FROM " + expr + ")) AS BIGINT)"
+}
+
+func (postgres) IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+func (postgres) IsForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
@@ -0,0 +1,101 @@
+package dialect
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT id FROM words WHERE id = ?", "SELECT id FROM words WHERE id = $1"},
+		{"LIMIT ? OFFSET ?", "LIMIT $1 OFFSET $2"},
+		{"WHERE id IN (?,?,?)", "WHERE id IN ($1,$2,$3)"},
+		{"WHERE name = '?' AND id = ?", "WHERE name = '?' AND id = $1"},
+		{"WHERE name = 'it''s?' AND id = ?", "WHERE name = 'it''s?' AND id = $1"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Postgres.Rebind(tt.query), tt.query)
+		assert.Equal(t, tt.query, SQLite.Rebind(tt.query), tt.query)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, d := range All {
+		parsed, err := Parse(d.Name())
+		require.NoError(t, err)
+		assert.Equal(t, d, parsed)
+	}
+	_, err := Parse("mysql")
+	assert.Error(t, err)
+}
+
+func TestOf(t *testing.T) {
+	sqliteDB, err := sql.Open(SQLite.DriverName(), ":memory:")
+	require.NoError(t, err)
+	defer sqliteDB.Close()
+	assert.Equal(t, SQLite, Of(sqliteDB))
+
+	// Opening doesn't connect, so no server is needed
+	postgresDB, err := sql.Open(Postgres.DriverName(), "postgres://localhost/portal")
+	require.NoError(t, err)
+	defer postgresDB.Close()
+	assert.Equal(t, Postgres, Of(postgresDB))
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	db, err := sql.Open(SQLite.DriverName(), ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (name TEXT UNIQUE); INSERT INTO t VALUES ('a')")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO t VALUES ('a')")
+	assert.True(t, SQLite.IsUniqueViolation(err))
+	assert.False(t, Postgres.IsUniqueViolation(err))
+
+	pgErr := &pgconn.PgError{Code: "23505"}
+	assert.True(t, Postgres.IsUniqueViolation(pgErr))
+	assert.False(t, SQLite.IsUniqueViolation(pgErr))
+	assert.False(t, Postgres.IsUniqueViolation(&pgconn.PgError{Code: "23503"}))
+}
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	db, err := sql.Open(SQLite.DriverName(), ":memory:?_foreign_keys=1")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE p (id INTEGER PRIMARY KEY); CREATE TABLE c (p_id INTEGER REFERENCES p(id))")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO c VALUES (1)")
+	assert.True(t, SQLite.IsForeignKeyViolation(err))
+	assert.False(t, SQLite.IsUniqueViolation(err))
+	assert.False(t, Postgres.IsForeignKeyViolation(err))
+
+	pgErr := &pgconn.PgError{Code: "23503"}
+	assert.True(t, Postgres.IsForeignKeyViolation(pgErr))
+	assert.False(t, SQLite.IsForeignKeyViolation(pgErr))
+	assert.False(t, Postgres.IsForeignKeyViolation(&pgconn.PgError{Code: "23505"}))
+}
+
+func TestEpoch(t *testing.T) {
+	db, err := sql.Open(SQLite.DriverName(), ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (created_at DATETIME)")
+	require.NoError(t, err)
+	started := time.Date(2025, 3, 9, 23, 50, 30, 123456789, time.FixedZone("+0545", (5*60+45)*60))
+	_, err = db.Exec("INSERT INTO t VALUES (?)", started)
+	require.NoError(t, err)
+
+	var epoch int64
+	require.NoError(t, db.QueryRow("SELECT "+SQLite.Epoch("created_at")+" FROM t").Scan(&epoch))
+	assert.Equal(t, started.Unix(), epoch)
+	assert.Equal(t, "CAST(floor(extract(epoch FROM created_at)) AS BIGINT)", Postgres.Epoch("created_at"))
+}
@@ -0,0 +1,287 @@
+// Package generate fills a database with synthetic learners, vocabulary and
+// months of study history, to load test the dashboard queries and to give
+// demos something to show. Everything is written through the repositories
+// the API uses, and the same options always generate the same data.
+package generate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
+
+// Options sizes the generated data
+type Options struct {
+	// Seed drives every random choice: the same options generate the same
+	// data
+	Seed int64
+	// Users is the number of students
+	Users int
+	// Groups is the number of word groups, each with WordsPerGroup new words
+	Groups        int
+	WordsPerGroup int
+	// Months is how far back the study history goes from Until
+	Months int
+	// Until is when the history ends. It defaults to the start of the
+	// current UTC day, so it must be set for runs on different days to
+	// generate the same data.
+	Until time.Time
+}
+
+// DefaultOptions generates a few thousand sessions, enough for a demo
+func DefaultOptions() Options {
+	return Options{
+		Seed:          1,
+		Users:         50,
+		Groups:        10,
+		WordsPerGroup: 30,
+		Months:        3,
+	}
+}
+
+// Validate checks that the options generate something
+func (o Options) Validate() error {
+	var v validation.Validator
+	if o.Users < 1 {
+		v.Add("users", "must be at least 1")
+	}
+	if o.Groups < 1 {
+		v.Add("groups", "must be at least 1")
+	}
+	if o.WordsPerGroup < 1 {
+		v.Add("words_per_group", "must be at least 1")
+	}
+	if o.Months < 1 {
+		v.Add("months", "must be at least 1")
+	}
+	return v.Err()
+}
+
+// Summary counts the records generated
+type Summary struct {
+	Users    int
+	Groups   int
+	Words    int
+	Sessions int
+	Reviews  int
+}
+
+// fallbackActivities are created when the database has no study activities
+// to run sessions in
+var fallbackActivities = []models.StudyActivity{
+	{Name: "Vocabulary Flashcards", Description: "Practice your vocabulary with interactive flashcards", Difficulty: 1},
+	{Name: "Word Matching Game", Description: "Match Portuguese words with their English translations", Difficulty: 2},
+	{Name: "Writing Practice", Description: "Practice writing Portuguese words and get instant feedback", Difficulty: 3},
+}
+
+// Run generates the data described by opts. The vocabulary is written in
+// one transaction, then the history of each user in a transaction of its
+// own, so an interrupted run keeps the users completed so far.
+func Run(ctx context.Context, uow service.UnitOfWork, opts Options) (Summary, error) {
+	if err := opts.Validate(); err != nil {
+		return Summary{}, err
+	}
+	if opts.Until.IsZero() {
+		opts.Until = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	var summary Summary
+	var activities []int64
+	var groups []group
+	err := uow.WithTx(ctx, func(repos service.Repositories) error {
+		var err error
+		if activities, err = ensureActivities(ctx, repos); err != nil {
+			return err
+		}
+		groups, err = createVocabulary(ctx, repos, rng, opts)
+		return err
+	})
+	if err != nil {
+		return summary, fmt.Errorf("failed to generate vocabulary: %w", err)
+	}
+	summary.Groups = opts.Groups
+	summary.Words = opts.Groups * opts.WordsPerGroup
+
+	from := opts.Until.AddDate(0, -opts.Months, 0)
+	names := newNamer(rng)
+	for i := 0; i < opts.Users; i++ {
+		// Each learner draws from a source of its own, so the history of
+		// one doesn't depend on how the others went
+		l := newLearner(rand.New(rand.NewSource(rng.Int63())), from, opts.Until)
+		name := names.next()
+		err := uow.WithTx(ctx, func(repos service.Repositories) error {
+			user, err := repos.Users.CreateUser(ctx, &models.User{Name: name, Role: models.RoleStudent})
+			if err != nil {
+				return err
+			}
+			sessions, reviews, err := l.study(ctx, repos.StudySessions, user.ID, activities, groups)
+			summary.Sessions += sessions
+			summary.Reviews += reviews
+			return err
+		})
+		if err != nil {
+			return summary, fmt.Errorf("failed to generate the history of user %d: %w", i+1, err)
+		}
+		summary.Users++
+		if step := max(opts.Users/10, 1); summary.Users%step == 0 {
+			slog.Info("generating study history", "users", summary.Users, "of", opts.Users, "reviews", summary.Reviews)
+		}
+	}
+	return summary, nil
+}
+
+// ensureActivities returns the IDs of the study activities, creating
+// fallbackActivities if there are none
+func ensureActivities(ctx context.Context, repos service.Repositories) ([]int64, error) {
+	activities, err := repos.StudyActivities.ListStudyActivities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(activities) == 0 {
+		for _, activity := range fallbackActivities {
+			activity := activity
+			if err := repos.StudyActivities.CreateStudyActivity(ctx, &activity); err != nil {
+				return nil, err
+			}
+			activities = append(activities, activity)
+		}
+	}
+	ids := make([]int64, len(activities))
+	for i, activity := range activities {
+		ids[i] = activity.ID
+	}
+	return ids, nil
+}
+
+// group is a generated group and the IDs of its words
+type group struct {
+	id    int64
+	words []int64
+}
+
+// createVocabulary creates the groups and their words
+func createVocabulary(ctx context.Context, repos service.Repositories, rng *rand.Rand, opts Options) ([]group, error) {
+	words := newWordMaker(rng)
+	groups := make([]group, opts.Groups)
+	for i := range groups {
+		theme := themes[i%len(themes)]
+		created, err := repos.Groups.CreateGroup(ctx, &models.Group{Name: fmt.Sprintf("%s (generated #%d)", theme, i+1)})
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int64, opts.WordsPerGroup)
+		for j := range ids {
+			id, err := createWord(ctx, repos.Words, words)
+			if err != nil {
+				return nil, err
+			}
+			ids[j] = id
+		}
+		if err := repos.Groups.AddWordsToGroup(ctx, created.ID, ids); err != nil {
+			return nil, err
+		}
+		groups[i] = group{id: created.ID, words: ids}
+	}
+	return groups, nil
+}
+
+// createWord creates the next word of words that isn't in the database yet,
+// since an earlier run or a seed pack may have the same
+func createWord(ctx context.Context, repo service.WordRepository, words *wordMaker) (int64, error) {
+	for {
+		portuguese, english := words.next()
+		existing, err := repo.FindWord(ctx, portuguese, english)
+		if err != nil {
+			return 0, err
+		}
+		if existing != nil {
+			continue
+		}
+		word, err := repo.CreateWord(ctx, &models.Word{Portuguese: portuguese, English: english})
+		if err != nil {
+			return 0, err
+		}
+		return word.ID, nil
+	}
+}
+
+var themes = []string{
+	"Greetings", "Food", "Travel", "Family", "Work", "Home", "Weather",
+	"Health", "Shopping", "Nature", "Sports", "Music", "City", "School",
+}
+
+var (
+	portugueseSyllables = []string{
+		"ba", "be", "ca", "co", "da", "de", "do", "fa", "ga", "go", "la", "le",
+		"li", "lo", "ma", "me", "mi", "na", "ne", "no", "pa", "pe", "ra", "re",
+		"ri", "sa", "se", "ta", "te", "ti", "to", "va", "vi", "ção", "nho", "lha",
+	}
+	englishSyllables = []string{
+		"ap", "ber", "can", "dle", "en", "fer", "gar", "hum", "ing", "jun", "kel",
+		"lo", "mar", "nit", "or", "pen", "quil", "ros", "sun", "ter", "um", "ver",
+		"wis", "yel", "zen",
+	}
+)
+
+// wordMaker makes up distinct pairs of pronounceable words
+type wordMaker struct {
+	rng  *rand.Rand
+	seen map[string]bool
+}
+
+func newWordMaker(rng *rand.Rand) *wordMaker {
+	return &wordMaker{rng: rng, seen: map[string]bool{}}
+}
+
+func (m *wordMaker) next() (portuguese, english string) {
+	for syllables := 2; ; syllables++ {
+		// Longer words are tried once the shorter ones run low
+		for attempt := 0; attempt < 10; attempt++ {
+			portuguese = m.word(portugueseSyllables, syllables)
+			if !m.seen[portuguese] {
+				m.seen[portuguese] = true
+				return portuguese, m.word(englishSyllables, syllables)
+			}
+		}
+	}
+}
+
+func (m *wordMaker) word(syllables []string, n int) string {
+	var word string
+	for i := 0; i < n; i++ {
+		word += syllables[m.rng.Intn(len(syllables))]
+	}
+	return word
+}
+
+var (
+	firstNames = []string{
+		"Ana", "Bruno", "Carla", "Diogo", "Eva", "Filipe", "Gabriela", "Hugo",
+		"Inês", "João", "Kiara", "Luís", "Marta", "Nuno", "Olívia", "Pedro",
+		"Rita", "Sofia", "Tiago", "Vera",
+	}
+	lastNames = []string{
+		"Almeida", "Barros", "Costa", "Dias", "Esteves", "Ferreira", "Gomes",
+		"Lopes", "Martins", "Nunes", "Pereira", "Ribeiro", "Santos", "Teixeira",
+	}
+)
+
+// namer names the generated users
+type namer struct {
+	rng *rand.Rand
+}
+
+func newNamer(rng *rand.Rand) *namer {
+	return &namer{rng: rng}
+}
+
+func (n *namer) next() string {
+	return firstNames[n.rng.Intn(len(firstNames))] + " " + lastNames[n.rng.Intn(len(lastNames))]
+}
@@ -0,0 +1,129 @@
+package generate
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUnitOfWork runs callbacks in transactions on a memory store
+type fakeUnitOfWork struct {
+	store *memory.Store
+}
+
+func (u fakeUnitOfWork) WithTx(ctx context.Context, fn func(repos service.Repositories) error) error {
+	return u.store.WithTx(ctx, func() error {
+		return fn(service.Repositories{
+			Words:           u.store.Words(),
+			Groups:          u.store.Groups(),
+			StudyActivities: u.store.StudyActivities(),
+			StudySessions:   u.store.StudySessions(),
+			Users:           u.store.Users(),
+			Classrooms:      u.store.Classrooms(),
+			Gamification:    u.store.Gamification(),
+			Leaderboards:    u.store.Leaderboards(),
+		})
+	})
+}
+
+var testOptions = Options{
+	Seed:          7,
+	Users:         4,
+	Groups:        3,
+	WordsPerGroup: 12,
+	Months:        2,
+	Until:         time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// generated is what a run wrote
+type generated struct {
+	summary  Summary
+	words    []*models.WordWithStats
+	sessions []models.StudySessionDetail
+}
+
+func generateInMemory(t *testing.T, opts Options) generated {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	store.SetClock(func() time.Time { return opts.Until })
+	summary, err := Run(ctx, fakeUnitOfWork{store}, opts)
+	require.NoError(t, err)
+
+	words, _, err := store.Words().ListWordsWithStatsPaginated(ctx, 1, summary.Words)
+	require.NoError(t, err)
+	sessions, err := store.StudySessions().ListStudySessions(ctx, 0, summary.Sessions)
+	require.NoError(t, err)
+	return generated{summary: summary, words: words, sessions: sessions}
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	first := generateInMemory(t, testOptions)
+	assert.Equal(t, 4, first.summary.Users)
+	assert.Equal(t, 36, first.summary.Words)
+	assert.NotZero(t, first.summary.Sessions)
+	assert.Greater(t, first.summary.Reviews, first.summary.Sessions)
+	assert.Len(t, first.sessions, first.summary.Sessions)
+
+	assert.Equal(t, first, generateInMemory(t, testOptions))
+
+	opts := testOptions
+	opts.Seed++
+	assert.NotEqual(t, first, generateInMemory(t, opts))
+}
+
+func TestRunStaysInTheWindow(t *testing.T) {
+	data := generateInMemory(t, testOptions)
+	from := testOptions.Until.AddDate(0, -testOptions.Months, 0)
+	for _, session := range data.sessions {
+		assert.False(t, session.CreatedAt.Before(from), "session %d starts at %v", session.ID, session.CreatedAt)
+		assert.True(t, session.CreatedAt.Before(testOptions.Until), "session %d starts at %v", session.ID, session.CreatedAt)
+		require.NotNil(t, session.EndTime)
+		assert.True(t, session.EndTime.After(session.CreatedAt))
+	}
+
+	correct, total := 0, 0
+	for _, word := range data.words {
+		correct += word.CorrectCount
+		total += word.CorrectCount + word.WrongCount
+	}
+	assert.Equal(t, data.summary.Reviews, total)
+	assert.InDelta(t, 0.6, float64(correct)/float64(total), 0.25, "accuracy is in a plausible range")
+}
+
+func TestRunRejectsEmptyOptions(t *testing.T) {
+	_, err := Run(context.Background(), fakeUnitOfWork{memory.NewStore()}, Options{Seed: 1})
+	assert.ErrorContains(t, err, "users")
+}
+
+func TestTraceFollowsForgettingCurve(t *testing.T) {
+	last := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tr := &trace{stability: 2, last: last}
+
+	assert.Equal(t, 1.0, tr.recall(last))
+	assert.InDelta(t, 1/math.E, tr.recall(last.Add(2*day)), 1e-9)
+	assert.Greater(t, tr.recall(last.Add(day)), tr.recall(last.Add(3*day)))
+
+	var unseen *trace
+	assert.Equal(t, firstGuess, unseen.recall(last))
+
+	// Remembering a nearly forgotten word makes it more stable than
+	// remembering a fresh one
+	fresh, faded := *tr, *tr
+	fresh.review(last.Add(time.Hour), 0.98, true, 1)
+	faded.review(last.Add(5*day), 0.08, true, 1)
+	assert.Greater(t, fresh.stability, tr.stability)
+	assert.Greater(t, faded.stability, fresh.stability)
+
+	forgotten := *tr
+	forgotten.review(last.Add(5*day), 0.08, false, 1)
+	assert.Less(t, forgotten.stability, tr.stability)
+	assert.Equal(t, last.Add(5*day), forgotten.last)
+}
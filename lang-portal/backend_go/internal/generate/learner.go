@@ -0,0 +1,266 @@
+package generate
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+)
+
+const (
+	day = 24 * time.Hour
+
+	// firstGuess is the chance of knowing a word never reviewed before
+	firstGuess = 0.3
+	// minStability is how quickly, in days, a word is forgotten at worst
+	minStability = 0.3
+	// dueRecall is the chance of recall under which a word is due a review
+	dueRecall = 0.9
+	// newPerSession is the number of new words introduced in a session
+	// before the due ones are reviewed
+	newPerSession = 6
+	// learnedStability is the stability, in days, of a word counted as
+	// learned when deciding whether to move on to the next group
+	learnedStability = 4
+	// learnedShare is the share of a group's words learned before moving on
+	learnedShare = 0.8
+)
+
+// trace is a learner's memory of a word. The chance of recalling it decays
+// exponentially with the time since the last review, the forgetting curve,
+// at a pace set by its stability.
+type trace struct {
+	// stability is the number of days after which the chance of recall has
+	// dropped to 1/e
+	stability float64
+	last      time.Time
+}
+
+// recall returns the chance of recalling the word at t. t is never before
+// the last review.
+func (tr *trace) recall(t time.Time) float64 {
+	if tr == nil {
+		return firstGuess
+	}
+	elapsed := t.Sub(tr.last).Hours() / 24
+	return math.Exp(-elapsed / tr.stability)
+}
+
+// review updates the trace after an answer given at t, when the chance of
+// recall was p. Remembering makes the word more stable, all the more if it
+// was nearly forgotten; forgetting makes it less stable.
+func (tr *trace) review(t time.Time, p float64, correct bool, memory float64) {
+	switch {
+	case tr.stability == 0 && correct:
+		tr.stability = 2 * memory
+	case tr.stability == 0:
+		tr.stability = 0.8 * memory
+	case correct:
+		tr.stability *= 1 + memory*(1.5+2*(1-p))
+	default:
+		tr.stability = math.Max(minStability, tr.stability*0.5)
+	}
+	tr.last = t
+}
+
+// learner simulates a student working through the groups in order, going
+// back to earlier ones now and then
+type learner struct {
+	rng *rand.Rand
+
+	// from and until bound the days on which the learner studies
+	from, until time.Time
+	// diligence is the chance of studying on a weekday
+	diligence float64
+	// memory scales how fast words become stable, around 1
+	memory float64
+	// hour is the hour of the day, UTC, at which the learner usually studies
+	hour float64
+
+	traces map[int64]*trace
+	// group is the index of the group being learned
+	group int
+}
+
+// newLearner draws a learner who joins some time between from and until,
+// and may give up before until
+func newLearner(rng *rand.Rand, from, until time.Time) *learner {
+	days := int(until.Sub(from) / day)
+	l := &learner{
+		rng:       rng,
+		from:      from.AddDate(0, 0, rng.Intn(days/3+1)),
+		until:     until,
+		diligence: 0.25 + 0.65*rng.Float64(),
+		memory:    0.6 + 0.8*rng.Float64(),
+		hour:      7 + 15*rng.Float64(),
+		traces:    map[int64]*trace{},
+	}
+	if rng.Float64() < 0.25 {
+		quit := int(l.until.Sub(l.from) / day)
+		l.until = l.from.AddDate(0, 0, quit/4+rng.Intn(quit-quit/4+1))
+	}
+	return l
+}
+
+// study records the learner's sessions, day by day, and returns how many
+// sessions and reviews were created
+func (l *learner) study(ctx context.Context, sessionRepo service.StudySessionRepository, userID int64, activities []int64, groups []group) (sessions, reviews int, err error) {
+	for date := l.from; date.Before(l.until); date = date.Add(day) {
+		if l.rng.Float64() < 0.02 {
+			// A holiday
+			date = date.AddDate(0, 0, 3+l.rng.Intn(12))
+			continue
+		}
+		chance := l.diligence
+		if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			chance *= 0.6
+		}
+		if l.rng.Float64() >= chance {
+			continue
+		}
+
+		for _, start := range l.sessionTimes(date) {
+			n, err := l.session(ctx, sessionRepo, userID, start, activities, groups)
+			if err != nil {
+				return sessions, reviews, err
+			}
+			sessions++
+			reviews += n
+		}
+	}
+	return sessions, reviews, nil
+}
+
+// sessionTimes returns when the sessions of a study day start: one around
+// the usual hour, sometimes followed by more a few hours apart
+func (l *learner) sessionTimes(date time.Time) []time.Time {
+	hour := math.Min(math.Max(l.hour+l.rng.NormFloat64()*1.5, 0), 23.5)
+	start := date.Add(time.Duration(hour * float64(time.Hour)))
+	times := []time.Time{start}
+	for len(times) < 4 && l.rng.Float64() < 0.25 {
+		start = start.Add(time.Hour + time.Duration(l.rng.Intn(180))*time.Minute)
+		if start.After(date.Add(day - time.Hour)) {
+			break
+		}
+		times = append(times, start)
+	}
+	return times
+}
+
+// session records a session started at start and its reviews, ending it
+// after the last, and returns how many reviews were made
+func (l *learner) session(ctx context.Context, sessionRepo service.StudySessionRepository, userID int64, start time.Time, activities []int64, groups []group) (int, error) {
+	index := l.group
+	if index > 0 && l.rng.Float64() < 0.25 {
+		index = l.rng.Intn(index)
+	}
+	session := &models.StudySession{
+		GroupID:         groups[index].id,
+		StudyActivityID: activities[l.rng.Intn(len(activities))],
+		UserID:          &userID,
+		CreatedAt:       start,
+	}
+	if err := sessionRepo.CreateStudySession(ctx, session); err != nil {
+		return 0, err
+	}
+
+	at := start
+	queue := l.pick(groups[index].words, start, 10+l.rng.Intn(16))
+	// Words answered wrong are asked again once at the end of the session,
+	// as flashcards are
+	retried := map[int64]bool{}
+	// combo counts the answers right in a row, which earn more XP
+	combo := 0
+	for i := 0; i < len(queue); i++ {
+		wordID := queue[i]
+		at = at.Add(time.Duration(4+l.rng.Intn(17)) * time.Second)
+		tr := l.traces[wordID]
+		p := tr.recall(at)
+		correct := l.rng.Float64() < p
+		if tr == nil {
+			tr = &trace{}
+			l.traces[wordID] = tr
+		}
+		tr.review(at, p, correct, l.memory)
+		if !correct && !retried[wordID] {
+			retried[wordID] = true
+			queue = append(queue, wordID)
+		}
+		combo++
+		if !correct {
+			combo = 0
+		}
+
+		review := &models.WordReviewItem{
+			WordID:         wordID,
+			StudySessionID: session.ID,
+			Correct:        correct,
+			XP:             models.ReviewXP(combo),
+			CreatedAt:      at,
+		}
+		if err := sessionRepo.CreateReview(ctx, review); err != nil {
+			return 0, err
+		}
+	}
+	if err := sessionRepo.EndStudySession(ctx, session.ID, at); err != nil {
+		return 0, err
+	}
+
+	if index == l.group && l.group < len(groups)-1 && l.learned(groups[index].words) {
+		l.group++
+	}
+	return len(queue), nil
+}
+
+// pick chooses up to n words of a group to review at t: a few new words,
+// then the words due a review, those most likely forgotten first, then
+// more new words and the other words if the session is long enough
+func (l *learner) pick(words []int64, t time.Time, n int) []int64 {
+	type candidate struct {
+		wordID int64
+		recall float64
+	}
+	var unseen []int64
+	var seen []candidate
+	for _, wordID := range words {
+		if tr := l.traces[wordID]; tr != nil {
+			// Some noise so that sessions vary
+			seen = append(seen, candidate{wordID, tr.recall(t) + 0.1*l.rng.Float64()})
+		} else {
+			unseen = append(unseen, wordID)
+		}
+	}
+	sort.SliceStable(seen, func(i, j int) bool { return seen[i].recall < seen[j].recall })
+	due := sort.Search(len(seen), func(i int) bool { return seen[i].recall >= dueRecall })
+
+	picked := make([]int64, 0, n)
+	take := func(wordIDs ...int64) {
+		picked = append(picked, wordIDs[:min(len(wordIDs), n-len(picked))]...)
+	}
+	fresh := min(len(unseen), newPerSession)
+	take(unseen[:fresh]...)
+	for _, c := range seen[:due] {
+		take(c.wordID)
+	}
+	take(unseen[fresh:]...)
+	for _, c := range seen[due:] {
+		take(c.wordID)
+	}
+	return picked
+}
+
+// learned reports whether enough of a group's words are stable to move on
+// to the next group
+func (l *learner) learned(words []int64) bool {
+	stable := 0
+	for _, wordID := range words {
+		if tr := l.traces[wordID]; tr != nil && tr.stability >= learnedStability {
+			stable++
+		}
+	}
+	return float64(stable) >= learnedShare*float64(len(words))
+}
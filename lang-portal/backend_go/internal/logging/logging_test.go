@@ -0,0 +1,60 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContextValues tests that request-scoped values reach every record
+func TestContextValues(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, slog.LevelInfo, logging.FormatJSON)
+	require.NoError(t, err)
+
+	ctx := logging.WithUser(logging.WithRequestID(context.Background(), "req-1"), "42")
+	logger.With("component", "test").InfoContext(ctx, "hello", "n", 1)
+	logger.Info("no context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "hello", record["msg"])
+	assert.Equal(t, "req-1", record[logging.RequestIDKey])
+	assert.Equal(t, "42", record[logging.UserKey])
+	assert.Equal(t, "test", record["component"])
+
+	record = nil
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.NotContains(t, record, logging.RequestIDKey)
+}
+
+// TestLevelAndFormat tests level filtering and format selection
+func TestLevelAndFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, slog.LevelWarn, logging.FormatText)
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "level=WARN msg=kept")
+
+	_, err = logging.New(&buf, slog.LevelInfo, "xml")
+	assert.Error(t, err)
+
+	level, err := logging.ParseLevel("debug")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+	_, err = logging.ParseLevel("verbose")
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// namespace prefixes every portal metric
+const namespace = "lang_portal_"
+
+// activeLearnerWindows are the periods over which learners count as active
+var activeLearnerWindows = []struct {
+	label  string
+	window time.Duration
+}{
+	{"1d", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// activeLearnersTimeout bounds the query run for each scrape
+const activeLearnersTimeout = 2 * time.Second
+
+// Portal holds the metrics of the language portal. It implements
+// service.Events and repository.QueryObserver's signature, so services and
+// repositories report to it without importing this package.
+type Portal struct {
+	Registry *Registry
+
+	httpRequests    *CounterVec
+	httpDuration    *HistogramVec
+	queryDuration   *HistogramVec
+	queryErrors     *CounterVec
+	reviewsRecorded *CounterVec
+	sessionsStarted *Counter
+}
+
+// NewPortal registers the portal's metrics in a new registry
+func NewPortal() *Portal {
+	r := NewRegistry()
+	return &Portal{
+		Registry: r,
+		httpRequests: r.NewCounterVec(namespace+"http_requests_total",
+			"HTTP requests served, by route template and status.", "method", "route", "status"),
+		httpDuration: r.NewHistogramVec(namespace+"http_request_duration_seconds",
+			"Time taken to serve HTTP requests, by route template.", DefaultBuckets, "method", "route"),
+		queryDuration: r.NewHistogramVec(namespace+"db_query_duration_seconds",
+			"Time taken by repository queries.", DefaultBuckets, "repository", "method"),
+		queryErrors: r.NewCounterVec(namespace+"db_query_errors_total",
+			"Repository queries that failed, including timeouts and cancellations.", "repository", "method"),
+		reviewsRecorded: r.NewCounterVec(namespace+"reviews_recorded_total",
+			"Word reviews recorded, by result.", "result"),
+		sessionsStarted: r.NewCounter(namespace+"study_sessions_started_total",
+			"Study sessions started."),
+	}
+}
+
+// ObserveRequest records a served HTTP request. route is the route template,
+// never the raw path, to keep the number of series bounded.
+func (p *Portal) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	p.httpRequests.With(method, route, strconv.Itoa(status)).Inc()
+	p.httpDuration.With(method, route).Observe(elapsed.Seconds())
+}
+
+// ObserveQuery records a repository query
+func (p *Portal) ObserveQuery(repository, method string, elapsed time.Duration, err error) {
+	p.queryDuration.With(repository, method).Observe(elapsed.Seconds())
+	if err != nil && err != sql.ErrNoRows {
+		p.queryErrors.With(repository, method).Inc()
+	}
+}
+
+// SessionStarted counts a started study session
+func (p *Portal) SessionStarted() {
+	p.sessionsStarted.Inc()
+}
+
+// ReviewRecorded counts a recorded word review
+func (p *Portal) ReviewRecorded(correct bool) {
+	result := "incorrect"
+	if correct {
+		result = "correct"
+	}
+	p.reviewsRecorded.With(result).Inc()
+}
+
+// RegisterDBStats exports the connection pool statistics of dbs, summed
+// over the distinct pools, such as the SQLite reader and writer
+func (p *Portal) RegisterDBStats(dbs ...*sql.DB) {
+	pools := make([]*sql.DB, 0, len(dbs))
+	for _, db := range dbs {
+		if !slices.Contains(pools, db) {
+			pools = append(pools, db)
+		}
+	}
+	sum := func(value func(sql.DBStats) float64) []Sample {
+		total := 0.0
+		for _, db := range pools {
+			total += value(db.Stats())
+		}
+		return []Sample{{Value: total}}
+	}
+
+	r := p.Registry
+	gauge := func(name, help string, value func(sql.DBStats) float64) {
+		r.NewGaugeFunc(namespace+name, help, func() []Sample { return sum(value) })
+	}
+	counter := func(name, help string, value func(sql.DBStats) float64) {
+		r.NewCounterFunc(namespace+name, help, func() []Sample { return sum(value) })
+	}
+
+	gauge("db_max_open_connections", "Maximum number of open connections to the database.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("db_open_connections", "Established connections, in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("db_in_use_connections", "Connections currently in use.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("db_idle_connections", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("db_wait_count_total", "Connections waited for.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("db_wait_duration_seconds_total", "Time spent waiting for a connection.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("db_max_idle_closed_total", "Connections closed due to the idle connection limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("db_max_lifetime_closed_total", "Connections closed due to their maximum lifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
+
+// RegisterActiveLearners exports the number of learners who started a study
+// session within the last day, week and month. count is queried on every
+// scrape; windows whose query fails are left out of the scrape.
+func (p *Portal) RegisterActiveLearners(count func(ctx context.Context, since time.Time) (int, error)) {
+	p.Registry.NewGaugeFunc(namespace+"active_learners",
+		"Learners who started a study session within the window.",
+		func() []Sample {
+			ctx, cancel := context.WithTimeout(context.Background(), activeLearnersTimeout)
+			defer cancel()
+
+			now := time.Now()
+			samples := make([]Sample, 0, len(activeLearnerWindows))
+			for _, w := range activeLearnerWindows {
+				n, err := count(ctx, now.Add(-w.window))
+				if err != nil {
+					slog.Warn("failed to count active learners", "window", w.label, "error", err)
+					continue
+				}
+				samples = append(samples, Sample{LabelValues: []string{w.label}, Value: float64(n)})
+			}
+			return samples
+		}, "window")
+}
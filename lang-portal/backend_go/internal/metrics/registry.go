@@ -0,0 +1,380 @@
+// Package metrics implements the subset of Prometheus metric types the portal
+// needs and serves them in the Prometheus text exposition format, without
+// depending on the Prometheus client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric types as written in # TYPE lines
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// Sample is one value of a metric read by a collect function
+type Sample struct {
+	// LabelValues are in the order of the metric's label names
+	LabelValues []string
+	Value       float64
+}
+
+// family is a named metric with all its label combinations
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families and writes them out
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.families[f.name()]; exists {
+		panic("metrics: duplicate metric " + f.name())
+	}
+	r.families[f.name()] = f
+}
+
+// WriteTo writes every family, sorted by name, in the text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	families := make([]family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name() < families[j].name() })
+
+	counter := &countingWriter{w: w}
+	buf := bufio.NewWriter(counter)
+	for _, f := range families {
+		f.write(buf)
+	}
+	err := buf.Flush()
+	return counter.n, err
+}
+
+// ServeHTTP serves the registry's metrics
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteTo(w)
+}
+
+// desc holds what every family shares
+type desc struct {
+	metricName string
+	help       string
+	labelNames []string
+}
+
+func (d desc) name() string {
+	return d.metricName
+}
+
+func (d desc) writeHeader(w *bufio.Writer, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.metricName, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.metricName, typ)
+}
+
+// writeSample writes one sample line. extraName and extraValue add a label
+// after the family's own, as histograms do with le.
+func (d desc) writeSample(w *bufio.Writer, suffix string, labelValues []string, extraName, extraValue string, value float64) {
+	w.WriteString(d.metricName)
+	w.WriteString(suffix)
+	if len(labelValues) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, v := range labelValues {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, d.labelNames[i], v)
+		}
+		if extraName != "" {
+			if len(labelValues) > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func (d desc) checkLabels(values []string) {
+	if len(values) != len(d.labelNames) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", d.metricName, len(d.labelNames), len(values)))
+	}
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds v, which must not be negative, to the counter
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.mu.Lock()
+	c.value += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) get() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	desc
+	mu       sync.Mutex
+	counters map[string]*labeled[*Counter]
+}
+
+// NewCounterVec registers a counter family with the given label names
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	v := &CounterVec{
+		desc:     desc{metricName: name, help: help, labelNames: labelNames},
+		counters: make(map[string]*labeled[*Counter]),
+	}
+	r.register(v)
+	return v
+}
+
+// NewCounter registers a counter without labels
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return r.NewCounterVec(name, help).With()
+}
+
+// With returns the counter for the label values, creating it at zero
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	v.checkLabels(labelValues)
+	key := labelKey(labelValues)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, ok := v.counters[key]; ok {
+		return c.metric
+	}
+	c := &labeled[*Counter]{values: labelValues, metric: &Counter{}}
+	v.counters[key] = c
+	return c.metric
+}
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	v.writeHeader(w, typeCounter)
+	for _, c := range sortedLabeled(&v.mu, v.counters) {
+		v.writeSample(w, "", c.values, "", "", c.metric.get())
+	}
+}
+
+// Histogram counts observations in cumulative buckets
+type Histogram struct {
+	upperBounds []float64
+	mu          sync.Mutex
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+// Observe records one observation
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upperBounds, v)
+	h.mu.Lock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	desc
+	buckets    []float64
+	mu         sync.Mutex
+	histograms map[string]*labeled[*Histogram]
+}
+
+// DefaultBuckets suit request and query latencies in seconds
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// NewHistogramVec registers a histogram family. Buckets are upper bounds in
+// increasing order; the +Inf bucket is implied.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if !sort.Float64sAreSorted(buckets) {
+		panic("metrics: buckets of " + name + " are not sorted")
+	}
+	v := &HistogramVec{
+		desc:       desc{metricName: name, help: help, labelNames: labelNames},
+		buckets:    buckets,
+		histograms: make(map[string]*labeled[*Histogram]),
+	}
+	r.register(v)
+	return v
+}
+
+// With returns the histogram for the label values, creating it empty
+func (v *HistogramVec) With(labelValues ...string) *Histogram {
+	v.checkLabels(labelValues)
+	key := labelKey(labelValues)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if h, ok := v.histograms[key]; ok {
+		return h.metric
+	}
+	h := &labeled[*Histogram]{values: labelValues, metric: &Histogram{
+		upperBounds: v.buckets,
+		counts:      make([]uint64, len(v.buckets)),
+	}}
+	v.histograms[key] = h
+	return h.metric
+}
+
+func (v *HistogramVec) write(w *bufio.Writer) {
+	v.writeHeader(w, typeHistogram)
+	for _, h := range sortedLabeled(&v.mu, v.histograms) {
+		hist := h.metric
+		hist.mu.Lock()
+		var cumulative uint64
+		for i, bound := range hist.upperBounds {
+			cumulative += hist.counts[i]
+			v.writeSample(w, "_bucket", h.values, "le", formatFloat(bound), float64(cumulative))
+		}
+		v.writeSample(w, "_bucket", h.values, "le", "+Inf", float64(hist.count))
+		v.writeSample(w, "_sum", h.values, "", "", hist.sum)
+		v.writeSample(w, "_count", h.values, "", "", float64(hist.count))
+		hist.mu.Unlock()
+	}
+}
+
+// funcFamily reads its samples when the registry is written
+type funcFamily struct {
+	desc
+	typ     string
+	collect func() []Sample
+}
+
+// NewGaugeFunc registers a gauge family whose samples are read from collect
+// on every scrape
+func (r *Registry) NewGaugeFunc(name, help string, collect func() []Sample, labelNames ...string) {
+	r.register(&funcFamily{desc: desc{metricName: name, help: help, labelNames: labelNames}, typ: typeGauge, collect: collect})
+}
+
+// NewCounterFunc registers a counter family whose samples are read from
+// collect on every scrape, for totals kept elsewhere
+func (r *Registry) NewCounterFunc(name, help string, collect func() []Sample, labelNames ...string) {
+	r.register(&funcFamily{desc: desc{metricName: name, help: help, labelNames: labelNames}, typ: typeCounter, collect: collect})
+}
+
+func (f *funcFamily) write(w *bufio.Writer) {
+	samples := f.collect()
+	if len(samples) == 0 {
+		return
+	}
+	f.writeHeader(w, f.typ)
+	for _, s := range samples {
+		f.checkLabels(s.LabelValues)
+		f.writeSample(w, "", s.LabelValues, "", "", s.Value)
+	}
+}
+
+// labeled pairs a metric with its label values
+type labeled[M any] struct {
+	values []string
+	metric M
+}
+
+func sortedLabeled[M any](mu *sync.Mutex, m map[string]*labeled[M]) []*labeled[M] {
+	mu.Lock()
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := make([]*labeled[M], len(keys))
+	for i, k := range keys {
+		result[i] = m[k]
+	}
+	mu.Unlock()
+	return result
+}
+
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	w.WriteString(name)
+	w.WriteString(`="`)
+	w.WriteString(labelEscaper.Replace(value))
+	w.WriteByte('"')
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
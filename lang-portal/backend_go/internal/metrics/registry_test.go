@@ -0,0 +1,72 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExposition tests the text format written for each metric type
+func TestExposition(t *testing.T) {
+	r := metrics.NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	requests.With("/words/:id", "200").Inc()
+	requests.With("/words/:id", "200").Add(2)
+	requests.With(`say "hi"`+"\n", "404").Inc()
+
+	latency := r.NewHistogramVec("latency_seconds", "Latency\nin seconds.", []float64{0.1, 1}, "route")
+	latency.With("/words").Observe(0.05)
+	latency.With("/words").Observe(0.5)
+	latency.With("/words").Observe(3)
+
+	r.NewGaugeFunc("open_connections", "Open connections.", func() []metrics.Sample {
+		return []metrics.Sample{{Value: 4}}
+	})
+	r.NewGaugeFunc("empty", "Never has samples.", func() []metrics.Sample { return nil })
+
+	var out strings.Builder
+	_, err := r.WriteTo(&out)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# HELP latency_seconds Latency\nin seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/words",le="0.1"} 1
+latency_seconds_bucket{route="/words",le="1"} 2
+latency_seconds_bucket{route="/words",le="+Inf"} 3
+latency_seconds_sum{route="/words"} 3.55
+latency_seconds_count{route="/words"} 3
+# HELP open_connections Open connections.
+# TYPE open_connections gauge
+open_connections 4
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/words/:id",status="200"} 3
+requests_total{route="say \"hi\"\n",status="404"} 1
+`, out.String())
+}
+
+// TestServeHTTP tests the content type of scrapes
+func TestServeHTTP(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.NewCounter("hits_total", "Hits.").Inc()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, metrics.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "hits_total 1\n")
+}
+
+// TestMisuse tests that registration and labelling mistakes panic
+func TestMisuse(t *testing.T) {
+	r := metrics.NewRegistry()
+	counter := r.NewCounterVec("a_total", "A.", "x")
+	assert.Panics(t, func() { r.NewCounter("a_total", "Again.") })
+	assert.Panics(t, func() { counter.With() })
+	assert.Panics(t, func() { counter.With("1").Add(-1) })
+	assert.Panics(t, func() { r.NewHistogramVec("h", "H.", []float64{1, 0.5}) })
+}
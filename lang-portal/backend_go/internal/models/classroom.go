@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+type Classroom struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	TeacherID int64     `json:"teacher_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClassroomDetail represents a classroom with its members
+type ClassroomDetail struct {
+	Classroom
+	Members []User `json:"members"`
+}
+
+type Assignment struct {
+	ID              int64     `json:"id"`
+	ClassroomID     int64     `json:"classroom_id"`
+	GroupID         int64     `json:"group_id"`
+	StudyActivityID *int64    `json:"study_activity_id,omitempty"`
+	DueAt           time.Time `json:"due_at"`
+	TargetAccuracy  float64   `json:"target_accuracy"`
+	CreatedAt       time.Time `json:"created_at"`
+	GroupName       string    `json:"group_name"`
+	ClassroomName   string    `json:"classroom_name"`
+}
+
+// Assignment progress statuses
+const (
+	AssignmentNotStarted = "not_started"
+	AssignmentInProgress = "in_progress"
+	AssignmentCompleted  = "completed"
+	AssignmentOverdue    = "overdue"
+)
+
+// AssignmentProgress represents a single student's progress on an assignment
+type AssignmentProgress struct {
+	UserID         int64      `json:"user_id"`
+	UserName       string     `json:"user_name"`
+	WordsReviewed  int        `json:"words_reviewed"`
+	TotalWords     int        `json:"total_words"`
+	CorrectCount   int        `json:"correct_count"`
+	WrongCount     int        `json:"wrong_count"`
+	Accuracy       float64    `json:"accuracy"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+	Status         string     `json:"status"`
+}
+
+// AssignmentSummary represents an assignment as seen by the teacher
+type AssignmentSummary struct {
+	Assignment
+	MemberCount    int `json:"member_count"`
+	CompletedCount int `json:"completed_count"`
+}
+
+// AssignmentReport represents an assignment with per-student progress
+type AssignmentReport struct {
+	Assignment
+	Progress []AssignmentProgress `json:"progress"`
+}
+
+// StudentAssignment represents an assignment as seen by a student
+type StudentAssignment struct {
+	Assignment
+	Progress AssignmentProgress `json:"progress"`
+}
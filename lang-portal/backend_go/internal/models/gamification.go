@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// XP awarded for a correct review. Answers right in a row in a session earn
+// a combo multiplier, which grows by one every comboStep answers up to
+// maxComboMultiplier. The migration that added the XP column applies the
+// same rules to the reviews recorded before.
+const (
+	XPPerReview        = 10
+	comboStep          = 5
+	maxComboMultiplier = 3
+)
+
+// ReviewXP returns the XP earned by a correct review that is the combo-th
+// answer right in a row in its session, counting itself
+func ReviewXP(combo int) int {
+	if combo < 1 {
+		return 0
+	}
+	return XPPerReview * min(1+(combo-1)/comboStep, maxComboMultiplier)
+}
+
+// GoalKind is what a daily goal counts
+type GoalKind string
+
+const (
+	// GoalReviews counts the reviews made during the day
+	GoalReviews GoalKind = "reviews"
+	// GoalMinutes counts the minutes studied during the day, from the start
+	// of each session to its last review
+	GoalMinutes GoalKind = "minutes"
+)
+
+// DailyGoal is what a learner aims to study every day. UserID is 0 for the
+// guest, who studies without a user.
+type DailyGoal struct {
+	UserID int64    `json:"-"`
+	Kind   GoalKind `json:"kind"`
+	Target int      `json:"target"`
+	// TimeZone is the IANA name of the time zone whose midnights end the
+	// learner's days
+	TimeZone string `json:"time_zone"`
+}
+
+// LearnerTotals sums up everything a learner has studied
+type LearnerTotals struct {
+	Reviews      int
+	WordsLearned int
+	XP           int
+}
+
+// UnlockedAchievement records when a learner unlocked an achievement. UserID
+// is 0 for the guest.
+type UnlockedAchievement struct {
+	UserID     int64
+	Key        string
+	UnlockedAt time.Time
+}
+
+// AchievementMetric is what an achievement counts
+type AchievementMetric string
+
+const (
+	MetricReviews      AchievementMetric = "reviews"
+	MetricWordsLearned AchievementMetric = "words_learned"
+	MetricXP           AchievementMetric = "xp"
+	// MetricLongestStreak is the longest study streak, in days
+	MetricLongestStreak   AchievementMetric = "longest_streak"
+	MetricPerfectSessions AchievementMetric = "perfect_sessions"
+)
+
+// Achievement is unlocked once its metric reaches Target
+type Achievement struct {
+	Key         string            `json:"key"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Metric      AchievementMetric `json:"metric"`
+	Target      int               `json:"target"`
+}
+
+// AchievementStatus is how far a learner is towards an achievement
+type AchievementStatus struct {
+	Achievement
+	// Progress is the metric, at most Target
+	Progress int `json:"progress"`
+	// UnlockedAt is nil until the achievement is unlocked
+	UnlockedAt *time.Time `json:"unlocked_at"`
+}
@@ -1,6 +1,18 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
+
+const (
+	// MaxGroupNameLength is the maximum length of a group name
+	MaxGroupNameLength = 100
+	// MaxGroupWordsPerRequest is the maximum number of words added to a group at once
+	MaxGroupWordsPerRequest = 500
+)
 
 type Group struct {
 	ID        int64     `json:"id"`
@@ -11,11 +23,14 @@ type Group struct {
 type GroupWithStats struct {
 	Group
 	WordCount int `json:"word_count"`
+	// GroupMastery is nil where it isn't computed, as for imported groups
+	*GroupMastery
 }
 
 // GroupStats represents statistics for a group
 type GroupStats struct {
 	TotalWordCount int `json:"total_word_count"`
+	GroupMastery
 }
 
 // GroupDetail represents a group with its statistics
@@ -24,3 +39,55 @@ type GroupDetail struct {
 	Name  string     `json:"name"`
 	Stats GroupStats `json:"stats"`
 }
+
+// GroupInput is the payload accepted when creating or updating a group.
+// IDs and timestamps are assigned by the server.
+type GroupInput struct {
+	Name string `json:"name"`
+}
+
+// Validate trims the input and checks it, reporting errors per field
+func (in *GroupInput) Validate() error {
+	var v validation.Validator
+	v.Text("name", &in.Name, MaxGroupNameLength)
+	return v.Err()
+}
+
+// ValidateGroupWordIDs checks the list of word IDs added to a group
+func ValidateGroupWordIDs(wordIDs []int64) error {
+	var v validation.Validator
+	v.IDs("word_ids", wordIDs, MaxGroupWordsPerRequest)
+	return v.Err()
+}
+
+// GroupImport is a new group created together with its words
+type GroupImport struct {
+	Name  string
+	Words []WordInput
+}
+
+// Validate trims the input and checks it, reporting errors per field. Word
+// fields are reported as words[i].portuguese and words[i].english, and a word
+// listed twice as words[i].
+func (in *GroupImport) Validate() error {
+	var v validation.Validator
+	v.Text("name", &in.Name, MaxGroupNameLength)
+	switch {
+	case len(in.Words) == 0:
+		v.Add("words", "must not be empty")
+	case len(in.Words) > MaxGroupWordsPerRequest:
+		v.Add("words", fmt.Sprintf("must contain at most %d items", MaxGroupWordsPerRequest))
+	default:
+		seen := make(map[WordInput]bool, len(in.Words))
+		for i := range in.Words {
+			word := &in.Words[i]
+			v.Text(fmt.Sprintf("words[%d].portuguese", i), &word.Portuguese, MaxWordLength)
+			v.Text(fmt.Sprintf("words[%d].english", i), &word.English, MaxWordLength)
+			if seen[*word] {
+				v.Add(fmt.Sprintf("words[%d]", i), "is a duplicate")
+			}
+			seen[*word] = true
+		}
+	}
+	return v.Err()
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// LeaderboardMetric is what a leaderboard ranks learners by
+type LeaderboardMetric string
+
+const (
+	LeaderboardXP            LeaderboardMetric = "xp"
+	LeaderboardAccuracy      LeaderboardMetric = "accuracy"
+	LeaderboardWordsMastered LeaderboardMetric = "words_mastered"
+)
+
+// LeaderboardMetrics lists the metrics, the default first
+var LeaderboardMetrics = []LeaderboardMetric{LeaderboardXP, LeaderboardAccuracy, LeaderboardWordsMastered}
+
+// LeaderboardPeriod is the rolling window of study a leaderboard covers
+type LeaderboardPeriod string
+
+const (
+	PeriodDaily   LeaderboardPeriod = "daily"
+	PeriodWeekly  LeaderboardPeriod = "weekly"
+	PeriodAllTime LeaderboardPeriod = "all_time"
+)
+
+// LeaderboardPeriods lists the periods, the default first
+var LeaderboardPeriods = []LeaderboardPeriod{PeriodWeekly, PeriodDaily, PeriodAllTime}
+
+// Since returns when the period starts if it ends at now, the zero time for
+// all time
+func (p LeaderboardPeriod) Since(now time.Time) time.Time {
+	switch p {
+	case PeriodDaily:
+		return now.Add(-24 * time.Hour)
+	case PeriodWeekly:
+		return now.Add(-7 * 24 * time.Hour)
+	default:
+		return time.Time{}
+	}
+}
+
+// LeaderboardStanding is the study of a classroom member over a period. The
+// words mastered are those answered right for the mastery streak's time in a
+// row during the period.
+type LeaderboardStanding struct {
+	UserID        int64
+	XP            int
+	Reviews       int
+	CorrectCount  int
+	WordsMastered int
+	// DisplayName and OptOut are the member's leaderboard profile, read along
+	// with a snapshot
+	DisplayName string
+	OptOut      bool
+}
+
+// LeaderboardSnapshot caches the standings of the members of a classroom
+// who studied during a period, computed at ComputedAt
+type LeaderboardSnapshot struct {
+	ClassroomID int64
+	Period      LeaderboardPeriod
+	ComputedAt  time.Time
+	Standings   []LeaderboardStanding
+}
+
+// LeaderboardProfile is how a learner appears on leaderboards. An empty
+// DisplayName stands for a pseudonym.
+type LeaderboardProfile struct {
+	UserID      int64  `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	OptOut      bool   `json:"opt_out"`
+}
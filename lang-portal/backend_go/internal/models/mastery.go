@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// WordState is how far a word has been learned
+type WordState string
+
+const (
+	// WordNew words have never been reviewed
+	WordNew WordState = "new"
+	// WordLearning words have been reviewed but not yet mastered
+	WordLearning WordState = "learning"
+	// WordMastered words have been answered right enough times in a row,
+	// recently enough
+	WordMastered WordState = "mastered"
+	// WordLapsed words were mastered but haven't been reviewed since long
+	// enough to be forgotten
+	WordLapsed WordState = "lapsed"
+)
+
+// MasteryThresholds tell the state of a word from its reviews. A word is
+// mastered once answered right MasteredStreak times in a row since its last
+// wrong answer, and lapses if it isn't reviewed again within LapsedAfter.
+type MasteryThresholds struct {
+	MasteredStreak int
+	LapsedAfter    time.Duration
+}
+
+// State returns the state of word as of now by t
+func (t MasteryThresholds) State(word *WordWithStats, now time.Time) WordState {
+	switch {
+	case word.LastReviewedAt == nil:
+		return WordNew
+	case word.Streak < t.MasteredStreak:
+		return WordLearning
+	case now.Sub(*word.LastReviewedAt) > t.LapsedAfter:
+		return WordLapsed
+	default:
+		return WordMastered
+	}
+}
+
+// GroupProgress counts the words of a group by state. The words being
+// learned are those counted in no other state.
+type GroupProgress struct {
+	GroupID       int64
+	WordCount     int
+	NewCount      int
+	MasteredCount int
+	LapsedCount   int
+	// LastStudiedAt is when the last study session of the group started, nil
+	// if it hasn't been studied
+	LastStudiedAt *time.Time
+}
+
+// GroupMastery breaks the words of a group down by state, and recommends
+// what to study next
+type GroupMastery struct {
+	NewCount      int `json:"new_count"`
+	LearningCount int `json:"learning_count"`
+	MasteredCount int `json:"mastered_count"`
+	LapsedCount   int `json:"lapsed_count"`
+	// PercentComplete is the percentage of the words mastered
+	PercentComplete float64    `json:"percent_complete"`
+	LastStudiedAt   *time.Time `json:"last_studied_at"`
+	// NextActivity is nil if the group has no words or there are no study
+	// activities
+	NextActivity *RecommendedActivity `json:"next_activity"`
+}
+
+// RecommendedActivity is the study activity recommended next for a group,
+// and the state of the words it should focus on
+type RecommendedActivity struct {
+	StudyActivityID int64     `json:"study_activity_id"`
+	Name            string    `json:"name"`
+	Focus           WordState `json:"focus"`
+}
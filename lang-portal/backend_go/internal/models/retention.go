@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// ReviewScope selects the reviews of a word, or of the words of a group, or
+// all of them when both IDs are 0
+type ReviewScope struct {
+	GroupID int64
+	WordID  int64
+}
+
+// RecallBucket counts the reviews made at least MinInterval after the
+// previous review of the same word by the same learner
+type RecallBucket struct {
+	MinInterval  time.Duration
+	Reviews      int
+	CorrectCount int
+	// RecallSeconds is the sum of the intervals the correct reviews were made
+	// after, in seconds. A time.Duration would overflow after 292 years,
+	// which a few thousand reviews a month apart add up to.
+	RecallSeconds int64
+}
+
+// LeechCriteria tell the words that keep being failed. A leech has been
+// answered wrong at least MinWrong times, and more often than right, and
+// hasn't since been answered right RecoveredStreak times in a row.
+type LeechCriteria struct {
+	MinWrong        int
+	RecoveredStreak int
+}
+
+// IsLeech reports whether word is a leech by c
+func (c LeechCriteria) IsLeech(word *WordWithStats) bool {
+	return word.WrongCount >= c.MinWrong && word.WrongCount > word.CorrectCount && word.Streak < c.RecoveredStreak
+}
@@ -7,5 +7,6 @@ type StudyActivity struct {
 	Name         string    `json:"name"`
 	ThumbnailURL string    `json:"thumbnail_url"`
 	Description  string    `json:"description"`
+	Difficulty   int       `json:"difficulty"`
 	CreatedAt    time.Time `json:"created_at"`
 }
@@ -3,24 +3,39 @@ package models
 import "time"
 
 type StudySession struct {
-	ID               int64     `json:"id"`
-	StudyActivityID  int64     `json:"study_activity_id"`
-	GroupID          int64     `json:"group_id"`
-	StartTime        time.Time `json:"start_time"`
-	EndTime          time.Time `json:"end_time"`
-	CreatedAt        time.Time `json:"created_at"`
-	ActivityName     string    `json:"activity_name"`
-	GroupName        string    `json:"group_name"`
-	ReviewItemsCount int       `json:"review_items_count"`
+	ID              int64  `json:"id"`
+	StudyActivityID int64  `json:"study_activity_id"`
+	GroupID         int64  `json:"group_id"`
+	UserID          *int64 `json:"user_id,omitempty"`
+	// EndedAt is when the session was ended, nil while it takes reviews
+	EndedAt          *time.Time `json:"ended_at,omitempty"`
+	StartTime        time.Time  `json:"start_time"`
+	EndTime          time.Time  `json:"end_time"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ActivityName     string     `json:"activity_name"`
+	GroupName        string     `json:"group_name"`
+	ReviewItemsCount int        `json:"review_items_count"`
 }
 
 type StudySessionDetail struct {
-	ID               int64      `json:"id"`
-	ActivityName     string     `json:"activity_name"`
-	GroupName        string     `json:"group_name"`
-	CreatedAt        time.Time  `json:"start_time"`
-	EndTime          *time.Time `json:"end_time,omitempty"`
+	ID           int64      `json:"id"`
+	ActivityName string     `json:"activity_name"`
+	GroupName    string     `json:"group_name"`
+	CreatedAt    time.Time  `json:"start_time"`
+	EndTime      *time.Time `json:"end_time,omitempty"`
+	// EndedAt is when the session was ended, nil while it takes reviews
+	EndedAt          *time.Time `json:"ended_at,omitempty"`
 	ReviewItemsCount int        `json:"review_items_count"`
 	StudyActivityID  int64      `json:"-"`
 	GroupID          int64      `json:"-"`
+	UserID           *int64     `json:"-"`
+}
+
+// SessionActivity is what a study session adds to the activity history
+type SessionActivity struct {
+	StartedAt time.Time
+	// EndedAt is when the last review was made, nil if there is none
+	EndedAt      *time.Time
+	ReviewCount  int
+	CorrectCount int
 }
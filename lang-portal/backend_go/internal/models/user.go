@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// User roles
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+)
+
+type User struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
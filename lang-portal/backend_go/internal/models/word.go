@@ -1,6 +1,13 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
+
+// MaxWordLength is the maximum length of the portuguese and english fields
+const MaxWordLength = 100
 
 type Word struct {
 	ID         int64     `json:"id"`
@@ -13,6 +20,10 @@ type WordWithStats struct {
 	Word
 	CorrectCount int `json:"correct_count"`
 	WrongCount   int `json:"wrong_count"`
+	// LastReviewedAt is when the word was last reviewed, nil if never
+	LastReviewedAt *time.Time `json:"-"`
+	// Streak is the number of correct answers since the last wrong one
+	Streak int `json:"-"`
 }
 
 // WordStats represents statistics for a word
@@ -35,3 +46,18 @@ type WordDetail struct {
 	Stats      WordStats   `json:"stats"`
 	Groups     []WordGroup `json:"groups"`
 }
+
+// WordInput is the payload accepted when creating or updating a word.
+// IDs and timestamps are assigned by the server.
+type WordInput struct {
+	Portuguese string `json:"portuguese"`
+	English    string `json:"english"`
+}
+
+// Validate trims the input and checks it, reporting errors per field
+func (in *WordInput) Validate() error {
+	var v validation.Validator
+	v.Text("portuguese", &in.Portuguese, MaxWordLength)
+	v.Text("english", &in.English, MaxWordLength)
+	return v.Err()
+}
@@ -1,11 +1,49 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
 
 type WordReviewItem struct {
-	ID             int64     `json:"id"`
-	WordID         int64     `json:"word_id"`
-	StudySessionID int64     `json:"study_session_id"`
-	Correct        bool      `json:"correct"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID             int64 `json:"id"`
+	WordID         int64 `json:"word_id"`
+	StudySessionID int64 `json:"study_session_id"`
+	Correct        bool  `json:"correct"`
+	// XP is what the review earned, 0 if it was wrong
+	XP        int       `json:"xp"`
+	CreatedAt time.Time `json:"created_at"`
+	// SecondsSincePrevious is the time since the previous review of the word
+	// by the same learner, nil for their first. Only ListWordReviews reads it.
+	SecondsSincePrevious *int64 `json:"-"`
+}
+
+// MaxReviewsPerBatch is the maximum number of reviews recorded at once
+const MaxReviewsPerBatch = 500
+
+// ReviewInput is one answer in a batch of reviews
+type ReviewInput struct {
+	WordID  int64
+	Correct bool
+}
+
+// ValidateReviews checks a batch of reviews. The same word may be reviewed
+// more than once.
+func ValidateReviews(reviews []ReviewInput) error {
+	var v validation.Validator
+	switch {
+	case len(reviews) == 0:
+		v.Add("reviews", "must not be empty")
+	case len(reviews) > MaxReviewsPerBatch:
+		v.Add("reviews", fmt.Sprintf("must contain at most %d items", MaxReviewsPerBatch))
+	default:
+		for i, review := range reviews {
+			if review.WordID < 1 {
+				v.Add(fmt.Sprintf("reviews[%d].word_id", i), "must be a positive integer")
+			}
+		}
+	}
+	return v.Err()
 }
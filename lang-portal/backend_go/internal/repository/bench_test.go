@@ -0,0 +1,212 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/generate"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+)
+
+// The benchmarks run the dashboard queries against a generated history of
+// over a million reviews. Generating it takes a while, so it is done once
+// per run, and can be kept between runs in the SQLite file named by
+// LANG_PORTAL_BENCH_DB:
+//
+//	LANG_PORTAL_BENCH_DB=/tmp/bench.db go test -run '^$' -bench . ./internal/repository
+
+// benchDBEnv names the file the benchmark data is kept in
+const benchDBEnv = "LANG_PORTAL_BENCH_DB"
+
+// benchReviews is the least number of reviews the benchmarks run on
+const benchReviews = 1_000_000
+
+// benchOptions generate a little over benchReviews reviews
+var benchOptions = generate.Options{
+	Seed:          1,
+	Users:         700,
+	Groups:        20,
+	WordsPerGroup: 50,
+	Months:        6,
+	Until:         time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var bench struct {
+	once  sync.Once
+	repos service.Repositories
+	words int
+	err   error
+	close func()
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if bench.close != nil {
+		bench.close()
+	}
+	os.Exit(code)
+}
+
+// benchRepositories returns repositories on the benchmark data, routed to
+// the read and write pools as the server does
+func benchRepositories(b *testing.B) (service.Repositories, int) {
+	b.Helper()
+	bench.once.Do(func() {
+		var store *database.Store
+		if path := os.Getenv(benchDBEnv); path != "" {
+			cfg := config.Default().Database
+			cfg.Path = path
+			if store, bench.err = database.Open(cfg); bench.err != nil {
+				return
+			}
+			bench.close = func() { store.Close() }
+			if bench.err = database.RunMigrations(store); bench.err != nil {
+				return
+			}
+		} else {
+			var tdb *database.TestDB
+			if tdb, bench.err = database.NewTestDB(); bench.err != nil {
+				return
+			}
+			store, bench.close = tdb.Store, tdb.Close
+		}
+
+		bench.repos = newRepositories(repository.ReadWrite(store.Read, store.DB))
+		bench.err = ensureBenchData(bench.repos, store)
+		if bench.err == nil {
+			bench.words, bench.err = bench.repos.Words.CountWords(context.Background())
+		}
+	})
+	if bench.err != nil {
+		b.Fatalf("failed to prepare the benchmark data: %v", bench.err)
+	}
+	b.ResetTimer()
+	return bench.repos, bench.words
+}
+
+// ensureBenchData generates the benchmark data unless the database already
+// holds enough reviews
+func ensureBenchData(repos service.Repositories, store *database.Store) error {
+	ctx := context.Background()
+	_, reviews, err := repos.StudySessions.GetWordReviewStats(ctx)
+	if err != nil || reviews >= benchReviews {
+		return err
+	}
+	summary, err := generate.Run(ctx, benchUnitOfWork{store.DB}, benchOptions)
+	if err != nil {
+		return err
+	}
+	if summary.Reviews < benchReviews {
+		return fmt.Errorf("benchOptions generated %d reviews, fewer than %d", summary.Reviews, benchReviews)
+	}
+	return nil
+}
+
+// benchUnitOfWork writes the benchmark data in transactions on the writer
+type benchUnitOfWork struct {
+	db repository.DB
+}
+
+func (u benchUnitOfWork) WithTx(ctx context.Context, fn func(repos service.Repositories) error) error {
+	return repository.WithTx(ctx, u.db, func(tx repository.DB) error {
+		return fn(newRepositories(tx))
+	})
+}
+
+func BenchmarkListWordsWithStatsPaginated(b *testing.B) {
+	repos, words := benchRepositories(b)
+	const pageSize = 100
+	for _, bm := range []struct {
+		name string
+		page int
+	}{
+		{"FirstPage", 1},
+		{"LastPage", (words + pageSize - 1) / pageSize},
+	} {
+		bm := bm
+		b.Run(bm.name, func(b *testing.B) {
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := repos.Words.ListWordsWithStatsPaginated(ctx, bm.page, pageSize); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkListStudyTimes times the query the guest's study streak is
+// counted from
+func BenchmarkListStudyTimes(b *testing.B) {
+	repos, _ := benchRepositories(b)
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := repos.StudySessions.ListStudyTimes(ctx, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetWordReviewStats(b *testing.B) {
+	repos, _ := benchRepositories(b)
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := repos.StudySessions.GetWordReviewStats(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetRecallBuckets times the retention of all words, which counts
+// every review made at least a day after the previous one of the same word
+func BenchmarkGetRecallBuckets(b *testing.B) {
+	repos, _ := benchRepositories(b)
+	ctx := context.Background()
+	day := 24 * time.Hour
+	bounds := []time.Duration{day, 7 * day, 30 * day}
+	for i := 0; i < b.N; i++ {
+		if _, err := repos.StudySessions.GetRecallBuckets(ctx, models.ReviewScope{}, bounds); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListLeeches(b *testing.B) {
+	repos, _ := benchRepositories(b)
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := repos.Words.ListLeeches(ctx, 0, models.LeechCriteria{MinWrong: 4, RecoveredStreak: 3}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetGroupProgress times the mastery of a page of groups, as the
+// group list shows it
+func BenchmarkGetGroupProgress(b *testing.B) {
+	repos, _ := benchRepositories(b)
+	ctx := context.Background()
+	groups, _, err := repos.Groups.ListGroupsPaginated(ctx, 1, 10)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ids := make([]int64, len(groups))
+	for i, g := range groups {
+		ids[i] = g.ID
+	}
+	thresholds := models.MasteryThresholds{MasteredStreak: 3, LapsedAfter: 30 * 24 * time.Hour}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repos.Groups.GetGroupProgress(ctx, ids, thresholds, time.Now()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
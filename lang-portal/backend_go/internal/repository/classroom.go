@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+type ClassroomRepository struct {
+	db DB
+}
+
+func NewClassroomRepository(db DB) *ClassroomRepository {
+	return &ClassroomRepository{db: db}
+}
+
+func (r *ClassroomRepository) GetClassroom(ctx context.Context, id int64) (*models.Classroom, error) {
+	classroom := &models.Classroom{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, teacher_id, created_at
+		FROM classrooms
+		WHERE id = ?
+	`, id).Scan(&classroom.ID, &classroom.Name, &classroom.TeacherID, &classroom.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return classroom, nil
+}
+
+// ListClassroomIDs returns the IDs of every classroom, in ascending order
+func (r *ClassroomRepository) ListClassroomIDs(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id
+		FROM classrooms
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *ClassroomRepository) CreateClassroom(ctx context.Context, classroom *models.Classroom) (*models.Classroom, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO classrooms (name, teacher_id, created_at)
+		VALUES (?, ?, ?)
+		RETURNING id
+	`, classroom.Name, classroom.TeacherID, utcNow()).Scan(&id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return r.GetClassroom(ctx, id)
+}
+
+// ListMembers returns the students enrolled in a classroom
+func (r *ClassroomRepository) ListMembers(ctx context.Context, classroomID int64) ([]models.User, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, u.name, u.role, u.created_at
+		FROM users u
+		JOIN classroom_members cm ON u.id = cm.user_id
+		WHERE cm.classroom_id = ?
+		ORDER BY u.name, u.id
+	`, classroomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.Role, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, user)
+	}
+	return members, rows.Err()
+}
+
+func (r *ClassroomRepository) CountMembers(ctx context.Context, classroomID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM classroom_members
+		WHERE classroom_id = ?
+	`, classroomID).Scan(&count)
+	return count, err
+}
+
+func (r *ClassroomRepository) AddMembers(ctx context.Context, classroomID int64, userIDs []int64) error {
+	return WithTx(ctx, r.db, func(tx DB) error {
+		// Enrolling a student twice is a no-op
+		for _, userID := range userIDs {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO classroom_members (classroom_id, user_id, joined_at)
+				VALUES (?, ?, ?)
+				ON CONFLICT (classroom_id, user_id) DO NOTHING
+			`, classroomID, userID, utcNow())
+			if err != nil {
+				return translateError(err)
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveMember removes a student from a classroom, or returns ErrNotFound
+// if they weren't a member
+func (r *ClassroomRepository) RemoveMember(ctx context.Context, classroomID, userID int64) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM classroom_members
+		WHERE classroom_id = ? AND user_id = ?
+	`, classroomID, userID)
+	if err != nil {
+		return err
+	}
+	return requireDeleted(result)
+}
+
+// DeleteGroupAssignments deletes the assignments of a group, in every classroom
+func (r *ClassroomRepository) DeleteGroupAssignments(ctx context.Context, groupID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM assignments WHERE group_id = ?`, groupID)
+	return err
+}
+
+const assignmentColumns = `
+		a.id, a.classroom_id, a.group_id, a.study_activity_id,
+		a.due_at, a.target_accuracy, a.created_at,
+		g.name as group_name,
+		c.name as classroom_name
+`
+
+func scanAssignment(scanner interface{ Scan(...interface{}) error }) (*models.Assignment, error) {
+	assignment := &models.Assignment{}
+	var activityID sql.NullInt64
+	err := scanner.Scan(
+		&assignment.ID, &assignment.ClassroomID, &assignment.GroupID, &activityID,
+		&assignment.DueAt, &assignment.TargetAccuracy, &assignment.CreatedAt,
+		&assignment.GroupName, &assignment.ClassroomName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if activityID.Valid {
+		assignment.StudyActivityID = &activityID.Int64
+	}
+	return assignment, nil
+}
+
+func (r *ClassroomRepository) GetAssignment(ctx context.Context, classroomID, id int64) (*models.Assignment, error) {
+	assignment, err := scanAssignment(r.db.QueryRowContext(ctx, `
+		SELECT `+assignmentColumns+`
+		FROM assignments a
+		JOIN groups g ON a.group_id = g.id
+		JOIN classrooms c ON a.classroom_id = c.id
+		WHERE a.classroom_id = ? AND a.id = ?
+	`, classroomID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+func (r *ClassroomRepository) CreateAssignment(ctx context.Context, assignment *models.Assignment) (*models.Assignment, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO assignments (classroom_id, group_id, study_activity_id, due_at, target_accuracy, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, assignment.ClassroomID, assignment.GroupID, assignment.StudyActivityID,
+		assignment.DueAt.UTC(), assignment.TargetAccuracy, utcNow()).Scan(&id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return r.GetAssignment(ctx, assignment.ClassroomID, id)
+}
+
+// ListAssignments returns the assignments of a classroom ordered by due date
+func (r *ClassroomRepository) ListAssignments(ctx context.Context, classroomID int64) ([]*models.Assignment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+assignmentColumns+`
+		FROM assignments a
+		JOIN groups g ON a.group_id = g.id
+		JOIN classrooms c ON a.classroom_id = c.id
+		WHERE a.classroom_id = ?
+		ORDER BY a.due_at, a.id
+	`, classroomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []*models.Assignment{}
+	for rows.Next() {
+		assignment, err := scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// ListUserAssignments returns the assignments of every classroom a student belongs to
+func (r *ClassroomRepository) ListUserAssignments(ctx context.Context, userID int64) ([]*models.Assignment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+assignmentColumns+`
+		FROM assignments a
+		JOIN groups g ON a.group_id = g.id
+		JOIN classrooms c ON a.classroom_id = c.id
+		JOIN classroom_members cm ON cm.classroom_id = a.classroom_id
+		WHERE cm.user_id = ?
+		ORDER BY a.due_at, a.id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []*models.Assignment{}
+	for rows.Next() {
+		assignment, err := scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// GetAssignmentProgress returns raw review counts for every student of the
+// assignment's classroom. Only reviews of words in the assigned group, made in
+// sessions started after the assignment was created (and with the assigned
+// activity, when there is one) are counted. If userID is not nil, only that
+// student's row is returned.
+func (r *ClassroomRepository) GetAssignmentProgress(ctx context.Context, assignment *models.Assignment, userID *int64) ([]models.AssignmentProgress, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			u.id, u.name,
+			COUNT(DISTINCT wri.word_id) as words_reviewed,
+			COALESCE(SUM(CASE WHEN wri.correct THEN 1 ELSE 0 END), 0) as correct_count,
+			COALESCE(SUM(CASE WHEN NOT wri.correct THEN 1 ELSE 0 END), 0) as wrong_count,
+			MAX(wri.created_at) as last_reviewed_at
+		FROM classroom_members cm
+		JOIN users u ON u.id = cm.user_id
+		LEFT JOIN study_sessions ss ON ss.user_id = u.id
+			AND ss.group_id = ?
+			AND (CAST(? AS BIGINT) IS NULL OR ss.study_activity_id = ?)
+			AND ss.created_at >= ?
+		LEFT JOIN word_review_items wri ON wri.study_session_id = ss.id
+			AND wri.word_id IN (SELECT word_id FROM words_groups WHERE group_id = ?)
+		WHERE cm.classroom_id = ?
+			AND (CAST(? AS BIGINT) IS NULL OR u.id = ?)
+		GROUP BY u.id
+		ORDER BY u.name, u.id
+	`,
+		assignment.GroupID,
+		assignment.StudyActivityID, assignment.StudyActivityID,
+		assignment.CreatedAt,
+		assignment.GroupID,
+		assignment.ClassroomID,
+		userID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := []models.AssignmentProgress{}
+	for rows.Next() {
+		var p models.AssignmentProgress
+		var lastReviewedAt sql.NullString
+		if err := rows.Scan(
+			&p.UserID, &p.UserName,
+			&p.WordsReviewed, &p.CorrectCount, &p.WrongCount,
+			&lastReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		p.LastReviewedAt = parseTimestamp(lastReviewedAt)
+		progress = append(progress, p)
+	}
+	return progress, rows.Err()
+}
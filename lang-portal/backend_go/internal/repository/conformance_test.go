@@ -0,0 +1,88 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/dialect"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/repotest"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+// postgresURLEnv names the Postgres server the Postgres tests run against
+const postgresURLEnv = "LANG_PORTAL_TEST_POSTGRES_URL"
+
+// TestConformance runs the repository conformance suite against SQLite
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) service.Repositories {
+		db, err := database.NewTestDB()
+		require.NoError(t, err)
+		t.Cleanup(db.Close)
+		return newRepositories(db.DB)
+	})
+}
+
+// TestConformanceReadWrite runs the repository conformance suite against
+// SQLite with the queries outside transactions on read-only connections, as
+// the server runs them, to check that writes are routed to the writer
+func TestConformanceReadWrite(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) service.Repositories {
+		db, err := database.NewTestDB()
+		require.NoError(t, err)
+		t.Cleanup(db.Close)
+		return newRepositories(repository.ReadWrite(db.Read, db.DB))
+	})
+}
+
+// numbered is SQLite with Postgres' numbered placeholders, which SQLite
+// accepts too
+type numbered struct {
+	dialect.Dialect
+}
+
+func (numbered) Rebind(query string) string {
+	return dialect.Postgres.Rebind(query)
+}
+
+// TestConformanceRebound runs the repository conformance suite against
+// SQLite with rebound placeholders, to check that every query goes through
+// the dialect, in and out of transactions
+func TestConformanceRebound(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) service.Repositories {
+		db, err := database.NewTestDB()
+		require.NoError(t, err)
+		t.Cleanup(db.Close)
+		return newRepositories(repository.WithDialect(db.DB, numbered{dialect.SQLite}))
+	})
+}
+
+// TestConformancePostgres runs the repository conformance suite against the
+// Postgres server at LANG_PORTAL_TEST_POSTGRES_URL, if set
+func TestConformancePostgres(t *testing.T) {
+	url := os.Getenv(postgresURLEnv)
+	if url == "" {
+		t.Skip(postgresURLEnv + " is not set")
+	}
+	repotest.Run(t, func(t *testing.T) service.Repositories {
+		db, err := database.NewPostgresTestDB(url)
+		require.NoError(t, err)
+		t.Cleanup(db.Close)
+		return newRepositories(repository.WithDialect(db.DB, dialect.Postgres))
+	})
+}
+
+func newRepositories(db repository.DB) service.Repositories {
+	return service.Repositories{
+		Words:           repository.NewWordRepository(db),
+		Groups:          repository.NewGroupRepository(db),
+		StudyActivities: repository.NewStudyActivityRepository(db),
+		StudySessions:   repository.NewStudySessionRepository(db),
+		Users:           repository.NewUserRepository(db),
+		Classrooms:      repository.NewClassroomRepository(db),
+		Gamification:    repository.NewGamificationRepository(db),
+		Leaderboards:    repository.NewLeaderboardRepository(db),
+	}
+}
@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlowRepository returns a study session repository whose aggregations
+// run for seconds unless interrupted
+func newSlowRepository(t *testing.T) *repository.StudySessionRepository {
+	db, err := database.NewTestDB()
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	require.NoError(t, db.SlowStudySessions())
+	return repository.NewStudySessionRepository(db.DB)
+}
+
+// TestCanceledContextAbortsQuery tests that canceling the context interrupts
+// a query that is already running
+func TestCanceledContextAbortsQuery(t *testing.T) {
+	t.Parallel()
+	repo := newSlowRepository(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := repo.ListStudyTimes(ctx, 0)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second, "the query kept running after the context was canceled")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+// TestDeadlineAbortsQuery tests that a context deadline interrupts a running query
+func TestDeadlineAbortsQuery(t *testing.T) {
+	t.Parallel()
+	repo := newSlowRepository(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := repo.GetWordReviewStats(ctx)
+	require.NoError(t, err, "word_review_items is not slow")
+
+	_, err = repo.GetTotalActiveGroups(ctx)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second, "the query kept running after the deadline")
+	assert.True(t, errors.Is(ctx.Err(), context.DeadlineExceeded))
+}
+
+// TestCanceledContextSkipsQuery tests that nothing runs once the context is done
+func TestCanceledContextSkipsQuery(t *testing.T) {
+	t.Parallel()
+	db, err := database.NewTestDB()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewWordRepository(db.DB)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = repo.CountWords(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DB is the part of *sql.DB and *sql.Tx the repositories use. Repositories
+// built on a *sql.Tx take part in that transaction; see WithTx.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// QueryObserver is told how long each query took. repository and method
+// identify the repository method that ran it, e.g. "study_session" and
+// "ListStudyTimes".
+type QueryObserver func(repository, method string, elapsed time.Duration, err error)
+
+// Instrument returns a DB that reports the duration of every query to
+// observe, including those run in transactions started with WithTx. Queries
+// returning rows are timed until the first row is ready.
+func Instrument(db DB, observe QueryObserver) DB {
+	return &instrumentedDB{DB: db, observe: observe}
+}
+
+type instrumentedDB struct {
+	DB
+	observe QueryObserver
+}
+
+func (db *instrumentedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	db.report(start, err)
+	return result, err
+}
+
+func (db *instrumentedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	db.report(start, err)
+	return rows, err
+}
+
+func (db *instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	err := row.Err()
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	db.report(start, err)
+	return row
+}
+
+// report attributes a query to the repository method two frames up
+func (db *instrumentedDB) report(start time.Time, err error) {
+	elapsed := time.Since(start)
+	repository, method := "unknown", "unknown"
+	if pc, _, _, ok := runtime.Caller(2); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			repository, method = splitMethodName(fn.Name())
+		}
+	}
+	db.observe(repository, method, elapsed, err)
+}
+
+// splitMethodName turns ".../repository.(*StudySessionRepository).ListStudyTimes.func1"
+// into "study_session" and "ListStudyTimes"
+func splitMethodName(name string) (repository, method string) {
+	name = name[strings.LastIndex(name, "/")+1:]
+	parts := strings.Split(name, ".")
+	if len(parts) < 3 {
+		return "unknown", parts[len(parts)-1]
+	}
+	receiver := strings.Trim(parts[1], "(*)")
+	return snakeCase(strings.TrimSuffix(receiver, "Repository")), parts[2]
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/dialect"
+)
+
+// WithDialect returns a DB that runs the repositories' queries, written for
+// SQLite, on a database speaking d. Wrap the result with Instrument rather
+// than the other way round, so that queries are attributed to the
+// repository method that ran them.
+func WithDialect(db DB, d dialect.Dialect) DB {
+	if d == dialect.SQLite {
+		return db
+	}
+	return &dialectDB{DB: db, dialect: d}
+}
+
+type dialectDB struct {
+	DB
+	dialect dialect.Dialect
+}
+
+func (db *dialectDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, db.dialect.Rebind(query), args...)
+}
+
+func (db *dialectDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, db.dialect.Rebind(query), args...)
+}
+
+func (db *dialectDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRowContext(ctx, db.dialect.Rebind(query), args...)
+}
+
+// dialectOf returns the dialect of the database db runs queries on
+func dialectOf(db DB) dialect.Dialect {
+	switch db := db.(type) {
+	case *dialectDB:
+		return db.dialect
+	case *instrumentedDB:
+		return dialectOf(db.DB)
+	default:
+		return dialect.SQLite
+	}
+}
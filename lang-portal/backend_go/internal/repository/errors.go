@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/dialect"
+)
+
+// ErrDuplicate is returned when a write violates a uniqueness constraint
+var ErrDuplicate = errors.New("duplicate record")
+
+// ErrForeignKey is returned when a write references a record that doesn't
+// exist
+var ErrForeignKey = errors.New("referenced record not found")
+
+// ErrNotFound is returned when a delete finds no record to delete
+var ErrNotFound = errors.New("record not found")
+
+// translateError maps driver-specific errors to repository errors
+func translateError(err error) error {
+	for _, d := range dialect.All {
+		if d.IsUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		if d.IsForeignKeyViolation(err) {
+			return ErrForeignKey
+		}
+	}
+	return err
+}
+
+// requireDeleted returns ErrNotFound if a delete affected no rows
+func requireDeleted(result sql.Result) error {
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+// GamificationRepository reads the study of each learner and stores their
+// daily goals and achievements. A learner is a user, or the guest, with ID
+// 0, whose sessions were started without a user.
+type GamificationRepository struct {
+	db DB
+}
+
+func NewGamificationRepository(db DB) *GamificationRepository {
+	return &GamificationRepository{db: db}
+}
+
+// learnerID is the user_id stored for the learner with ID userID
+func learnerID(userID int64) interface{} {
+	if userID == 0 {
+		return nil
+	}
+	return userID
+}
+
+// GetLearnerTotals returns the totals of a learner, kept up to date with
+// each review in learner_stats
+func (r *GamificationRepository) GetLearnerTotals(ctx context.Context, userID int64) (*models.LearnerTotals, error) {
+	totals := &models.LearnerTotals{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT reviews, words_learned, xp
+		FROM learner_stats
+		WHERE learner_id = ?
+	`, userID).Scan(&totals.Reviews, &totals.WordsLearned, &totals.XP)
+	if err == sql.ErrNoRows {
+		return totals, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// CountPerfectSessions counts the sessions of a learner ended with at least
+// perfectReviews reviews, all correct
+func (r *GamificationRepository) CountPerfectSessions(ctx context.Context, userID int64, perfectReviews int) (int, error) {
+	filter, args := learnerFilter(userID)
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM (
+			SELECT ss.id
+			FROM study_sessions ss
+			JOIN word_review_items wri ON wri.study_session_id = ss.id
+			WHERE ss.ended_at IS NOT NULL AND `+filter+`
+			GROUP BY ss.id
+			HAVING COUNT(*) >= ? AND SUM(CASE WHEN wri.correct THEN 0 ELSE 1 END) = 0
+		) perfect
+	`, append(args, perfectReviews)...).Scan(&count)
+	return count, err
+}
+
+// ListSessionActivity returns the sessions the learner started from from
+// until before to, oldest first, with their review counts
+func (r *GamificationRepository) ListSessionActivity(ctx context.Context, userID int64, from, to time.Time) ([]models.SessionActivity, error) {
+	filter, args := learnerFilter(userID)
+	return listSessionActivity(ctx, r.db, from, to, filter, args...)
+}
+
+// GetDailyGoal returns the daily goal of a learner, or nil if they haven't
+// set one
+func (r *GamificationRepository) GetDailyGoal(ctx context.Context, userID int64) (*models.DailyGoal, error) {
+	goal := &models.DailyGoal{UserID: userID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT kind, target, time_zone
+		FROM daily_goals
+		WHERE COALESCE(user_id, 0) = ?
+	`, userID).Scan(&goal.Kind, &goal.Target, &goal.TimeZone)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+// SetDailyGoal sets the daily goal of goal.UserID, replacing the one they had
+func (r *GamificationRepository) SetDailyGoal(ctx context.Context, goal *models.DailyGoal) error {
+	return WithTx(ctx, r.db, func(tx DB) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE daily_goals
+			SET kind = ?, target = ?, time_zone = ?, updated_at = ?
+			WHERE COALESCE(user_id, 0) = ?
+		`, goal.Kind, goal.Target, goal.TimeZone, utcNow(), goal.UserID)
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated > 0 {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO daily_goals (user_id, kind, target, time_zone, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, learnerID(goal.UserID), goal.Kind, goal.Target, goal.TimeZone, utcNow())
+		return translateError(err)
+	})
+}
+
+// ListUnlockedAchievements returns the achievements a learner has unlocked,
+// the first unlocked first
+func (r *GamificationRepository) ListUnlockedAchievements(ctx context.Context, userID int64) ([]models.UnlockedAchievement, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT achievement, unlocked_at
+		FROM achievements
+		WHERE COALESCE(user_id, 0) = ?
+		ORDER BY unlocked_at, id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	unlocked := []models.UnlockedAchievement{}
+	for rows.Next() {
+		achievement := models.UnlockedAchievement{UserID: userID}
+		if err := rows.Scan(&achievement.Key, &achievement.UnlockedAt); err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, achievement)
+	}
+	return unlocked, rows.Err()
+}
+
+// UnlockAchievement records that a learner unlocked an achievement. It does
+// nothing if they already had.
+func (r *GamificationRepository) UnlockAchievement(ctx context.Context, achievement *models.UnlockedAchievement) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO achievements (user_id, achievement, unlocked_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`, learnerID(achievement.UserID), achievement.Key, achievement.UnlockedAt.UTC())
+	return err
+}
@@ -1,36 +1,61 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 )
 
 type GroupRepository struct {
-	db *sql.DB
+	db DB
 }
 
-func NewGroupRepository(db *sql.DB) *GroupRepository {
+func NewGroupRepository(db DB) *GroupRepository {
 	return &GroupRepository{db: db}
 }
 
-func (r *GroupRepository) GetGroup(id int64) (*models.Group, error) {
+func (r *GroupRepository) GetGroup(ctx context.Context, id int64) (*models.Group, error) {
 	group := &models.Group{}
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT id, name, created_at
 		FROM groups
 		WHERE id = ?
 	`, id).Scan(&group.ID, &group.Name, &group.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 	return group, nil
 }
 
-func (r *GroupRepository) GetGroupWithStats(id int64) (*models.GroupWithStats, error) {
+// FindGroupByName returns the group with the given name, ignoring case, or
+// nil if there is none
+func (r *GroupRepository) FindGroupByName(ctx context.Context, name string) (*models.Group, error) {
+	group := &models.Group{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, created_at
+		FROM groups
+		WHERE lower(name) = lower(?)
+		ORDER BY id
+		LIMIT 1
+	`, name).Scan(&group.ID, &group.Name, &group.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (r *GroupRepository) GetGroupWithStats(ctx context.Context, id int64) (*models.GroupWithStats, error) {
 	group := &models.GroupWithStats{}
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT 
 			g.id, g.name, g.created_at,
 			COUNT(DISTINCT wg.word_id) as word_count
@@ -42,14 +67,17 @@ func (r *GroupRepository) GetGroupWithStats(id int64) (*models.GroupWithStats, e
 		&group.ID, &group.Name, &group.CreatedAt,
 		&group.WordCount,
 	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 	return group, nil
 }
 
-func (r *GroupRepository) ListGroups() ([]*models.Group, error) {
-	rows, err := r.db.Query(`SELECT id, name, created_at FROM groups`)
+func (r *GroupRepository) ListGroups(ctx context.Context) ([]*models.Group, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, created_at FROM groups`)
 	if err != nil {
 		return nil, err
 	}
@@ -66,31 +94,28 @@ func (r *GroupRepository) ListGroups() ([]*models.Group, error) {
 	return groups, rows.Err()
 }
 
-func (r *GroupRepository) CountGroups() (int, error) {
+func (r *GroupRepository) CountGroups(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM groups").Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM groups").Scan(&count)
 	return count, err
 }
 
-func (r *GroupRepository) CreateGroup(group *models.Group) (*models.Group, error) {
-	result, err := r.db.Exec(`
+func (r *GroupRepository) CreateGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
 		INSERT INTO groups (name, created_at)
 		VALUES (?, ?)
-	`, group.Name, time.Now())
+		RETURNING id
+	`, group.Name, utcNow()).Scan(&id)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return nil, err
-	}
-
-	return r.GetGroup(id)
+	return r.GetGroup(ctx, id)
 }
 
-func (r *GroupRepository) GetGroupWords(groupID int64) ([]*models.Word, error) {
-	rows, err := r.db.Query(`
+func (r *GroupRepository) GetGroupWords(ctx context.Context, groupID int64) ([]*models.Word, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT w.id, w.portuguese, w.english, w.created_at
 		FROM words w
 		JOIN words_groups wg ON w.id = wg.word_id
@@ -113,9 +138,9 @@ func (r *GroupRepository) GetGroupWords(groupID int64) ([]*models.Word, error) {
 	return words, rows.Err()
 }
 
-func (r *GroupRepository) CountGroupWords(groupID int64) (int, error) {
+func (r *GroupRepository) CountGroupWords(ctx context.Context, groupID int64) (int, error) {
 	var count int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM words_groups
 		WHERE group_id = ?
@@ -123,78 +148,69 @@ func (r *GroupRepository) CountGroupWords(groupID int64) (int, error) {
 	return count, err
 }
 
-func (r *GroupRepository) UpdateGroup(group *models.Group) (*models.Group, error) {
-	_, err := r.db.Exec(`
+func (r *GroupRepository) UpdateGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE groups 
 		SET name = ? 
 		WHERE id = ?
 	`, group.Name, group.ID)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
-	return r.GetGroup(group.ID)
+	return r.GetGroup(ctx, group.ID)
 }
 
-func (r *GroupRepository) DeleteGroup(id int64) error {
-	// Start a transaction since we need to delete from multiple tables
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
-	}
-
-	// Delete from words_groups first (due to foreign key constraint)
-	_, err = tx.Exec(`DELETE FROM words_groups WHERE group_id = ?`, id)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
+func (r *GroupRepository) DeleteGroup(ctx context.Context, id int64) error {
+	// Delete from both tables or neither
+	return WithTx(ctx, r.db, func(tx DB) error {
+		// Delete from words_groups first (due to foreign key constraint)
+		if _, err := tx.ExecContext(ctx, `DELETE FROM words_groups WHERE group_id = ?`, id); err != nil {
+			return err
+		}
 
-	// Then delete from groups
-	_, err = tx.Exec(`DELETE FROM groups WHERE id = ?`, id)
-	if err != nil {
-		tx.Rollback()
+		// Then delete from groups
+		_, err := tx.ExecContext(ctx, `DELETE FROM groups WHERE id = ?`, id)
 		return err
-	}
-
-	return tx.Commit()
+	})
 }
 
-func (r *GroupRepository) AddWordsToGroup(groupID int64, wordIDs []int64) error {
-	// Start a transaction
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
-	}
-
-	// Insert each word-group association
-	for _, wordID := range wordIDs {
-		_, err = tx.Exec(`
-			INSERT INTO words_groups (word_id, group_id)
-			VALUES (?, ?)
-		`, wordID, groupID)
-		if err != nil {
-			tx.Rollback()
-			return err
+// AddWordsToGroup adds all the words or, if one of them is already in the
+// group, none
+func (r *GroupRepository) AddWordsToGroup(ctx context.Context, groupID int64, wordIDs []int64) error {
+	return WithTx(ctx, r.db, func(tx DB) error {
+		// Insert each word-group association
+		for _, wordID := range wordIDs {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO words_groups (word_id, group_id)
+				VALUES (?, ?)
+			`, wordID, groupID)
+			if err != nil {
+				return translateError(err)
+			}
 		}
-	}
-
-	return tx.Commit()
+		return nil
+	})
 }
 
-func (r *GroupRepository) RemoveWordFromGroup(groupID, wordID int64) error {
-	_, err := r.db.Exec(`
+// RemoveWordFromGroup removes a word from a group, or returns ErrNotFound if
+// it wasn't in the group
+func (r *GroupRepository) RemoveWordFromGroup(ctx context.Context, groupID, wordID int64) error {
+	result, err := r.db.ExecContext(ctx, `
 		DELETE FROM words_groups 
 		WHERE group_id = ? AND word_id = ?
 	`, groupID, wordID)
-	return err
+	if err != nil {
+		return err
+	}
+	return requireDeleted(result)
 }
 
 // ListGroupsPaginated returns a paginated list of groups
-func (r *GroupRepository) ListGroupsPaginated(page, pageSize int) ([]*models.Group, int, error) {
+func (r *GroupRepository) ListGroupsPaginated(ctx context.Context, page, pageSize int) ([]*models.Group, int, error) {
 	// Get total count for pagination
 	var totalCount int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM groups").Scan(&totalCount)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM groups").Scan(&totalCount)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -203,7 +219,7 @@ func (r *GroupRepository) ListGroupsPaginated(page, pageSize int) ([]*models.Gro
 	offset := (page - 1) * pageSize
 
 	// Query for paginated groups
-	rows, err := r.db.Query(`
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, name, created_at
 		FROM groups
 		ORDER BY id
@@ -226,12 +242,79 @@ func (r *GroupRepository) ListGroupsPaginated(page, pageSize int) ([]*models.Gro
 	return groups, totalCount, nil
 }
 
+// GetGroupProgress counts the words of each of the groups by state as of
+// now, in the order of groupIDs, from word_stats
+func (r *GroupRepository) GetGroupProgress(ctx context.Context, groupIDs []int64, t models.MasteryThresholds, now time.Time) ([]models.GroupProgress, error) {
+	progress := make([]models.GroupProgress, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return progress, nil
+	}
+	byID := make(map[int64]*models.GroupProgress, len(groupIDs))
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(groupIDs)), ",")
+	ids := make([]interface{}, len(groupIDs))
+	for i, id := range groupIDs {
+		progress[i].GroupID = id
+		byID[id] = &progress[i]
+		ids[i] = id
+	}
+
+	lapsedBefore := now.Add(-t.LapsedAfter).UTC()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			wg.group_id,
+			COUNT(*),
+			SUM(CASE WHEN ws.last_reviewed_at IS NULL THEN 1 ELSE 0 END),
+			SUM(CASE WHEN ws.streak >= ? AND ws.last_reviewed_at >= ? THEN 1 ELSE 0 END),
+			SUM(CASE WHEN ws.streak >= ? AND ws.last_reviewed_at < ? THEN 1 ELSE 0 END)
+		FROM words_groups wg
+		LEFT JOIN word_stats ws ON ws.word_id = wg.word_id
+		WHERE wg.group_id IN (`+placeholders+`)
+		GROUP BY wg.group_id
+	`, append([]interface{}{t.MasteredStreak, lapsedBefore, t.MasteredStreak, lapsedBefore}, ids...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.GroupProgress
+		if err := rows.Scan(&p.GroupID, &p.WordCount, &p.NewCount, &p.MasteredCount, &p.LapsedCount); err != nil {
+			return nil, err
+		}
+		*byID[p.GroupID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A subquery per group finds its last session with a single index
+	// lookup. last_studied_at is an aggregate, so SQLite returns it as text;
+	// see parseTimestamp
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT g.id, (SELECT MAX(ss.created_at) FROM study_sessions ss WHERE ss.group_id = g.id) AS last_studied_at
+		FROM groups g
+		WHERE g.id IN (`+placeholders+`)
+	`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var groupID int64
+		var lastStudiedAt sql.NullString
+		if err := rows.Scan(&groupID, &lastStudiedAt); err != nil {
+			return nil, err
+		}
+		byID[groupID].LastStudiedAt = parseTimestamp(lastStudiedAt)
+	}
+	return progress, rows.Err()
+}
+
 // GetGroupWordsPaginated returns a paginated list of words in a group with stats
-func (r *GroupRepository) GetGroupWordsPaginated(groupID int64, page, pageSize int) ([]*models.WordWithStats, int, error) {
+func (r *GroupRepository) GetGroupWordsPaginated(ctx context.Context, groupID int64, page, pageSize int) ([]*models.WordWithStats, int, error) {
 	// Get total count for pagination
 	var totalCount int
 	// TODO: not sure if Join is needed here
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) 
 		FROM words_groups gw
 		JOIN words w ON gw.word_id = w.id
@@ -245,16 +328,12 @@ func (r *GroupRepository) GetGroupWordsPaginated(groupID int64, page, pageSize i
 	offset := (page - 1) * pageSize
 
 	// Query for paginated words with stats
-	rows, err := r.db.Query(`
-		SELECT 
-			w.id, w.portuguese, w.english, w.created_at,
-			COALESCE(SUM(CASE WHEN wri.correct = 1 THEN 1 ELSE 0 END), 0) as correct_count,
-			COALESCE(SUM(CASE WHEN wri.correct = 0 THEN 1 ELSE 0 END), 0) as wrong_count
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+wordWithStatsColumns+`
 		FROM words_groups gw
 		JOIN words w ON gw.word_id = w.id
-		LEFT JOIN word_review_items wri ON w.id = wri.word_id
+		LEFT JOIN word_stats ws ON ws.word_id = w.id
 		WHERE gw.group_id = ?
-		GROUP BY w.id
 		ORDER BY w.id
 		LIMIT ? OFFSET ?
 	`, groupID, pageSize, offset)
@@ -263,17 +342,9 @@ func (r *GroupRepository) GetGroupWordsPaginated(groupID int64, page, pageSize i
 	}
 	defer rows.Close()
 
-	var words []*models.WordWithStats
-	for rows.Next() {
-		word := &models.WordWithStats{}
-		if err := rows.Scan(
-			&word.ID, &word.Portuguese, &word.English, &word.CreatedAt,
-			&word.CorrectCount, &word.WrongCount,
-		); err != nil {
-			return nil, 0, err
-		}
-		words = append(words, word)
+	words, err := scanWordsWithStats(rows)
+	if err != nil {
+		return nil, 0, err
 	}
-
 	return words, totalCount, nil
 }
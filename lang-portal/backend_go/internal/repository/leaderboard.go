@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+// LeaderboardRepository computes the standings of classroom members from
+// their reviews, caches them in leaderboard_snapshots, and stores the
+// leaderboard profiles of learners
+type LeaderboardRepository struct {
+	db DB
+}
+
+func NewLeaderboardRepository(db DB) *LeaderboardRepository {
+	return &LeaderboardRepository{db: db}
+}
+
+// sinceFilter returns the condition selecting the rows whose column is at
+// or after since, every row if since is zero, and its arguments
+func sinceFilter(column string, since time.Time) (string, []interface{}) {
+	if since.IsZero() {
+		return "1 = 1", nil
+	}
+	return column + " >= ?", []interface{}{since.UTC()}
+}
+
+// ComputeStandings aggregates the reviews the members of a classroom made
+// since the given time in their own sessions. A word counts as mastered
+// during the period if the answer completing masteryStreak right in a row,
+// since the learner's last wrong answer to it, was given in the period.
+func (r *LeaderboardRepository) ComputeStandings(ctx context.Context, classroomID int64, since time.Time, masteryStreak int) ([]models.LeaderboardStanding, error) {
+	filter, args := sinceFilter("wri.created_at", since)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			ss.user_id,
+			COALESCE(SUM(wri.xp), 0),
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN wri.correct THEN 1 ELSE 0 END), 0)
+		FROM classroom_members cm
+		JOIN study_sessions ss ON ss.user_id = cm.user_id
+		JOIN word_review_items wri ON wri.study_session_id = ss.id
+		WHERE cm.classroom_id = ? AND `+filter+`
+		GROUP BY ss.user_id
+		ORDER BY ss.user_id
+	`, append([]interface{}{classroomID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []models.LeaderboardStanding{}
+	index := map[int64]int{}
+	for rows.Next() {
+		var standing models.LeaderboardStanding
+		if err := rows.Scan(&standing.UserID, &standing.XP, &standing.Reviews, &standing.CorrectCount); err != nil {
+			return nil, err
+		}
+		index[standing.UserID] = len(standings)
+		standings = append(standings, standing)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Number the answers right in a row to each word, per learner, the way
+	// the XP backfill numbers combos: a run is the answers after the same
+	// count of wrong ones
+	filter, args = sinceFilter("runs.created_at", since)
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT runs.user_id, COUNT(DISTINCT runs.word_id)
+		FROM (
+			SELECT user_id, word_id, created_at,
+				ROW_NUMBER() OVER (PARTITION BY user_id, word_id, misses ORDER BY created_at, id) AS streak
+			FROM (
+				SELECT ss.user_id, wri.id, wri.word_id, wri.correct, wri.created_at,
+					SUM(CASE WHEN wri.correct THEN 0 ELSE 1 END)
+						OVER (PARTITION BY ss.user_id, wri.word_id ORDER BY wri.created_at, wri.id) AS misses
+				FROM classroom_members cm
+				JOIN study_sessions ss ON ss.user_id = cm.user_id
+				JOIN word_review_items wri ON wri.study_session_id = ss.id
+				WHERE cm.classroom_id = ?
+			) answers
+			WHERE correct
+		) runs
+		WHERE runs.streak = ? AND `+filter+`
+		GROUP BY runs.user_id
+	`, append([]interface{}{classroomID, masteryStreak}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var mastered int
+		if err := rows.Scan(&userID, &mastered); err != nil {
+			return nil, err
+		}
+		if i, ok := index[userID]; ok {
+			standings[i].WordsMastered = mastered
+		}
+	}
+	return standings, rows.Err()
+}
+
+// SaveSnapshot replaces the snapshot of snapshot.ClassroomID over
+// snapshot.Period
+func (r *LeaderboardRepository) SaveSnapshot(ctx context.Context, snapshot *models.LeaderboardSnapshot) error {
+	return WithTx(ctx, r.db, func(tx DB) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO leaderboard_snapshots (classroom_id, period, computed_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT (classroom_id, period) DO UPDATE SET computed_at = excluded.computed_at
+		`, snapshot.ClassroomID, snapshot.Period, snapshot.ComputedAt.UTC())
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM leaderboard_standings
+			WHERE classroom_id = ? AND period = ?
+		`, snapshot.ClassroomID, snapshot.Period)
+		if err != nil {
+			return err
+		}
+
+		for _, standing := range snapshot.Standings {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO leaderboard_standings (classroom_id, period, user_id, xp, reviews, correct_count, words_mastered)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, snapshot.ClassroomID, snapshot.Period, standing.UserID,
+				standing.XP, standing.Reviews, standing.CorrectCount, standing.WordsMastered)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetSnapshot returns the last snapshot of a classroom over a period, or nil
+// if there is none. Members removed since it was computed are left out, and
+// the profiles are read as they are now.
+func (r *LeaderboardRepository) GetSnapshot(ctx context.Context, classroomID int64, period models.LeaderboardPeriod) (*models.LeaderboardSnapshot, error) {
+	snapshot := &models.LeaderboardSnapshot{ClassroomID: classroomID, Period: period}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT computed_at
+		FROM leaderboard_snapshots
+		WHERE classroom_id = ? AND period = ?
+	`, classroomID, period).Scan(&snapshot.ComputedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			ls.user_id, ls.xp, ls.reviews, ls.correct_count, ls.words_mastered,
+			COALESCE(lp.display_name, ''), COALESCE(lp.opt_out, FALSE)
+		FROM leaderboard_standings ls
+		JOIN classroom_members cm ON cm.classroom_id = ls.classroom_id AND cm.user_id = ls.user_id
+		LEFT JOIN leaderboard_profiles lp ON lp.user_id = ls.user_id
+		WHERE ls.classroom_id = ? AND ls.period = ?
+		ORDER BY ls.user_id
+	`, classroomID, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshot.Standings = []models.LeaderboardStanding{}
+	for rows.Next() {
+		var s models.LeaderboardStanding
+		if err := rows.Scan(&s.UserID, &s.XP, &s.Reviews, &s.CorrectCount, &s.WordsMastered, &s.DisplayName, &s.OptOut); err != nil {
+			return nil, err
+		}
+		snapshot.Standings = append(snapshot.Standings, s)
+	}
+	return snapshot, rows.Err()
+}
+
+// GetProfile returns the leaderboard profile of a user, or nil if they
+// haven't set one
+func (r *LeaderboardRepository) GetProfile(ctx context.Context, userID int64) (*models.LeaderboardProfile, error) {
+	profile := &models.LeaderboardProfile{UserID: userID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(display_name, ''), opt_out
+		FROM leaderboard_profiles
+		WHERE user_id = ?
+	`, userID).Scan(&profile.DisplayName, &profile.OptOut)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// SetProfile sets the leaderboard profile of profile.UserID. An empty display
+// name is stored as NULL.
+func (r *LeaderboardRepository) SetProfile(ctx context.Context, profile *models.LeaderboardProfile) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO leaderboard_profiles (user_id, display_name, opt_out, updated_at)
+		VALUES (?, NULLIF(?, ''), ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			display_name = excluded.display_name,
+			opt_out = excluded.opt_out,
+			updated_at = excluded.updated_at
+	`, profile.UserID, profile.DisplayName, profile.OptOut, utcNow())
+	return translateError(err)
+}
@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+)
+
+type UserRepository struct {
+	s *Store
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id int64) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	r.s.mu.Lock()
+	created := models.User{
+		ID:        r.s.nextID("users"),
+		Name:      user.Name,
+		Role:      user.Role,
+		CreatedAt: r.s.now(),
+	}
+	r.s.users[created.ID] = created
+	r.s.mu.Unlock()
+	return r.GetUser(ctx, created.ID)
+}
+
+type ClassroomRepository struct {
+	s *Store
+}
+
+func (r *ClassroomRepository) GetClassroom(ctx context.Context, id int64) (*models.Classroom, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	classroom, ok := r.s.classrooms[id]
+	if !ok {
+		return nil, nil
+	}
+	return &classroom, nil
+}
+
+func (r *ClassroomRepository) ListClassroomIDs(ctx context.Context) ([]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return sortedIDs(r.s.classrooms), nil
+}
+
+func (r *ClassroomRepository) CreateClassroom(ctx context.Context, classroom *models.Classroom) (*models.Classroom, error) {
+	r.s.mu.Lock()
+	if _, ok := r.s.users[classroom.TeacherID]; !ok {
+		r.s.mu.Unlock()
+		return nil, repository.ErrForeignKey
+	}
+	created := models.Classroom{
+		ID:        r.s.nextID("classrooms"),
+		Name:      classroom.Name,
+		TeacherID: classroom.TeacherID,
+		CreatedAt: r.s.now(),
+	}
+	r.s.classrooms[created.ID] = created
+	r.s.mu.Unlock()
+	return r.GetClassroom(ctx, created.ID)
+}
+
+// ListMembers returns the students enrolled in a classroom, by name
+func (r *ClassroomRepository) ListMembers(ctx context.Context, classroomID int64) ([]models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.listMembers(classroomID), nil
+}
+
+// AddMembers enrolls the users. Enrolling a student twice is a no-op.
+func (r *ClassroomRepository) AddMembers(ctx context.Context, classroomID int64, userIDs []int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.classrooms[classroomID]; !ok {
+		return repository.ErrForeignKey
+	}
+	for _, userID := range userIDs {
+		if _, ok := r.s.users[userID]; !ok {
+			return repository.ErrForeignKey
+		}
+	}
+	if r.s.members[classroomID] == nil {
+		r.s.members[classroomID] = map[int64]bool{}
+	}
+	for _, userID := range userIDs {
+		r.s.members[classroomID][userID] = true
+	}
+	return nil
+}
+
+func (r *ClassroomRepository) RemoveMember(ctx context.Context, classroomID, userID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if !r.s.members[classroomID][userID] {
+		return repository.ErrNotFound
+	}
+	delete(r.s.members[classroomID], userID)
+	return nil
+}
+
+// DeleteGroupAssignments deletes the assignments of a group, in every classroom
+func (r *ClassroomRepository) DeleteGroupAssignments(ctx context.Context, groupID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, assignment := range r.s.assignments {
+		if assignment.GroupID == groupID {
+			delete(r.s.assignments, id)
+		}
+	}
+	return nil
+}
+
+func (r *ClassroomRepository) GetAssignment(ctx context.Context, classroomID, id int64) (*models.Assignment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	assignment, ok := r.s.assignments[id]
+	if !ok || assignment.ClassroomID != classroomID {
+		return nil, nil
+	}
+	return r.s.assignmentDetail(assignment), nil
+}
+
+func (r *ClassroomRepository) CreateAssignment(ctx context.Context, assignment *models.Assignment) (*models.Assignment, error) {
+	r.s.mu.Lock()
+	_, classroomFound := r.s.classrooms[assignment.ClassroomID]
+	_, groupFound := r.s.groups[assignment.GroupID]
+	activityFound := true
+	if assignment.StudyActivityID != nil {
+		_, activityFound = r.s.activities[*assignment.StudyActivityID]
+	}
+	if !classroomFound || !groupFound || !activityFound {
+		r.s.mu.Unlock()
+		return nil, repository.ErrForeignKey
+	}
+	created := models.Assignment{
+		ID:             r.s.nextID("assignments"),
+		ClassroomID:    assignment.ClassroomID,
+		GroupID:        assignment.GroupID,
+		DueAt:          assignment.DueAt,
+		TargetAccuracy: assignment.TargetAccuracy,
+		CreatedAt:      r.s.now(),
+	}
+	if assignment.StudyActivityID != nil {
+		activityID := *assignment.StudyActivityID
+		created.StudyActivityID = &activityID
+	}
+	r.s.assignments[created.ID] = created
+	r.s.mu.Unlock()
+	return r.GetAssignment(ctx, created.ClassroomID, created.ID)
+}
+
+// ListAssignments returns the assignments of a classroom ordered by due date
+func (r *ClassroomRepository) ListAssignments(ctx context.Context, classroomID int64) ([]*models.Assignment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.listAssignments(func(a models.Assignment) bool {
+		return a.ClassroomID == classroomID
+	}), nil
+}
+
+// ListUserAssignments returns the assignments of every classroom a student belongs to
+func (r *ClassroomRepository) ListUserAssignments(ctx context.Context, userID int64) ([]*models.Assignment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.listAssignments(func(a models.Assignment) bool {
+		return r.s.members[a.ClassroomID][userID]
+	}), nil
+}
+
+// GetAssignmentProgress counts, for every student of the classroom, the
+// reviews of words in the assigned group made in matching sessions started
+// after the assignment was created
+func (r *ClassroomRepository) GetAssignmentProgress(ctx context.Context, assignment *models.Assignment, userID *int64) ([]models.AssignmentProgress, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	progress := []models.AssignmentProgress{}
+	for _, user := range r.s.listMembers(assignment.ClassroomID) {
+		if userID != nil && user.ID != *userID {
+			continue
+		}
+		p := models.AssignmentProgress{UserID: user.ID, UserName: user.Name}
+		reviewed := map[int64]bool{}
+		for _, review := range r.s.reviews {
+			session, ok := r.s.sessions[review.StudySessionID]
+			if !ok || !r.s.countsTowards(assignment, session, user.ID) || !r.s.inGroup(assignment.GroupID, review.WordID) {
+				continue
+			}
+			reviewed[review.WordID] = true
+			if review.Correct {
+				p.CorrectCount++
+			} else {
+				p.WrongCount++
+			}
+			if p.LastReviewedAt == nil || review.CreatedAt.After(*p.LastReviewedAt) {
+				reviewedAt := review.CreatedAt
+				p.LastReviewedAt = &reviewedAt
+			}
+		}
+		p.WordsReviewed = len(reviewed)
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
+
+// countsTowards reports whether a student's session counts towards an assignment
+func (s *Store) countsTowards(assignment *models.Assignment, session models.StudySession, userID int64) bool {
+	if session.UserID == nil || *session.UserID != userID || session.GroupID != assignment.GroupID {
+		return false
+	}
+	if assignment.StudyActivityID != nil && session.StudyActivityID != *assignment.StudyActivityID {
+		return false
+	}
+	return !session.CreatedAt.Before(assignment.CreatedAt)
+}
+
+// listMembers returns the existing users enrolled in a classroom, by name and ID
+func (s *Store) listMembers(classroomID int64) []models.User {
+	members := []models.User{}
+	for userID := range s.members[classroomID] {
+		if user, ok := s.users[userID]; ok {
+			members = append(members, user)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Name != members[j].Name {
+			return members[i].Name < members[j].Name
+		}
+		return members[i].ID < members[j].ID
+	})
+	return members
+}
+
+// listAssignments returns the assignments matching keep, by due date and ID
+func (s *Store) listAssignments(keep func(models.Assignment) bool) []*models.Assignment {
+	assignments := []*models.Assignment{}
+	for _, id := range sortedIDs(s.assignments) {
+		if a := s.assignments[id]; keep(a) {
+			if detail := s.assignmentDetail(a); detail != nil {
+				assignments = append(assignments, detail)
+			}
+		}
+	}
+	sort.SliceStable(assignments, func(i, j int) bool {
+		return assignments[i].DueAt.Before(assignments[j].DueAt)
+	})
+	return assignments
+}
+
+// assignmentDetail adds the group and classroom names to an assignment, or
+// returns nil if either doesn't exist
+func (s *Store) assignmentDetail(assignment models.Assignment) *models.Assignment {
+	group, ok := s.groups[assignment.GroupID]
+	if !ok {
+		return nil
+	}
+	classroom, ok := s.classrooms[assignment.ClassroomID]
+	if !ok {
+		return nil
+	}
+	assignment.GroupName = group.Name
+	assignment.ClassroomName = classroom.Name
+	if assignment.StudyActivityID != nil {
+		activityID := *assignment.StudyActivityID
+		assignment.StudyActivityID = &activityID
+	}
+	return &assignment
+}
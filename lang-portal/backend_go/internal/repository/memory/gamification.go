@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+type GamificationRepository struct {
+	s *Store
+}
+
+func (r *GamificationRepository) GetLearnerTotals(ctx context.Context, userID int64) (*models.LearnerTotals, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	keep := ofLearner(userID)
+	totals := &models.LearnerTotals{}
+	learned := map[int64]bool{}
+	for _, review := range r.s.reviews {
+		session, ok := r.s.sessions[review.StudySessionID]
+		if !ok || !keep(session) {
+			continue
+		}
+		totals.Reviews++
+		totals.XP += review.XP
+		if review.Correct {
+			learned[review.WordID] = true
+		}
+	}
+	totals.WordsLearned = len(learned)
+	return totals, nil
+}
+
+func (r *GamificationRepository) CountPerfectSessions(ctx context.Context, userID int64, perfectReviews int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	keep := ofLearner(userID)
+	reviews := map[int64]int{}
+	wrong := map[int64]bool{}
+	for _, review := range r.s.reviews {
+		session, ok := r.s.sessions[review.StudySessionID]
+		if !ok || !keep(session) {
+			continue
+		}
+		reviews[session.ID]++
+		if !review.Correct {
+			wrong[session.ID] = true
+		}
+	}
+	var count int
+	for id, n := range reviews {
+		if r.s.sessions[id].EndedAt != nil && n >= perfectReviews && !wrong[id] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *GamificationRepository) ListSessionActivity(ctx context.Context, userID int64, from, to time.Time) ([]models.SessionActivity, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.sessionActivity(from, to, ofLearner(userID)), nil
+}
+
+func (r *GamificationRepository) GetDailyGoal(ctx context.Context, userID int64) (*models.DailyGoal, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	goal, ok := r.s.goals[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &goal, nil
+}
+
+func (r *GamificationRepository) SetDailyGoal(ctx context.Context, goal *models.DailyGoal) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.goals[goal.UserID] = *goal
+	return nil
+}
+
+func (r *GamificationRepository) ListUnlockedAchievements(ctx context.Context, userID int64) ([]models.UnlockedAchievement, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	unlocked := []models.UnlockedAchievement{}
+	for _, achievement := range r.s.unlocked {
+		if achievement.UserID == userID {
+			unlocked = append(unlocked, achievement)
+		}
+	}
+	sort.SliceStable(unlocked, func(i, j int) bool { return unlocked[i].UnlockedAt.Before(unlocked[j].UnlockedAt) })
+	return unlocked, nil
+}
+
+func (r *GamificationRepository) UnlockAchievement(ctx context.Context, achievement *models.UnlockedAchievement) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, unlocked := range r.s.unlocked {
+		if unlocked.UserID == achievement.UserID && unlocked.Key == achievement.Key {
+			return nil
+		}
+	}
+	r.s.unlocked = append(r.s.unlocked, *achievement)
+	return nil
+}
@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+)
+
+type GroupRepository struct {
+	s *Store
+}
+
+func (r *GroupRepository) GetGroup(ctx context.Context, id int64) (*models.Group, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	group, ok := r.s.groups[id]
+	if !ok {
+		return nil, nil
+	}
+	return &group, nil
+}
+
+func (r *GroupRepository) FindGroupByName(ctx context.Context, name string) (*models.Group, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, id := range sortedIDs(r.s.groups) {
+		if group := r.s.groups[id]; strings.EqualFold(group.Name, name) {
+			return &group, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *GroupRepository) GetGroupWithStats(ctx context.Context, id int64) (*models.GroupWithStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	group, ok := r.s.groups[id]
+	if !ok {
+		return nil, nil
+	}
+	return &models.GroupWithStats{Group: group, WordCount: r.s.countGroupWords(id)}, nil
+}
+
+func (r *GroupRepository) ListGroups(ctx context.Context) ([]*models.Group, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var groups []*models.Group
+	for _, id := range sortedIDs(r.s.groups) {
+		group := r.s.groups[id]
+		groups = append(groups, &group)
+	}
+	return groups, nil
+}
+
+func (r *GroupRepository) ListGroupsPaginated(ctx context.Context, page, pageSize int) ([]*models.Group, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ids := sortedIDs(r.s.groups)
+	var groups []*models.Group
+	for _, id := range window(ids, (page-1)*pageSize, pageSize) {
+		group := r.s.groups[id]
+		groups = append(groups, &group)
+	}
+	return groups, len(ids), nil
+}
+
+func (r *GroupRepository) CreateGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	r.s.mu.Lock()
+	if r.s.groupNameTaken(group.Name, 0) {
+		r.s.mu.Unlock()
+		return nil, repository.ErrDuplicate
+	}
+	created := models.Group{
+		ID:        r.s.nextID("groups"),
+		Name:      group.Name,
+		CreatedAt: r.s.now(),
+	}
+	r.s.groups[created.ID] = created
+	r.s.mu.Unlock()
+	return r.GetGroup(ctx, created.ID)
+}
+
+func (r *GroupRepository) UpdateGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	r.s.mu.Lock()
+	if r.s.groupNameTaken(group.Name, group.ID) {
+		r.s.mu.Unlock()
+		return nil, repository.ErrDuplicate
+	}
+	if existing, ok := r.s.groups[group.ID]; ok {
+		existing.Name = group.Name
+		r.s.groups[group.ID] = existing
+	}
+	r.s.mu.Unlock()
+	return r.GetGroup(ctx, group.ID)
+}
+
+func (r *GroupRepository) DeleteGroup(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.deleteGroup(id)
+	return nil
+}
+
+func (r *GroupRepository) GetGroupWords(ctx context.Context, groupID int64) ([]*models.Word, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var words []*models.Word
+	for _, id := range r.s.groupWordIDs(groupID) {
+		word := r.s.words[id]
+		words = append(words, &word)
+	}
+	return words, nil
+}
+
+func (r *GroupRepository) GetGroupWordsPaginated(ctx context.Context, groupID int64, page, pageSize int) ([]*models.WordWithStats, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ids := r.s.groupWordIDs(groupID)
+	var words []*models.WordWithStats
+	for _, id := range window(ids, (page-1)*pageSize, pageSize) {
+		words = append(words, r.s.withStats(r.s.words[id]))
+	}
+	return words, len(ids), nil
+}
+
+func (r *GroupRepository) CountGroupWords(ctx context.Context, groupID int64) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.countGroupWords(groupID), nil
+}
+
+// AddWordsToGroup adds all the words or, if one of them is already in the
+// group, none
+func (r *GroupRepository) AddWordsToGroup(ctx context.Context, groupID int64, wordIDs []int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	seen := make(map[int64]bool, len(wordIDs))
+	for _, wordID := range wordIDs {
+		if seen[wordID] || r.s.inGroup(groupID, wordID) {
+			return repository.ErrDuplicate
+		}
+		seen[wordID] = true
+	}
+	for _, wordID := range wordIDs {
+		r.s.wordsGroups = append(r.s.wordsGroups, wordGroup{wordID: wordID, groupID: groupID})
+	}
+	return nil
+}
+
+func (r *GroupRepository) RemoveWordFromGroup(ctx context.Context, groupID, wordID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if !r.s.inGroup(groupID, wordID) {
+		return repository.ErrNotFound
+	}
+	kept := r.s.wordsGroups[:0]
+	for _, wg := range r.s.wordsGroups {
+		if wg.groupID != groupID || wg.wordID != wordID {
+			kept = append(kept, wg)
+		}
+	}
+	r.s.wordsGroups = kept
+	return nil
+}
+
+func (r *GroupRepository) GetGroupProgress(ctx context.Context, groupIDs []int64, t models.MasteryThresholds, now time.Time) ([]models.GroupProgress, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	progress := make([]models.GroupProgress, len(groupIDs))
+	for i, id := range groupIDs {
+		p := &progress[i]
+		p.GroupID = id
+		for _, wg := range r.s.wordsGroups {
+			if wg.groupID != id {
+				continue
+			}
+			p.WordCount++
+			switch t.State(r.s.withStats(r.s.words[wg.wordID]), now) {
+			case models.WordNew:
+				p.NewCount++
+			case models.WordMastered:
+				p.MasteredCount++
+			case models.WordLapsed:
+				p.LapsedCount++
+			}
+		}
+		for _, session := range r.s.sessions {
+			if session.GroupID == id && (p.LastStudiedAt == nil || session.CreatedAt.After(*p.LastStudiedAt)) {
+				createdAt := session.CreatedAt
+				p.LastStudiedAt = &createdAt
+			}
+		}
+	}
+	return progress, nil
+}
+
+// groupWordIDs returns the IDs of the existing words in a group, ascending
+func (s *Store) groupWordIDs(groupID int64) []int64 {
+	var ids []int64
+	for _, id := range sortedIDs(s.words) {
+		if s.inGroup(groupID, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// countGroupWords counts the words_groups rows of a group, including those
+// of deleted words
+func (s *Store) countGroupWords(groupID int64) int {
+	count := 0
+	for _, wg := range s.wordsGroups {
+		if wg.groupID == groupID {
+			count++
+		}
+	}
+	return count
+}
+
+// groupNameTaken reports whether a group other than exceptID has name,
+// regardless of case, as the unique index on the group names does
+func (s *Store) groupNameTaken(name string, exceptID int64) bool {
+	for id, group := range s.groups {
+		if id != exceptID && strings.EqualFold(group.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+type LeaderboardRepository struct {
+	s *Store
+}
+
+func (r *LeaderboardRepository) ComputeStandings(ctx context.Context, classroomID int64, since time.Time, masteryStreak int) ([]models.LeaderboardStanding, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	members := r.s.members[classroomID]
+	var reviews []models.WordReviewItem
+	owners := map[int64]int64{}
+	for _, review := range r.s.reviews {
+		session, ok := r.s.sessions[review.StudySessionID]
+		if !ok || session.UserID == nil || !members[*session.UserID] {
+			continue
+		}
+		reviews = append(reviews, review)
+		owners[review.ID] = *session.UserID
+	}
+	sort.SliceStable(reviews, func(i, j int) bool {
+		if !reviews[i].CreatedAt.Equal(reviews[j].CreatedAt) {
+			return reviews[i].CreatedAt.Before(reviews[j].CreatedAt)
+		}
+		return reviews[i].ID < reviews[j].ID
+	})
+
+	type learnerWord struct{ userID, wordID int64 }
+	byUser := map[int64]*models.LeaderboardStanding{}
+	streaks := map[learnerWord]int{}
+	mastered := map[learnerWord]bool{}
+	for _, review := range reviews {
+		userID := owners[review.ID]
+		key := learnerWord{userID, review.WordID}
+		streaks[key]++
+		if !review.Correct {
+			streaks[key] = 0
+		}
+		if review.CreatedAt.Before(since) {
+			continue
+		}
+
+		standing := byUser[userID]
+		if standing == nil {
+			standing = &models.LeaderboardStanding{UserID: userID}
+			byUser[userID] = standing
+		}
+		standing.XP += review.XP
+		standing.Reviews++
+		if review.Correct {
+			standing.CorrectCount++
+		}
+		if streaks[key] == masteryStreak && !mastered[key] {
+			mastered[key] = true
+			standing.WordsMastered++
+		}
+	}
+
+	standings := []models.LeaderboardStanding{}
+	for _, userID := range sortedIDs(byUser) {
+		standings = append(standings, *byUser[userID])
+	}
+	return standings, nil
+}
+
+func (r *LeaderboardRepository) SaveSnapshot(ctx context.Context, snapshot *models.LeaderboardSnapshot) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	saved := *snapshot
+	saved.Standings = slices.Clone(snapshot.Standings)
+	r.s.snapshots[snapshotKey{snapshot.ClassroomID, snapshot.Period}] = saved
+	return nil
+}
+
+func (r *LeaderboardRepository) GetSnapshot(ctx context.Context, classroomID int64, period models.LeaderboardPeriod) (*models.LeaderboardSnapshot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	saved, ok := r.s.snapshots[snapshotKey{classroomID, period}]
+	if !ok {
+		return nil, nil
+	}
+	snapshot := saved
+	snapshot.Standings = []models.LeaderboardStanding{}
+	for _, standing := range saved.Standings {
+		if !r.s.members[classroomID][standing.UserID] {
+			continue
+		}
+		profile := r.s.profiles[standing.UserID]
+		standing.DisplayName = profile.DisplayName
+		standing.OptOut = profile.OptOut
+		snapshot.Standings = append(snapshot.Standings, standing)
+	}
+	sort.Slice(snapshot.Standings, func(i, j int) bool { return snapshot.Standings[i].UserID < snapshot.Standings[j].UserID })
+	return &snapshot, nil
+}
+
+func (r *LeaderboardRepository) GetProfile(ctx context.Context, userID int64) (*models.LeaderboardProfile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	profile, ok := r.s.profiles[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &profile, nil
+}
+
+func (r *LeaderboardRepository) SetProfile(ctx context.Context, profile *models.LeaderboardProfile) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.profiles[profile.UserID] = *profile
+	return nil
+}
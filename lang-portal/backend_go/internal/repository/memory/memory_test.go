@@ -0,0 +1,26 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/repotest"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+)
+
+// TestConformance runs the repository conformance suite against the fakes
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) service.Repositories {
+		store := memory.NewStore()
+		return service.Repositories{
+			Words:           store.Words(),
+			Groups:          store.Groups(),
+			StudyActivities: store.StudyActivities(),
+			StudySessions:   store.StudySessions(),
+			Users:           store.Users(),
+			Classrooms:      store.Classrooms(),
+			Gamification:    store.Gamification(),
+			Leaderboards:    store.Leaderboards(),
+		}
+	})
+}
@@ -0,0 +1,157 @@
+// Package memory implements the repositories the services depend on with
+// plain maps, for unit tests that don't need SQLite. It mirrors the SQLite
+// repositories closely enough to pass the same conformance suite, including
+// their joins and the deletes their foreign keys cascade: deleting a group,
+// for instance, deletes its study sessions.
+package memory
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+// Store holds the records shared by the repositories it hands out. It is
+// safe for concurrent use.
+type Store struct {
+	mu sync.Mutex
+
+	// lastID is the last ID assigned per table, like SQLite's AUTOINCREMENT
+	lastID map[string]int64
+
+	words       map[int64]models.Word
+	groups      map[int64]models.Group
+	wordsGroups []wordGroup
+	activities  map[int64]models.StudyActivity
+	sessions    map[int64]models.StudySession
+	reviews     []models.WordReviewItem
+	users       map[int64]models.User
+	classrooms  map[int64]models.Classroom
+	members     map[int64]map[int64]bool
+	assignments map[int64]models.Assignment
+	goals       map[int64]models.DailyGoal
+	unlocked    []models.UnlockedAchievement
+	profiles    map[int64]models.LeaderboardProfile
+	snapshots   map[snapshotKey]models.LeaderboardSnapshot
+
+	// now stamps created records
+	now func() time.Time
+}
+
+// snapshotKey identifies a row of leaderboard_snapshots
+type snapshotKey struct {
+	classroomID int64
+	period      models.LeaderboardPeriod
+}
+
+// wordGroup is a row of words_groups
+type wordGroup struct {
+	wordID  int64
+	groupID int64
+}
+
+// NewStore returns an empty store
+func NewStore() *Store {
+	return &Store{
+		lastID:      map[string]int64{},
+		words:       map[int64]models.Word{},
+		groups:      map[int64]models.Group{},
+		activities:  map[int64]models.StudyActivity{},
+		sessions:    map[int64]models.StudySession{},
+		users:       map[int64]models.User{},
+		classrooms:  map[int64]models.Classroom{},
+		members:     map[int64]map[int64]bool{},
+		assignments: map[int64]models.Assignment{},
+		goals:       map[int64]models.DailyGoal{},
+		profiles:    map[int64]models.LeaderboardProfile{},
+		snapshots:   map[snapshotKey]models.LeaderboardSnapshot{},
+		now:         time.Now,
+	}
+}
+
+// SetClock makes the store stamp created records with now instead of the
+// current time
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+func (s *Store) Words() *WordRepository                    { return &WordRepository{s} }
+func (s *Store) Groups() *GroupRepository                  { return &GroupRepository{s} }
+func (s *Store) StudyActivities() *StudyActivityRepository { return &StudyActivityRepository{s} }
+func (s *Store) StudySessions() *StudySessionRepository    { return &StudySessionRepository{s} }
+func (s *Store) Users() *UserRepository                    { return &UserRepository{s} }
+func (s *Store) Classrooms() *ClassroomRepository          { return &ClassroomRepository{s} }
+func (s *Store) Gamification() *GamificationRepository     { return &GamificationRepository{s} }
+func (s *Store) Leaderboards() *LeaderboardRepository      { return &LeaderboardRepository{s} }
+
+// nextID assigns the next ID of table
+func (s *Store) nextID(table string) int64 {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+// sortedIDs returns the keys of m in ascending order
+func sortedIDs[V any](m map[int64]V) []int64 {
+	ids := make([]int64, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// window returns the items of a LIMIT/OFFSET query
+func window[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// inGroup reports whether wordID has a words_groups row for groupID
+func (s *Store) inGroup(groupID, wordID int64) bool {
+	for _, wg := range s.wordsGroups {
+		if wg.groupID == groupID && wg.wordID == wordID {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteWord deletes a word along with its group rows and reviews, as the
+// foreign keys of the SQL schemas cascade
+func (s *Store) deleteWord(id int64) {
+	delete(s.words, id)
+	s.wordsGroups = slices.DeleteFunc(s.wordsGroups, func(wg wordGroup) bool { return wg.wordID == id })
+	s.reviews = slices.DeleteFunc(s.reviews, func(review models.WordReviewItem) bool { return review.WordID == id })
+}
+
+// deleteGroup deletes a group along with its word rows, assignments and
+// study sessions, whose reviews go too
+func (s *Store) deleteGroup(id int64) {
+	delete(s.groups, id)
+	s.wordsGroups = slices.DeleteFunc(s.wordsGroups, func(wg wordGroup) bool { return wg.groupID == id })
+	for assignmentID, assignment := range s.assignments {
+		if assignment.GroupID == id {
+			delete(s.assignments, assignmentID)
+		}
+	}
+	for sessionID, session := range s.sessions {
+		if session.GroupID == id {
+			delete(s.sessions, sessionID)
+		}
+	}
+	s.reviews = slices.DeleteFunc(s.reviews, func(review models.WordReviewItem) bool {
+		_, ok := s.sessions[review.StudySessionID]
+		return !ok
+	})
+}
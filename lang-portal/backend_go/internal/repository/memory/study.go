@@ -0,0 +1,431 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+)
+
+type StudyActivityRepository struct {
+	s *Store
+}
+
+func (r *StudyActivityRepository) GetStudyActivity(ctx context.Context, id int64) (*models.StudyActivity, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	activity, ok := r.s.activities[id]
+	if !ok {
+		return nil, nil
+	}
+	return &activity, nil
+}
+
+func (r *StudyActivityRepository) ListStudyActivities(ctx context.Context) ([]models.StudyActivity, error) {
+	return r.ListStudyActivitiesPaginated(ctx, 0, -1)
+}
+
+func (r *StudyActivityRepository) ListStudyActivitiesPaginated(ctx context.Context, offset, limit int) ([]models.StudyActivity, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var activities []models.StudyActivity
+	for _, id := range window(sortedIDs(r.s.activities), offset, limit) {
+		activities = append(activities, r.s.activities[id])
+	}
+	return activities, nil
+}
+
+func (r *StudyActivityRepository) CountStudyActivities(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return len(r.s.activities), nil
+}
+
+func (r *StudyActivityRepository) CreateStudyActivity(ctx context.Context, activity *models.StudyActivity) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, existing := range r.s.activities {
+		if existing.Name == activity.Name {
+			return repository.ErrDuplicate
+		}
+	}
+	activity.ID = r.s.nextID("study_activities")
+	created := *activity
+	created.CreatedAt = r.s.now()
+	r.s.activities[activity.ID] = created
+	return nil
+}
+
+func (r *StudyActivityRepository) GetStudyActivitySessions(ctx context.Context, activityID int64, offset, limit int) ([]models.StudySessionDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	sessions := r.s.sessionDetails(func(session models.StudySession) bool {
+		return session.StudyActivityID == activityID
+	})
+	return window(sessions, offset, limit), nil
+}
+
+func (r *StudyActivityRepository) CountStudyActivitySessions(ctx context.Context, activityID int64) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	count := 0
+	for _, session := range r.s.sessions {
+		if session.StudyActivityID == activityID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type StudySessionRepository struct {
+	s *Store
+}
+
+func (r *StudySessionRepository) GetStudySession(ctx context.Context, id int64) (*models.StudySessionDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	session, ok := r.s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	detail, ok := r.s.sessionDetail(session)
+	if !ok {
+		return nil, nil
+	}
+	return &detail, nil
+}
+
+func (r *StudySessionRepository) GetLastStudySession(ctx context.Context) (*models.StudySessionDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	sessions := r.s.sessionDetails(func(models.StudySession) bool { return true })
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return &sessions[0], nil
+}
+
+func (r *StudySessionRepository) ListStudySessions(ctx context.Context, offset, limit int) ([]models.StudySessionDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	sessions := r.s.sessionDetails(func(models.StudySession) bool { return true })
+	return window(sessions, offset, limit), nil
+}
+
+func (r *StudySessionRepository) CountStudySessions(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return len(r.s.sessions), nil
+}
+
+func (r *StudySessionRepository) CreateStudySession(ctx context.Context, session *models.StudySession) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	_, groupFound := r.s.groups[session.GroupID]
+	_, activityFound := r.s.activities[session.StudyActivityID]
+	userFound := true
+	if session.UserID != nil {
+		_, userFound = r.s.users[*session.UserID]
+	}
+	if !groupFound || !activityFound || !userFound {
+		return repository.ErrForeignKey
+	}
+	session.ID = r.s.nextID("study_sessions")
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = r.s.now()
+	}
+	created := models.StudySession{
+		ID:              session.ID,
+		StudyActivityID: session.StudyActivityID,
+		GroupID:         session.GroupID,
+		CreatedAt:       session.CreatedAt,
+	}
+	if session.UserID != nil {
+		userID := *session.UserID
+		created.UserID = &userID
+	}
+	r.s.sessions[session.ID] = created
+	return nil
+}
+
+func (r *StudySessionRepository) GetSessionCombo(ctx context.Context, sessionID int64) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	combo := 0
+	for _, review := range r.s.reviews {
+		switch {
+		case review.StudySessionID != sessionID:
+		case review.Correct:
+			combo++
+		default:
+			combo = 0
+		}
+	}
+	return combo, nil
+}
+
+func (r *StudySessionRepository) EndStudySession(ctx context.Context, id int64, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	session, ok := r.s.sessions[id]
+	if !ok || session.EndedAt != nil {
+		return nil
+	}
+	session.EndedAt = &at
+	r.s.sessions[id] = session
+	return nil
+}
+
+func (r *StudySessionRepository) CreateReview(ctx context.Context, review *models.WordReviewItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	review.ID = r.s.nextID("word_review_items")
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = r.s.now()
+	}
+	r.s.reviews = append(r.s.reviews, *review)
+	return nil
+}
+
+func (r *StudySessionRepository) GetTotalDistinctWordsStudied(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	studied := map[int64]bool{}
+	for _, review := range r.s.reviews {
+		studied[review.WordID] = true
+	}
+	return len(studied), nil
+}
+
+func (r *StudySessionRepository) GetWordReviewStats(ctx context.Context) (correct int, total int, err error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, review := range r.s.reviews {
+		if review.Correct {
+			correct++
+		}
+	}
+	return correct, len(r.s.reviews), nil
+}
+
+func (r *StudySessionRepository) GetTotalActiveGroups(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	groups := map[int64]bool{}
+	for _, session := range r.s.sessions {
+		groups[session.GroupID] = true
+	}
+	return len(groups), nil
+}
+
+// ofLearner returns whether a session was started by the learner with ID
+// userID, the guest if 0
+func ofLearner(userID int64) func(models.StudySession) bool {
+	return func(session models.StudySession) bool {
+		if session.UserID == nil {
+			return userID == 0
+		}
+		return *session.UserID == userID
+	}
+}
+
+func (r *StudySessionRepository) ListStudyTimes(ctx context.Context, userID int64) ([]time.Time, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.studyTimes(ofLearner(userID)), nil
+}
+
+func (r *StudySessionRepository) ListSessionActivity(ctx context.Context, from, to time.Time) ([]models.SessionActivity, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.sessionActivity(from, to, func(models.StudySession) bool { return true }), nil
+}
+
+// studyTimes returns the quarter hours in which the sessions matching keep
+// were started, oldest first
+func (s *Store) studyTimes(keep func(models.StudySession) bool) []time.Time {
+	seen := map[time.Time]bool{}
+	times := []time.Time{}
+	for _, session := range s.sessions {
+		if !keep(session) {
+			continue
+		}
+		slot := session.CreatedAt.UTC().Truncate(15 * time.Minute)
+		if !seen[slot] {
+			seen[slot] = true
+			times = append(times, slot)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// sessionActivity returns the sessions matching keep started from from until
+// before to, oldest first
+func (s *Store) sessionActivity(from, to time.Time, keep func(models.StudySession) bool) []models.SessionActivity {
+	var sessions []models.StudySession
+	for _, session := range s.sessions {
+		if keep(session) && !session.CreatedAt.Before(from) && session.CreatedAt.Before(to) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+
+	activity := []models.SessionActivity{}
+	for _, session := range sessions {
+		entry := models.SessionActivity{StartedAt: session.CreatedAt}
+		for _, review := range s.reviews {
+			if review.StudySessionID != session.ID {
+				continue
+			}
+			entry.ReviewCount++
+			if review.Correct {
+				entry.CorrectCount++
+			}
+			if entry.EndedAt == nil || review.CreatedAt.After(*entry.EndedAt) {
+				endedAt := review.CreatedAt
+				entry.EndedAt = &endedAt
+			}
+		}
+		activity = append(activity, entry)
+	}
+	return activity
+}
+
+// sessionDetails returns the sessions matching keep, most recent first. Like
+// the SQL joins, sessions whose group or activity is gone are left out.
+func (s *Store) sessionDetails(keep func(models.StudySession) bool) []models.StudySessionDetail {
+	var details []models.StudySessionDetail
+	for _, session := range s.sessions {
+		if !keep(session) {
+			continue
+		}
+		if detail, ok := s.sessionDetail(session); ok {
+			details = append(details, detail)
+		}
+	}
+	sort.Slice(details, func(i, j int) bool {
+		if !details[i].CreatedAt.Equal(details[j].CreatedAt) {
+			return details[i].CreatedAt.After(details[j].CreatedAt)
+		}
+		return details[i].ID > details[j].ID
+	})
+	return details
+}
+
+// sessionDetail adds the activity and group names and the review counts to a
+// session. It reports false if the activity or group doesn't exist.
+func (s *Store) sessionDetail(session models.StudySession) (models.StudySessionDetail, bool) {
+	activity, ok := s.activities[session.StudyActivityID]
+	if !ok {
+		return models.StudySessionDetail{}, false
+	}
+	group, ok := s.groups[session.GroupID]
+	if !ok {
+		return models.StudySessionDetail{}, false
+	}
+	detail := models.StudySessionDetail{
+		ID:              session.ID,
+		ActivityName:    activity.Name,
+		GroupName:       group.Name,
+		CreatedAt:       session.CreatedAt,
+		EndedAt:         session.EndedAt,
+		StudyActivityID: session.StudyActivityID,
+		GroupID:         session.GroupID,
+		UserID:          session.UserID,
+	}
+	for _, review := range s.reviews {
+		if review.StudySessionID != session.ID {
+			continue
+		}
+		detail.ReviewItemsCount++
+		if detail.EndTime == nil || review.CreatedAt.After(*detail.EndTime) {
+			endTime := review.CreatedAt
+			detail.EndTime = &endTime
+		}
+	}
+	return detail, true
+}
+
+func (r *StudySessionRepository) GetRecallBuckets(ctx context.Context, scope models.ReviewScope, bounds []time.Duration) ([]models.RecallBucket, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	buckets := make([]models.RecallBucket, len(bounds))
+	for i, bound := range bounds {
+		buckets[i].MinInterval = bound
+	}
+
+	// Intervals are measured between the reviews of a word by one learner,
+	// the guest being learner 0
+	type wordLearner struct{ wordID, learner int64 }
+	byLearner := map[wordLearner][]models.WordReviewItem{}
+	for _, review := range r.s.reviews {
+		switch {
+		case scope.WordID != 0 && review.WordID != scope.WordID:
+		case scope.GroupID != 0 && !r.s.inGroup(scope.GroupID, review.WordID):
+		default:
+			key := wordLearner{wordID: review.WordID}
+			if userID := r.s.sessions[review.StudySessionID].UserID; userID != nil {
+				key.learner = *userID
+			}
+			byLearner[key] = append(byLearner[key], review)
+		}
+	}
+	for _, reviews := range byLearner {
+		// Ties are broken by ID, the order the reviews were recorded in
+		sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CreatedAt.Before(reviews[j].CreatedAt) })
+		for i := 1; i < len(reviews); i++ {
+			// Intervals are counted in whole seconds, as the databases store them
+			gap := reviews[i].CreatedAt.Truncate(time.Second).Sub(reviews[i-1].CreatedAt.Truncate(time.Second))
+			b := len(bounds) - 1
+			for b >= 0 && gap < bounds[b] {
+				b--
+			}
+			if b < 0 {
+				continue
+			}
+			buckets[b].Reviews++
+			if reviews[i].Correct {
+				buckets[b].CorrectCount++
+				buckets[b].RecallSeconds += int64(gap / time.Second)
+			}
+		}
+	}
+	return buckets, nil
+}
+
+func (r *StudySessionRepository) ListWordReviews(ctx context.Context, wordID int64) ([]models.WordReviewItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reviews := []models.WordReviewItem{}
+	for _, review := range r.s.reviews {
+		if review.WordID == wordID {
+			reviews = append(reviews, review)
+		}
+	}
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CreatedAt.Before(reviews[j].CreatedAt) })
+
+	// Intervals are measured from the same learner's previous review, in
+	// whole seconds as the databases store them
+	previous := map[int64]time.Time{}
+	for i, review := range reviews {
+		var learner int64
+		if userID := r.s.sessions[review.StudySessionID].UserID; userID != nil {
+			learner = *userID
+		}
+		at := review.CreatedAt.Truncate(time.Second)
+		if last, ok := previous[learner]; ok {
+			seconds := int64(at.Sub(last) / time.Second)
+			reviews[i].SecondsSincePrevious = &seconds
+		}
+		previous[learner] = at
+	}
+	return reviews, nil
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+// WithTx runs fn and, if it returns an error or panics, restores the records
+// to what they were before. Unlike a SQL transaction it doesn't isolate fn
+// from concurrent writers, whose changes are lost on rollback, so tests that
+// roll back shouldn't write concurrently.
+func (s *Store) WithTx(ctx context.Context, fn func() error) (err error) {
+	saved := s.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			s.restore(saved)
+			panic(p)
+		}
+		if err != nil {
+			s.restore(saved)
+		}
+	}()
+	return fn()
+}
+
+// records are the contents of a store
+type records struct {
+	lastID      map[string]int64
+	words       map[int64]models.Word
+	groups      map[int64]models.Group
+	wordsGroups []wordGroup
+	activities  map[int64]models.StudyActivity
+	sessions    map[int64]models.StudySession
+	reviews     []models.WordReviewItem
+	users       map[int64]models.User
+	classrooms  map[int64]models.Classroom
+	members     map[int64]map[int64]bool
+	assignments map[int64]models.Assignment
+	goals       map[int64]models.DailyGoal
+	unlocked    []models.UnlockedAchievement
+	profiles    map[int64]models.LeaderboardProfile
+	snapshots   map[snapshotKey]models.LeaderboardSnapshot
+}
+
+// snapshot copies the records. The models it holds are values, and the
+// pointers in them are never written through, so shallow copies suffice.
+func (s *Store) snapshot() records {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	members := make(map[int64]map[int64]bool, len(s.members))
+	for id, users := range s.members {
+		members[id] = maps.Clone(users)
+	}
+	return records{
+		lastID:      maps.Clone(s.lastID),
+		words:       maps.Clone(s.words),
+		groups:      maps.Clone(s.groups),
+		wordsGroups: slices.Clone(s.wordsGroups),
+		activities:  maps.Clone(s.activities),
+		sessions:    maps.Clone(s.sessions),
+		reviews:     slices.Clone(s.reviews),
+		users:       maps.Clone(s.users),
+		classrooms:  maps.Clone(s.classrooms),
+		members:     members,
+		assignments: maps.Clone(s.assignments),
+		goals:       maps.Clone(s.goals),
+		unlocked:    slices.Clone(s.unlocked),
+		profiles:    maps.Clone(s.profiles),
+		snapshots:   maps.Clone(s.snapshots),
+	}
+}
+
+// restore puts back the records of a snapshot
+func (s *Store) restore(r records) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID = r.lastID
+	s.words = r.words
+	s.groups = r.groups
+	s.wordsGroups = r.wordsGroups
+	s.activities = r.activities
+	s.sessions = r.sessions
+	s.reviews = r.reviews
+	s.users = r.users
+	s.classrooms = r.classrooms
+	s.members = r.members
+	s.assignments = r.assignments
+	s.goals = r.goals
+	s.unlocked = r.unlocked
+	s.profiles = r.profiles
+	s.snapshots = r.snapshots
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+)
+
+type WordRepository struct {
+	s *Store
+}
+
+func (r *WordRepository) GetWord(ctx context.Context, id int64) (*models.Word, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	word, ok := r.s.words[id]
+	if !ok {
+		return nil, nil
+	}
+	return &word, nil
+}
+
+func (r *WordRepository) GetWordWithStats(ctx context.Context, id int64) (*models.WordWithStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	word, ok := r.s.words[id]
+	if !ok {
+		return nil, nil
+	}
+	return r.s.withStats(word), nil
+}
+
+func (r *WordRepository) ListWords(ctx context.Context) ([]*models.Word, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var words []*models.Word
+	for _, id := range sortedIDs(r.s.words) {
+		word := r.s.words[id]
+		words = append(words, &word)
+	}
+	return words, nil
+}
+
+func (r *WordRepository) ListWordsWithStatsPaginated(ctx context.Context, page, pageSize int) ([]*models.WordWithStats, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ids := sortedIDs(r.s.words)
+	var words []*models.WordWithStats
+	for _, id := range window(ids, (page-1)*pageSize, pageSize) {
+		words = append(words, r.s.withStats(r.s.words[id]))
+	}
+	return words, len(ids), nil
+}
+
+func (r *WordRepository) CountWords(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return len(r.s.words), nil
+}
+
+func (r *WordRepository) CreateWord(ctx context.Context, word *models.Word) (*models.Word, error) {
+	r.s.mu.Lock()
+	if r.s.findWord(word.Portuguese, word.English, 0) != 0 {
+		r.s.mu.Unlock()
+		return nil, repository.ErrDuplicate
+	}
+	created := models.Word{
+		ID:         r.s.nextID("words"),
+		Portuguese: word.Portuguese,
+		English:    word.English,
+		CreatedAt:  r.s.now(),
+	}
+	r.s.words[created.ID] = created
+	r.s.mu.Unlock()
+	return r.GetWord(ctx, created.ID)
+}
+
+func (r *WordRepository) UpdateWord(ctx context.Context, word *models.Word) (*models.Word, error) {
+	r.s.mu.Lock()
+	if r.s.findWord(word.Portuguese, word.English, word.ID) != 0 {
+		r.s.mu.Unlock()
+		return nil, repository.ErrDuplicate
+	}
+	if existing, ok := r.s.words[word.ID]; ok {
+		existing.Portuguese = word.Portuguese
+		existing.English = word.English
+		r.s.words[word.ID] = existing
+	}
+	r.s.mu.Unlock()
+	return r.GetWord(ctx, word.ID)
+}
+
+func (r *WordRepository) FindWord(ctx context.Context, portuguese, english string) (*models.Word, error) {
+	r.s.mu.Lock()
+	id := r.s.findWord(portuguese, english, 0)
+	r.s.mu.Unlock()
+	if id == 0 {
+		return nil, nil
+	}
+	return r.GetWord(ctx, id)
+}
+
+// DeleteWord deletes the word along with its group rows and reviews
+func (r *WordRepository) DeleteWord(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.words[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.deleteWord(id)
+	return nil
+}
+
+func (r *WordRepository) GetWordGroups(ctx context.Context, wordID int64) ([]models.WordGroup, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var groups []models.WordGroup
+	for _, id := range sortedIDs(r.s.groups) {
+		if r.s.inGroup(id, wordID) {
+			groups = append(groups, models.WordGroup{ID: id, Name: r.s.groups[id].Name})
+		}
+	}
+	return groups, nil
+}
+
+func (r *WordRepository) FindMissingWordIDs(ctx context.Context, wordIDs []int64) ([]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var missing []int64
+	for _, id := range wordIDs {
+		if _, ok := r.s.words[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+func (r *WordRepository) ListLeeches(ctx context.Context, groupID int64, c models.LeechCriteria) ([]*models.WordWithStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	leeches := []*models.WordWithStats{}
+	for _, word := range r.s.words {
+		if groupID != 0 && !r.s.inGroup(groupID, word.ID) {
+			continue
+		}
+		if stats := r.s.withStats(word); c.IsLeech(stats) {
+			leeches = append(leeches, stats)
+		}
+	}
+	sort.Slice(leeches, func(i, j int) bool {
+		if leeches[i].WrongCount != leeches[j].WrongCount {
+			return leeches[i].WrongCount > leeches[j].WrongCount
+		}
+		return leeches[i].ID < leeches[j].ID
+	})
+	return leeches, nil
+}
+
+// withStats adds the statistics of its reviews to a word
+func (s *Store) withStats(word models.Word) *models.WordWithStats {
+	stats := &models.WordWithStats{Word: word}
+	var reviews []models.WordReviewItem
+	for _, review := range s.reviews {
+		if review.WordID != word.ID {
+			continue
+		}
+		if review.Correct {
+			stats.CorrectCount++
+		} else {
+			stats.WrongCount++
+		}
+		reviews = append(reviews, review)
+	}
+	// The reviews are in the order they were recorded, which ties are
+	// broken by, as by their IDs in the database
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CreatedAt.Before(reviews[j].CreatedAt) })
+	for i := len(reviews) - 1; i >= 0 && reviews[i].Correct; i-- {
+		stats.Streak++
+	}
+	if len(reviews) > 0 {
+		last := reviews[len(reviews)-1].CreatedAt
+		stats.LastReviewedAt = &last
+	}
+	return stats
+}
+
+// findWord returns the ID of the word other than exceptID with the given
+// text, or 0 if there is none, as the unique index on the words does
+func (s *Store) findWord(portuguese, english string, exceptID int64) int64 {
+	for id, word := range s.words {
+		if id != exceptID && word.Portuguese == portuguese && word.English == english {
+			return id
+		}
+	}
+	return 0
+}
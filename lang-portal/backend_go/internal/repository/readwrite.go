@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+)
+
+// ReadWrite returns a DB that runs the queries reading rows on read and
+// everything else on write. Transactions started with WithTx run on write,
+// reads included, so they see their own writes. With SQLite, read is a pool
+// of read-only connections that WAL mode lets run alongside the single
+// writer.
+func ReadWrite(read, write DB) DB {
+	return &readWriteDB{read: read, write: write}
+}
+
+type readWriteDB struct {
+	read, write DB
+}
+
+func (db *readWriteDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.write.ExecContext(ctx, query, args...)
+}
+
+func (db *readWriteDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.route(query).QueryContext(ctx, query, args...)
+}
+
+func (db *readWriteDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.route(query).QueryRowContext(ctx, query, args...)
+}
+
+// route picks read for SELECT statements and write for the others, such as
+// the INSERT ... RETURNING statements that create rows
+func (db *readWriteDB) route(query string) DB {
+	query = strings.TrimLeftFunc(query, unicode.IsSpace)
+	if len(query) >= len("SELECT") && strings.EqualFold(query[:len("SELECT")], "SELECT") {
+		return db.read
+	}
+	return db.write
+}
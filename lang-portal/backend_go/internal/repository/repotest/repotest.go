@@ -0,0 +1,1356 @@
+// Package repotest is the conformance suite of the repositories the services
+// depend on. Every implementation runs it, so the services can be unit tested
+// against the in-memory fakes and trust the result to hold on SQLite.
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run runs the suite. newRepos must return repositories backed by an empty
+// store on every call.
+func Run(t *testing.T, newRepos func(t *testing.T) service.Repositories) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r service.Repositories)
+	}{
+		{"Words", testWords},
+		{"WordsPagination", testWordsPagination},
+		{"Groups", testGroups},
+		{"GroupWords", testGroupWords},
+		{"StudyActivities", testStudyActivities},
+		{"StudySessions", testStudySessions},
+		{"Reviews", testReviews},
+		{"BackdatedSessions", testBackdatedSessions},
+		{"WordStats", testWordStats},
+		{"SessionActivity", testSessionActivity},
+		{"Gamification", testGamification},
+		{"RecallBuckets", testRecallBuckets},
+		{"Leeches", testLeeches},
+		{"GroupProgress", testGroupProgress},
+		{"Classrooms", testClassrooms},
+		{"AssignmentProgress", testAssignmentProgress},
+		{"Leaderboards", testLeaderboards},
+		{"ForeignKeys", testForeignKeys},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.test(t, newRepos(t))
+		})
+	}
+}
+
+func createWord(t *testing.T, r service.Repositories, portuguese, english string) *models.Word {
+	t.Helper()
+	word, err := r.Words.CreateWord(context.Background(), &models.Word{Portuguese: portuguese, English: english})
+	require.NoError(t, err)
+	require.NotNil(t, word)
+	return word
+}
+
+func createGroup(t *testing.T, r service.Repositories, name string) *models.Group {
+	t.Helper()
+	group, err := r.Groups.CreateGroup(context.Background(), &models.Group{Name: name})
+	require.NoError(t, err)
+	require.NotNil(t, group)
+	return group
+}
+
+func createActivity(t *testing.T, r service.Repositories, name string) *models.StudyActivity {
+	t.Helper()
+	activity := &models.StudyActivity{Name: name, ThumbnailURL: "/" + name + ".png", Description: name + " practice"}
+	require.NoError(t, r.StudyActivities.CreateStudyActivity(context.Background(), activity))
+	require.NotZero(t, activity.ID)
+	return activity
+}
+
+func createSession(t *testing.T, r service.Repositories, groupID, activityID int64, userID *int64) *models.StudySession {
+	t.Helper()
+	session := &models.StudySession{GroupID: groupID, StudyActivityID: activityID, UserID: userID}
+	require.NoError(t, r.StudySessions.CreateStudySession(context.Background(), session))
+	require.NotZero(t, session.ID)
+	require.False(t, session.CreatedAt.IsZero())
+	return session
+}
+
+func review(t *testing.T, r service.Repositories, sessionID, wordID int64, correct bool) {
+	t.Helper()
+	item := &models.WordReviewItem{StudySessionID: sessionID, WordID: wordID, Correct: correct}
+	require.NoError(t, r.StudySessions.CreateReview(context.Background(), item))
+	require.NotZero(t, item.ID)
+}
+
+func testWords(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	word := createWord(t, r, "olá", "hello")
+	assert.Equal(t, "olá", word.Portuguese)
+	assert.Equal(t, "hello", word.English)
+	assert.False(t, word.CreatedAt.IsZero())
+
+	got, err := r.Words.GetWord(ctx, word.ID)
+	require.NoError(t, err)
+	assert.Equal(t, word.ID, got.ID)
+
+	missing, err := r.Words.GetWord(ctx, word.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+	missingStats, err := r.Words.GetWordWithStats(ctx, word.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, missingStats)
+
+	updated, err := r.Words.UpdateWord(ctx, &models.Word{ID: word.ID, Portuguese: "oi", English: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "oi", updated.Portuguese)
+	assert.Equal(t, "hi", updated.English)
+
+	other := createWord(t, r, "adeus", "goodbye")
+
+	found, err := r.Words.FindWord(ctx, "adeus", "goodbye")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, other.ID, found.ID)
+	notFound, err := r.Words.FindWord(ctx, "adeus", "bye")
+	require.NoError(t, err)
+	assert.Nil(t, notFound)
+
+	// Words are unique by their text
+	_, err = r.Words.CreateWord(ctx, &models.Word{Portuguese: "adeus", English: "goodbye"})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+	_, err = r.Words.UpdateWord(ctx, &models.Word{ID: word.ID, Portuguese: "adeus", English: "goodbye"})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+	unchanged, err := r.Words.UpdateWord(ctx, &models.Word{ID: other.ID, Portuguese: "adeus", English: "goodbye"})
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, unchanged.ID, "a word can be saved with its own text")
+
+	count, err := r.Words.CountWords(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	words, err := r.Words.ListWords(ctx)
+	require.NoError(t, err)
+	assert.Len(t, words, 2)
+
+	missingIDs, err := r.Words.FindMissingWordIDs(ctx, []int64{word.ID, other.ID + 1, other.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{other.ID + 1}, missingIDs)
+
+	require.NoError(t, r.Words.DeleteWord(ctx, word.ID))
+	deleted, err := r.Words.GetWord(ctx, word.ID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+	assert.ErrorIs(t, r.Words.DeleteWord(ctx, word.ID), repository.ErrNotFound)
+	count, err = r.Words.CountWords(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func testWordsPagination(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	ids := make([]int64, 5)
+	for i, pt := range []string{"um", "dois", "três", "quatro", "cinco"} {
+		ids[i] = createWord(t, r, pt, pt).ID
+	}
+
+	page, total, err := r.Words.ListWordsWithStatsPaginated(ctx, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, ids[2], page[0].ID)
+	assert.Equal(t, ids[3], page[1].ID)
+
+	last, total, err := r.Words.ListWordsWithStatsPaginated(ctx, 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, last, 1)
+	assert.Equal(t, ids[4], last[0].ID)
+
+	beyond, _, err := r.Words.ListWordsWithStatsPaginated(ctx, 4, 2)
+	require.NoError(t, err)
+	assert.Empty(t, beyond)
+}
+
+func testGroups(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	group := createGroup(t, r, "Basic Greetings")
+	got, err := r.Groups.GetGroup(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Basic Greetings", got.Name)
+
+	missing, err := r.Groups.GetGroup(ctx, group.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+	missingStats, err := r.Groups.GetGroupWithStats(ctx, group.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, missingStats)
+
+	found, err := r.Groups.FindGroupByName(ctx, "basic GREETINGS")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, group.ID, found.ID)
+	notFound, err := r.Groups.FindGroupByName(ctx, "Food")
+	require.NoError(t, err)
+	assert.Nil(t, notFound)
+
+	updated, err := r.Groups.UpdateGroup(ctx, &models.Group{ID: group.ID, Name: "Greetings"})
+	require.NoError(t, err)
+	assert.Equal(t, "Greetings", updated.Name)
+
+	second := createGroup(t, r, "Food")
+	createGroup(t, r, "Travel")
+
+	// Group names are unique regardless of case
+	_, err = r.Groups.CreateGroup(ctx, &models.Group{Name: "FOOD"})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+	_, err = r.Groups.UpdateGroup(ctx, &models.Group{ID: group.ID, Name: "food"})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+	renamed, err := r.Groups.UpdateGroup(ctx, &models.Group{ID: second.ID, Name: "FOOD"})
+	require.NoError(t, err)
+	assert.Equal(t, "FOOD", renamed.Name, "a group can change the case of its own name")
+
+	groups, err := r.Groups.ListGroups(ctx)
+	require.NoError(t, err)
+	assert.Len(t, groups, 3)
+
+	page, total, err := r.Groups.ListGroupsPaginated(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, group.ID, page[0].ID)
+	assert.Equal(t, second.ID, page[1].ID)
+
+	require.NoError(t, r.Groups.DeleteGroup(ctx, group.ID))
+	deleted, err := r.Groups.GetGroup(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+}
+
+func testGroupWords(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	group := createGroup(t, r, "Greetings")
+	hello := createWord(t, r, "olá", "hello")
+	bye := createWord(t, r, "adeus", "goodbye")
+	thanks := createWord(t, r, "obrigado", "thank you")
+
+	require.NoError(t, r.Groups.AddWordsToGroup(ctx, group.ID, []int64{bye.ID, hello.ID}))
+
+	// A duplicate fails the whole batch
+	err := r.Groups.AddWordsToGroup(ctx, group.ID, []int64{thanks.ID, hello.ID})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+
+	count, err := r.Groups.CountGroupWords(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	stats, err := r.Groups.GetGroupWithStats(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.WordCount)
+
+	words, err := r.Groups.GetGroupWords(ctx, group.ID)
+	require.NoError(t, err)
+	require.Len(t, words, 2)
+	assert.Equal(t, hello.ID, words[0].ID, "group words are ordered by ID")
+	assert.Equal(t, bye.ID, words[1].ID)
+
+	page, total, err := r.Groups.GetGroupWordsPaginated(ctx, group.ID, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, page, 1)
+	assert.Equal(t, bye.ID, page[0].ID)
+
+	wordGroups, err := r.Words.GetWordGroups(ctx, hello.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.WordGroup{{ID: group.ID, Name: "Greetings"}}, wordGroups)
+
+	require.NoError(t, r.Groups.RemoveWordFromGroup(ctx, group.ID, hello.ID))
+	count, err = r.Groups.CountGroupWords(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.ErrorIs(t, r.Groups.RemoveWordFromGroup(ctx, group.ID, hello.ID), repository.ErrNotFound)
+
+	// Deleting the group removes its word associations, but not the words
+	require.NoError(t, r.Groups.DeleteGroup(ctx, group.ID))
+	wordGroups, err = r.Words.GetWordGroups(ctx, bye.ID)
+	require.NoError(t, err)
+	assert.Empty(t, wordGroups)
+	count, err = r.Groups.CountGroupWords(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	word, err := r.Words.GetWord(ctx, bye.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, word)
+}
+
+func testStudyActivities(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	flashcards := createActivity(t, r, "flashcards")
+	quiz := &models.StudyActivity{Name: "quiz", ThumbnailURL: "/quiz.png", Description: "quiz practice", Difficulty: 2}
+	require.NoError(t, r.StudyActivities.CreateStudyActivity(ctx, quiz))
+	createActivity(t, r, "typing")
+
+	got, err := r.StudyActivities.GetStudyActivity(ctx, quiz.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "quiz", got.Name)
+	assert.Equal(t, "/quiz.png", got.ThumbnailURL)
+	assert.Equal(t, "quiz practice", got.Description)
+	assert.Equal(t, 2, got.Difficulty)
+
+	missing, err := r.StudyActivities.GetStudyActivity(ctx, flashcards.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	all, err := r.StudyActivities.ListStudyActivities(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	page, err := r.StudyActivities.ListStudyActivitiesPaginated(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, quiz.ID, page[0].ID)
+
+	count, err := r.StudyActivities.CountStudyActivities(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// Activity names are unique
+	err = r.StudyActivities.CreateStudyActivity(ctx, &models.StudyActivity{Name: "quiz"})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+
+	group := createGroup(t, r, "Greetings")
+	createSession(t, r, group.ID, flashcards.ID, nil)
+	createSession(t, r, group.ID, quiz.ID, nil)
+	latest := createSession(t, r, group.ID, quiz.ID, nil)
+
+	sessions, err := r.StudyActivities.GetStudyActivitySessions(ctx, quiz.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, latest.ID, sessions[0].ID, "sessions are listed most recent first")
+	assert.Equal(t, "quiz", sessions[0].ActivityName)
+	assert.Equal(t, "Greetings", sessions[0].GroupName)
+
+	sessionCount, err := r.StudyActivities.CountStudyActivitySessions(ctx, quiz.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sessionCount)
+}
+
+func testStudySessions(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	last, err := r.StudySessions.GetLastStudySession(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+	times, err := r.StudySessions.ListStudyTimes(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, times)
+
+	activity := createActivity(t, r, "flashcards")
+	greetings := createGroup(t, r, "Greetings")
+	food := createGroup(t, r, "Food")
+	before := time.Now().UTC()
+	first := createSession(t, r, greetings.ID, activity.ID, nil)
+	second := createSession(t, r, food.ID, activity.ID, nil)
+	third := createSession(t, r, food.ID, activity.ID, nil)
+	after := time.Now().UTC()
+
+	session, err := r.StudySessions.GetStudySession(ctx, first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	assert.Equal(t, "flashcards", session.ActivityName)
+	assert.Equal(t, "Greetings", session.GroupName)
+	assert.Equal(t, greetings.ID, session.GroupID)
+	assert.Equal(t, activity.ID, session.StudyActivityID)
+	assert.Zero(t, session.ReviewItemsCount)
+	assert.Nil(t, session.EndTime, "a session without reviews has no end time")
+
+	missing, err := r.StudySessions.GetStudySession(ctx, third.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	last, err = r.StudySessions.GetLastStudySession(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.Equal(t, third.ID, last.ID)
+
+	sessions, err := r.StudySessions.ListStudySessions(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, second.ID, sessions[0].ID)
+	assert.Equal(t, first.ID, sessions[1].ID)
+
+	count, err := r.StudySessions.CountStudySessions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	active, err := r.StudySessions.GetTotalActiveGroups(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, active)
+
+	times, err = r.StudySessions.ListStudyTimes(ctx, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, times)
+	assert.LessOrEqual(t, len(times), 2, "sessions started together share a quarter hour")
+	for _, started := range times {
+		assert.Equal(t, time.UTC, started.Location())
+		assert.True(t, started.Equal(started.Truncate(15*time.Minute)), "got %v", started)
+		assert.False(t, started.Before(before.Truncate(15*time.Minute)) || started.After(after), "got %v", started)
+	}
+
+	// A user's study times are their own sessions', the guest's those
+	// started without a user
+	ana, err := r.Users.CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleStudent})
+	require.NoError(t, err)
+	times, err = r.StudySessions.ListStudyTimes(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Empty(t, times)
+	createSession(t, r, greetings.ID, activity.ID, &ana.ID)
+	times, err = r.StudySessions.ListStudyTimes(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Len(t, times, 1)
+
+	// Deleting a group deletes its sessions
+	require.NoError(t, r.Groups.DeleteGroup(ctx, greetings.ID))
+	deleted, err := r.StudySessions.GetStudySession(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+	count, err = r.StudySessions.CountStudySessions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	sessions, err = r.StudySessions.ListStudySessions(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+}
+
+func testReviews(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	activity := createActivity(t, r, "flashcards")
+	group := createGroup(t, r, "Greetings")
+	hello := createWord(t, r, "olá", "hello")
+	bye := createWord(t, r, "adeus", "goodbye")
+	createWord(t, r, "obrigado", "thank you")
+	session := createSession(t, r, group.ID, activity.ID, nil)
+
+	review(t, r, session.ID, hello.ID, true)
+	review(t, r, session.ID, hello.ID, true)
+	review(t, r, session.ID, hello.ID, false)
+	review(t, r, session.ID, bye.ID, false)
+
+	correct, total, err := r.StudySessions.GetWordReviewStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, correct)
+	assert.Equal(t, 4, total)
+
+	studied, err := r.StudySessions.GetTotalDistinctWordsStudied(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, studied)
+
+	stats, err := r.Words.GetWordWithStats(ctx, hello.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.CorrectCount)
+	assert.Equal(t, 1, stats.WrongCount)
+
+	words, _, err := r.Words.ListWordsWithStatsPaginated(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, words, 3)
+	assert.Equal(t, 0, words[1].CorrectCount)
+	assert.Equal(t, 1, words[1].WrongCount)
+	assert.Zero(t, words[2].CorrectCount+words[2].WrongCount)
+
+	require.NoError(t, r.Groups.AddWordsToGroup(ctx, group.ID, []int64{hello.ID}))
+	groupWords, _, err := r.Groups.GetGroupWordsPaginated(ctx, group.ID, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, groupWords, 1)
+	assert.Equal(t, 2, groupWords[0].CorrectCount)
+	assert.Equal(t, 1, groupWords[0].WrongCount)
+
+	detail, err := r.StudySessions.GetStudySession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, detail.ReviewItemsCount)
+	require.NotNil(t, detail.EndTime)
+	assert.False(t, detail.EndTime.Before(detail.CreatedAt.Truncate(time.Second)))
+
+	// Deleting a word deletes its reviews and group memberships
+	require.NoError(t, r.Words.DeleteWord(ctx, hello.ID))
+	correct, total, err = r.StudySessions.GetWordReviewStats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, correct)
+	assert.Equal(t, 1, total)
+	wordCount, err := r.Groups.CountGroupWords(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Zero(t, wordCount)
+}
+
+func testBackdatedSessions(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	activity := createActivity(t, r, "flashcards")
+	group := createGroup(t, r, "Greetings")
+	word := createWord(t, r, "olá", "hello")
+
+	started := time.Date(2025, 3, 10, 23, 30, 0, 0, time.UTC)
+	session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: started}
+	require.NoError(t, r.StudySessions.CreateStudySession(ctx, session))
+	assert.True(t, session.CreatedAt.Equal(started))
+	answered := started.Add(45 * time.Minute)
+	item := &models.WordReviewItem{StudySessionID: session.ID, WordID: word.ID, Correct: true, CreatedAt: answered}
+	require.NoError(t, r.StudySessions.CreateReview(ctx, item))
+	createSession(t, r, group.ID, activity.ID, nil)
+
+	detail, err := r.StudySessions.GetStudySession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.True(t, detail.CreatedAt.Equal(started), "got %v", detail.CreatedAt)
+	require.NotNil(t, detail.EndTime)
+	assert.True(t, detail.EndTime.Equal(answered), "got %v", detail.EndTime)
+
+	last, err := r.StudySessions.GetLastStudySession(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, session.ID, last.ID, "the most recent session is the last one started")
+
+	// In the same quarter hour as the first
+	sameQuarter := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: started.Add(14*time.Minute + 59*time.Second)}
+	require.NoError(t, r.StudySessions.CreateStudySession(ctx, sameQuarter))
+	// 23:50 in Kathmandu, five hours and three quarters ahead
+	kathmandu := time.FixedZone("+0545", (5*60+45)*60)
+	earlier := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: time.Date(2025, 3, 9, 23, 50, 0, 0, kathmandu)}
+	require.NoError(t, r.StudySessions.CreateStudySession(ctx, earlier))
+
+	times, err := r.StudySessions.ListStudyTimes(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, times, 3)
+	assert.True(t, times[0].Equal(time.Date(2025, 3, 9, 18, 0, 0, 0, time.UTC)), "got %v", times[0])
+	assert.Equal(t, "2025-03-09", times[0].In(kathmandu).Format(time.DateOnly))
+	assert.True(t, times[1].Equal(started), "got %v", times[1])
+}
+
+// testWordStats tests the last review and streak of a word, as reviews are
+// recorded out of order and deleted along with their sessions
+func testWordStats(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	activity := createActivity(t, r, "flashcards")
+	group := createGroup(t, r, "Greetings")
+	imported := createGroup(t, r, "Imported")
+	word := createWord(t, r, "olá", "hello")
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: start}
+	require.NoError(t, r.StudySessions.CreateStudySession(ctx, session))
+	past := &models.StudySession{GroupID: imported.ID, StudyActivityID: activity.ID, CreatedAt: start}
+	require.NoError(t, r.StudySessions.CreateStudySession(ctx, past))
+
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	answer := func(sessionID int64, minutes int, correct bool) {
+		t.Helper()
+		item := &models.WordReviewItem{StudySessionID: sessionID, WordID: word.ID, Correct: correct, CreatedAt: at(minutes)}
+		require.NoError(t, r.StudySessions.CreateReview(ctx, item))
+	}
+	assertStats := func(correct, wrong, streak int, last time.Time) {
+		t.Helper()
+		stats, err := r.Words.GetWordWithStats(ctx, word.ID)
+		require.NoError(t, err)
+		assert.Equal(t, correct, stats.CorrectCount, "correct")
+		assert.Equal(t, wrong, stats.WrongCount, "wrong")
+		assert.Equal(t, streak, stats.Streak, "streak")
+		require.NotNil(t, stats.LastReviewedAt)
+		assert.True(t, stats.LastReviewedAt.Equal(last), "last reviewed at %v", stats.LastReviewedAt)
+
+		words, _, err := r.Words.ListWordsWithStatsPaginated(ctx, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, words, 1)
+		assert.Equal(t, stats, words[0])
+	}
+
+	stats, err := r.Words.GetWordWithStats(ctx, word.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stats.LastReviewedAt)
+	assert.Zero(t, stats.Streak)
+
+	answer(session.ID, 1, true)
+	answer(session.ID, 2, false)
+	answer(session.ID, 3, true)
+	answer(session.ID, 5, true)
+	assertStats(3, 1, 2, at(5))
+
+	// A wrong answer recorded late breaks the streak where it falls
+	answer(past.ID, 4, false)
+	assertStats(3, 2, 1, at(5))
+	// and one older than the streak doesn't change it
+	answer(past.ID, 0, true)
+	assertStats(4, 2, 1, at(5))
+
+	// Deleting the reviews recounts the streak
+	require.NoError(t, r.Groups.DeleteGroup(ctx, imported.ID))
+	assertStats(3, 1, 2, at(5))
+	require.NoError(t, r.Groups.DeleteGroup(ctx, group.ID))
+	stats, err = r.Words.GetWordWithStats(ctx, word.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stats.CorrectCount+stats.WrongCount+stats.Streak)
+	assert.Nil(t, stats.LastReviewedAt)
+}
+
+// testSessionActivity tests listing the sessions started in a time range
+// with their review counts
+func testSessionActivity(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	activity := createActivity(t, r, "flashcards")
+	group := createGroup(t, r, "Greetings")
+	word := createWord(t, r, "olá", "hello")
+	startSession := func(at time.Time) int64 {
+		t.Helper()
+		session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: at}
+		require.NoError(t, r.StudySessions.CreateStudySession(ctx, session))
+		return session.ID
+	}
+	answer := func(sessionID int64, at time.Time, correct bool) {
+		t.Helper()
+		item := &models.WordReviewItem{StudySessionID: sessionID, WordID: word.ID, Correct: correct, CreatedAt: at}
+		require.NoError(t, r.StudySessions.CreateReview(ctx, item))
+	}
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	startSession(day.Add(23*time.Hour + 50*time.Minute))
+	early := startSession(day.Add(2 * time.Hour))
+	answer(early, day.Add(2*time.Hour+time.Minute), true)
+	answer(early, day.Add(2*time.Hour+3*time.Minute), false)
+	answer(early, day.Add(2*time.Hour+2*time.Minute), true)
+	startSession(day.Add(-time.Second))
+	startSession(day.Add(24 * time.Hour))
+
+	sessions, err := r.StudySessions.ListSessionActivity(ctx, day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.True(t, sessions[0].StartedAt.Equal(day.Add(2*time.Hour)), "got %v", sessions[0].StartedAt)
+	assert.Equal(t, 3, sessions[0].ReviewCount)
+	assert.Equal(t, 2, sessions[0].CorrectCount)
+	require.NotNil(t, sessions[0].EndedAt)
+	assert.True(t, sessions[0].EndedAt.Equal(day.Add(2*time.Hour+3*time.Minute)), "got %v", sessions[0].EndedAt)
+	assert.True(t, sessions[1].StartedAt.Equal(day.Add(23*time.Hour+50*time.Minute)))
+	assert.Zero(t, sessions[1].ReviewCount)
+	assert.Nil(t, sessions[1].EndedAt)
+
+	// The bounds are instants, whatever their location
+	saoPaulo := time.FixedZone("-03", -3*60*60)
+	sessions, err = r.StudySessions.ListSessionActivity(ctx, day.In(saoPaulo), day.Add(3*time.Hour).In(saoPaulo))
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, 3, sessions[0].ReviewCount)
+
+	sessions, err = r.StudySessions.ListSessionActivity(ctx, day.AddDate(0, 0, 5), day.AddDate(0, 0, 6))
+	require.NoError(t, err)
+	assert.NotNil(t, sessions)
+	assert.Empty(t, sessions)
+}
+
+func testGamification(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	ana, err := r.Users.CreateUser(ctx, &models.User{Name: "Ana"})
+	require.NoError(t, err)
+	activity := createActivity(t, r, "flashcards")
+	group := createGroup(t, r, "Greetings")
+	hello := createWord(t, r, "olá", "hello")
+	bye := createWord(t, r, "adeus", "goodbye")
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	startSession := func(userID *int64, at time.Time) int64 {
+		t.Helper()
+		session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: userID, CreatedAt: at}
+		require.NoError(t, r.StudySessions.CreateStudySession(ctx, session))
+		return session.ID
+	}
+	answer := func(sessionID, wordID int64, correct bool, xp int) {
+		t.Helper()
+		item := &models.WordReviewItem{StudySessionID: sessionID, WordID: wordID, Correct: correct, XP: xp}
+		require.NoError(t, r.StudySessions.CreateReview(ctx, item))
+	}
+	combo := func(sessionID int64) int {
+		t.Helper()
+		combo, err := r.StudySessions.GetSessionCombo(ctx, sessionID)
+		require.NoError(t, err)
+		return combo
+	}
+
+	// The guest's session has a mistake, Ana's are all right
+	guest := startSession(nil, day.Add(9*time.Hour))
+	assert.Zero(t, combo(guest))
+	answer(guest, hello.ID, true, 10)
+	answer(guest, hello.ID, true, 10)
+	assert.Equal(t, 2, combo(guest))
+	answer(guest, bye.ID, false, 0)
+	assert.Zero(t, combo(guest))
+	answer(guest, hello.ID, true, 10)
+	assert.Equal(t, 1, combo(guest))
+	perfect := startSession(&ana.ID, day.Add(10*time.Hour))
+	answer(perfect, hello.ID, true, 10)
+	answer(perfect, bye.ID, true, 10)
+	unended := startSession(&ana.ID, day.AddDate(0, 0, 1).Add(10*time.Hour))
+	answer(unended, bye.ID, true, 10)
+	answer(unended, bye.ID, true, 10)
+	answer(unended, bye.ID, true, 20)
+
+	reviews, err := r.StudySessions.ListWordReviews(ctx, bye.ID)
+	require.NoError(t, err)
+	require.Len(t, reviews, 5)
+	assert.Equal(t, []int{0, 10, 10, 10, 20}, []int{reviews[0].XP, reviews[1].XP, reviews[2].XP, reviews[3].XP, reviews[4].XP})
+
+	// Sessions end once
+	session, err := r.StudySessions.GetStudySession(ctx, perfect)
+	require.NoError(t, err)
+	assert.Nil(t, session.EndedAt)
+	require.Equal(t, &ana.ID, session.UserID)
+	endedAt := day.Add(10*time.Hour + 5*time.Minute)
+	require.NoError(t, r.StudySessions.EndStudySession(ctx, perfect, endedAt))
+	require.NoError(t, r.StudySessions.EndStudySession(ctx, perfect, endedAt.Add(time.Hour)))
+	require.NoError(t, r.StudySessions.EndStudySession(ctx, guest, endedAt))
+	session, err = r.StudySessions.GetStudySession(ctx, perfect)
+	require.NoError(t, err)
+	require.NotNil(t, session.EndedAt)
+	assert.True(t, session.EndedAt.Equal(endedAt), "got %v", session.EndedAt)
+	session, err = r.StudySessions.GetStudySession(ctx, guest)
+	require.NoError(t, err)
+	assert.Nil(t, session.UserID)
+
+	totals, err := r.Gamification.GetLearnerTotals(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.LearnerTotals{Reviews: 5, WordsLearned: 2, XP: 60}, *totals)
+	totals, err = r.Gamification.GetLearnerTotals(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, models.LearnerTotals{Reviews: 4, WordsLearned: 1, XP: 30}, *totals)
+	totals, err = r.Gamification.GetLearnerTotals(ctx, ana.ID+1)
+	require.NoError(t, err)
+	assert.Zero(t, *totals)
+
+	perfectCount, err := r.Gamification.CountPerfectSessions(ctx, ana.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, perfectCount, "the session not yet ended isn't perfect")
+	perfectCount, err = r.Gamification.CountPerfectSessions(ctx, ana.ID, 3)
+	require.NoError(t, err)
+	assert.Zero(t, perfectCount, "perfect sessions need enough reviews")
+	perfectCount, err = r.Gamification.CountPerfectSessions(ctx, 0, 1)
+	require.NoError(t, err)
+	assert.Zero(t, perfectCount)
+
+	times, err := r.StudySessions.ListStudyTimes(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{day.Add(10 * time.Hour), day.AddDate(0, 0, 1).Add(10 * time.Hour)}, times)
+	times, err = r.StudySessions.ListStudyTimes(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{day.Add(9 * time.Hour)}, times)
+
+	activities, err := r.Gamification.ListSessionActivity(ctx, ana.ID, day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, 2, activities[0].ReviewCount)
+	activities, err = r.Gamification.ListSessionActivity(ctx, 0, day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, 4, activities[0].ReviewCount)
+
+	// Goals are set and replaced per learner
+	goal, err := r.Gamification.GetDailyGoal(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Nil(t, goal)
+	require.NoError(t, r.Gamification.SetDailyGoal(ctx, &models.DailyGoal{UserID: ana.ID, Kind: models.GoalReviews, Target: 30, TimeZone: "UTC"}))
+	require.NoError(t, r.Gamification.SetDailyGoal(ctx, &models.DailyGoal{UserID: ana.ID, Kind: models.GoalMinutes, Target: 15, TimeZone: "Europe/Lisbon"}))
+	require.NoError(t, r.Gamification.SetDailyGoal(ctx, &models.DailyGoal{Kind: models.GoalReviews, Target: 50, TimeZone: "UTC"}))
+	goal, err = r.Gamification.GetDailyGoal(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &models.DailyGoal{UserID: ana.ID, Kind: models.GoalMinutes, Target: 15, TimeZone: "Europe/Lisbon"}, goal)
+	goal, err = r.Gamification.GetDailyGoal(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &models.DailyGoal{Kind: models.GoalReviews, Target: 50, TimeZone: "UTC"}, goal)
+
+	// Achievements are unlocked once per learner
+	unlocked, err := r.Gamification.ListUnlockedAchievements(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, unlocked)
+	assert.Empty(t, unlocked)
+	unlock := func(userID int64, key string, at time.Time) {
+		t.Helper()
+		require.NoError(t, r.Gamification.UnlockAchievement(ctx, &models.UnlockedAchievement{UserID: userID, Key: key, UnlockedAt: at}))
+	}
+	unlock(ana.ID, "words_10", day.Add(2*time.Hour))
+	unlock(ana.ID, "first_review", day.Add(time.Hour))
+	unlock(ana.ID, "first_review", day.Add(3*time.Hour))
+	unlock(0, "first_review", day)
+	unlocked, err = r.Gamification.ListUnlockedAchievements(ctx, ana.ID)
+	require.NoError(t, err)
+	require.Len(t, unlocked, 2)
+	assert.Equal(t, "first_review", unlocked[0].Key)
+	assert.True(t, unlocked[0].UnlockedAt.Equal(day.Add(time.Hour)), "the first unlock stands, got %v", unlocked[0].UnlockedAt)
+	assert.Equal(t, "words_10", unlocked[1].Key)
+	unlocked, err = r.Gamification.ListUnlockedAchievements(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, unlocked, 1)
+	assert.Equal(t, int64(0), unlocked[0].UserID)
+
+	// The totals follow the reviews deleted with their word, and with their
+	// sessions
+	require.NoError(t, r.Words.DeleteWord(ctx, hello.ID))
+	totals, err = r.Gamification.GetLearnerTotals(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.LearnerTotals{Reviews: 4, WordsLearned: 1, XP: 50}, *totals)
+	totals, err = r.Gamification.GetLearnerTotals(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, models.LearnerTotals{Reviews: 1}, *totals)
+	require.NoError(t, r.Groups.DeleteGroup(ctx, group.ID))
+	totals, err = r.Gamification.GetLearnerTotals(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Zero(t, *totals)
+}
+
+func testClassrooms(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	teacher, err := r.Users.CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleTeacher})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleTeacher, teacher.Role)
+	zoe, err := r.Users.CreateUser(ctx, &models.User{Name: "Zoe", Role: models.RoleStudent})
+	require.NoError(t, err)
+	bruno, err := r.Users.CreateUser(ctx, &models.User{Name: "Bruno", Role: models.RoleStudent})
+	require.NoError(t, err)
+
+	missingUser, err := r.Users.GetUser(ctx, bruno.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, missingUser)
+
+	classroom, err := r.Classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: teacher.ID})
+	require.NoError(t, err)
+	assert.Equal(t, teacher.ID, classroom.TeacherID)
+	missingClassroom, err := r.Classrooms.GetClassroom(ctx, classroom.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, missingClassroom)
+
+	require.NoError(t, r.Classrooms.AddMembers(ctx, classroom.ID, []int64{zoe.ID, bruno.ID}))
+	require.NoError(t, r.Classrooms.AddMembers(ctx, classroom.ID, []int64{zoe.ID}), "enrolling twice is a no-op")
+	members, err := r.Classrooms.ListMembers(ctx, classroom.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "Bruno", members[0].Name, "members are ordered by name")
+	assert.Equal(t, "Zoe", members[1].Name)
+
+	group := createGroup(t, r, "Greetings")
+	activity := createActivity(t, r, "flashcards")
+	due := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	later, err := r.Classrooms.CreateAssignment(ctx, &models.Assignment{
+		ClassroomID: classroom.ID, GroupID: group.ID, DueAt: due.Add(time.Hour), TargetAccuracy: 80,
+	})
+	require.NoError(t, err)
+	sooner, err := r.Classrooms.CreateAssignment(ctx, &models.Assignment{
+		ClassroomID: classroom.ID, GroupID: group.ID, StudyActivityID: &activity.ID, DueAt: due, TargetAccuracy: 50,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Greetings", sooner.GroupName)
+	assert.Equal(t, "A1", sooner.ClassroomName)
+	require.NotNil(t, sooner.StudyActivityID)
+	assert.Equal(t, activity.ID, *sooner.StudyActivityID)
+	assert.Nil(t, later.StudyActivityID)
+	assert.Equal(t, 50.0, sooner.TargetAccuracy)
+
+	wrongClassroom, err := r.Classrooms.GetAssignment(ctx, classroom.ID+1, sooner.ID)
+	require.NoError(t, err)
+	assert.Nil(t, wrongClassroom)
+
+	assignments, err := r.Classrooms.ListAssignments(ctx, classroom.ID)
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+	assert.Equal(t, sooner.ID, assignments[0].ID, "assignments are ordered by due date")
+	assert.Equal(t, later.ID, assignments[1].ID)
+
+	require.NoError(t, r.Classrooms.RemoveMember(ctx, classroom.ID, zoe.ID))
+	members, err = r.Classrooms.ListMembers(ctx, classroom.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 1)
+	assert.ErrorIs(t, r.Classrooms.RemoveMember(ctx, classroom.ID, zoe.ID), repository.ErrNotFound)
+
+	zoeAssignments, err := r.Classrooms.ListUserAssignments(ctx, zoe.ID)
+	require.NoError(t, err)
+	assert.Empty(t, zoeAssignments)
+	brunoAssignments, err := r.Classrooms.ListUserAssignments(ctx, bruno.ID)
+	require.NoError(t, err)
+	assert.Len(t, brunoAssignments, 2)
+
+	other := createGroup(t, r, "Food")
+	kept, err := r.Classrooms.CreateAssignment(ctx, &models.Assignment{
+		ClassroomID: classroom.ID, GroupID: other.ID, DueAt: due, TargetAccuracy: 50,
+	})
+	require.NoError(t, err)
+	require.NoError(t, r.Classrooms.DeleteGroupAssignments(ctx, group.ID))
+	assignments, err = r.Classrooms.ListAssignments(ctx, classroom.ID)
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, kept.ID, assignments[0].ID)
+}
+
+func testAssignmentProgress(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	teacher, err := r.Users.CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleTeacher})
+	require.NoError(t, err)
+	bruno, err := r.Users.CreateUser(ctx, &models.User{Name: "Bruno", Role: models.RoleStudent})
+	require.NoError(t, err)
+	carla, err := r.Users.CreateUser(ctx, &models.User{Name: "Carla", Role: models.RoleStudent})
+	require.NoError(t, err)
+	classroom, err := r.Classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: teacher.ID})
+	require.NoError(t, err)
+	require.NoError(t, r.Classrooms.AddMembers(ctx, classroom.ID, []int64{carla.ID, bruno.ID}))
+
+	greetings := createGroup(t, r, "Greetings")
+	food := createGroup(t, r, "Food")
+	flashcards := createActivity(t, r, "flashcards")
+	quiz := createActivity(t, r, "quiz")
+	hello := createWord(t, r, "olá", "hello")
+	bye := createWord(t, r, "adeus", "goodbye")
+	bread := createWord(t, r, "pão", "bread")
+	require.NoError(t, r.Groups.AddWordsToGroup(ctx, greetings.ID, []int64{hello.ID, bye.ID}))
+	require.NoError(t, r.Groups.AddWordsToGroup(ctx, food.ID, []int64{bread.ID}))
+
+	// Sessions started before the assignment don't count
+	early := createSession(t, r, greetings.ID, flashcards.ID, &bruno.ID)
+	review(t, r, early.ID, hello.ID, true)
+
+	time.Sleep(10 * time.Millisecond)
+	assignment, err := r.Classrooms.CreateAssignment(ctx, &models.Assignment{
+		ClassroomID: classroom.ID, GroupID: greetings.ID, StudyActivityID: &flashcards.ID,
+		DueAt: time.Now().Add(24 * time.Hour), TargetAccuracy: 50,
+	})
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	session := createSession(t, r, greetings.ID, flashcards.ID, &bruno.ID)
+	review(t, r, session.ID, hello.ID, true)
+	review(t, r, session.ID, hello.ID, false)
+	review(t, r, session.ID, bye.ID, true)
+	// Words outside the group, other activities and other groups don't count
+	review(t, r, session.ID, bread.ID, true)
+	otherActivity := createSession(t, r, greetings.ID, quiz.ID, &bruno.ID)
+	review(t, r, otherActivity.ID, bye.ID, false)
+	otherGroup := createSession(t, r, food.ID, flashcards.ID, &bruno.ID)
+	review(t, r, otherGroup.ID, bread.ID, false)
+	// Nor do anonymous sessions
+	anonymous := createSession(t, r, greetings.ID, flashcards.ID, nil)
+	review(t, r, anonymous.ID, bye.ID, false)
+
+	progress, err := r.Classrooms.GetAssignmentProgress(ctx, assignment, nil)
+	require.NoError(t, err)
+	require.Len(t, progress, 2)
+
+	assert.Equal(t, bruno.ID, progress[0].UserID)
+	assert.Equal(t, "Bruno", progress[0].UserName)
+	assert.Equal(t, 2, progress[0].WordsReviewed)
+	assert.Equal(t, 2, progress[0].CorrectCount)
+	assert.Equal(t, 1, progress[0].WrongCount)
+	assert.NotNil(t, progress[0].LastReviewedAt)
+
+	assert.Equal(t, carla.ID, progress[1].UserID)
+	assert.Zero(t, progress[1].WordsReviewed)
+	assert.Zero(t, progress[1].CorrectCount+progress[1].WrongCount)
+	assert.Nil(t, progress[1].LastReviewedAt)
+
+	mine, err := r.Classrooms.GetAssignmentProgress(ctx, assignment, &carla.ID)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, carla.ID, mine[0].UserID)
+}
+
+// testRecallBuckets tests counting reviews by the interval since the previous
+// review of the same word by the same learner, and listing the reviews of a
+// word
+func testRecallBuckets(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+	day := 24 * time.Hour
+	daySeconds := int64(day / time.Second)
+	bounds := []time.Duration{day, 7 * day, 30 * day}
+
+	activity := createActivity(t, r, "flashcards")
+	group := createGroup(t, r, "Greetings")
+	hello := createWord(t, r, "olá", "hello")
+	bye := createWord(t, r, "tchau", "bye")
+	thanks := createWord(t, r, "obrigado", "thank you")
+	require.NoError(t, r.Groups.AddWordsToGroup(ctx, group.ID, []int64{hello.ID, bye.ID}))
+	session := createSession(t, r, group.ID, activity.ID, nil)
+
+	buckets, err := r.StudySessions.GetRecallBuckets(ctx, models.ReviewScope{}, bounds)
+	require.NoError(t, err)
+	assert.Equal(t, []models.RecallBucket{{MinInterval: day}, {MinInterval: 7 * day}, {MinInterval: 30 * day}}, buckets)
+
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(wordID int64, after time.Duration, correct bool) {
+		t.Helper()
+		item := &models.WordReviewItem{StudySessionID: session.ID, WordID: wordID, Correct: correct, CreatedAt: start.Add(after)}
+		require.NoError(t, r.StudySessions.CreateReview(ctx, item))
+	}
+	// Recorded out of order, and the first reviews of each word don't count
+	at(hello.ID, 14*day+12*time.Hour, false) // 12 days after the previous one
+	at(hello.ID, 0, true)
+	at(hello.ID, 12*time.Hour, true)        // too soon to count
+	at(hello.ID, 2*day+12*time.Hour, true)  // 2 days
+	at(hello.ID, 44*day+12*time.Hour, true) // 30 days
+	at(bye.ID, day, false)
+	at(bye.ID, 2*day, true) // exactly a day
+	at(thanks.ID, day, true)
+	at(thanks.ID, 8*day-time.Second, true) // just under a week
+
+	buckets, err = r.StudySessions.GetRecallBuckets(ctx, models.ReviewScope{}, bounds)
+	require.NoError(t, err)
+	assert.Equal(t, []models.RecallBucket{
+		{MinInterval: day, Reviews: 3, CorrectCount: 3, RecallSeconds: 10*daySeconds - 1},
+		{MinInterval: 7 * day, Reviews: 1},
+		{MinInterval: 30 * day, Reviews: 1, CorrectCount: 1, RecallSeconds: 30 * daySeconds},
+	}, buckets)
+
+	buckets, err = r.StudySessions.GetRecallBuckets(ctx, models.ReviewScope{GroupID: group.ID}, bounds)
+	require.NoError(t, err)
+	assert.Equal(t, 2, buckets[0].Reviews, "thanks isn't in the group")
+	assert.Equal(t, 1, buckets[2].Reviews)
+
+	buckets, err = r.StudySessions.GetRecallBuckets(ctx, models.ReviewScope{WordID: bye.ID}, bounds)
+	require.NoError(t, err)
+	assert.Equal(t, models.RecallBucket{MinInterval: day, Reviews: 1, CorrectCount: 1, RecallSeconds: daySeconds}, buckets[0])
+	assert.Zero(t, buckets[1].Reviews+buckets[2].Reviews)
+
+	reviews, err := r.StudySessions.ListWordReviews(ctx, hello.ID)
+	require.NoError(t, err)
+	require.Len(t, reviews, 5)
+	for i, after := range []time.Duration{0, 12 * time.Hour, 2*day + 12*time.Hour, 14*day + 12*time.Hour, 44*day + 12*time.Hour} {
+		assert.True(t, reviews[i].CreatedAt.Equal(start.Add(after)), "review %d at %v", i, reviews[i].CreatedAt)
+		assert.Equal(t, hello.ID, reviews[i].WordID)
+		assert.Equal(t, session.ID, reviews[i].StudySessionID)
+		assert.Equal(t, i != 3, reviews[i].Correct)
+	}
+	require.NotNil(t, reviews[3].SecondsSincePrevious)
+	assert.Equal(t, 12*daySeconds, *reviews[3].SecondsSincePrevious)
+	reviews, err = r.StudySessions.ListWordReviews(ctx, hello.ID+100)
+	require.NoError(t, err)
+	assert.Empty(t, reviews)
+	assert.NotNil(t, reviews)
+
+	// Intervals are measured between the reviews of each learner, the guest
+	// being one too
+	yes := createWord(t, r, "sim", "yes")
+	ana, err := r.Users.CreateUser(ctx, &models.User{Name: "Ana"})
+	require.NoError(t, err)
+	bruno, err := r.Users.CreateUser(ctx, &models.User{Name: "Bruno"})
+	require.NoError(t, err)
+	anaSession := createSession(t, r, group.ID, activity.ID, &ana.ID)
+	brunoSession := createSession(t, r, group.ID, activity.ID, &bruno.ID)
+	by := func(session *models.StudySession, after time.Duration) {
+		t.Helper()
+		item := &models.WordReviewItem{StudySessionID: session.ID, WordID: yes.ID, Correct: true, CreatedAt: start.Add(after)}
+		require.NoError(t, r.StudySessions.CreateReview(ctx, item))
+	}
+	by(anaSession, 0)
+	by(brunoSession, 3*day) // Bruno's first
+	by(session, 5*day)      // the guest's first
+	by(anaSession, 10*day)  // 10 days after Ana's previous review
+
+	buckets, err = r.StudySessions.GetRecallBuckets(ctx, models.ReviewScope{WordID: yes.ID}, bounds)
+	require.NoError(t, err)
+	assert.Equal(t, []models.RecallBucket{
+		{MinInterval: day},
+		{MinInterval: 7 * day, Reviews: 1, CorrectCount: 1, RecallSeconds: 10 * daySeconds},
+		{MinInterval: 30 * day},
+	}, buckets)
+
+	reviews, err = r.StudySessions.ListWordReviews(ctx, yes.ID)
+	require.NoError(t, err)
+	require.Len(t, reviews, 4)
+	intervals := make([]*int64, len(reviews))
+	for i, review := range reviews {
+		intervals[i] = review.SecondsSincePrevious
+	}
+	tenDays := 10 * daySeconds
+	assert.Equal(t, []*int64{nil, nil, nil, &tenDays}, intervals)
+}
+
+// testGroupProgress tests counting the words of groups by state
+func testGroupProgress(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+	day := 24 * time.Hour
+	thresholds := models.MasteryThresholds{MasteredStreak: 2, LapsedAfter: 30 * day}
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	activity := createActivity(t, r, "flashcards")
+	group := createGroup(t, r, "Greetings")
+	other := createGroup(t, r, "Numbers")
+	unstudied := createGroup(t, r, "Empty")
+	hello := createWord(t, r, "olá", "hello")
+	bye := createWord(t, r, "tchau", "bye")
+	yes := createWord(t, r, "sim", "yes")
+	no := createWord(t, r, "não", "no")
+	thanks := createWord(t, r, "obrigado", "thank you")
+	require.NoError(t, r.Groups.AddWordsToGroup(ctx, group.ID, []int64{hello.ID, bye.ID, yes.ID, no.ID, thanks.ID}))
+	require.NoError(t, r.Groups.AddWordsToGroup(ctx, other.ID, []int64{hello.ID}))
+
+	started := now.Add(-40 * day)
+	session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: started}
+	require.NoError(t, r.StudySessions.CreateStudySession(ctx, session))
+	latest := now.Add(-time.Hour)
+	require.NoError(t, r.StudySessions.CreateStudySession(ctx, &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: latest}))
+	answer := func(word *models.Word, at time.Time, correct bool) {
+		t.Helper()
+		item := &models.WordReviewItem{StudySessionID: session.ID, WordID: word.ID, Correct: correct, CreatedAt: at}
+		require.NoError(t, r.StudySessions.CreateReview(ctx, item))
+	}
+	// hello is new, bye is being learned, yes mastered, no mastered 31
+	// days ago and lapsed since, and thanks was forgotten after being mastered
+	answer(bye, now.Add(-day), true)
+	answer(yes, now.Add(-2*day), true)
+	answer(yes, now.Add(-30*day), true)
+	answer(no, now.Add(-31*day), true)
+	answer(no, now.Add(-32*day), true)
+	answer(thanks, now.Add(-3*day), true)
+	answer(thanks, now.Add(-2*day), true)
+	answer(thanks, now.Add(-day), false)
+
+	progress, err := r.Groups.GetGroupProgress(ctx, []int64{unstudied.ID, group.ID, other.ID}, thresholds, now)
+	require.NoError(t, err)
+	require.Len(t, progress, 3)
+	assert.Equal(t, models.GroupProgress{GroupID: unstudied.ID}, progress[0])
+	assert.Equal(t, models.GroupProgress{GroupID: other.ID, WordCount: 1, NewCount: 1}, progress[2])
+	want := models.GroupProgress{GroupID: group.ID, WordCount: 5, NewCount: 1, MasteredCount: 1, LapsedCount: 1}
+	require.NotNil(t, progress[1].LastStudiedAt)
+	assert.True(t, progress[1].LastStudiedAt.Equal(latest), "got %v", progress[1].LastStudiedAt)
+	progress[1].LastStudiedAt = nil
+	assert.Equal(t, want, progress[1])
+
+	progress, err = r.Groups.GetGroupProgress(ctx, nil, thresholds, now)
+	require.NoError(t, err)
+	assert.Empty(t, progress)
+}
+
+// testLeeches tests listing the words that keep being answered wrong
+func testLeeches(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+	criteria := models.LeechCriteria{MinWrong: 4, RecoveredStreak: 3}
+
+	activity := createActivity(t, r, "flashcards")
+	group := createGroup(t, r, "Greetings")
+	hello := createWord(t, r, "olá", "hello")
+	bye := createWord(t, r, "tchau", "bye")
+	thanks := createWord(t, r, "obrigado", "thank you")
+	please := createWord(t, r, "por favor", "please")
+	require.NoError(t, r.Groups.AddWordsToGroup(ctx, group.ID, []int64{hello.ID, thanks.ID}))
+	session := createSession(t, r, group.ID, activity.ID, nil)
+
+	leeches, err := r.Words.ListLeeches(ctx, 0, criteria)
+	require.NoError(t, err)
+	assert.Empty(t, leeches)
+	assert.NotNil(t, leeches)
+
+	answer := func(wordID int64, answers ...bool) {
+		t.Helper()
+		for _, correct := range answers {
+			review(t, r, session.ID, wordID, correct)
+		}
+	}
+	answer(hello.ID, false, false, false, false)
+	answer(bye.ID, false, false, false, false, false, true, true)
+	answer(thanks.ID, true, true, true, true, false, false, false, false) // as often right as wrong
+	answer(please.ID, false, false, false)                                // not failed often enough
+
+	leeches, err = r.Words.ListLeeches(ctx, 0, criteria)
+	require.NoError(t, err)
+	require.Len(t, leeches, 2)
+	assert.Equal(t, bye.ID, leeches[0].ID, "the most failed first")
+	assert.Equal(t, 5, leeches[0].WrongCount)
+	assert.Equal(t, 2, leeches[0].CorrectCount)
+	assert.Equal(t, 2, leeches[0].Streak)
+	assert.Equal(t, hello.ID, leeches[1].ID)
+
+	leeches, err = r.Words.ListLeeches(ctx, group.ID, criteria)
+	require.NoError(t, err)
+	require.Len(t, leeches, 1)
+	assert.Equal(t, hello.ID, leeches[0].ID)
+
+	// Recovered after three right answers in a row
+	answer(bye.ID, true)
+	leeches, err = r.Words.ListLeeches(ctx, 0, criteria)
+	require.NoError(t, err)
+	require.Len(t, leeches, 1)
+	assert.Equal(t, hello.ID, leeches[0].ID)
+}
+
+func testLeaderboards(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	teacher, err := r.Users.CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleTeacher})
+	require.NoError(t, err)
+	zoe, err := r.Users.CreateUser(ctx, &models.User{Name: "Zoe", Role: models.RoleStudent})
+	require.NoError(t, err)
+	bruno, err := r.Users.CreateUser(ctx, &models.User{Name: "Bruno", Role: models.RoleStudent})
+	require.NoError(t, err)
+	eva, err := r.Users.CreateUser(ctx, &models.User{Name: "Eva", Role: models.RoleStudent})
+	require.NoError(t, err)
+	classroom, err := r.Classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: teacher.ID})
+	require.NoError(t, err)
+	empty, err := r.Classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A2", TeacherID: teacher.ID})
+	require.NoError(t, err)
+	require.NoError(t, r.Classrooms.AddMembers(ctx, classroom.ID, []int64{zoe.ID, bruno.ID}))
+
+	ids, err := r.Classrooms.ListClassroomIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{classroom.ID, empty.ID}, ids)
+
+	group := createGroup(t, r, "Greetings")
+	activity := createActivity(t, r, "flashcards")
+	hello := createWord(t, r, "olá", "hello")
+	bye := createWord(t, r, "adeus", "goodbye")
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	lastWeek := now.AddDate(0, 0, -3)
+	answer := func(userID *int64, at time.Time, answers ...models.WordReviewItem) {
+		t.Helper()
+		session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: userID, CreatedAt: at}
+		require.NoError(t, r.StudySessions.CreateStudySession(ctx, session))
+		for i, item := range answers {
+			item.StudySessionID = session.ID
+			item.CreatedAt = at.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, r.StudySessions.CreateReview(ctx, &item))
+		}
+	}
+	right := func(word *models.Word, xp int) models.WordReviewItem {
+		return models.WordReviewItem{WordID: word.ID, Correct: true, XP: xp}
+	}
+	wrong := func(word *models.Word) models.WordReviewItem {
+		return models.WordReviewItem{WordID: word.ID}
+	}
+
+	// Zoe masters hello last week, and goodbye today after a mistake. Only
+	// the sessions of members count.
+	answer(&zoe.ID, lastWeek, right(hello, 10), right(hello, 10))
+	answer(&zoe.ID, now.Add(-time.Hour), right(bye, 10), wrong(bye), right(bye, 10), right(bye, 20), right(hello, 30))
+	answer(&bruno.ID, now.Add(-2*time.Hour), wrong(hello))
+	answer(&eva.ID, now.Add(-time.Hour), right(hello, 10), right(hello, 10))
+	answer(nil, now.Add(-time.Hour), right(hello, 10), right(hello, 10))
+
+	standings, err := r.Leaderboards.ComputeStandings(ctx, classroom.ID, now.Add(-24*time.Hour), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []models.LeaderboardStanding{
+		{UserID: zoe.ID, XP: 70, Reviews: 5, CorrectCount: 4, WordsMastered: 1},
+		{UserID: bruno.ID, Reviews: 1},
+	}, standings)
+	allTime, err := r.Leaderboards.ComputeStandings(ctx, classroom.ID, time.Time{}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []models.LeaderboardStanding{
+		{UserID: zoe.ID, XP: 90, Reviews: 7, CorrectCount: 6, WordsMastered: 2},
+		{UserID: bruno.ID, Reviews: 1},
+	}, allTime)
+	standings, err = r.Leaderboards.ComputeStandings(ctx, classroom.ID, time.Time{}, 4)
+	require.NoError(t, err)
+	assert.Zero(t, standings[0].WordsMastered, "hello was answered right 3 times in a row, goodbye twice")
+	standings, err = r.Leaderboards.ComputeStandings(ctx, empty.ID, time.Time{}, 2)
+	require.NoError(t, err)
+	assert.NotNil(t, standings)
+	assert.Empty(t, standings)
+
+	// Snapshots are replaced, and read with the current members and profiles
+	snapshot, err := r.Leaderboards.GetSnapshot(ctx, classroom.ID, models.PeriodWeekly)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+	require.NoError(t, r.Leaderboards.SaveSnapshot(ctx, &models.LeaderboardSnapshot{
+		ClassroomID: classroom.ID, Period: models.PeriodWeekly, ComputedAt: now.Add(-time.Hour),
+		Standings: []models.LeaderboardStanding{{UserID: eva.ID, XP: 500}},
+	}))
+	require.NoError(t, r.Leaderboards.SaveSnapshot(ctx, &models.LeaderboardSnapshot{
+		ClassroomID: classroom.ID, Period: models.PeriodWeekly, ComputedAt: now, Standings: allTime,
+	}))
+	require.NoError(t, r.Leaderboards.SaveSnapshot(ctx, &models.LeaderboardSnapshot{
+		ClassroomID: classroom.ID, Period: models.PeriodDaily, ComputedAt: now, Standings: allTime[:1],
+	}))
+	snapshot, err = r.Leaderboards.GetSnapshot(ctx, classroom.ID, models.PeriodWeekly)
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	assert.True(t, snapshot.ComputedAt.Equal(now), "got %v", snapshot.ComputedAt)
+	assert.Equal(t, models.PeriodWeekly, snapshot.Period)
+	assert.Equal(t, []int64{zoe.ID, bruno.ID}, []int64{snapshot.Standings[0].UserID, snapshot.Standings[1].UserID},
+		"eva's standing was replaced")
+	assert.Equal(t, "", snapshot.Standings[0].DisplayName)
+	assert.False(t, snapshot.Standings[0].OptOut)
+
+	profile, err := r.Leaderboards.GetProfile(ctx, zoe.ID)
+	require.NoError(t, err)
+	assert.Nil(t, profile)
+	require.NoError(t, r.Leaderboards.SetProfile(ctx, &models.LeaderboardProfile{UserID: zoe.ID, DisplayName: "Blue Fox"}))
+	require.NoError(t, r.Leaderboards.SetProfile(ctx, &models.LeaderboardProfile{UserID: zoe.ID, DisplayName: "Red Fox", OptOut: true}))
+	require.NoError(t, r.Leaderboards.SetProfile(ctx, &models.LeaderboardProfile{UserID: bruno.ID}))
+	profile, err = r.Leaderboards.GetProfile(ctx, zoe.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &models.LeaderboardProfile{UserID: zoe.ID, DisplayName: "Red Fox", OptOut: true}, profile)
+	require.NoError(t, r.Classrooms.RemoveMember(ctx, classroom.ID, bruno.ID))
+
+	snapshot, err = r.Leaderboards.GetSnapshot(ctx, classroom.ID, models.PeriodWeekly)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Standings, 1, "bruno has left the classroom")
+	assert.Equal(t, models.LeaderboardStanding{
+		UserID: zoe.ID, XP: 90, Reviews: 7, CorrectCount: 6, WordsMastered: 2, DisplayName: "Red Fox", OptOut: true,
+	}, snapshot.Standings[0])
+	snapshot, err = r.Leaderboards.GetSnapshot(ctx, classroom.ID, models.PeriodDaily)
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Standings, 1)
+	snapshot, err = r.Leaderboards.GetSnapshot(ctx, empty.ID, models.PeriodWeekly)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
+
+// testForeignKeys tests that the writes referencing a record that doesn't
+// exist fail with repository.ErrForeignKey, writing nothing
+func testForeignKeys(t *testing.T, r service.Repositories) {
+	ctx := context.Background()
+
+	teacher, err := r.Users.CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleTeacher})
+	require.NoError(t, err)
+	student, err := r.Users.CreateUser(ctx, &models.User{Name: "Bruno", Role: models.RoleStudent})
+	require.NoError(t, err)
+	group := createGroup(t, r, "Greetings")
+	activity := createActivity(t, r, "flashcards")
+	missing := int64(999)
+
+	for _, session := range []*models.StudySession{
+		{GroupID: missing, StudyActivityID: activity.ID},
+		{GroupID: group.ID, StudyActivityID: missing},
+		{GroupID: group.ID, StudyActivityID: activity.ID, UserID: &missing},
+	} {
+		assert.ErrorIs(t, r.StudySessions.CreateStudySession(ctx, session), repository.ErrForeignKey)
+	}
+	count, err := r.StudySessions.CountStudySessions(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = r.Classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: missing})
+	assert.ErrorIs(t, err, repository.ErrForeignKey)
+	classroom, err := r.Classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: teacher.ID})
+	require.NoError(t, err)
+
+	err = r.Classrooms.AddMembers(ctx, classroom.ID, []int64{student.ID, missing})
+	assert.ErrorIs(t, err, repository.ErrForeignKey)
+	members, err := r.Classrooms.ListMembers(ctx, classroom.ID)
+	require.NoError(t, err)
+	assert.Empty(t, members, "no member is added unless all are")
+	assert.ErrorIs(t, r.Classrooms.AddMembers(ctx, missing, []int64{student.ID}), repository.ErrForeignKey)
+
+	due := time.Now().Add(24 * time.Hour)
+	for _, assignment := range []*models.Assignment{
+		{ClassroomID: classroom.ID, GroupID: missing, DueAt: due, TargetAccuracy: 80},
+		{ClassroomID: classroom.ID, GroupID: group.ID, StudyActivityID: &missing, DueAt: due, TargetAccuracy: 80},
+		{ClassroomID: missing, GroupID: group.ID, DueAt: due, TargetAccuracy: 80},
+	} {
+		_, err = r.Classrooms.CreateAssignment(ctx, assignment)
+		assert.ErrorIs(t, err, repository.ErrForeignKey)
+	}
+	assignments, err := r.Classrooms.ListAssignments(ctx, classroom.ID)
+	require.NoError(t, err)
+	assert.Empty(t, assignments)
+}
@@ -1,27 +1,27 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-	"time"
 
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 )
 
 type StudyActivityRepository struct {
-	db *sql.DB
+	db DB
 }
 
-func NewStudyActivityRepository(db *sql.DB) *StudyActivityRepository {
+func NewStudyActivityRepository(db DB) *StudyActivityRepository {
 	return &StudyActivityRepository{db: db}
 }
 
-func (r *StudyActivityRepository) GetStudyActivity(id int64) (*models.StudyActivity, error) {
+func (r *StudyActivityRepository) GetStudyActivity(ctx context.Context, id int64) (*models.StudyActivity, error) {
 	activity := &models.StudyActivity{}
-	err := r.db.QueryRow(`
-		SELECT id, name, thumbnail_url, description, created_at
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, thumbnail_url, description, difficulty, created_at
 		FROM study_activities
 		WHERE id = ?
-	`, id).Scan(&activity.ID, &activity.Name, &activity.ThumbnailURL, &activity.Description, &activity.CreatedAt)
+	`, id).Scan(&activity.ID, &activity.Name, &activity.ThumbnailURL, &activity.Description, &activity.Difficulty, &activity.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -31,9 +31,9 @@ func (r *StudyActivityRepository) GetStudyActivity(id int64) (*models.StudyActiv
 	return activity, nil
 }
 
-func (r *StudyActivityRepository) ListStudyActivities() ([]models.StudyActivity, error) {
-	rows, err := r.db.Query(`
-		SELECT id, name, thumbnail_url, description, created_at
+func (r *StudyActivityRepository) ListStudyActivities(ctx context.Context) ([]models.StudyActivity, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, thumbnail_url, description, difficulty, created_at
 		FROM study_activities
 		ORDER BY id
 	`)
@@ -47,7 +47,7 @@ func (r *StudyActivityRepository) ListStudyActivities() ([]models.StudyActivity,
 		var activity models.StudyActivity
 		err := rows.Scan(
 			&activity.ID, &activity.Name, &activity.ThumbnailURL,
-			&activity.Description, &activity.CreatedAt,
+			&activity.Description, &activity.Difficulty, &activity.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -57,28 +57,59 @@ func (r *StudyActivityRepository) ListStudyActivities() ([]models.StudyActivity,
 	return activities, nil
 }
 
-func (r *StudyActivityRepository) CreateStudyActivity(activity *models.StudyActivity) error {
-	result, err := r.db.Exec(`
-		INSERT INTO study_activities (name, thumbnail_url, description, created_at)
-		VALUES (?, ?, ?, ?)
-	`, activity.Name, activity.ThumbnailURL, activity.Description, time.Now())
+// ListStudyActivitiesPaginated returns a page of study activities ordered by ID
+func (r *StudyActivityRepository) ListStudyActivitiesPaginated(ctx context.Context, offset, limit int) ([]models.StudyActivity, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, thumbnail_url, description, difficulty, created_at
+		FROM study_activities
+		ORDER BY id
+		LIMIT ? OFFSET ?
+	`, limit, offset)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	id, err := result.LastInsertId()
+	var activities []models.StudyActivity
+	for rows.Next() {
+		var activity models.StudyActivity
+		err := rows.Scan(
+			&activity.ID, &activity.Name, &activity.ThumbnailURL,
+			&activity.Description, &activity.Difficulty, &activity.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, activity)
+	}
+	return activities, rows.Err()
+}
+
+func (r *StudyActivityRepository) CountStudyActivities(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM study_activities").Scan(&count)
+	return count, err
+}
+
+func (r *StudyActivityRepository) CreateStudyActivity(ctx context.Context, activity *models.StudyActivity) error {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO study_activities (name, thumbnail_url, description, difficulty, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`, activity.Name, activity.ThumbnailURL, activity.Description, activity.Difficulty, utcNow()).Scan(&id)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	activity.ID = id
 	return nil
 }
 
-func (r *StudyActivityRepository) GetStudyActivitySessions(activityID int64, offset, limit int) ([]models.StudySessionDetail, error) {
-	rows, err := r.db.Query(`
+func (r *StudyActivityRepository) GetStudyActivitySessions(ctx context.Context, activityID int64, offset, limit int) ([]models.StudySessionDetail, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
-			ss.id, ss.group_id, ss.study_activity_id, ss.created_at,
+			ss.id, ss.group_id, ss.study_activity_id, ss.user_id, ss.created_at, ss.ended_at,
 			sa.name as activity_name,
 			g.name as group_name,
 			COUNT(wri.id) as review_items_count,
@@ -88,7 +119,7 @@ func (r *StudyActivityRepository) GetStudyActivitySessions(activityID int64, off
 		JOIN groups g ON ss.group_id = g.id
 		LEFT JOIN word_review_items wri ON ss.id = wri.study_session_id
 		WHERE ss.study_activity_id = ?
-		GROUP BY ss.id
+		GROUP BY ss.id, sa.name, g.name
 		ORDER BY ss.created_at DESC
 		LIMIT ? OFFSET ?
 	`, activityID, limit, offset)
@@ -100,26 +131,24 @@ func (r *StudyActivityRepository) GetStudyActivitySessions(activityID int64, off
 	var sessions []models.StudySessionDetail
 	for rows.Next() {
 		var session models.StudySessionDetail
-		var endTime sql.NullTime
+		var endTime sql.NullString
 		err := rows.Scan(
-			&session.ID, &session.GroupID, &session.StudyActivityID,
-			&session.CreatedAt, &session.ActivityName, &session.GroupName,
+			&session.ID, &session.GroupID, &session.StudyActivityID, &session.UserID,
+			&session.CreatedAt, &session.EndedAt, &session.ActivityName, &session.GroupName,
 			&session.ReviewItemsCount, &endTime,
 		)
 		if err != nil {
 			return nil, err
 		}
-		if endTime.Valid {
-			session.EndTime = &endTime.Time
-		}
+		session.EndTime = parseTimestamp(endTime)
 		sessions = append(sessions, session)
 	}
 	return sessions, nil
 }
 
-func (r *StudyActivityRepository) CountStudyActivitySessions(activityID int64) (int, error) {
+func (r *StudyActivityRepository) CountStudyActivitySessions(ctx context.Context, activityID int64) (int, error) {
 	var count int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM study_sessions
 		WHERE study_activity_id = ?
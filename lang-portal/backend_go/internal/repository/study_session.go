@@ -1,78 +1,142 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 )
 
 type StudySessionRepository struct {
-	db *sql.DB
+	db DB
 }
 
-func (r *StudySessionRepository) GetTotalDistinctWordsStudied() (int, error) {
+func (r *StudySessionRepository) GetTotalDistinctWordsStudied(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRow(`
-		SELECT COUNT(DISTINCT word_id)
-		FROM word_review_items
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM word_stats
 	`).Scan(&count)
 	return count, err
 }
 
-func (r *StudySessionRepository) GetWordReviewStats() (correct int, total int, err error) {
-	err = r.db.QueryRow(`
-		SELECT 
-			COUNT(CASE WHEN correct = 1 THEN 1 END) as correct,
-			COUNT(*) as total
-		FROM word_review_items
+func (r *StudySessionRepository) GetWordReviewStats(ctx context.Context) (correct int, total int, err error) {
+	err = r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(correct_count), 0) as correct,
+			COALESCE(SUM(correct_count + wrong_count), 0) as total
+		FROM word_stats
 	`).Scan(&correct, &total)
 	return
 }
 
-func (r *StudySessionRepository) GetTotalActiveGroups() (int, error) {
+func (r *StudySessionRepository) GetTotalActiveGroups(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT COUNT(DISTINCT group_id)
 		FROM study_sessions
 	`).Scan(&count)
 	return count, err
 }
 
-func (r *StudySessionRepository) GetStudyStreak() (int, error) {
-	var streak int
-	err := r.db.QueryRow(`
-		WITH RECURSIVE dates AS (
-			SELECT date(created_at) as study_date
-			FROM study_sessions
-			GROUP BY date(created_at)
-			ORDER BY study_date DESC
-		),
-		streak_calc AS (
-			SELECT study_date, 1 as streak
-			FROM dates
-			WHERE study_date = date('now')
-			UNION ALL
-			SELECT d.study_date, s.streak + 1
-			FROM dates d
-			JOIN streak_calc s ON date(d.study_date, '+1 day') = date(s.study_date)
-		)
-		SELECT COALESCE(MAX(streak), 0)
-		FROM streak_calc
-	`).Scan(&streak)
-	return streak, err
+// studyTimeSeconds is the granularity of ListStudyTimes, a quarter hour
+const studyTimeSeconds = 15 * 60
+
+// learnerFilter returns the condition on study_sessions ss selecting the
+// sessions of the learner with ID userID, and its arguments
+func learnerFilter(userID int64) (string, []interface{}) {
+	if userID == 0 {
+		return "ss.user_id IS NULL", nil
+	}
+	return "ss.user_id = ?", []interface{}{userID}
+}
+
+// ListStudyTimes returns the quarter hours in which the learner with ID
+// userID, the guest if 0, started sessions, oldest first. Every UTC offset in
+// use is a whole number of quarter hours, so each falls on the same day as
+// its sessions in any time zone.
+func (r *StudySessionRepository) ListStudyTimes(ctx context.Context, userID int64) ([]time.Time, error) {
+	filter, args := learnerFilter(userID)
+	return listStudyTimes(ctx, r.db, filter, args...)
+}
+
+// listStudyTimes returns the quarter hours in which the sessions matching
+// filter, a condition on study_sessions ss, were started, oldest first
+func listStudyTimes(ctx context.Context, db DB, filter string, args ...interface{}) ([]time.Time, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT DISTINCT `+dialectOf(db).Epoch("ss.created_at")+` / ? AS slot
+		FROM study_sessions ss
+		WHERE `+filter+`
+		ORDER BY slot
+	`, append([]interface{}{studyTimeSeconds}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := []time.Time{}
+	for rows.Next() {
+		var slot int64
+		if err := rows.Scan(&slot); err != nil {
+			return nil, err
+		}
+		times = append(times, time.Unix(slot*studyTimeSeconds, 0).UTC())
+	}
+	return times, rows.Err()
+}
+
+// ListSessionActivity returns the sessions started from from until before
+// to, oldest first, with their review counts
+func (r *StudySessionRepository) ListSessionActivity(ctx context.Context, from, to time.Time) ([]models.SessionActivity, error) {
+	return listSessionActivity(ctx, r.db, from, to, "1 = 1")
 }
 
-func NewStudySessionRepository(db *sql.DB) *StudySessionRepository {
+// listSessionActivity returns the sessions matching filter, a condition on
+// study_sessions ss, started from from until before to
+func listSessionActivity(ctx context.Context, db DB, from, to time.Time, filter string, args ...interface{}) ([]models.SessionActivity, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			ss.created_at,
+			COUNT(wri.id) as review_count,
+			COALESCE(SUM(CASE WHEN wri.correct THEN 1 ELSE 0 END), 0) as correct_count,
+			MAX(wri.created_at) as ended_at
+		FROM study_sessions ss
+		LEFT JOIN word_review_items wri ON wri.study_session_id = ss.id
+		WHERE ss.created_at >= ? AND ss.created_at < ? AND `+filter+`
+		GROUP BY ss.id, ss.created_at
+		ORDER BY ss.created_at, ss.id
+	`, append([]interface{}{from.UTC(), to.UTC()}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := []models.SessionActivity{}
+	for rows.Next() {
+		var session models.SessionActivity
+		var endedAt sql.NullString
+		if err := rows.Scan(&session.StartedAt, &session.ReviewCount, &session.CorrectCount, &endedAt); err != nil {
+			return nil, err
+		}
+		session.EndedAt = parseTimestamp(endedAt)
+		activity = append(activity, session)
+	}
+	return activity, rows.Err()
+}
+
+func NewStudySessionRepository(db DB) *StudySessionRepository {
 	return &StudySessionRepository{db: db}
 }
 
-func (r *StudySessionRepository) GetStudySession(id int64) (*models.StudySessionDetail, error) {
+func (r *StudySessionRepository) GetStudySession(ctx context.Context, id int64) (*models.StudySessionDetail, error) {
 	session := &models.StudySessionDetail{}
-	var endTime sql.NullTime
-	err := r.db.QueryRow(`
+	var endTime sql.NullString
+	err := r.db.QueryRowContext(ctx, `
 		SELECT 
-			ss.id, ss.group_id, ss.study_activity_id, ss.created_at,
+			ss.id, ss.group_id, ss.study_activity_id, ss.user_id, ss.created_at, ss.ended_at,
 			sa.name as activity_name,
 			g.name as group_name,
 			COUNT(wri.id) as review_items_count,
@@ -82,10 +146,10 @@ func (r *StudySessionRepository) GetStudySession(id int64) (*models.StudySession
 		JOIN groups g ON ss.group_id = g.id
 		LEFT JOIN word_review_items wri ON ss.id = wri.study_session_id
 		WHERE ss.id = ?
-		GROUP BY ss.id
+		GROUP BY ss.id, sa.name, g.name
 	`, id).Scan(
-		&session.ID, &session.GroupID, &session.StudyActivityID,
-		&session.CreatedAt, &session.ActivityName, &session.GroupName,
+		&session.ID, &session.GroupID, &session.StudyActivityID, &session.UserID,
+		&session.CreatedAt, &session.EndedAt, &session.ActivityName, &session.GroupName,
 		&session.ReviewItemsCount, &endTime,
 	)
 	if err == sql.ErrNoRows {
@@ -94,16 +158,14 @@ func (r *StudySessionRepository) GetStudySession(id int64) (*models.StudySession
 	if err != nil {
 		return nil, err
 	}
-	if endTime.Valid {
-		session.EndTime = &endTime.Time
-	}
+	session.EndTime = parseTimestamp(endTime)
 	return session, nil
 }
 
-func (r *StudySessionRepository) ListStudySessions(offset, limit int) ([]models.StudySessionDetail, error) {
-	rows, err := r.db.Query(`
+func (r *StudySessionRepository) ListStudySessions(ctx context.Context, offset, limit int) ([]models.StudySessionDetail, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
-			ss.id, ss.group_id, ss.study_activity_id, ss.created_at,
+			ss.id, ss.group_id, ss.study_activity_id, ss.user_id, ss.created_at, ss.ended_at,
 			sa.name as activity_name,
 			g.name as group_name,
 			COUNT(wri.id) as review_items_count,
@@ -112,7 +174,7 @@ func (r *StudySessionRepository) ListStudySessions(offset, limit int) ([]models.
 		JOIN study_activities sa ON ss.study_activity_id = sa.id
 		JOIN groups g ON ss.group_id = g.id
 		LEFT JOIN word_review_items wri ON ss.id = wri.study_session_id
-		GROUP BY ss.id
+		GROUP BY ss.id, sa.name, g.name
 		ORDER BY ss.created_at DESC
 		LIMIT ? OFFSET ?
 	`, limit, offset)
@@ -127,8 +189,8 @@ func (r *StudySessionRepository) ListStudySessions(offset, limit int) ([]models.
 		var endTimeStr sql.NullString
 		var groupID, studyActivityID int64
 		err := rows.Scan(
-			&session.ID, &groupID, &studyActivityID,
-			&session.CreatedAt, &session.ActivityName, &session.GroupName,
+			&session.ID, &groupID, &studyActivityID, &session.UserID,
+			&session.CreatedAt, &session.EndedAt, &session.ActivityName, &session.GroupName,
 			&session.ReviewItemsCount, &endTimeStr,
 		)
 		if err != nil {
@@ -138,57 +200,48 @@ func (r *StudySessionRepository) ListStudySessions(offset, limit int) ([]models.
 		session.GroupID = groupID
 		session.StudyActivityID = studyActivityID
 
-		// Handle the end time string
-		if endTimeStr.Valid {
-			endTime, err := time.Parse(time.RFC3339, endTimeStr.String)
-			if err == nil {
-				session.EndTime = &endTime
-			}
-		}
+		session.EndTime = parseTimestamp(endTimeStr)
 
 		sessions = append(sessions, session)
 	}
 	return sessions, nil
 }
 
-func (r *StudySessionRepository) CountStudySessions() (int, error) {
+func (r *StudySessionRepository) CountStudySessions(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM study_sessions
 	`).Scan(&count)
 	return count, err
 }
 
-func (r *StudySessionRepository) CreateStudySession(session *models.StudySession) error {
-	result, err := r.db.Exec(`
-		INSERT INTO study_sessions (group_id, study_activity_id, created_at)
-		VALUES (?, ?, ?)
-	`, session.GroupID, session.StudyActivityID, time.Now())
-	if err != nil {
-		return err
-	}
-
-	id, err := result.LastInsertId()
+// CreateStudySession records a session started now, or at session.CreatedAt
+// if set, as when importing or generating past sessions
+func (r *StudySessionRepository) CreateStudySession(ctx context.Context, session *models.StudySession) error {
+	now := createdAt(session.CreatedAt)
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO study_sessions (group_id, study_activity_id, user_id, created_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id
+	`, session.GroupID, session.StudyActivityID, session.UserID, now).Scan(&id)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	session.ID = id
+	session.CreatedAt = now
 	return nil
 }
 
-func (r *StudySessionRepository) GetLastStudySession() (*models.StudySessionDetail, error) {
+func (r *StudySessionRepository) GetLastStudySession(ctx context.Context) (*models.StudySessionDetail, error) {
 	session := &models.StudySessionDetail{}
-	// Use NullString instead of NullTime for end_time because:
-	// 1. SQLite stores timestamps as strings, not native time types
-	// 2. When scanning directly into sql.NullTime, it causes a type mismatch error:
-	//    "sql: Scan error on column index 7, name "end_time": unsupported Scan, storing driver.Value type string into type *time.Time"
-	// 3. We need to first scan into a string, then parse it into a time.Time
+	// end_time is an aggregate, so SQLite returns it as text; see parseTimestamp
 	var endTimeStr sql.NullString
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT 
-			ss.id, ss.group_id, ss.study_activity_id, ss.created_at,
+			ss.id, ss.group_id, ss.study_activity_id, ss.user_id, ss.created_at, ss.ended_at,
 			sa.name as activity_name,
 			g.name as group_name,
 			COUNT(wri.id) as review_items_count,
@@ -197,12 +250,12 @@ func (r *StudySessionRepository) GetLastStudySession() (*models.StudySessionDeta
 		JOIN study_activities sa ON ss.study_activity_id = sa.id
 		JOIN groups g ON ss.group_id = g.id
 		LEFT JOIN word_review_items wri ON ss.id = wri.study_session_id
-		GROUP BY ss.id
+		GROUP BY ss.id, sa.name, g.name
 		ORDER BY ss.created_at DESC
 		LIMIT 1
 	`).Scan(
-		&session.ID, &session.GroupID, &session.StudyActivityID,
-		&session.CreatedAt, &session.ActivityName, &session.GroupName,
+		&session.ID, &session.GroupID, &session.StudyActivityID, &session.UserID,
+		&session.CreatedAt, &session.EndedAt, &session.ActivityName, &session.GroupName,
 		&session.ReviewItemsCount, &endTimeStr,
 	)
 	if err == sql.ErrNoRows {
@@ -211,22 +264,16 @@ func (r *StudySessionRepository) GetLastStudySession() (*models.StudySessionDeta
 	if err != nil {
 		return nil, err
 	}
-	if endTimeStr.Valid {
-		// Parse the string timestamp into a time.Time object
-		endTime, err := time.Parse(time.RFC3339, endTimeStr.String)
-		if err == nil {
-			session.EndTime = &endTime
-		}
-	}
+	session.EndTime = parseTimestamp(endTimeStr)
 	return session, nil
 }
 
-func (r *StudySessionRepository) GetStudySessionWords(sessionID int64, offset, limit int) ([]models.WordWithStats, error) {
-	rows, err := r.db.Query(`
+func (r *StudySessionRepository) GetStudySessionWords(ctx context.Context, sessionID int64, offset, limit int) ([]models.WordWithStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
 			w.id, w.portuguese, w.english, w.created_at,
-			COUNT(CASE WHEN wri.correct = 1 THEN 1 END) as correct_count,
-			COUNT(CASE WHEN wri.correct = 0 THEN 1 END) as wrong_count
+			COUNT(CASE WHEN wri.correct THEN 1 END) as correct_count,
+			COUNT(CASE WHEN NOT wri.correct THEN 1 END) as wrong_count
 		FROM words w
 		JOIN word_review_items wri ON w.id = wri.word_id
 		WHERE wri.study_session_id = ?
@@ -254,12 +301,159 @@ func (r *StudySessionRepository) GetStudySessionWords(sessionID int64, offset, l
 	return words, nil
 }
 
-func (r *StudySessionRepository) CountStudySessionWords(sessionID int64) (int, error) {
+func (r *StudySessionRepository) CountStudySessionWords(ctx context.Context, sessionID int64) (int, error) {
 	var count int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT COUNT(DISTINCT word_id)
 		FROM word_review_items
 		WHERE study_session_id = ?
 	`, sessionID).Scan(&count)
 	return count, err
 }
+
+// CreateReview records the answer given for a word during a study session,
+// now or at review.CreatedAt if set
+func (r *StudySessionRepository) CreateReview(ctx context.Context, review *models.WordReviewItem) error {
+	now := createdAt(review.CreatedAt)
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO word_review_items (word_id, study_session_id, correct, xp, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`, review.WordID, review.StudySessionID, review.Correct, review.XP, now).Scan(&id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	review.ID = id
+	review.CreatedAt = now
+	return nil
+}
+
+// GetSessionCombo counts the answers right in a row at the end of a study
+// session, in the order they were recorded
+func (r *StudySessionRepository) GetSessionCombo(ctx context.Context, sessionID int64) (int, error) {
+	var combo int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM word_review_items
+		WHERE study_session_id = ? AND correct AND id > COALESCE((
+			SELECT MAX(id)
+			FROM word_review_items
+			WHERE study_session_id = ? AND NOT correct
+		), 0)
+	`, sessionID, sessionID).Scan(&combo)
+	return combo, err
+}
+
+// EndStudySession ends a study session at at, unless it has already ended
+func (r *StudySessionRepository) EndStudySession(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE study_sessions
+		SET ended_at = ?
+		WHERE id = ? AND ended_at IS NULL
+	`, at.UTC(), id)
+	return err
+}
+
+// CountActiveLearners counts the users who started a study session since the given time
+func (r *StudySessionRepository) CountActiveLearners(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT user_id)
+		FROM study_sessions
+		WHERE user_id IS NOT NULL AND created_at >= ?
+	`, since).Scan(&count)
+	return count, err
+}
+
+// reviewScopeFilter returns the condition on word_review_items selecting the
+// reviews of scope, and its arguments
+func reviewScopeFilter(scope models.ReviewScope) (string, []interface{}) {
+	switch {
+	case scope.WordID != 0:
+		return "word_id = ?", []interface{}{scope.WordID}
+	case scope.GroupID != 0:
+		return "word_id IN (SELECT word_id FROM words_groups WHERE group_id = ?)", []interface{}{scope.GroupID}
+	default:
+		return "1 = 1", nil
+	}
+}
+
+// GetRecallBuckets counts the reviews of scope by the interval since the
+// previous review of the same word by the same learner, kept in
+// seconds_since_previous: bucket i holds those made at least bounds[i] and
+// less than bounds[i+1] after it. bounds must be increasing. Reviews made
+// sooner than bounds[0] after the previous one, and the first reviews of
+// words by each learner, aren't counted.
+func (r *StudySessionRepository) GetRecallBuckets(ctx context.Context, scope models.ReviewScope, bounds []time.Duration) ([]models.RecallBucket, error) {
+	buckets := make([]models.RecallBucket, len(bounds))
+	if len(bounds) == 0 {
+		return buckets, nil
+	}
+
+	var bucketOf strings.Builder
+	var args []interface{}
+	bucketOf.WriteString("CASE")
+	for i := len(bounds) - 1; i >= 0; i-- {
+		fmt.Fprintf(&bucketOf, " WHEN seconds_since_previous >= ? THEN %d", i)
+		args = append(args, int64(bounds[i]/time.Second))
+		buckets[i].MinInterval = bounds[i]
+	}
+	bucketOf.WriteString(" END")
+	filter, filterArgs := reviewScopeFilter(scope)
+	args = append(args, int64(bounds[0]/time.Second))
+	args = append(args, filterArgs...)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			`+bucketOf.String()+` AS bucket,
+			COUNT(*),
+			SUM(CASE WHEN correct THEN 1 ELSE 0 END),
+			SUM(CASE WHEN correct THEN seconds_since_previous ELSE 0 END)
+		FROM word_review_items
+		WHERE seconds_since_previous >= ? AND `+filter+`
+		GROUP BY 1
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i int
+		var reviews, correct, recallSeconds int64
+		if err := rows.Scan(&i, &reviews, &correct, &recallSeconds); err != nil {
+			return nil, err
+		}
+		buckets[i].Reviews = int(reviews)
+		buckets[i].CorrectCount = int(correct)
+		buckets[i].RecallSeconds = recallSeconds
+	}
+	return buckets, rows.Err()
+}
+
+// ListWordReviews returns the reviews of a word, oldest first, with the
+// interval since the same learner's previous one
+func (r *StudySessionRepository) ListWordReviews(ctx context.Context, wordID int64) ([]models.WordReviewItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, word_id, study_session_id, correct, xp, created_at, seconds_since_previous
+		FROM word_review_items
+		WHERE word_id = ?
+		ORDER BY created_at, id
+	`, wordID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []models.WordReviewItem{}
+	for rows.Next() {
+		var review models.WordReviewItem
+		if err := rows.Scan(&review.ID, &review.WordID, &review.StudySessionID, &review.Correct, &review.XP, &review.CreatedAt, &review.SecondsSincePrevious); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
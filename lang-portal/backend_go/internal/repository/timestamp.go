@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// timestampLayouts are the layouts parseTimestamp tries, in order
+var timestampLayouts = append(slices.Clone(sqlite3.SQLiteTimestampFormats), time.RFC3339Nano)
+
+// parseTimestamp parses a timestamp scanned into a string.
+// In SQLite, aggregates such as MAX(created_at) lose the column's DATETIME
+// type, so the driver hands them back as strings in one of its supported
+// layouts. Postgres keeps the type, and database/sql formats it as RFC 3339.
+func parseTimestamp(value sql.NullString) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, value.String, time.UTC); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// utcNow is the current time in UTC. Every time written is in UTC: SQLite
+// stores timestamps as text and compares them as strings, so a row stamped
+// with a local offset, such as 23:35-03:00, would fall outside the UTC
+// bounds of the day it was written on.
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
+
+// createdAt is the time a record is stamped with: t, unless it is zero, in
+// which case the current time, in UTC
+func createdAt(t time.Time) time.Time {
+	if t.IsZero() {
+		return utcNow()
+	}
+	return t.UTC()
+}
@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTimestampsInLocalZone tests that the times written on a host outside
+// UTC fall on their UTC day. SQLite compares timestamps as text, so a
+// session stamped 23:35-03:00 must be stored as 02:35 UTC the next day.
+func TestTimestampsInLocalZone(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("BRT", -3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	ctx := context.Background()
+	db := newTestDB(t)
+	group, err := createGroupWithWord(ctx, db.DB)
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "Flashcards"}
+	require.NoError(t, repository.NewStudyActivityRepository(db.DB).CreateStudyActivity(ctx, activity))
+	user, err := repository.NewUserRepository(db.DB).CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleStudent})
+	require.NoError(t, err)
+	classrooms := repository.NewClassroomRepository(db.DB)
+	classroom, err := classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: user.ID})
+	require.NoError(t, err)
+	require.NoError(t, classrooms.AddMembers(ctx, classroom.ID, []int64{user.ID}))
+	var wordID int64
+	require.NoError(t, db.DB.QueryRow("SELECT word_id FROM words_groups WHERE group_id = ?", group.ID).Scan(&wordID))
+
+	sessions := repository.NewStudySessionRepository(db.DB)
+	late := time.Date(2025, 3, 10, 23, 35, 0, 0, time.Local)
+	session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: &user.ID, CreatedAt: late}
+	require.NoError(t, sessions.CreateStudySession(ctx, session))
+	require.NoError(t, sessions.CreateReview(ctx, &models.WordReviewItem{StudySessionID: session.ID, WordID: wordID, Correct: true, XP: 10, CreatedAt: late}))
+	assert.Equal(t, time.UTC, session.CreatedAt.Location())
+
+	day := time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)
+	activityOn := func(day time.Time) []models.SessionActivity {
+		activities, err := sessions.ListSessionActivity(ctx, day, day.Add(24*time.Hour))
+		require.NoError(t, err)
+		return activities
+	}
+	assert.Len(t, activityOn(day), 1)
+	assert.Empty(t, activityOn(day.Add(-24*time.Hour)))
+
+	// Sessions stamped now count on today's UTC day
+	now := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: &user.ID}
+	require.NoError(t, sessions.CreateStudySession(ctx, now))
+	assert.Len(t, activityOn(time.Now().UTC().Truncate(24*time.Hour)), 1)
+
+	// The review made at 02:35 UTC counts since midnight UTC, not since 03:00
+	leaderboards := repository.NewLeaderboardRepository(db.DB)
+	standings, err := leaderboards.ComputeStandings(ctx, classroom.ID, day, 3)
+	require.NoError(t, err)
+	require.Len(t, standings, 1)
+	assert.Equal(t, 10, standings[0].XP)
+	standings, err = leaderboards.ComputeStandings(ctx, classroom.ID, day.Add(3*time.Hour), 3)
+	require.NoError(t, err)
+	assert.Empty(t, standings)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// txBeginner is implemented by the DBs that can start a transaction, such as
+// *sql.DB. A *sql.Tx can't, so repositories built on one join it instead.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// WithTx runs fn with a DB bound to a new transaction on db. The transaction
+// is committed if fn returns nil and rolled back if it returns an error or
+// panics; fn's error is returned as is. If db is already bound to a
+// transaction, fn runs in it and the outermost WithTx decides the outcome,
+// so repository methods that need a transaction of their own compose with
+// the caller's.
+func WithTx(ctx context.Context, db DB, fn func(tx DB) error) (err error) {
+	tx, bound, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return fn(bound)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(bound); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// begin starts a transaction on db and returns it along with db bound to it.
+// tx is nil when db is already bound to a transaction.
+func begin(ctx context.Context, db DB) (tx *sql.Tx, bound DB, err error) {
+	switch db := db.(type) {
+	case *instrumentedDB:
+		tx, bound, err := begin(ctx, db.DB)
+		if err != nil || tx == nil {
+			return tx, db, err
+		}
+		return tx, Instrument(bound, db.observe), nil
+	case *dialectDB:
+		tx, bound, err := begin(ctx, db.DB)
+		if err != nil || tx == nil {
+			return tx, db, err
+		}
+		return tx, WithDialect(bound, db.dialect), nil
+	case *readWriteDB:
+		return begin(ctx, db.write)
+	case txBeginner:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tx, tx, nil
+	default:
+		return nil, db, nil
+	}
+}
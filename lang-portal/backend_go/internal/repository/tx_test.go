@@ -0,0 +1,145 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/database"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errPartial = errors.New("failed part way through")
+
+func newTestDB(t *testing.T) *database.TestDB {
+	db, err := database.NewTestDB()
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	return db
+}
+
+// createGroupWithWord writes to three tables through repositories bound to db
+func createGroupWithWord(ctx context.Context, db repository.DB) (*models.Group, error) {
+	groups, words := repository.NewGroupRepository(db), repository.NewWordRepository(db)
+	group, err := groups.CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	if err != nil {
+		return nil, err
+	}
+	word, err := words.CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	if err != nil {
+		return nil, err
+	}
+	return group, groups.AddWordsToGroup(ctx, group.ID, []int64{word.ID})
+}
+
+func countRows(t *testing.T, db *database.TestDB, table string) int {
+	t.Helper()
+	var count int
+	require.NoError(t, db.DB.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+	return count
+}
+
+// TestWithTxCommits tests that the writes made in a transaction are kept
+func TestWithTxCommits(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+
+	err := repository.WithTx(context.Background(), db.DB, func(tx repository.DB) error {
+		_, err := createGroupWithWord(context.Background(), tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, countRows(t, db, "groups"))
+	assert.Equal(t, 1, countRows(t, db, "words"))
+	assert.Equal(t, 1, countRows(t, db, "words_groups"))
+}
+
+// TestWithTxRollsBack tests that a failure part way through undoes every
+// write made in the transaction
+func TestWithTxRollsBack(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+
+	err := repository.WithTx(context.Background(), db.DB, func(tx repository.DB) error {
+		if _, err := createGroupWithWord(context.Background(), tx); err != nil {
+			return err
+		}
+		return errPartial
+	})
+	assert.ErrorIs(t, err, errPartial)
+
+	assert.Zero(t, countRows(t, db, "groups"))
+	assert.Zero(t, countRows(t, db, "words"))
+	assert.Zero(t, countRows(t, db, "words_groups"))
+}
+
+// TestWithTxJoinsOuterTransaction tests that repository methods with a
+// transaction of their own don't commit independently inside WithTx
+func TestWithTxJoinsOuterTransaction(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	ctx := context.Background()
+	group, err := createGroupWithWord(ctx, db.DB)
+	require.NoError(t, err)
+
+	err = repository.WithTx(ctx, db.DB, func(tx repository.DB) error {
+		// DeleteGroup runs its two statements in a transaction
+		if err := repository.NewGroupRepository(tx).DeleteGroup(ctx, group.ID); err != nil {
+			return err
+		}
+		return errPartial
+	})
+	assert.ErrorIs(t, err, errPartial)
+
+	assert.Equal(t, 1, countRows(t, db, "groups"))
+	assert.Equal(t, 1, countRows(t, db, "words_groups"))
+}
+
+// TestWithTxRollsBackOnPanic tests that a panicking callback leaves nothing behind
+func TestWithTxRollsBackOnPanic(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		repository.WithTx(context.Background(), db.DB, func(tx repository.DB) error {
+			if _, err := createGroupWithWord(context.Background(), tx); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	})
+
+	assert.Zero(t, countRows(t, db, "groups"))
+	// The connection went back to the pool usable
+	assert.Zero(t, countRows(t, db, "words"))
+}
+
+// TestWithTxInstrumented tests that queries run in transactions are timed
+func TestWithTxInstrumented(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+
+	var mu sync.Mutex
+	var methods []string
+	instrumented := repository.Instrument(db.DB, func(repo, method string, elapsed time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		methods = append(methods, repo+"."+method)
+	})
+
+	err := repository.WithTx(context.Background(), instrumented, func(tx repository.DB) error {
+		_, err := createGroupWithWord(context.Background(), tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, methods, "group.CreateGroup")
+	assert.Contains(t, methods, "word.CreateWord")
+	assert.Contains(t, methods, "group.AddWordsToGroup")
+}
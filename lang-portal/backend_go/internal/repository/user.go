@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+type UserRepository struct {
+	db DB
+}
+
+func NewUserRepository(db DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id int64) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, role, created_at
+		FROM users
+		WHERE id = ?
+	`, id).Scan(&user.ID, &user.Name, &user.Role, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO users (name, role, created_at)
+		VALUES (?, ?, ?)
+		RETURNING id
+	`, user.Name, user.Role, utcNow()).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetUser(ctx, id)
+}
@@ -1,23 +1,24 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-	"time"
+	"strings"
 
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 )
 
 type WordRepository struct {
-	db *sql.DB
+	db DB
 }
 
-func NewWordRepository(db *sql.DB) *WordRepository {
+func NewWordRepository(db DB) *WordRepository {
 	return &WordRepository{db: db}
 }
 
-func (r *WordRepository) GetWord(id int64) (*models.Word, error) {
+func (r *WordRepository) GetWord(ctx context.Context, id int64) (*models.Word, error) {
 	word := &models.Word{}
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT id, portuguese, english, created_at
 		FROM words
 		WHERE id = ?
@@ -31,21 +32,13 @@ func (r *WordRepository) GetWord(id int64) (*models.Word, error) {
 	return word, nil
 }
 
-func (r *WordRepository) GetWordWithStats(id int64) (*models.WordWithStats, error) {
-	word := &models.WordWithStats{}
-	err := r.db.QueryRow(`
-		SELECT 
-			w.id, w.portuguese, w.english, w.created_at,
-			COUNT(CASE WHEN wri.correct = 1 THEN 1 END) as correct_count,
-			COUNT(CASE WHEN wri.correct = 0 THEN 1 END) as wrong_count
+func (r *WordRepository) GetWordWithStats(ctx context.Context, id int64) (*models.WordWithStats, error) {
+	word, err := scanWordWithStats(r.db.QueryRowContext(ctx, `
+		SELECT `+wordWithStatsColumns+`
 		FROM words w
-		LEFT JOIN word_review_items wri ON w.id = wri.word_id
+		LEFT JOIN word_stats ws ON ws.word_id = w.id
 		WHERE w.id = ?
-		GROUP BY w.id
-	`, id).Scan(
-		&word.ID, &word.Portuguese, &word.English, &word.CreatedAt,
-		&word.CorrectCount, &word.WrongCount,
-	)
+	`, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -55,8 +48,8 @@ func (r *WordRepository) GetWordWithStats(id int64) (*models.WordWithStats, erro
 	return word, nil
 }
 
-func (r *WordRepository) ListWords() ([]*models.Word, error) {
-	rows, err := r.db.Query(`SELECT id, portuguese, english, created_at FROM words`)
+func (r *WordRepository) ListWords(ctx context.Context) ([]*models.Word, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, portuguese, english, created_at FROM words`)
 	if err != nil {
 		return nil, err
 	}
@@ -74,10 +67,10 @@ func (r *WordRepository) ListWords() ([]*models.Word, error) {
 }
 
 // ListWordsWithStatsPaginated returns a paginated list of words with their stats
-func (r *WordRepository) ListWordsWithStatsPaginated(page, pageSize int) ([]*models.WordWithStats, int, error) {
+func (r *WordRepository) ListWordsWithStatsPaginated(ctx context.Context, page, pageSize int) ([]*models.WordWithStats, int, error) {
 	// Get total count for pagination
 	var totalCount int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM words").Scan(&totalCount)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM words").Scan(&totalCount)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -86,14 +79,10 @@ func (r *WordRepository) ListWordsWithStatsPaginated(page, pageSize int) ([]*mod
 	offset := (page - 1) * pageSize
 
 	// Query for paginated words with stats
-	rows, err := r.db.Query(`
-		SELECT 
-			w.id, w.portuguese, w.english, w.created_at,
-			COALESCE(SUM(CASE WHEN wri.correct = 1 THEN 1 ELSE 0 END), 0) as correct_count,
-			COALESCE(SUM(CASE WHEN wri.correct = 0 THEN 1 ELSE 0 END), 0) as wrong_count
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+wordWithStatsColumns+`
 		FROM words w
-		LEFT JOIN word_review_items wri ON w.id = wri.word_id
-		GROUP BY w.id
+		LEFT JOIN word_stats ws ON ws.word_id = w.id
 		ORDER BY w.id
 		LIMIT ? OFFSET ?
 	`, pageSize, offset)
@@ -102,65 +91,112 @@ func (r *WordRepository) ListWordsWithStatsPaginated(page, pageSize int) ([]*mod
 	}
 	defer rows.Close()
 
+	words, err := scanWordsWithStats(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return words, totalCount, nil
+}
+
+// wordWithStatsColumns selects a word w and its statistics ws, which the
+// word_stats triggers keep up to date with its reviews. Words never reviewed
+// have no statistics row.
+const wordWithStatsColumns = `
+			w.id, w.portuguese, w.english, w.created_at,
+			COALESCE(ws.correct_count, 0), COALESCE(ws.wrong_count, 0),
+			ws.last_reviewed_at, COALESCE(ws.streak, 0)`
+
+// scanWordWithStats scans a row of wordWithStatsColumns
+func scanWordWithStats(scanner interface{ Scan(...interface{}) error }) (*models.WordWithStats, error) {
+	word := &models.WordWithStats{}
+	var lastReviewedAt sql.NullString
+	if err := scanner.Scan(
+		&word.ID, &word.Portuguese, &word.English, &word.CreatedAt,
+		&word.CorrectCount, &word.WrongCount, &lastReviewedAt, &word.Streak,
+	); err != nil {
+		return nil, err
+	}
+	word.LastReviewedAt = parseTimestamp(lastReviewedAt)
+	return word, nil
+}
+
+// scanWordsWithStats scans the rows of wordWithStatsColumns
+func scanWordsWithStats(rows *sql.Rows) ([]*models.WordWithStats, error) {
 	var words []*models.WordWithStats
 	for rows.Next() {
-		word := &models.WordWithStats{}
-		if err := rows.Scan(
-			&word.ID, &word.Portuguese, &word.English, &word.CreatedAt,
-			&word.CorrectCount, &word.WrongCount,
-		); err != nil {
-			return nil, 0, err
+		word, err := scanWordWithStats(rows)
+		if err != nil {
+			return nil, err
 		}
 		words = append(words, word)
 	}
-
-	return words, totalCount, nil
+	return words, rows.Err()
 }
 
-func (r *WordRepository) CountWords() (int, error) {
+func (r *WordRepository) CountWords(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM words").Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM words").Scan(&count)
 	return count, err
 }
 
-func (r *WordRepository) UpdateWord(word *models.Word) (*models.Word, error) {
-	_, err := r.db.Exec(`
+func (r *WordRepository) UpdateWord(ctx context.Context, word *models.Word) (*models.Word, error) {
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE words 
 		SET portuguese = ?, english = ? 
 		WHERE id = ?
 	`, word.Portuguese, word.English, word.ID)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
-	return r.GetWord(word.ID)
+	return r.GetWord(ctx, word.ID)
 }
 
-func (r *WordRepository) DeleteWord(id int64) error {
-	_, err := r.db.Exec(`DELETE FROM words WHERE id = ?`, id)
-	return err
+// DeleteWord deletes a word, or returns ErrNotFound if there is none with
+// the ID
+func (r *WordRepository) DeleteWord(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM words WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireDeleted(result)
 }
 
-func (r *WordRepository) CreateWord(word *models.Word) (*models.Word, error) {
-	result, err := r.db.Exec(`
+func (r *WordRepository) CreateWord(ctx context.Context, word *models.Word) (*models.Word, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
 		INSERT INTO words (portuguese, english, created_at)
 		VALUES (?, ?, ?)
-	`, word.Portuguese, word.English, time.Now())
+		RETURNING id
+	`, word.Portuguese, word.English, utcNow()).Scan(&id)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
-	id, err := result.LastInsertId()
+	return r.GetWord(ctx, id)
+}
+
+// FindWord returns the word with the given Portuguese and English text, or
+// nil if there is none
+func (r *WordRepository) FindWord(ctx context.Context, portuguese, english string) (*models.Word, error) {
+	word := &models.Word{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, portuguese, english, created_at
+		FROM words
+		WHERE portuguese = ? AND english = ?
+	`, portuguese, english).Scan(&word.ID, &word.Portuguese, &word.English, &word.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-
-	return r.GetWord(id)
+	return word, nil
 }
 
 // GetWordGroups returns the groups that a word belongs to
-func (r *WordRepository) GetWordGroups(wordID int64) ([]models.WordGroup, error) {
-	rows, err := r.db.Query(`
+func (r *WordRepository) GetWordGroups(ctx context.Context, wordID int64) ([]models.WordGroup, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT g.id, g.name
 		FROM groups g
 		JOIN words_groups wg ON g.id = wg.group_id
@@ -181,3 +217,73 @@ func (r *WordRepository) GetWordGroups(wordID int64) ([]models.WordGroup, error)
 	}
 	return groups, nil
 }
+
+// FindMissingWordIDs returns the IDs from wordIDs that don't match any word
+func (r *WordRepository) FindMissingWordIDs(ctx context.Context, wordIDs []int64) ([]int64, error) {
+	if len(wordIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(wordIDs)), ",")
+	args := make([]interface{}, len(wordIDs))
+	for i, id := range wordIDs {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM words WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[int64]bool, len(wordIDs))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []int64
+	for _, id := range wordIDs {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// ListLeeches returns the words that are leeches by c, of the group with ID
+// groupID or of all groups if 0, the most failed first
+func (r *WordRepository) ListLeeches(ctx context.Context, groupID int64, c models.LeechCriteria) ([]*models.WordWithStats, error) {
+	query := `
+		SELECT ` + wordWithStatsColumns + `
+		FROM word_stats ws
+		JOIN words w ON w.id = ws.word_id
+		WHERE ws.wrong_count >= ? AND ws.wrong_count > ws.correct_count AND ws.streak < ?`
+	args := []interface{}{c.MinWrong, c.RecoveredStreak}
+	if groupID != 0 {
+		query += ` AND ws.word_id IN (SELECT word_id FROM words_groups WHERE group_id = ?)`
+		args = append(args, groupID)
+	}
+	rows, err := r.db.QueryContext(ctx, query+`
+		ORDER BY ws.wrong_count DESC, w.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words, err := scanWordsWithStats(rows)
+	if err != nil {
+		return nil, err
+	}
+	if words == nil {
+		words = []*models.WordWithStats{}
+	}
+	return words, nil
+}
@@ -0,0 +1,124 @@
+// Package server runs the HTTP server and the background jobs that live as
+// long as it does, and shuts both down gracefully.
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+)
+
+// Server serves HTTP until its context is canceled
+type Server struct {
+	cfg    config.Server
+	http   *http.Server
+	logger *slog.Logger
+
+	jobs sync.WaitGroup
+	// stopJobs cancels the context passed to background jobs
+	jobsCtx  context.Context
+	stopJobs context.CancelFunc
+}
+
+// New returns a server for handler configured from cfg
+func New(cfg config.Server, handler http.Handler, logger *slog.Logger) *Server {
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	return &Server{
+		cfg: cfg,
+		http: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+			ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+		},
+		logger:   logger,
+		jobsCtx:  jobsCtx,
+		stopJobs: stopJobs,
+	}
+}
+
+// Go runs job in the background. Its context is canceled once the server
+// has stopped accepting requests, and shutdown waits for it to return.
+func (s *Server) Go(name string, job func(ctx context.Context)) {
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		s.logger.Debug("background job started", "job", name)
+		job(s.jobsCtx)
+		s.logger.Debug("background job stopped", "job", name)
+	}()
+}
+
+// Run listens on the configured address and serves until ctx is canceled,
+// then shuts down
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		s.stopJobs()
+		s.jobs.Wait()
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves on ln until ctx is canceled. It then stops accepting
+// connections, waits up to the shutdown timeout for in-flight requests,
+// and stops the background jobs. Requests still running after the timeout
+// are cut off and reported in the returned error.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	tls := s.cfg.TLSCertFile != ""
+	serveErr := make(chan error, 1)
+	go func() {
+		var err error
+		if tls {
+			err = s.http.ServeTLS(ln, s.cfg.TLSCertFile, s.cfg.TLSKeyFile)
+		} else {
+			err = s.http.Serve(ln)
+		}
+		serveErr <- err
+	}()
+	s.logger.Info("server listening", "addr", ln.Addr().String(), "tls", tls)
+
+	var err error
+	select {
+	case err = <-serveErr:
+		// The server failed on its own, e.g. with an unreadable certificate
+	case <-ctx.Done():
+		s.logger.Info("shutting down", "timeout", s.cfg.ShutdownTimeout)
+		err = s.shutdown()
+		<-serveErr
+	}
+
+	s.stopJobs()
+	s.jobs.Wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return err
+}
+
+// shutdown drains in-flight requests, closing the remaining connections
+// once the shutdown timeout has passed
+func (s *Server) shutdown() error {
+	ctx := context.Background()
+	if s.cfg.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
+		defer cancel()
+	}
+
+	if err := s.http.Shutdown(ctx); err != nil {
+		s.http.Close()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/config"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// start serves handler on a random local port and returns its base URL and
+// a channel receiving Serve's result
+func start(t *testing.T, ctx context.Context, srv *server.Server) (string, <-chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+	return ln.Addr().String(), done
+}
+
+// TestGracefulShutdown tests that in-flight requests finish and background
+// jobs stop before Serve returns
+func TestGracefulShutdown(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	cfg := config.Default().Server
+	srv := server.New(cfg, handler, discardLogger)
+	var jobStopped atomic.Bool
+	srv.Go("test", func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		jobStopped.Store(true)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, done := start(t, ctx, srv)
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{string(body), err}
+	}()
+
+	<-entered
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("Serve returned before the in-flight request finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.False(t, jobStopped.Load(), "jobs stop once requests are drained")
+
+	close(release)
+	r := <-response
+	require.NoError(t, r.err)
+	assert.Equal(t, "done", r.body)
+	require.NoError(t, <-done)
+	assert.True(t, jobStopped.Load())
+
+	// No new connections are accepted
+	_, err := http.Get("http://" + addr + "/")
+	assert.Error(t, err)
+}
+
+// TestShutdownTimeout tests that requests outliving the shutdown timeout are
+// cut off
+func TestShutdownTimeout(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	})
+
+	cfg := config.Default().Server
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, done := start(t, ctx, server.New(cfg, handler, discardLogger))
+
+	go http.Get("http://" + addr + "/stuck")
+	<-entered
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve didn't give up on the stuck request")
+	}
+}
+
+// TestMaxHeaderBytes tests that oversized request headers are rejected
+func TestMaxHeaderBytes(t *testing.T) {
+	cfg := config.Default().Server
+	cfg.MaxHeaderBytes = 1024
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addr, _ := start(t, ctx, server.New(cfg, http.NotFoundHandler(), discardLogger))
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+addr+"/", nil)
+	require.NoError(t, err)
+	// net/http allows 4KiB on top of MaxHeaderBytes
+	req.Header.Set("X-Padding", strings.Repeat("a", 8<<10))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+}
+
+// TestTLS tests serving HTTPS from a certificate and key file
+func TestTLS(t *testing.T) {
+	cfg := config.Default().Server
+	cfg.TLSCertFile, cfg.TLSKeyFile = writeSelfSignedCert(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotNil(t, r.TLS)
+		io.WriteString(w, "secure")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, done := start(t, ctx, server.New(cfg, handler, discardLogger))
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + addr + "/")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "secure", string(body))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+// writeSelfSignedCert writes a certificate for 127.0.0.1 and its key to PEM
+// files and returns their paths
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "lang-portal test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+// perfectSessionReviews is how many reviews a session needs, all correct, to
+// count as perfect
+const perfectSessionReviews = 10
+
+// achievements are the achievements learners can unlock, in the order they
+// are listed
+var achievements = []models.Achievement{
+	{Key: "first_review", Name: "First steps", Description: "Review a word", Metric: models.MetricReviews, Target: 1},
+	{Key: "words_10", Name: "First 10 words", Description: "Answer 10 different words right", Metric: models.MetricWordsLearned, Target: 10},
+	{Key: "words_100", Name: "First 100 words", Description: "Answer 100 different words right", Metric: models.MetricWordsLearned, Target: 100},
+	{Key: "words_500", Name: "500 words", Description: "Answer 500 different words right", Metric: models.MetricWordsLearned, Target: 500},
+	{Key: "reviews_1000", Name: "Dedicated", Description: "Review 1,000 words", Metric: models.MetricReviews, Target: 1000},
+	{Key: "xp_1000", Name: "1,000 XP", Description: "Earn 1,000 XP", Metric: models.MetricXP, Target: 1000},
+	{Key: "xp_10000", Name: "10,000 XP", Description: "Earn 10,000 XP", Metric: models.MetricXP, Target: 10000},
+	{Key: "streak_7", Name: "7-day streak", Description: "Study 7 days in a row", Metric: models.MetricLongestStreak, Target: 7},
+	{Key: "streak_30", Name: "30-day streak", Description: "Study 30 days in a row", Metric: models.MetricLongestStreak, Target: 30},
+	{Key: "perfect_session", Name: "Perfect session", Description: "End a session of at least 10 reviews without a mistake", Metric: models.MetricPerfectSessions, Target: 1},
+}
+
+// changesOnReview reports whether a review can move metric. The others are
+// measured when a session ends, as they aren't kept with each review.
+func changesOnReview(metric models.AchievementMetric) bool {
+	switch metric {
+	case models.MetricReviews, models.MetricWordsLearned, models.MetricXP:
+		return true
+	default:
+		return false
+	}
+}
+
+// learnerMetrics measures the study of a learner as of now. The totals kept
+// with each review are always measured; the perfect sessions and the streak,
+// in the time zone of their daily goal, only if one of candidates needs them.
+func learnerMetrics(ctx context.Context, repo GamificationRepository, sessions StudySessionRepository, userID int64, candidates []models.Achievement, now time.Time) (map[models.AchievementMetric]int, error) {
+	totals, err := repo.GetLearnerTotals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	metrics := map[models.AchievementMetric]int{
+		models.MetricReviews:      totals.Reviews,
+		models.MetricWordsLearned: totals.WordsLearned,
+		models.MetricXP:           totals.XP,
+	}
+	needs := map[models.AchievementMetric]bool{}
+	for _, achievement := range candidates {
+		needs[achievement.Metric] = true
+	}
+
+	if needs[models.MetricPerfectSessions] {
+		metrics[models.MetricPerfectSessions], err = repo.CountPerfectSessions(ctx, userID, perfectSessionReviews)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if needs[models.MetricLongestStreak] {
+		streak, err := loadStreak(ctx, sessions, repo, userID, nil, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics[models.MetricLongestStreak] = streak.Longest
+	}
+	return metrics, nil
+}
+
+// evaluateAchievements is the rules engine: it unlocks the achievements the
+// learner has earned as of now, and returns those it unlocked. Unless
+// sessionEnded, only the achievements a review can earn are considered.
+// Achievements already unlocked aren't measured again, so once a learner
+// has them all it costs a single query.
+func evaluateAchievements(ctx context.Context, repos Repositories, userID int64, sessionEnded bool, now time.Time) ([]models.Achievement, error) {
+	unlocked, err := repos.Gamification.ListUnlockedAchievements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[string]bool, len(unlocked))
+	for _, achievement := range unlocked {
+		have[achievement.Key] = true
+	}
+
+	var pending []models.Achievement
+	for _, achievement := range achievements {
+		if have[achievement.Key] || !(sessionEnded || changesOnReview(achievement.Metric)) {
+			continue
+		}
+		pending = append(pending, achievement)
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	metrics, err := learnerMetrics(ctx, repos.Gamification, repos.StudySessions, userID, pending, now)
+	if err != nil {
+		return nil, err
+	}
+	return unlockEarned(ctx, repos.Gamification, userID, pending, metrics, now)
+}
+
+// unlockEarned unlocks the achievements of candidates whose metric has
+// reached their target, and returns them
+func unlockEarned(ctx context.Context, repo GamificationRepository, userID int64, candidates []models.Achievement, metrics map[models.AchievementMetric]int, now time.Time) ([]models.Achievement, error) {
+	earned := []models.Achievement{}
+	for _, achievement := range candidates {
+		if metrics[achievement.Metric] < achievement.Target {
+			continue
+		}
+		err := repo.UnlockAchievement(ctx, &models.UnlockedAchievement{
+			UserID:     userID,
+			Key:        achievement.Key,
+			UnlockedAt: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		earned = append(earned, achievement)
+	}
+	return earned, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+// retentionDays are the intervals since a learner's previous review of a word
+// that retention is measured after
+var retentionDays = []int{1, 7, 30}
+
+// leechCriteria tell the words that keep being failed: answered wrong at
+// least 4 times, and more often than right, and not right 3 times in a row
+// since
+var leechCriteria = models.LeechCriteria{MinWrong: 4, RecoveredStreak: 3}
+
+// AnalyticsService measures how well words stick, from the sequences of their
+// reviews
+type AnalyticsService struct {
+	studySessionRepo StudySessionRepository
+	wordRepo         WordRepository
+	groupRepo        GroupRepository
+}
+
+func NewAnalyticsService(studySessionRepo StudySessionRepository, wordRepo WordRepository, groupRepo GroupRepository) *AnalyticsService {
+	return &AnalyticsService{
+		studySessionRepo: studySessionRepo,
+		wordRepo:         wordRepo,
+		groupRepo:        groupRepo,
+	}
+}
+
+// RetentionRate is how often words were recalled after an interval: the
+// reviews made at least Days days after the previous review of the same
+// word by the same learner, and less than the next rate's Days
+type RetentionRate struct {
+	Days         int `json:"days"`
+	Reviews      int `json:"reviews"`
+	CorrectCount int `json:"correct_count"`
+	// Retention is the percentage of the reviews that were correct
+	Retention float64 `json:"retention"`
+}
+
+// Retention describes how well words stick. Reviews made less than a day
+// after the previous one test short-term memory, and aren't counted.
+type Retention struct {
+	Rates []RetentionRate `json:"retention"`
+	// AverageRecallDays is the average time words were recalled after, since
+	// their previous review
+	AverageRecallDays float64 `json:"average_recall_days"`
+}
+
+// RetentionReport is the retention of all words, with the words that keep
+// being failed
+type RetentionReport struct {
+	Retention
+	Leeches []*models.WordWithStats `json:"leeches"`
+}
+
+// GroupAnalytics is the retention of the words of a group, with those that
+// keep being failed
+type GroupAnalytics struct {
+	GroupID        int64 `json:"group_id"`
+	TotalWordCount int   `json:"total_word_count"`
+	Retention
+	Leeches []*models.WordWithStats `json:"leeches"`
+}
+
+// WordHistory is every review of a word, with its retention
+type WordHistory struct {
+	WordID int64 `json:"word_id"`
+	models.WordStats
+	// Streak is the number of correct answers since the last wrong one
+	Streak int `json:"streak"`
+	// Leech is whether the word keeps being failed
+	Leech bool `json:"leech"`
+	Retention
+	Reviews []WordReview `json:"reviews"`
+}
+
+// WordReview is a review in a word's history
+type WordReview struct {
+	StudySessionID int64     `json:"study_session_id"`
+	Correct        bool      `json:"correct"`
+	CreatedAt      time.Time `json:"created_at"`
+	// IntervalDays is the time since the previous review by the same learner,
+	// nil for their first
+	IntervalDays *float64 `json:"interval_days"`
+}
+
+// GetRetention returns the retention of all words and the leeches
+func (s *AnalyticsService) GetRetention(ctx context.Context) (*RetentionReport, error) {
+	retention, err := s.retention(ctx, models.ReviewScope{})
+	if err != nil {
+		return nil, err
+	}
+	leeches, err := s.wordRepo.ListLeeches(ctx, 0, leechCriteria)
+	if err != nil {
+		return nil, err
+	}
+	return &RetentionReport{Retention: *retention, Leeches: leeches}, nil
+}
+
+// GetGroupStats returns the retention of the words of a group and its leeches
+func (s *AnalyticsService) GetGroupStats(ctx context.Context, id int64) (*GroupAnalytics, error) {
+	group, err := s.groupRepo.GetGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, NotFound("group")
+	}
+
+	wordCount, err := s.groupRepo.CountGroupWords(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	retention, err := s.retention(ctx, models.ReviewScope{GroupID: id})
+	if err != nil {
+		return nil, err
+	}
+	leeches, err := s.wordRepo.ListLeeches(ctx, id, leechCriteria)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GroupAnalytics{
+		GroupID:        id,
+		TotalWordCount: wordCount,
+		Retention:      *retention,
+		Leeches:        leeches,
+	}, nil
+}
+
+// GetWordHistory returns the reviews of a word, oldest first, and its retention
+func (s *AnalyticsService) GetWordHistory(ctx context.Context, id int64) (*WordHistory, error) {
+	word, err := s.wordRepo.GetWordWithStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, NotFound("word")
+	}
+
+	retention, err := s.retention(ctx, models.ReviewScope{WordID: id})
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := s.studySessionRepo.ListWordReviews(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &WordHistory{
+		WordID:    id,
+		WordStats: models.WordStats{CorrectCount: word.CorrectCount, WrongCount: word.WrongCount},
+		Streak:    word.Streak,
+		Leech:     leechCriteria.IsLeech(word),
+		Retention: *retention,
+		Reviews:   make([]WordReview, len(reviews)),
+	}
+	for i, review := range reviews {
+		history.Reviews[i] = WordReview{
+			StudySessionID: review.StudySessionID,
+			Correct:        review.Correct,
+			CreatedAt:      review.CreatedAt,
+		}
+		if review.SecondsSincePrevious != nil {
+			interval := roundTenth(inDays(time.Duration(*review.SecondsSincePrevious) * time.Second))
+			history.Reviews[i].IntervalDays = &interval
+		}
+	}
+	return history, nil
+}
+
+// retention measures the retention of the reviews of scope
+func (s *AnalyticsService) retention(ctx context.Context, scope models.ReviewScope) (*Retention, error) {
+	bounds := make([]time.Duration, len(retentionDays))
+	for i, d := range retentionDays {
+		bounds[i] = time.Duration(d) * 24 * time.Hour
+	}
+	buckets, err := s.studySessionRepo.GetRecallBuckets(ctx, scope, bounds)
+	if err != nil {
+		return nil, err
+	}
+
+	retention := &Retention{Rates: make([]RetentionRate, len(buckets))}
+	var recalled int
+	var recallSeconds int64
+	for i, bucket := range buckets {
+		retention.Rates[i] = RetentionRate{
+			Days:         retentionDays[i],
+			Reviews:      bucket.Reviews,
+			CorrectCount: bucket.CorrectCount,
+			Retention:    accuracy(bucket.CorrectCount, bucket.Reviews-bucket.CorrectCount),
+		}
+		recalled += bucket.CorrectCount
+		recallSeconds += bucket.RecallSeconds
+	}
+	if recalled > 0 {
+		retention.AverageRecallDays = roundTenth(float64(recallSeconds) / secondsPerDay / float64(recalled))
+	}
+	return retention, nil
+}
+
+// secondsPerDay is the length of a day of 24 hours
+const secondsPerDay = 24 * 60 * 60
+
+// inDays converts d to days of 24 hours
+func inDays(d time.Duration) float64 {
+	return d.Hours() / 24
+}
+
+// roundTenth rounds x to one decimal place
+func roundTenth(x float64) float64 {
+	return math.Round(x*10) / 10
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAnalytics tests retention, recall intervals and leeches computed from
+// the sequences of reviews
+func TestAnalytics(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	words, groups, sessions := store.Words(), store.Groups(), store.StudySessions()
+	hello, err := words.CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	require.NoError(t, err)
+	bye, err := words.CreateWord(ctx, &models.Word{Portuguese: "tchau", English: "bye"})
+	require.NoError(t, err)
+	group, err := groups.CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	require.NoError(t, groups.AddWordsToGroup(ctx, group.ID, []int64{hello.ID}))
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+	session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID}
+	require.NoError(t, sessions.CreateStudySession(ctx, session))
+
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	answer := func(word *models.Word, after time.Duration, correct bool) {
+		t.Helper()
+		review := &models.WordReviewItem{StudySessionID: session.ID, WordID: word.ID, Correct: correct, CreatedAt: start.Add(after)}
+		require.NoError(t, sessions.CreateReview(ctx, review))
+	}
+	answer(hello, 0, true)
+	answer(hello, 2*time.Hour, true)
+	answer(hello, 2*day+2*time.Hour, true)
+	answer(hello, 10*day, false)
+	answer(hello, 40*day, true)
+	for i := 0; i < 4; i++ {
+		answer(bye, time.Duration(i)*3*day, false)
+	}
+
+	svc := NewAnalyticsService(sessions, words, groups)
+
+	report, err := svc.GetRetention(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RetentionRate{
+		{Days: 1, Reviews: 4, CorrectCount: 1, Retention: 25},
+		{Days: 7, Reviews: 1},
+		{Days: 30, Reviews: 1, CorrectCount: 1, Retention: 100},
+	}, report.Rates)
+	// Recalled after 2 and 30 days
+	assert.Equal(t, 16.0, report.AverageRecallDays)
+	require.Len(t, report.Leeches, 1)
+	assert.Equal(t, bye.ID, report.Leeches[0].ID)
+
+	stats, err := svc.GetGroupStats(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, group.ID, stats.GroupID)
+	assert.Equal(t, 1, stats.TotalWordCount)
+	assert.Equal(t, []RetentionRate{
+		{Days: 1, Reviews: 1, CorrectCount: 1, Retention: 100},
+		{Days: 7, Reviews: 1},
+		{Days: 30, Reviews: 1, CorrectCount: 1, Retention: 100},
+	}, stats.Rates)
+	assert.Empty(t, stats.Leeches, "bye isn't in the group")
+	_, err = svc.GetGroupStats(ctx, group.ID+100)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	history, err := svc.GetWordHistory(ctx, hello.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hello.ID, history.WordID)
+	assert.Equal(t, models.WordStats{CorrectCount: 4, WrongCount: 1}, history.WordStats)
+	assert.Equal(t, 1, history.Streak)
+	assert.False(t, history.Leech)
+	require.Len(t, history.Reviews, 5)
+	assert.Nil(t, history.Reviews[0].IntervalDays)
+	intervals := make([]float64, 0, 4)
+	for _, review := range history.Reviews[1:] {
+		require.NotNil(t, review.IntervalDays)
+		intervals = append(intervals, *review.IntervalDays)
+	}
+	assert.Equal(t, []float64{0.1, 2, 7.9, 30}, intervals)
+	assert.True(t, history.Reviews[4].CreatedAt.Equal(start.Add(40*day)))
+	assert.Equal(t, session.ID, history.Reviews[4].StudySessionID)
+
+	// Ana's review a day after the guest's last one is her first
+	ana, err := store.Users().CreateUser(ctx, &models.User{Name: "Ana"})
+	require.NoError(t, err)
+	anaSession := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: &ana.ID}
+	require.NoError(t, sessions.CreateStudySession(ctx, anaSession))
+	require.NoError(t, sessions.CreateReview(ctx, &models.WordReviewItem{StudySessionID: anaSession.ID, WordID: hello.ID, Correct: true, CreatedAt: start.Add(41 * day)}))
+	history, err = svc.GetWordHistory(ctx, hello.ID)
+	require.NoError(t, err)
+	require.Len(t, history.Reviews, 6)
+	assert.Nil(t, history.Reviews[5].IntervalDays)
+
+	history, err = svc.GetWordHistory(ctx, bye.ID)
+	require.NoError(t, err)
+	assert.True(t, history.Leech)
+	assert.Zero(t, history.AverageRecallDays, "never recalled")
+	_, err = svc.GetWordHistory(ctx, bye.ID+100)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// recallBuckets returns the same recall buckets for any scope
+type recallBuckets struct {
+	StudySessionRepository
+	buckets []models.RecallBucket
+}
+
+func (r recallBuckets) GetRecallBuckets(ctx context.Context, scope models.ReviewScope, bounds []time.Duration) ([]models.RecallBucket, error) {
+	return r.buckets, nil
+}
+
+// TestAverageRecallDaysOverYears tests averaging recall intervals that add up
+// to more than a time.Duration holds
+func TestAverageRecallDaysOverYears(t *testing.T) {
+	// 3,000 correct reviews a year apart on average, 951 years in all
+	year := int64(365 * secondsPerDay)
+	svc := NewAnalyticsService(recallBuckets{buckets: []models.RecallBucket{
+		{CorrectCount: 1000, RecallSeconds: 1000 * year},
+		{CorrectCount: 1000, RecallSeconds: 1000 * year},
+		{CorrectCount: 1000, RecallSeconds: 1000 * year},
+	}}, nil, nil)
+
+	retention, err := svc.retention(context.Background(), models.ReviewScope{})
+	require.NoError(t, err)
+	assert.Equal(t, 365.0, retention.AverageRecallDays)
+}
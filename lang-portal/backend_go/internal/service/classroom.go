@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+)
+
+type ClassroomService struct {
+	classroomRepo ClassroomRepository
+	groupRepo     GroupRepository
+	userRepo      UserRepository
+	activityRepo  StudyActivityRepository
+	now           func() time.Time
+}
+
+func NewClassroomService(
+	classroomRepo ClassroomRepository,
+	groupRepo GroupRepository,
+	userRepo UserRepository,
+	activityRepo StudyActivityRepository,
+) *ClassroomService {
+	return &ClassroomService{
+		classroomRepo: classroomRepo,
+		groupRepo:     groupRepo,
+		userRepo:      userRepo,
+		activityRepo:  activityRepo,
+		now:           time.Now,
+	}
+}
+
+// CreateClassroom creates a classroom taught by an existing teacher
+func (s *ClassroomService) CreateClassroom(ctx context.Context, classroom *models.Classroom) (*models.Classroom, error) {
+	message, err := s.checkRole(ctx, classroom.TeacherID, models.RoleTeacher)
+	if err != nil {
+		return nil, err
+	}
+	if message != "" {
+		return nil, Validation("invalid input", FieldError{Field: "teacher_id", Message: message})
+	}
+
+	created, err := s.classroomRepo.CreateClassroom(ctx, classroom)
+	return created, missingReference(err)
+}
+
+// checkRole returns why userID can't act with role, or "" if it can
+func (s *ClassroomService) checkRole(ctx context.Context, userID int64, role string) (string, error) {
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case user == nil:
+		return "does not exist", nil
+	case user.Role != role:
+		return "must be a " + role, nil
+	}
+	return "", nil
+}
+
+// checkClassroomExists returns a not found error if the classroom doesn't exist
+func (s *ClassroomService) checkClassroomExists(ctx context.Context, id int64) error {
+	classroom, err := s.classroomRepo.GetClassroom(ctx, id)
+	if err != nil {
+		return err
+	}
+	if classroom == nil {
+		return NotFound("classroom")
+	}
+	return nil
+}
+
+// GetClassroom returns a classroom with its members
+func (s *ClassroomService) GetClassroom(ctx context.Context, id int64) (*models.ClassroomDetail, error) {
+	classroom, err := s.classroomRepo.GetClassroom(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if classroom == nil {
+		return nil, NotFound("classroom")
+	}
+
+	members, err := s.classroomRepo.ListMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ClassroomDetail{
+		Classroom: *classroom,
+		Members:   members,
+	}, nil
+}
+
+// AddMembers adds existing students to a classroom. Teachers can't be
+// members, including the classroom's own.
+func (s *ClassroomService) AddMembers(ctx context.Context, classroomID int64, userIDs []int64) error {
+	if err := s.checkClassroomExists(ctx, classroomID); err != nil {
+		return err
+	}
+
+	var fields []FieldError
+	for i, userID := range userIDs {
+		message, err := s.checkRole(ctx, userID, models.RoleStudent)
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("user_ids[%d]", i), Message: message})
+		}
+	}
+	if len(fields) > 0 {
+		return Validation("invalid input", fields...)
+	}
+
+	return missingReference(s.classroomRepo.AddMembers(ctx, classroomID, userIDs))
+}
+
+// RemoveMember removes a student from a classroom
+func (s *ClassroomService) RemoveMember(ctx context.Context, classroomID, userID int64) error {
+	if err := s.checkClassroomExists(ctx, classroomID); err != nil {
+		return err
+	}
+	err := s.classroomRepo.RemoveMember(ctx, classroomID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return NotFound("classroom member")
+	}
+	return err
+}
+
+// CreateAssignment assigns an existing group, and optionally the activity to
+// study it with, to a classroom
+func (s *ClassroomService) CreateAssignment(ctx context.Context, assignment *models.Assignment) (*models.Assignment, error) {
+	if err := s.checkClassroomExists(ctx, assignment.ClassroomID); err != nil {
+		return nil, err
+	}
+
+	var fields []FieldError
+	group, err := s.groupRepo.GetGroup(ctx, assignment.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		fields = append(fields, FieldError{Field: "group_id", Message: "does not exist"})
+	}
+	if assignment.StudyActivityID != nil {
+		activity, err := s.activityRepo.GetStudyActivity(ctx, *assignment.StudyActivityID)
+		if err != nil {
+			return nil, err
+		}
+		if activity == nil {
+			fields = append(fields, FieldError{Field: "study_activity_id", Message: "does not exist"})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, Validation("invalid input", fields...)
+	}
+
+	created, err := s.classroomRepo.CreateAssignment(ctx, assignment)
+	return created, missingReference(err)
+}
+
+// ListAssignments returns the teacher view of a classroom's assignments,
+// including how many students have completed each one
+func (s *ClassroomService) ListAssignments(ctx context.Context, classroomID int64) ([]models.AssignmentSummary, error) {
+	if err := s.checkClassroomExists(ctx, classroomID); err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.classroomRepo.ListAssignments(ctx, classroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]models.AssignmentSummary, 0, len(assignments))
+	for _, assignment := range assignments {
+		progress, err := s.getProgress(ctx, assignment, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		summary := models.AssignmentSummary{
+			Assignment:  *assignment,
+			MemberCount: len(progress),
+		}
+		for _, p := range progress {
+			if p.Status == models.AssignmentCompleted {
+				summary.CompletedCount++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// GetAssignmentReport returns an assignment with the progress of every student
+func (s *ClassroomService) GetAssignmentReport(ctx context.Context, classroomID, assignmentID int64) (*models.AssignmentReport, error) {
+	assignment, err := s.classroomRepo.GetAssignment(ctx, classroomID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment == nil {
+		return nil, NotFound("assignment")
+	}
+
+	progress, err := s.getProgress(ctx, assignment, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AssignmentReport{
+		Assignment: *assignment,
+		Progress:   progress,
+	}, nil
+}
+
+// ListStudentAssignments returns the student view of their assignments
+func (s *ClassroomService) ListStudentAssignments(ctx context.Context, userID int64) ([]models.StudentAssignment, error) {
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, NotFound("user")
+	}
+
+	assignments, err := s.classroomRepo.ListUserAssignments(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.StudentAssignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		progress, err := s.getProgress(ctx, assignment, &userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(progress) == 0 {
+			continue
+		}
+		result = append(result, models.StudentAssignment{
+			Assignment: *assignment,
+			Progress:   progress[0],
+		})
+	}
+	return result, nil
+}
+
+// getProgress loads the review counts for an assignment and derives
+// accuracy and completion status for each student
+func (s *ClassroomService) getProgress(ctx context.Context, assignment *models.Assignment, userID *int64) ([]models.AssignmentProgress, error) {
+	totalWords, err := s.groupRepo.CountGroupWords(ctx, assignment.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := s.classroomRepo.GetAssignmentProgress(ctx, assignment, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	for i := range progress {
+		progress[i].TotalWords = totalWords
+		progress[i].Accuracy = accuracy(progress[i].CorrectCount, progress[i].WrongCount)
+		progress[i].Status = assignmentStatus(assignment, progress[i], now)
+	}
+	return progress, nil
+}
+
+// accuracy returns the percentage of correct reviews
+func accuracy(correct, wrong int) float64 {
+	total := correct + wrong
+	if total == 0 {
+		return 0
+	}
+	return float64(correct) * 100 / float64(total)
+}
+
+// assignmentStatus decides whether a student has finished an assignment.
+// A student is done once every word in the group has been reviewed with an
+// overall accuracy at or above the assignment target.
+func assignmentStatus(assignment *models.Assignment, p models.AssignmentProgress, now time.Time) string {
+	switch {
+	case p.TotalWords > 0 && p.WordsReviewed >= p.TotalWords && p.Accuracy >= assignment.TargetAccuracy:
+		return models.AssignmentCompleted
+	case now.After(assignment.DueAt):
+		return models.AssignmentOverdue
+	case p.WordsReviewed == 0:
+		return models.AssignmentNotStarted
+	default:
+		return models.AssignmentInProgress
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAccuracy tests the percentage of correct answers
+func TestAccuracy(t *testing.T) {
+	assert.Zero(t, accuracy(0, 0))
+	assert.Equal(t, 100.0, accuracy(3, 0))
+	assert.Equal(t, 0.0, accuracy(0, 2))
+	assert.InDelta(t, 66.67, accuracy(2, 1), 0.01)
+}
+
+// TestAssignmentStatus tests deciding whether a student finished an assignment
+func TestAssignmentStatus(t *testing.T) {
+	due := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	assignment := &models.Assignment{DueAt: due, TargetAccuracy: 80}
+	before, after := due.Add(-time.Hour), due.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		progress models.AssignmentProgress
+		now      time.Time
+		want     string
+	}{
+		{"nothing reviewed", models.AssignmentProgress{TotalWords: 5}, before, models.AssignmentNotStarted},
+		{"some words reviewed", models.AssignmentProgress{TotalWords: 5, WordsReviewed: 2, Accuracy: 100}, before, models.AssignmentInProgress},
+		{"all words below target", models.AssignmentProgress{TotalWords: 5, WordsReviewed: 5, Accuracy: 79.9}, before, models.AssignmentInProgress},
+		{"all words at target", models.AssignmentProgress{TotalWords: 5, WordsReviewed: 5, Accuracy: 80}, before, models.AssignmentCompleted},
+		{"completed late", models.AssignmentProgress{TotalWords: 5, WordsReviewed: 5, Accuracy: 90}, after, models.AssignmentCompleted},
+		{"unfinished past due", models.AssignmentProgress{TotalWords: 5, WordsReviewed: 4, Accuracy: 90}, after, models.AssignmentOverdue},
+		{"empty group", models.AssignmentProgress{}, before, models.AssignmentNotStarted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, assignmentStatus(assignment, tt.progress, tt.now))
+		})
+	}
+}
+
+// TestGetAssignmentReport tests the per-student report built from the fakes
+func TestGetAssignmentReport(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	users, classrooms, groups, words, activities, sessions := store.Users(), store.Classrooms(), store.Groups(), store.Words(), store.StudyActivities(), store.StudySessions()
+
+	teacher, err := users.CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleTeacher})
+	require.NoError(t, err)
+	student, err := users.CreateUser(ctx, &models.User{Name: "Bruno", Role: models.RoleStudent})
+	require.NoError(t, err)
+	classroom, err := classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: teacher.ID})
+	require.NoError(t, err)
+	require.NoError(t, classrooms.AddMembers(ctx, classroom.ID, []int64{student.ID}))
+
+	group, err := groups.CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	hello, err := words.CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	require.NoError(t, err)
+	bye, err := words.CreateWord(ctx, &models.Word{Portuguese: "adeus", English: "goodbye"})
+	require.NoError(t, err)
+	require.NoError(t, groups.AddWordsToGroup(ctx, group.ID, []int64{hello.ID, bye.ID}))
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, activities.CreateStudyActivity(ctx, activity))
+
+	created := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	store.SetClock(func() time.Time { return created })
+	assignment, err := classrooms.CreateAssignment(ctx, &models.Assignment{
+		ClassroomID: classroom.ID, GroupID: group.ID, DueAt: created.Add(7 * 24 * time.Hour), TargetAccuracy: 75,
+	})
+	require.NoError(t, err)
+
+	store.SetClock(func() time.Time { return created.Add(time.Hour) })
+	session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: &student.ID}
+	require.NoError(t, sessions.CreateStudySession(ctx, session))
+	for _, r := range []struct {
+		wordID  int64
+		correct bool
+	}{{hello.ID, true}, {bye.ID, false}, {bye.ID, true}, {hello.ID, true}} {
+		require.NoError(t, sessions.CreateReview(ctx, &models.WordReviewItem{StudySessionID: session.ID, WordID: r.wordID, Correct: r.correct}))
+	}
+
+	svc := NewClassroomService(classrooms, groups, users, activities)
+	svc.now = func() time.Time { return created.Add(24 * time.Hour) }
+	report, err := svc.GetAssignmentReport(ctx, classroom.ID, assignment.ID)
+	require.NoError(t, err)
+	require.Len(t, report.Progress, 1)
+	p := report.Progress[0]
+	assert.Equal(t, 2, p.TotalWords)
+	assert.Equal(t, 2, p.WordsReviewed)
+	assert.Equal(t, 75.0, p.Accuracy)
+	assert.Equal(t, models.AssignmentCompleted, p.Status)
+
+	_, err = svc.GetAssignmentReport(ctx, classroom.ID, assignment.ID+1)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestClassroomReferences tests that classrooms, members and assignments only
+// reference records that exist, with teachers teaching and students as members
+func TestClassroomReferences(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	users, activities := store.Users(), store.StudyActivities()
+	svc := NewClassroomService(store.Classrooms(), store.Groups(), users, activities)
+
+	teacher, err := users.CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleTeacher})
+	require.NoError(t, err)
+	student, err := users.CreateUser(ctx, &models.User{Name: "Bruno", Role: models.RoleStudent})
+	require.NoError(t, err)
+	group, err := store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, activities.CreateStudyActivity(ctx, activity))
+	missing := int64(999)
+
+	fieldErrors := func(err error) []FieldError {
+		var domainErr *Error
+		require.True(t, errors.As(err, &domainErr), "got %v", err)
+		assert.ErrorIs(t, err, ErrValidation)
+		return domainErr.Fields
+	}
+
+	_, err = svc.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: missing})
+	assert.Equal(t, []FieldError{{Field: "teacher_id", Message: "does not exist"}}, fieldErrors(err))
+	_, err = svc.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: student.ID})
+	assert.Equal(t, []FieldError{{Field: "teacher_id", Message: "must be a teacher"}}, fieldErrors(err))
+	classroom, err := svc.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: teacher.ID})
+	require.NoError(t, err)
+
+	err = svc.AddMembers(ctx, classroom.ID, []int64{student.ID, teacher.ID, missing})
+	assert.Equal(t, []FieldError{
+		{Field: "user_ids[1]", Message: "must be a student"},
+		{Field: "user_ids[2]", Message: "does not exist"},
+	}, fieldErrors(err))
+	members, err := store.Classrooms().ListMembers(ctx, classroom.ID)
+	require.NoError(t, err)
+	assert.Empty(t, members, "no member is added when one is rejected")
+	assert.ErrorIs(t, svc.AddMembers(ctx, missing, []int64{student.ID}), ErrNotFound)
+	require.NoError(t, svc.AddMembers(ctx, classroom.ID, []int64{student.ID}))
+
+	due := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	_, err = svc.CreateAssignment(ctx, &models.Assignment{ClassroomID: classroom.ID, GroupID: missing, StudyActivityID: &missing, DueAt: due})
+	assert.Equal(t, []FieldError{
+		{Field: "group_id", Message: "does not exist"},
+		{Field: "study_activity_id", Message: "does not exist"},
+	}, fieldErrors(err))
+	_, err = svc.CreateAssignment(ctx, &models.Assignment{ClassroomID: missing, GroupID: group.ID, DueAt: due})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.CreateAssignment(ctx, &models.Assignment{ClassroomID: classroom.ID, GroupID: group.ID, StudyActivityID: &activity.ID, DueAt: due})
+	assert.NoError(t, err)
+}
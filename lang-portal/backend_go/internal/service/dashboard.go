@@ -1,30 +1,38 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
 )
 
 type DashboardService struct {
-	studySessionRepo *repository.StudySessionRepository
-	wordRepo         *repository.WordRepository
-	groupRepo        *repository.GroupRepository
+	studySessionRepo StudySessionRepository
+	wordRepo         WordRepository
+	groupRepo        GroupRepository
+	gamificationRepo GamificationRepository
+	now              func() time.Time
 }
 
 func NewDashboardService(
-	studySessionRepo *repository.StudySessionRepository,
-	wordRepo *repository.WordRepository,
-	groupRepo *repository.GroupRepository,
+	studySessionRepo StudySessionRepository,
+	wordRepo WordRepository,
+	groupRepo GroupRepository,
+	gamificationRepo GamificationRepository,
 ) *DashboardService {
 	return &DashboardService{
 		studySessionRepo: studySessionRepo,
 		wordRepo:         wordRepo,
 		groupRepo:        groupRepo,
+		gamificationRepo: gamificationRepo,
+		now:              time.Now,
 	}
 }
 
-func (s *DashboardService) GetLastStudySession() (*models.StudySessionDetail, error) {
-	return s.studySessionRepo.GetLastStudySession()
+func (s *DashboardService) GetLastStudySession(ctx context.Context) (*models.StudySessionDetail, error) {
+	return s.studySessionRepo.GetLastStudySession(ctx)
 }
 
 type StudyProgress struct {
@@ -32,15 +40,15 @@ type StudyProgress struct {
 	TotalAvailableWords int `json:"total_available_words"`
 }
 
-func (s *DashboardService) GetStudyProgress() (*StudyProgress, error) {
+func (s *DashboardService) GetStudyProgress(ctx context.Context) (*StudyProgress, error) {
 	// Get total available words
-	totalWords, err := s.wordRepo.CountWords()
+	totalWords, err := s.wordRepo.CountWords(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get total words studied (distinct words that have been reviewed)
-	totalStudied, err := s.studySessionRepo.GetTotalDistinctWordsStudied()
+	totalStudied, err := s.studySessionRepo.GetTotalDistinctWordsStudied(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -58,32 +66,41 @@ type QuickStats struct {
 	StudyStreakDays    int     `json:"study_streak_days"`
 }
 
-func (s *DashboardService) GetQuickStats() (*QuickStats, error) {
+// GetQuickStats returns the dashboard statistics, with the study streak of
+// the learner with ID userID, the guest if 0, counted in the time zone called
+// timeZone, or in that of their daily goal if empty
+func (s *DashboardService) GetQuickStats(ctx context.Context, userID int64, timeZone string) (*QuickStats, error) {
+	var v validation.Validator
+	var loc *time.Location
+	if timeZone != "" {
+		loc = parseTimeZone(&v, timeZone)
+	}
+	if err := v.Err(); err != nil {
+		return nil, invalidInput(err)
+	}
+
 	// Get success rate
-	correct, total, err := s.studySessionRepo.GetWordReviewStats()
+	correct, total, err := s.studySessionRepo.GetWordReviewStats(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var successRate float64
-	if total > 0 {
-		successRate = float64(correct) * 100 / float64(total)
-	}
+	successRate := accuracy(correct, total-correct)
 
 	// Get total study sessions
-	totalSessions, err := s.studySessionRepo.CountStudySessions()
+	totalSessions, err := s.studySessionRepo.CountStudySessions(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get total active groups
-	activeGroups, err := s.studySessionRepo.GetTotalActiveGroups()
+	activeGroups, err := s.studySessionRepo.GetTotalActiveGroups(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get study streak
-	streak, err := s.studySessionRepo.GetStudyStreak()
+	streak, err := loadStreak(ctx, s.studySessionRepo, s.gamificationRepo, userID, loc, s.now())
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +109,6 @@ func (s *DashboardService) GetQuickStats() (*QuickStats, error) {
 		SuccessRate:        successRate,
 		TotalStudySessions: totalSessions,
 		TotalActiveGroups:  activeGroups,
-		StudyStreakDays:    streak,
+		StudyStreakDays:    streak.Current,
 	}, nil
 }
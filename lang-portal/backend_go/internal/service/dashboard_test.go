@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// TestGetQuickStats tests the dashboard statistics computed from the repositories
+func TestGetQuickStats(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	words, groups, activities, sessions := store.Words(), store.Groups(), store.StudyActivities(), store.StudySessions()
+
+	word, err := words.CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	require.NoError(t, err)
+	group, err := groups.CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, activities.CreateStudyActivity(ctx, activity))
+
+	// Study on three consecutive days, then skip one
+	for _, d := range []string{"2025-03-06", "2025-03-08", "2025-03-09", "2025-03-10"} {
+		studiedAt := day(d).Add(9 * time.Hour)
+		store.SetClock(func() time.Time { return studiedAt })
+		session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID}
+		require.NoError(t, sessions.CreateStudySession(ctx, session))
+		require.NoError(t, sessions.CreateReview(ctx, &models.WordReviewItem{StudySessionID: session.ID, WordID: word.ID, Correct: d != "2025-03-06"}))
+	}
+	// Another learner studying on the day skipped doesn't extend the guest's
+	// streak
+	ana, err := store.Users().CreateUser(ctx, &models.User{Name: "Ana"})
+	require.NoError(t, err)
+	store.SetClock(func() time.Time { return day("2025-03-07").Add(9 * time.Hour) })
+	require.NoError(t, sessions.CreateStudySession(ctx, &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: &ana.ID}))
+
+	svc := NewDashboardService(sessions, words, groups, store.Gamification())
+	svc.now = func() time.Time { return time.Date(2025, 3, 10, 20, 0, 0, 0, time.UTC) }
+
+	stats, err := svc.GetQuickStats(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, 75.0, stats.SuccessRate)
+	assert.Equal(t, 5, stats.TotalStudySessions)
+	assert.Equal(t, 1, stats.TotalActiveGroups)
+	assert.Equal(t, 3, stats.StudyStreakDays)
+
+	// Not studied yet today, which has only begun in UTC
+	svc.now = func() time.Time { return time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC) }
+	stats, err = svc.GetQuickStats(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.StudyStreakDays, "the streak lasts until the end of the day")
+	stats, err = svc.GetQuickStats(ctx, 0, "Asia/Tokyo")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.StudyStreakDays)
+
+	stats, err = svc.GetQuickStats(ctx, ana.ID, "")
+	require.NoError(t, err)
+	assert.Zero(t, stats.StudyStreakDays, "Ana's one day is long past")
+
+	_, err = svc.GetQuickStats(ctx, 0, "Moon/Tranquility_Base")
+	assert.ErrorIs(t, err, ErrValidation)
+
+	emptyStore := memory.NewStore()
+	empty := NewDashboardService(emptyStore.StudySessions(), words, groups, emptyStore.Gamification())
+	stats, err = empty.GetQuickStats(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Zero(t, stats.SuccessRate, "no reviews means no success rate, not NaN")
+	assert.Zero(t, stats.StudyStreakDays)
+}
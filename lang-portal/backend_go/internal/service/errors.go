@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
+
+// Sentinel errors identifying the kind of a domain error. Use errors.Is to
+// check the kind of an error returned by a service.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+)
+
+// Error codes included in API error responses
+const (
+	CodeNotFound   = "not_found"
+	CodeConflict   = "conflict"
+	CodeValidation = "validation_failed"
+	CodeForbidden  = "forbidden"
+	CodeInternal   = "internal_error"
+	CodeTimeout    = "timeout"
+	CodeCanceled   = "canceled"
+)
+
+// FieldError describes why a single input field was rejected
+type FieldError = validation.FieldError
+
+// Error is a domain error returned by services. Its message is safe to show
+// to API clients; the underlying cause, if any, is kept in Err.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrNotFound) and friends match on the error kind
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// NotFound returns an error for a resource that doesn't exist
+func NotFound(resource string) *Error {
+	return &Error{Kind: ErrNotFound, Code: CodeNotFound, Message: resource + " not found"}
+}
+
+// Conflict returns an error for a request that clashes with existing data
+func Conflict(message string, cause error) *Error {
+	return &Error{Kind: ErrConflict, Code: CodeConflict, Message: message, Err: cause}
+}
+
+// Validation returns an error for invalid input, with details per field
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrValidation, Code: CodeValidation, Message: message, Fields: fields}
+}
+
+// invalidInput converts the result of an input's Validate method into a
+// validation error. Other errors are returned unchanged.
+func invalidInput(err error) error {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return Validation("invalid input", fields...)
+	}
+	return err
+}
+
+// missingIDs returns a validation error naming the items of ids that are in
+// missing. field is a format with the item index, e.g. "word_ids[%d]".
+func missingIDs(field string, ids, missing []int64) error {
+	isMissing := make(map[int64]bool, len(missing))
+	for _, id := range missing {
+		isMissing[id] = true
+	}
+
+	fields := make([]FieldError, 0, len(missing))
+	for i, id := range ids {
+		if isMissing[id] {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf(field, i),
+				Message: "does not exist",
+			})
+		}
+	}
+	return Validation("invalid input", fields...)
+}
+
+// missingReference converts repository.ErrForeignKey, returned when a write
+// references a record that doesn't exist, such as one deleted meanwhile,
+// into a not found error. Other errors are returned unchanged.
+func missingReference(err error) error {
+	if errors.Is(err, repository.ErrForeignKey) {
+		notFound := NotFound("referenced record")
+		notFound.Err = err
+		return notFound
+	}
+	return err
+}
+
+// Forbidden returns an error for an operation the caller may not perform
+func Forbidden(message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: CodeForbidden, Message: message}
+}
@@ -0,0 +1,14 @@
+package service
+
+// Events is told about learning activity as it is recorded, e.g. to keep
+// metrics. Methods must be safe for concurrent use and return quickly.
+type Events interface {
+	SessionStarted()
+	ReviewRecorded(correct bool)
+}
+
+// noEvents ignores every event
+type noEvents struct{}
+
+func (noEvents) SessionStarted()     {}
+func (noEvents) ReviewRecorded(bool) {}
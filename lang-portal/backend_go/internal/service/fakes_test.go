@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+)
+
+// errInjected is returned by the failing repositories
+var errInjected = errors.New("injected failure")
+
+func fakeRepositories(store *memory.Store) Repositories {
+	return Repositories{
+		Words:           store.Words(),
+		Groups:          store.Groups(),
+		StudyActivities: store.StudyActivities(),
+		StudySessions:   store.StudySessions(),
+		Users:           store.Users(),
+		Classrooms:      store.Classrooms(),
+		Gamification:    store.Gamification(),
+		Leaderboards:    store.Leaderboards(),
+	}
+}
+
+// fakeUnitOfWork runs callbacks in transactions on a memory store
+type fakeUnitOfWork struct {
+	store *memory.Store
+	// wrap, when set, decorates the repositories handed to each callback,
+	// typically to make one of them fail part way through
+	wrap func(Repositories) Repositories
+}
+
+func (u *fakeUnitOfWork) WithTx(ctx context.Context, fn func(repos Repositories) error) error {
+	return u.store.WithTx(ctx, func() error {
+		repos := fakeRepositories(u.store)
+		if u.wrap != nil {
+			repos = u.wrap(repos)
+		}
+		return fn(repos)
+	})
+}
+
+// failingGroups fails the chosen group writes
+type failingGroups struct {
+	GroupRepository
+	failAdd    bool
+	failDelete bool
+}
+
+func (r failingGroups) AddWordsToGroup(ctx context.Context, groupID int64, wordIDs []int64) error {
+	if r.failAdd {
+		return errInjected
+	}
+	return r.GroupRepository.AddWordsToGroup(ctx, groupID, wordIDs)
+}
+
+func (r failingGroups) DeleteGroup(ctx context.Context, id int64) error {
+	if r.failDelete {
+		return errInjected
+	}
+	return r.GroupRepository.DeleteGroup(ctx, id)
+}
+
+// racingGroups finds no group by name, as when another request takes the
+// name between the check and the write
+type racingGroups struct {
+	GroupRepository
+}
+
+func (r racingGroups) FindGroupByName(ctx context.Context, name string) (*models.Group, error) {
+	return nil, nil
+}
+
+// failingWords fails every word created after the first succeed ones
+type failingWords struct {
+	WordRepository
+	succeed int
+}
+
+func (r *failingWords) CreateWord(ctx context.Context, word *models.Word) (*models.Word, error) {
+	if r.succeed == 0 {
+		return nil, errInjected
+	}
+	r.succeed--
+	return r.WordRepository.CreateWord(ctx, word)
+}
+
+// failingSessions fails every review created after the first succeed ones
+type failingSessions struct {
+	StudySessionRepository
+	succeed int
+}
+
+func (r *failingSessions) CreateReview(ctx context.Context, review *models.WordReviewItem) error {
+	if r.succeed == 0 {
+		return errInjected
+	}
+	r.succeed--
+	return r.StudySessionRepository.CreateReview(ctx, review)
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
+
+// maxDailyGoal is the highest daily goal, in reviews or minutes
+const maxDailyGoal = 1000
+
+// defaultDailyGoal is the goal of the learners who haven't set one
+var defaultDailyGoal = models.DailyGoal{Kind: models.GoalReviews, Target: 20, TimeZone: "UTC"}
+
+// GamificationService reports the daily goals, XP and achievements of each
+// learner. A learner is the user with ID userID, or the guest if 0: the
+// sessions started without a user.
+type GamificationService struct {
+	gamificationRepo GamificationRepository
+	studySessionRepo StudySessionRepository
+	userRepo         UserRepository
+	now              func() time.Time
+}
+
+func NewGamificationService(gamificationRepo GamificationRepository, studySessionRepo StudySessionRepository, userRepo UserRepository) *GamificationService {
+	return &GamificationService{
+		gamificationRepo: gamificationRepo,
+		studySessionRepo: studySessionRepo,
+		userRepo:         userRepo,
+		now:              time.Now,
+	}
+}
+
+// DailyGoalInput sets a learner's daily goal. An empty TimeZone means UTC.
+type DailyGoalInput struct {
+	Kind     models.GoalKind
+	Target   int
+	TimeZone string
+}
+
+// DailyGoalStatus is how far a learner is towards their daily goal today
+type DailyGoalStatus struct {
+	models.DailyGoal
+	// Date is today in the goal's time zone, as YYYY-MM-DD
+	Date string `json:"date"`
+	// Progress is the reviews or minutes studied today
+	Progress        float64 `json:"progress"`
+	PercentComplete float64 `json:"percent_complete"`
+	Met             bool    `json:"met"`
+}
+
+// AchievementReport lists the achievements of a learner, unlocked or not
+type AchievementReport struct {
+	XP int `json:"xp"`
+	// Unlocked lists the achievements unlocked, the first unlocked first
+	Unlocked   []models.AchievementStatus `json:"unlocked"`
+	InProgress []models.AchievementStatus `json:"in_progress"`
+}
+
+// checkLearner returns a not found error if userID is neither 0 nor a user
+func checkLearner(ctx context.Context, users UserRepository, userID int64) error {
+	if userID == 0 {
+		return nil
+	}
+	user, err := users.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return NotFound("user")
+	}
+	return nil
+}
+
+// dailyGoal returns the daily goal of a learner, the default one if they
+// haven't set one
+func dailyGoal(ctx context.Context, repo GamificationRepository, userID int64) (*models.DailyGoal, error) {
+	goal, err := repo.GetDailyGoal(ctx, userID)
+	if err != nil || goal != nil {
+		return goal, err
+	}
+	goal = &models.DailyGoal{}
+	*goal = defaultDailyGoal
+	goal.UserID = userID
+	return goal, nil
+}
+
+// GetDailyGoal returns the learner's progress towards their daily goal today
+func (s *GamificationService) GetDailyGoal(ctx context.Context, userID int64) (*DailyGoalStatus, error) {
+	if err := checkLearner(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	goal, err := dailyGoal(ctx, s.gamificationRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(goal.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	sessions, err := s.gamificationRepo.ListSessionActivity(ctx, userID, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	var reviews int
+	var studied time.Duration
+	for _, session := range sessions {
+		reviews += session.ReviewCount
+		if session.EndedAt != nil && session.EndedAt.After(session.StartedAt) {
+			studied += session.EndedAt.Sub(session.StartedAt)
+		}
+	}
+
+	status := &DailyGoalStatus{DailyGoal: *goal, Date: today.Format(time.DateOnly)}
+	switch goal.Kind {
+	case models.GoalMinutes:
+		status.Progress = roundTenth(studied.Minutes())
+	default:
+		status.Progress = float64(reviews)
+	}
+	status.Met = status.Progress >= float64(goal.Target)
+	status.PercentComplete = roundTenth(min(status.Progress/float64(goal.Target), 1) * 100)
+	return status, nil
+}
+
+// SetDailyGoal sets the learner's daily goal and returns their progress
+// towards it today
+func (s *GamificationService) SetDailyGoal(ctx context.Context, userID int64, in DailyGoalInput) (*DailyGoalStatus, error) {
+	var v validation.Validator
+	if in.Kind != models.GoalReviews && in.Kind != models.GoalMinutes {
+		v.Add("kind", fmt.Sprintf("must be one of: %s %s", models.GoalReviews, models.GoalMinutes))
+	}
+	if in.Target < 1 || in.Target > maxDailyGoal {
+		v.Add("target", fmt.Sprintf("must be between 1 and %d", maxDailyGoal))
+	}
+	loc := parseTimeZone(&v, in.TimeZone)
+	if err := v.Err(); err != nil {
+		return nil, invalidInput(err)
+	}
+	if err := checkLearner(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	err := s.gamificationRepo.SetDailyGoal(ctx, &models.DailyGoal{
+		UserID:   userID,
+		Kind:     in.Kind,
+		Target:   in.Target,
+		TimeZone: loc.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDailyGoal(ctx, userID)
+}
+
+// GetAchievements returns the XP of a learner and their achievements. It
+// only reads them: achievements are unlocked by the rules engine as reviews
+// are recorded and sessions end, so one earned before its rule was
+// introduced is listed in progress until the learner's next session ends.
+func (s *GamificationService) GetAchievements(ctx context.Context, userID int64) (*AchievementReport, error) {
+	if err := checkLearner(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	unlocked, err := s.gamificationRepo.ListUnlockedAchievements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[string]bool, len(unlocked))
+	for _, achievement := range unlocked {
+		have[achievement.Key] = true
+	}
+	var locked []models.Achievement
+	for _, achievement := range achievements {
+		if !have[achievement.Key] {
+			locked = append(locked, achievement)
+		}
+	}
+	metrics, err := learnerMetrics(ctx, s.gamificationRepo, s.studySessionRepo, userID, locked, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AchievementReport{
+		XP:         metrics[models.MetricXP],
+		Unlocked:   []models.AchievementStatus{},
+		InProgress: []models.AchievementStatus{},
+	}
+	for _, achievement := range locked {
+		report.InProgress = append(report.InProgress, models.AchievementStatus{
+			Achievement: achievement,
+			Progress:    min(metrics[achievement.Metric], achievement.Target),
+		})
+	}
+	byKey := make(map[string]models.Achievement, len(achievements))
+	for _, achievement := range achievements {
+		byKey[achievement.Key] = achievement
+	}
+	for _, u := range unlocked {
+		achievement, ok := byKey[u.Key]
+		if !ok {
+			// Unlocked under a rule that has since been retired
+			continue
+		}
+		at := u.UnlockedAt
+		report.Unlocked = append(report.Unlocked, models.AchievementStatus{
+			Achievement: achievement,
+			Progress:    achievement.Target,
+			UnlockedAt:  &at,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// correctly returns n reviews of word answered right
+func correctly(wordID int64, n int) []models.ReviewInput {
+	reviews := make([]models.ReviewInput, n)
+	for i := range reviews {
+		reviews[i] = models.ReviewInput{WordID: wordID, Correct: true}
+	}
+	return reviews
+}
+
+func xpOf(reviews []*models.WordReviewItem) []int {
+	xp := make([]int, len(reviews))
+	for i, review := range reviews {
+		xp[i] = review.XP
+	}
+	return xp
+}
+
+func achievementKeys(achievements []models.Achievement) []string {
+	keys := make([]string, len(achievements))
+	for i, achievement := range achievements {
+		keys[i] = achievement.Key
+	}
+	return keys
+}
+
+// TestReviewXPAndAchievements tests awarding XP for combos of answers right,
+// and unlocking achievements as reviews are recorded and sessions end
+func TestReviewXPAndAchievements(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	now := time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC)
+	store.SetClock(func() time.Time { return now })
+	svc := NewStudyActivityService(store.StudyActivities(), store.StudySessions(), store.Words(), &fakeUnitOfWork{store: store}, &recordedEvents{})
+	svc.now = func() time.Time { return now }
+	gamification := NewGamificationService(store.Gamification(), store.StudySessions(), store.Users())
+	gamification.now = svc.now
+
+	ana, err := store.Users().CreateUser(ctx, &models.User{Name: "Ana"})
+	require.NoError(t, err)
+	word, err := store.Words().CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	require.NoError(t, err)
+	group, err := store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+
+	session, err := svc.CreateStudySession(ctx, group.ID, activity.ID, &ana.ID)
+	require.NoError(t, err)
+	reviews, err := svc.RecordReviews(ctx, session.ID, correctly(word.ID, 7))
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 10, 10, 10, 10, 20, 20}, xpOf(reviews))
+	unlocked, err := store.Gamification().ListUnlockedAchievements(ctx, ana.ID)
+	require.NoError(t, err)
+	require.Len(t, unlocked, 1)
+	assert.Equal(t, "first_review", unlocked[0].Key)
+
+	// The combo carries over from one call to the next
+	reviews, err = svc.RecordReviews(ctx, session.ID, correctly(word.ID, 4))
+	require.NoError(t, err)
+	assert.Equal(t, []int{20, 20, 20, 30}, xpOf(reviews))
+	review, err := svc.RecordReview(ctx, session.ID, word.ID, true)
+	require.NoError(t, err)
+	assert.Equal(t, 30, review.XP)
+
+	end, err := svc.EndStudySession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now, end.EndedAt)
+	assert.Equal(t, []string{"perfect_session"}, achievementKeys(end.Unlocked))
+	_, err = svc.RecordReview(ctx, session.ID, word.ID, true)
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = svc.RecordReviews(ctx, session.ID, correctly(word.ID, 1))
+	assert.ErrorIs(t, err, ErrConflict)
+
+	now = now.Add(time.Hour)
+	end, err = svc.EndStudySession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), end.EndedAt, "ending a session again changes nothing")
+	assert.Empty(t, end.Unlocked)
+	_, err = svc.EndStudySession(ctx, session.ID+1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// A wrong answer breaks the combo, and perfect sessions need no mistake
+	session, err = svc.CreateStudySession(ctx, group.ID, activity.ID, &ana.ID)
+	require.NoError(t, err)
+	reviews, err = svc.RecordReviews(ctx, session.ID, append(correctly(word.ID, 6),
+		models.ReviewInput{WordID: word.ID}, models.ReviewInput{WordID: word.ID, Correct: true}))
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 10, 10, 10, 10, 20, 0, 10}, xpOf(reviews))
+
+	// The guest's achievements are their own
+	guest, err := svc.CreateStudySession(ctx, group.ID, activity.ID, nil)
+	require.NoError(t, err)
+	_, err = svc.RecordReview(ctx, guest.ID, word.ID, false)
+	require.NoError(t, err)
+
+	report, err := gamification.GetAchievements(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 290, report.XP)
+	require.Len(t, report.Unlocked, 2)
+	assert.Equal(t, "first_review", report.Unlocked[0].Key)
+	assert.Equal(t, "perfect_session", report.Unlocked[1].Key)
+	assert.Equal(t, 1, report.Unlocked[1].Progress)
+	require.Len(t, report.InProgress, len(achievements)-2)
+	assert.Equal(t, "words_10", report.InProgress[0].Key)
+	assert.Equal(t, 1, report.InProgress[0].Progress)
+	assert.Nil(t, report.InProgress[0].UnlockedAt)
+
+	report, err = gamification.GetAchievements(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, report.XP)
+	require.Len(t, report.Unlocked, 1)
+	assert.Equal(t, "first_review", report.Unlocked[0].Key)
+
+	_, err = gamification.GetAchievements(ctx, ana.ID+1)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestGetAchievementsOnlyReads tests that achievements earned before they
+// were evaluated, like a streak, are listed in progress until the rules
+// engine unlocks them when a session ends
+func TestGetAchievementsOnlyReads(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	svc := NewGamificationService(store.Gamification(), store.StudySessions(), store.Users())
+	now := time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	group, err := store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+	for i := 0; i < 7; i++ {
+		session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: now.AddDate(0, 0, -i)}
+		require.NoError(t, store.StudySessions().CreateStudySession(ctx, session))
+	}
+
+	report, err := svc.GetAchievements(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, report.Unlocked)
+	var streak models.AchievementStatus
+	for _, status := range report.InProgress {
+		if status.Key == "streak_7" {
+			streak = status
+		}
+	}
+	assert.Equal(t, 7, streak.Progress)
+	unlocked, err := store.Gamification().ListUnlockedAchievements(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, unlocked)
+
+	earned, err := evaluateAchievements(ctx, fakeRepositories(store), 0, true, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"streak_7"}, achievementKeys(earned))
+	report, err = svc.GetAchievements(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, report.Unlocked, 1)
+	assert.Equal(t, "streak_7", report.Unlocked[0].Key)
+	assert.Equal(t, now, *report.Unlocked[0].UnlockedAt)
+}
+
+// TestDailyGoal tests setting daily goals and measuring today's progress in
+// the goal's time zone
+func TestDailyGoal(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	svc := NewGamificationService(store.Gamification(), store.StudySessions(), store.Users())
+	// 23:30 on the 10th in UTC is already the 11th in Tokyo
+	now := time.Date(2025, 3, 10, 23, 30, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	ana, err := store.Users().CreateUser(ctx, &models.User{Name: "Ana"})
+	require.NoError(t, err)
+	word, err := store.Words().CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	require.NoError(t, err)
+	group, err := store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+	study := func(startedAt time.Time, minutes int) {
+		t.Helper()
+		session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: &ana.ID, CreatedAt: startedAt}
+		require.NoError(t, store.StudySessions().CreateStudySession(ctx, session))
+		for i := 0; i <= minutes; i++ {
+			review := &models.WordReviewItem{StudySessionID: session.ID, WordID: word.ID, Correct: true, CreatedAt: startedAt.Add(time.Duration(i) * time.Minute)}
+			require.NoError(t, store.StudySessions().CreateReview(ctx, review))
+		}
+	}
+	study(time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC), 10)
+	study(time.Date(2025, 3, 10, 22, 0, 0, 0, time.UTC), 5)
+
+	status, err := svc.GetDailyGoal(ctx, ana.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &DailyGoalStatus{
+		DailyGoal:       models.DailyGoal{UserID: ana.ID, Kind: models.GoalReviews, Target: 20, TimeZone: "UTC"},
+		Date:            "2025-03-10",
+		Progress:        17,
+		PercentComplete: 85,
+	}, status)
+
+	status, err = svc.SetDailyGoal(ctx, ana.ID, DailyGoalInput{Kind: models.GoalMinutes, Target: 10, TimeZone: "Asia/Tokyo"})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-11", status.Date)
+	assert.Equal(t, 5.0, status.Progress, "only the session started after midnight in Tokyo counts")
+	assert.Equal(t, 50.0, status.PercentComplete)
+	assert.False(t, status.Met)
+
+	status, err = svc.SetDailyGoal(ctx, ana.ID, DailyGoalInput{Kind: models.GoalReviews, Target: 6, TimeZone: "Asia/Tokyo"})
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, status.Progress)
+	assert.Equal(t, 100.0, status.PercentComplete)
+	assert.True(t, status.Met)
+
+	_, err = svc.SetDailyGoal(ctx, ana.ID, DailyGoalInput{Kind: "pages", Target: 0, TimeZone: "Mars/Olympus"})
+	var svcErr *Error
+	require.ErrorAs(t, err, &svcErr)
+	assert.Equal(t, ErrValidation, svcErr.Kind)
+	assert.Len(t, svcErr.Fields, 3)
+	_, err = svc.SetDailyGoal(ctx, ana.ID, DailyGoalInput{Kind: models.GoalReviews, Target: maxDailyGoal + 1})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	status, err = svc.GetDailyGoal(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, status.Progress, "the guest didn't study")
+	_, err = svc.GetDailyGoal(ctx, ana.ID+1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.SetDailyGoal(ctx, ana.ID+1, DailyGoalInput{Kind: models.GoalReviews, Target: 5})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -1,76 +1,381 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
 )
 
 type GroupService struct {
-	groupRepo *repository.GroupRepository
+	groupRepo    GroupRepository
+	wordRepo     WordRepository
+	activityRepo StudyActivityRepository
+	uow          UnitOfWork
+	mastery      models.MasteryThresholds
+	now          func() time.Time
 }
 
-func NewGroupService(groupRepo *repository.GroupRepository) *GroupService {
-	return &GroupService{groupRepo: groupRepo}
+func NewGroupService(groupRepo GroupRepository, wordRepo WordRepository, activityRepo StudyActivityRepository, uow UnitOfWork, mastery models.MasteryThresholds) *GroupService {
+	return &GroupService{
+		groupRepo:    groupRepo,
+		wordRepo:     wordRepo,
+		activityRepo: activityRepo,
+		uow:          uow,
+		mastery:      mastery,
+		now:          time.Now,
+	}
 }
 
-func (s *GroupService) ListGroups() ([]*models.Group, error) {
-	return s.groupRepo.ListGroups()
+func (s *GroupService) ListGroups(ctx context.Context) ([]*models.Group, error) {
+	return s.groupRepo.ListGroups(ctx)
 }
 
-// GetGroup returns a group with its stats
-func (s *GroupService) GetGroup(id int64) (*models.GroupDetail, error) {
-	// Get the basic group info
-	group, err := s.groupRepo.GetGroup(id)
+// GetGroup returns a group with its stats and mastery
+func (s *GroupService) GetGroup(ctx context.Context, id int64) (*models.GroupDetail, error) {
+	group, err := s.groupRepo.GetGroup(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if group == nil {
+		return nil, NotFound("group")
+	}
 
-	// Get the word count for this group
-	wordCount, err := s.groupRepo.CountGroupWords(id)
+	progress, mastery, err := s.loadMastery(ctx, []int64{id})
 	if err != nil {
 		return nil, err
 	}
-
-	// Create a GroupDetail object with the required stats
 	return &models.GroupDetail{
 		ID:   group.ID,
 		Name: group.Name,
 		Stats: models.GroupStats{
-			TotalWordCount: wordCount,
+			TotalWordCount: progress[0].WordCount,
+			GroupMastery:   *mastery[0],
 		},
 	}, nil
 }
 
-func (s *GroupService) GetGroupWords(id int64) ([]*models.Word, error) {
-	return s.groupRepo.GetGroupWords(id)
+// GetGroupWithStats returns a group with its word count and mastery
+func (s *GroupService) GetGroupWithStats(ctx context.Context, id int64) (*models.GroupWithStats, error) {
+	group, err := s.groupRepo.GetGroupWithStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, NotFound("group")
+	}
+	_, mastery, err := s.loadMastery(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	group.GroupMastery = mastery[0]
+	return group, nil
+}
+
+// loadMastery counts the words of each of the groups by state and
+// recommends what to study next in them, in the order of ids
+func (s *GroupService) loadMastery(ctx context.Context, ids []int64) ([]models.GroupProgress, []*models.GroupMastery, error) {
+	progress, err := s.groupRepo.GetGroupProgress(ctx, ids, s.mastery, s.now())
+	if err != nil {
+		return nil, nil, err
+	}
+	activities, err := s.activityRepo.ListStudyActivities(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].Difficulty < activities[j].Difficulty
+	})
+	mastery := make([]*models.GroupMastery, len(progress))
+	for i, p := range progress {
+		mastery[i] = groupMastery(p, activities)
+	}
+	return progress, mastery, nil
+}
+
+// groupMastery breaks the words of a group down by state, given the study
+// activities from the easiest to the hardest
+func groupMastery(p models.GroupProgress, activities []models.StudyActivity) *models.GroupMastery {
+	mastery := &models.GroupMastery{
+		NewCount:      p.NewCount,
+		LearningCount: p.WordCount - p.NewCount - p.MasteredCount - p.LapsedCount,
+		MasteredCount: p.MasteredCount,
+		LapsedCount:   p.LapsedCount,
+		LastStudiedAt: p.LastStudiedAt,
+	}
+	if p.WordCount == 0 || len(activities) == 0 {
+		return mastery
+	}
+	mastery.PercentComplete = roundTenth(100 * float64(p.MasteredCount) / float64(p.WordCount))
+
+	// Lapsed words come first, before they are forgotten further, then those
+	// being learned, then new ones. New and lapsed words are recommended the
+	// easiest activity, words being learned the middle one, and once all are
+	// mastered, the hardest keeps them so.
+	focus, level := models.WordMastered, 2
+	switch {
+	case mastery.LapsedCount > 0:
+		focus, level = models.WordLapsed, 0
+	case mastery.LearningCount > 0:
+		focus, level = models.WordLearning, 1
+	case mastery.NewCount > 0:
+		focus, level = models.WordNew, 0
+	}
+	activity := activities[level*(len(activities)-1)/2]
+	mastery.NextActivity = &models.RecommendedActivity{
+		StudyActivityID: activity.ID,
+		Name:            activity.Name,
+		Focus:           focus,
+	}
+	return mastery
+}
+
+func (s *GroupService) GetGroupWords(ctx context.Context, id int64) ([]*models.Word, error) {
+	return s.groupRepo.GetGroupWords(ctx, id)
+}
+
+func (s *GroupService) CreateGroup(ctx context.Context, input models.GroupInput) (*models.Group, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalidInput(err)
+	}
+	if err := checkNameAvailable(ctx, s.groupRepo, input.Name, 0); err != nil {
+		return nil, err
+	}
+	group, err := s.groupRepo.CreateGroup(ctx, &models.Group{Name: input.Name})
+	return group, nameTaken(err)
+}
+
+func (s *GroupService) UpdateGroup(ctx context.Context, id int64, input models.GroupInput) (*models.Group, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalidInput(err)
+	}
+	if err := checkGroupExists(ctx, s.groupRepo, id); err != nil {
+		return nil, err
+	}
+	if err := checkNameAvailable(ctx, s.groupRepo, input.Name, id); err != nil {
+		return nil, err
+	}
+	group, err := s.groupRepo.UpdateGroup(ctx, &models.Group{ID: id, Name: input.Name})
+	return group, nameTaken(err)
+}
+
+// checkGroupExists returns a not found error if there is no group with id
+func checkGroupExists(ctx context.Context, groups GroupRepository, id int64) error {
+	group, err := groups.GetGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return NotFound("group")
+	}
+	return nil
 }
 
-func (s *GroupService) CreateGroup(group *models.Group) (*models.Group, error) {
-	return s.groupRepo.CreateGroup(group)
+// checkNameAvailable returns a conflict if a group other than exceptID
+// already uses name. A group taking the name meanwhile is caught by the
+// unique index on the names instead, see nameTaken.
+func checkNameAvailable(ctx context.Context, groups GroupRepository, name string, exceptID int64) error {
+	existing, err := groups.FindGroupByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != exceptID {
+		return nameTakenConflict(nil)
+	}
+	return nil
 }
 
-func (s *GroupService) UpdateGroup(group *models.Group) (*models.Group, error) {
-	return s.groupRepo.UpdateGroup(group)
+// nameTaken converts repository.ErrDuplicate, returned when creating or
+// renaming a group to a name taken meanwhile, into the conflict that
+// checkNameAvailable returns. Other errors are returned unchanged.
+func nameTaken(err error) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nameTakenConflict(err)
+	}
+	return err
 }
 
-func (s *GroupService) DeleteGroup(id int64) error {
-	return s.groupRepo.DeleteGroup(id)
+// nameTakenConflict returns the conflict for a group name already taken
+func nameTakenConflict(cause error) error {
+	conflict := Conflict("group name is already taken", cause)
+	conflict.Fields = []FieldError{{Field: "name", Message: "is already taken"}}
+	return conflict
 }
 
-func (s *GroupService) AddWordsToGroup(groupID int64, wordIDs []int64) error {
-	return s.groupRepo.AddWordsToGroup(groupID, wordIDs)
+// DeleteGroup deletes a group along with its word associations and the
+// assignments given on it. The words are kept, but the study sessions on the
+// group cascade, and with them every learner's reviews of it: their XP,
+// streak days and word statistics go too. Deleting a group that doesn't
+// exist is a no-op.
+func (s *GroupService) DeleteGroup(ctx context.Context, id int64) error {
+	return s.uow.WithTx(ctx, func(repos Repositories) error {
+		if err := repos.Classrooms.DeleteGroupAssignments(ctx, id); err != nil {
+			return err
+		}
+		return repos.Groups.DeleteGroup(ctx, id)
+	})
 }
 
-func (s *GroupService) RemoveWordFromGroup(groupID, wordID int64) error {
-	return s.groupRepo.RemoveWordFromGroup(groupID, wordID)
+// AddWordsToGroup adds existing words to a group. Every word ID is checked
+// before anything is written.
+func (s *GroupService) AddWordsToGroup(ctx context.Context, groupID int64, wordIDs []int64) error {
+	if err := models.ValidateGroupWordIDs(wordIDs); err != nil {
+		return invalidInput(err)
+	}
+	if err := checkGroupExists(ctx, s.groupRepo, groupID); err != nil {
+		return err
+	}
+
+	missing, err := s.wordRepo.FindMissingWordIDs(ctx, wordIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return missingIDs("word_ids[%d]", wordIDs, missing)
+	}
+
+	err = s.groupRepo.AddWordsToGroup(ctx, groupID, wordIDs)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return Conflict("word is already in the group", err)
+	}
+	return err
 }
 
-// ListGroupsPaginated returns a paginated list of groups
-func (s *GroupService) ListGroupsPaginated(page, pageSize int) ([]*models.Group, int, error) {
-	return s.groupRepo.ListGroupsPaginated(page, pageSize)
+// MoveWords moves words from one group to another. Either every word moves
+// or, if one of them isn't in the source group or is already in the target
+// group, none does.
+func (s *GroupService) MoveWords(ctx context.Context, fromID, toID int64, wordIDs []int64) error {
+	if err := models.ValidateGroupWordIDs(wordIDs); err != nil {
+		return invalidInput(err)
+	}
+	if toID == fromID {
+		return Validation("invalid input", FieldError{Field: "to_group_id", Message: "must differ from the source group"})
+	}
+
+	return s.uow.WithTx(ctx, func(repos Repositories) error {
+		if err := checkGroupExists(ctx, repos.Groups, fromID); err != nil {
+			return err
+		}
+		target, err := repos.Groups.GetGroup(ctx, toID)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return Validation("invalid input", FieldError{Field: "to_group_id", Message: "does not exist"})
+		}
+
+		words, err := repos.Groups.GetGroupWords(ctx, fromID)
+		if err != nil {
+			return err
+		}
+		inGroup := make(map[int64]bool, len(words))
+		for _, word := range words {
+			inGroup[word.ID] = true
+		}
+		var fields []FieldError
+		for i, id := range wordIDs {
+			if !inGroup[id] {
+				fields = append(fields, FieldError{Field: fmt.Sprintf("word_ids[%d]", i), Message: "is not in the group"})
+			}
+		}
+		if len(fields) > 0 {
+			return Validation("invalid input", fields...)
+		}
+
+		for _, id := range wordIDs {
+			if err := repos.Groups.RemoveWordFromGroup(ctx, fromID, id); err != nil {
+				return err
+			}
+		}
+		err = repos.Groups.AddWordsToGroup(ctx, toID, wordIDs)
+		if errors.Is(err, repository.ErrDuplicate) {
+			return Conflict("word is already in the target group", err)
+		}
+		return err
+	})
+}
+
+// ImportGroup creates a group together with its words, reusing the words
+// that already exist. Nothing is created unless all of it is.
+func (s *GroupService) ImportGroup(ctx context.Context, input models.GroupImport) (*models.GroupWithStats, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalidInput(err)
+	}
+
+	var imported *models.GroupWithStats
+	err := s.uow.WithTx(ctx, func(repos Repositories) error {
+		if err := checkNameAvailable(ctx, repos.Groups, input.Name, 0); err != nil {
+			return err
+		}
+		group, err := repos.Groups.CreateGroup(ctx, &models.Group{Name: input.Name})
+		if err != nil {
+			return nameTaken(err)
+		}
+
+		wordIDs := make([]int64, 0, len(input.Words))
+		for _, in := range input.Words {
+			word, err := repos.Words.FindWord(ctx, in.Portuguese, in.English)
+			if err != nil {
+				return err
+			}
+			if word == nil {
+				word, err = repos.Words.CreateWord(ctx, &models.Word{Portuguese: in.Portuguese, English: in.English})
+				if err != nil {
+					return wordExists(err)
+				}
+			}
+			wordIDs = append(wordIDs, word.ID)
+		}
+		if err := repos.Groups.AddWordsToGroup(ctx, group.ID, wordIDs); err != nil {
+			return err
+		}
+
+		imported, err = repos.Groups.GetGroupWithStats(ctx, group.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return imported, nil
+}
+
+// RemoveWordFromGroup removes a word from a group, keeping the word
+func (s *GroupService) RemoveWordFromGroup(ctx context.Context, groupID, wordID int64) error {
+	err := s.groupRepo.RemoveWordFromGroup(ctx, groupID, wordID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return NotFound("word in group")
+	}
+	return err
+}
+
+// ListGroupsPaginated returns a paginated list of groups with their word
+// counts and mastery
+func (s *GroupService) ListGroupsPaginated(ctx context.Context, page, pageSize int) ([]*models.GroupWithStats, int, error) {
+	groups, total, err := s.groupRepo.ListGroupsPaginated(ctx, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]int64, len(groups))
+	for i, g := range groups {
+		ids[i] = g.ID
+	}
+	progress, mastery, err := s.loadMastery(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*models.GroupWithStats, len(groups))
+	for i, g := range groups {
+		items[i] = &models.GroupWithStats{Group: *g, WordCount: progress[i].WordCount, GroupMastery: mastery[i]}
+	}
+	return items, total, nil
 }
 
 // GetGroupWordsPaginated returns a paginated list of words in a group with stats
-func (s *GroupService) GetGroupWordsPaginated(groupID int64, page, pageSize int) ([]*models.WordWithStats, int, error) {
-	return s.groupRepo.GetGroupWordsPaginated(groupID, page, pageSize)
+func (s *GroupService) GetGroupWordsPaginated(ctx context.Context, groupID int64, page, pageSize int) ([]*models.WordWithStats, int, error) {
+	return s.groupRepo.GetGroupWordsPaginated(ctx, groupID, page, pageSize)
 }
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMastery masters a word after 3 correct answers in a row, for 30 days
+var testMastery = models.MasteryThresholds{MasteredStreak: 3, LapsedAfter: 30 * 24 * time.Hour}
+
+// TestAddWordsToGroup tests the checks made before words are added to a group
+func TestAddWordsToGroup(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	svc := NewGroupService(store.Groups(), store.Words(), store.StudyActivities(), &fakeUnitOfWork{store: store}, testMastery)
+
+	group, err := svc.CreateGroup(ctx, models.GroupInput{Name: "Greetings"})
+	require.NoError(t, err)
+	hello, err := store.Words().CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.AddWordsToGroup(ctx, group.ID, []int64{hello.ID}))
+
+	err = svc.AddWordsToGroup(ctx, group.ID, []int64{hello.ID})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	err = svc.AddWordsToGroup(ctx, group.ID+1, []int64{hello.ID})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = svc.AddWordsToGroup(ctx, group.ID, []int64{hello.ID + 1, hello.ID, hello.ID + 2})
+	var domainErr *Error
+	require.True(t, errors.As(err, &domainErr))
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, []FieldError{
+		{Field: "word_ids[0]", Message: "does not exist"},
+		{Field: "word_ids[2]", Message: "does not exist"},
+	}, domainErr.Fields)
+
+	_, err = svc.CreateGroup(ctx, models.GroupInput{Name: "GREETINGS"})
+	assert.ErrorIs(t, err, ErrConflict, "group names are unique regardless of case")
+
+	count, err := store.Groups().CountGroupWords(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+// TestGroupNameTakenMeanwhile tests that a group name taken between the
+// check and the write is a conflict on the name, not an internal error
+func TestGroupNameTakenMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	groups := racingGroups{store.Groups()}
+	svc := NewGroupService(groups, store.Words(), store.StudyActivities(), &fakeUnitOfWork{store: store}, testMastery)
+
+	_, err := svc.CreateGroup(ctx, models.GroupInput{Name: "Greetings"})
+	require.NoError(t, err)
+	food, err := svc.CreateGroup(ctx, models.GroupInput{Name: "Food"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateGroup(ctx, models.GroupInput{Name: "greetings"})
+	var domainErr *Error
+	require.True(t, errors.As(err, &domainErr))
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, []FieldError{{Field: "name", Message: "is already taken"}}, domainErr.Fields)
+
+	_, err = svc.UpdateGroup(ctx, food.ID, models.GroupInput{Name: "GREETINGS"})
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+// TestGroupMastery tests breaking the words of groups down by state, and the
+// activity recommended next, ranked by difficulty rather than ID
+func TestGroupMastery(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	svc := NewGroupService(store.Groups(), store.Words(), store.StudyActivities(), &fakeUnitOfWork{store: store}, testMastery)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	activities := map[string]*models.StudyActivity{}
+	for _, activity := range []*models.StudyActivity{
+		{Name: "writing", Difficulty: 3},
+		{Name: "flashcards", Difficulty: 1},
+		{Name: "matching", Difficulty: 2},
+	} {
+		require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+		activities[activity.Name] = activity
+	}
+	group, err := svc.CreateGroup(ctx, models.GroupInput{Name: "Greetings"})
+	require.NoError(t, err)
+	empty, err := svc.CreateGroup(ctx, models.GroupInput{Name: "Empty"})
+	require.NoError(t, err)
+
+	startedAt := now.Add(-2 * time.Hour)
+	store.SetClock(func() time.Time { return startedAt })
+	session := &models.StudySession{GroupID: group.ID, StudyActivityID: activities["flashcards"].ID}
+	require.NoError(t, store.StudySessions().CreateStudySession(ctx, session))
+	word := func(portuguese string) *models.Word {
+		t.Helper()
+		w, err := store.Words().CreateWord(ctx, &models.Word{Portuguese: portuguese, English: portuguese})
+		require.NoError(t, err)
+		require.NoError(t, svc.AddWordsToGroup(ctx, group.ID, []int64{w.ID}))
+		return w
+	}
+	answer := func(w *models.Word, at time.Time, answers ...bool) {
+		t.Helper()
+		for i, correct := range answers {
+			review := &models.WordReviewItem{StudySessionID: session.ID, WordID: w.ID, Correct: correct, CreatedAt: at.Add(time.Duration(i) * time.Minute)}
+			require.NoError(t, store.StudySessions().CreateReview(ctx, review))
+		}
+	}
+	word("olá")
+	answer(word("tchau"), now.Add(-time.Hour), true, true)
+	answer(word("sim"), now.Add(-time.Hour), true, true, true)
+	lapsed := word("não")
+	answer(lapsed, now.Add(-31*24*time.Hour), false, true, true, true)
+	answer(word("obrigado"), now.Add(-time.Hour), true, true, true, false)
+
+	detail, err := svc.GetGroup(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.GroupStats{
+		TotalWordCount: 5,
+		GroupMastery: models.GroupMastery{
+			NewCount:        1,
+			LearningCount:   2,
+			MasteredCount:   1,
+			LapsedCount:     1,
+			PercentComplete: 20,
+			LastStudiedAt:   &startedAt,
+			NextActivity:    &models.RecommendedActivity{StudyActivityID: activities["flashcards"].ID, Name: "flashcards", Focus: models.WordLapsed},
+		},
+	}, detail.Stats)
+
+	groups, total, err := svc.ListGroupsPaginated(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, groups, 2)
+	assert.Equal(t, 5, groups[0].WordCount)
+	assert.Equal(t, detail.Stats.GroupMastery, *groups[0].GroupMastery)
+	assert.Equal(t, empty.ID, groups[1].ID)
+	assert.Equal(t, models.GroupMastery{}, *groups[1].GroupMastery, "nothing to recommend without words")
+
+	// Reviewing the lapsed word again leaves the words being learned
+	now = now.Add(time.Hour)
+	answer(lapsed, now, true)
+	withStats, err := svc.GetGroupWithStats(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, withStats.MasteredCount)
+	assert.Equal(t, 40.0, withStats.PercentComplete)
+	assert.Equal(t, &models.RecommendedActivity{StudyActivityID: activities["matching"].ID, Name: "matching", Focus: models.WordLearning}, withStats.NextActivity)
+}
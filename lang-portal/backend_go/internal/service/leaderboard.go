@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
+
+// minAccuracyReviews is how many reviews a learner needs in a period to be
+// ranked by accuracy, so that a single right answer doesn't top the board
+const minAccuracyReviews = 10
+
+// maxDisplayNameLength is the longest leaderboard display name, in characters
+const maxDisplayNameLength = 30
+
+// LeaderboardService ranks the members of classrooms by what they studied
+// over rolling periods. Leaderboards are read from snapshots, which
+// RefreshLeaderboards recomputes in the background; a leaderboard without a
+// snapshot yet is computed when first read.
+type LeaderboardService struct {
+	leaderboardRepo LeaderboardRepository
+	classroomRepo   ClassroomRepository
+	userRepo        UserRepository
+	masteryStreak   int
+	// pseudonymSecret keys the pseudonyms, so that they can't be told from
+	// the user IDs without it
+	pseudonymSecret []byte
+	now             func() time.Time
+}
+
+func NewLeaderboardService(
+	leaderboardRepo LeaderboardRepository,
+	classroomRepo ClassroomRepository,
+	userRepo UserRepository,
+	masteryStreak int,
+	pseudonymSecret []byte,
+) *LeaderboardService {
+	return &LeaderboardService{
+		leaderboardRepo: leaderboardRepo,
+		classroomRepo:   classroomRepo,
+		userRepo:        userRepo,
+		masteryStreak:   masteryStreak,
+		pseudonymSecret: pseudonymSecret,
+		now:             time.Now,
+	}
+}
+
+// LeaderboardEntry is a learner's place on a leaderboard. Learners with the
+// same value share a rank.
+type LeaderboardEntry struct {
+	Rank        int     `json:"rank"`
+	DisplayName string  `json:"display_name"`
+	Value       float64 `json:"value"`
+}
+
+// Leaderboard ranks the members of a classroom by a metric over a period, as
+// of ComputedAt
+type Leaderboard struct {
+	ClassroomID int64                    `json:"classroom_id"`
+	Metric      models.LeaderboardMetric `json:"metric"`
+	Period      models.LeaderboardPeriod `json:"period"`
+	ComputedAt  time.Time                `json:"computed_at"`
+	Entries     []LeaderboardEntry       `json:"entries"`
+}
+
+// LeaderboardProfileInput sets how a learner appears on leaderboards. An
+// empty DisplayName means a pseudonym.
+type LeaderboardProfileInput struct {
+	DisplayName string
+	OptOut      bool
+}
+
+// GetLeaderboard returns the leaderboard of a classroom by metric over
+// period, xp and weekly if empty. Members who opted out are left out, as
+// are those with nothing to show: no XP or words mastered, or fewer than
+// minAccuracyReviews reviews for accuracy.
+func (s *LeaderboardService) GetLeaderboard(ctx context.Context, classroomID int64, metric models.LeaderboardMetric, period models.LeaderboardPeriod) (*Leaderboard, error) {
+	if metric == "" {
+		metric = models.LeaderboardMetrics[0]
+	}
+	if period == "" {
+		period = models.LeaderboardPeriods[0]
+	}
+	var v validation.Validator
+	if !slices.Contains(models.LeaderboardMetrics, metric) {
+		v.Add("metric", fmt.Sprintf("must be one of: %s %s %s",
+			models.LeaderboardXP, models.LeaderboardAccuracy, models.LeaderboardWordsMastered))
+	}
+	if !slices.Contains(models.LeaderboardPeriods, period) {
+		v.Add("period", fmt.Sprintf("must be one of: %s %s %s", models.PeriodDaily, models.PeriodWeekly, models.PeriodAllTime))
+	}
+	if err := v.Err(); err != nil {
+		return nil, invalidInput(err)
+	}
+
+	classroom, err := s.classroomRepo.GetClassroom(ctx, classroomID)
+	if err != nil {
+		return nil, err
+	}
+	if classroom == nil {
+		return nil, NotFound("classroom")
+	}
+
+	snapshot, err := s.leaderboardRepo.GetSnapshot(ctx, classroomID, period)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		if err := s.refresh(ctx, classroomID, period, s.now()); err != nil {
+			return nil, err
+		}
+		if snapshot, err = s.leaderboardRepo.GetSnapshot(ctx, classroomID, period); err != nil {
+			return nil, err
+		}
+	}
+
+	board := &Leaderboard{
+		ClassroomID: classroomID,
+		Metric:      metric,
+		Period:      period,
+		ComputedAt:  snapshot.ComputedAt,
+		Entries:     []LeaderboardEntry{},
+	}
+	for _, standing := range snapshot.Standings {
+		if standing.OptOut {
+			continue
+		}
+		var value float64
+		switch metric {
+		case models.LeaderboardAccuracy:
+			if standing.Reviews < minAccuracyReviews {
+				continue
+			}
+			value = roundTenth(accuracy(standing.CorrectCount, standing.Reviews-standing.CorrectCount))
+		case models.LeaderboardWordsMastered:
+			value = float64(standing.WordsMastered)
+		default:
+			value = float64(standing.XP)
+		}
+		if value == 0 {
+			continue
+		}
+		board.Entries = append(board.Entries, LeaderboardEntry{
+			DisplayName: s.displayName(standing.UserID, standing.DisplayName),
+			Value:       value,
+		})
+	}
+
+	sort.SliceStable(board.Entries, func(i, j int) bool {
+		a, b := board.Entries[i], board.Entries[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return a.DisplayName < b.DisplayName
+	})
+	for i := range board.Entries {
+		board.Entries[i].Rank = i + 1
+		if i > 0 && board.Entries[i].Value == board.Entries[i-1].Value {
+			board.Entries[i].Rank = board.Entries[i-1].Rank
+		}
+	}
+	return board, nil
+}
+
+// RefreshLeaderboards recomputes the snapshots of every classroom over every
+// period. A classroom that fails doesn't stop the others; the errors are
+// returned together.
+func (s *LeaderboardService) RefreshLeaderboards(ctx context.Context) error {
+	ids, err := s.classroomRepo.ListClassroomIDs(ctx)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	var errs []error
+	for _, id := range ids {
+		for _, period := range models.LeaderboardPeriods {
+			if err := s.refresh(ctx, id, period, now); err != nil {
+				errs = append(errs, fmt.Errorf("classroom %d, %s: %w", id, period, err))
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refresh recomputes the snapshot of a classroom over the period ending now
+func (s *LeaderboardService) refresh(ctx context.Context, classroomID int64, period models.LeaderboardPeriod, now time.Time) error {
+	standings, err := s.leaderboardRepo.ComputeStandings(ctx, classroomID, period.Since(now), s.masteryStreak)
+	if err != nil {
+		return err
+	}
+	return s.leaderboardRepo.SaveSnapshot(ctx, &models.LeaderboardSnapshot{
+		ClassroomID: classroomID,
+		Period:      period,
+		ComputedAt:  now,
+		Standings:   standings,
+	})
+}
+
+// GetLeaderboardProfile returns how a user appears on leaderboards, under
+// their pseudonym if they haven't chosen a display name
+func (s *LeaderboardService) GetLeaderboardProfile(ctx context.Context, userID int64) (*models.LeaderboardProfile, error) {
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, NotFound("user")
+	}
+
+	profile, err := s.leaderboardRepo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		profile = &models.LeaderboardProfile{UserID: userID}
+	}
+	profile.DisplayName = s.displayName(userID, profile.DisplayName)
+	return profile, nil
+}
+
+// SetLeaderboardProfile sets how a user appears on leaderboards. Opting out
+// and changing names apply to the snapshots already computed.
+func (s *LeaderboardService) SetLeaderboardProfile(ctx context.Context, userID int64, in LeaderboardProfileInput) (*models.LeaderboardProfile, error) {
+	name := strings.TrimSpace(in.DisplayName)
+	var v validation.Validator
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		v.Add("display_name", fmt.Sprintf("must be at most %d characters", maxDisplayNameLength))
+	}
+	if err := v.Err(); err != nil {
+		return nil, invalidInput(err)
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, NotFound("user")
+	}
+
+	err = s.leaderboardRepo.SetProfile(ctx, &models.LeaderboardProfile{UserID: userID, DisplayName: name, OptOut: in.OptOut})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetLeaderboardProfile(ctx, userID)
+}
+
+// Words pseudonyms are made of
+var (
+	pseudonymAdjectives = []string{
+		"Brave", "Bright", "Calm", "Clever", "Curious", "Eager", "Gentle", "Happy",
+		"Jolly", "Keen", "Lively", "Lucky", "Nimble", "Quiet", "Swift", "Witty",
+	}
+	pseudonymAnimals = []string{
+		"Badger", "Dolphin", "Falcon", "Fox", "Gecko", "Heron", "Koala", "Lynx",
+		"Otter", "Owl", "Panda", "Parrot", "Puffin", "Seal", "Tiger", "Wolf",
+	}
+)
+
+// displayName returns chosen, or if empty the pseudonym of the user, such as
+// "Swift Otter 42". The pseudonym is an HMAC of the user ID, so it stays the
+// same as long as the secret does, and the IDs being sequential doesn't give
+// away whom it stands for.
+func (s *LeaderboardService) displayName(userID int64, chosen string) string {
+	if chosen != "" {
+		return chosen
+	}
+	mac := hmac.New(sha256.New, s.pseudonymSecret)
+	binary.Write(mac, binary.BigEndian, userID)
+	sum := binary.BigEndian.Uint64(mac.Sum(nil))
+	adjective := pseudonymAdjectives[sum%uint64(len(pseudonymAdjectives))]
+	sum /= uint64(len(pseudonymAdjectives))
+	animal := pseudonymAnimals[sum%uint64(len(pseudonymAnimals))]
+	sum /= uint64(len(pseudonymAnimals))
+	return fmt.Sprintf("%s %s %d", adjective, animal, sum%100)
+}
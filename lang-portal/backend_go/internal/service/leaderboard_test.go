@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetLeaderboard tests ranking classroom members by each metric over the
+// rolling periods, from snapshots refreshed in the background
+func TestGetLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	users, classrooms, sessions := store.Users(), store.Classrooms(), store.StudySessions()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	svc := NewLeaderboardService(store.Leaderboards(), classrooms, users, 3, []byte("secret"))
+	svc.now = func() time.Time { return now }
+
+	teacher, err := users.CreateUser(ctx, &models.User{Name: "Teresa", Role: models.RoleTeacher})
+	require.NoError(t, err)
+	classroom, err := classrooms.CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: teacher.ID})
+	require.NoError(t, err)
+	var students []*models.User
+	for _, name := range []string{"Ana", "Bruno", "Carla", "Duarte"} {
+		student, err := users.CreateUser(ctx, &models.User{Name: name, Role: models.RoleStudent})
+		require.NoError(t, err)
+		students = append(students, student)
+	}
+	ana, bruno, carla, duarte := students[0], students[1], students[2], students[3]
+	require.NoError(t, classrooms.AddMembers(ctx, classroom.ID, []int64{ana.ID, bruno.ID, carla.ID}))
+
+	group, err := store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+
+	hello, bye, thanks := int64(1), int64(2), int64(3)
+	review := func(user *models.User, wordID int64, correct bool, xp int, at time.Time) {
+		session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, UserID: &user.ID, CreatedAt: at}
+		require.NoError(t, sessions.CreateStudySession(ctx, session))
+		require.NoError(t, sessions.CreateReview(ctx, &models.WordReviewItem{
+			StudySessionID: session.ID, WordID: wordID, Correct: correct, XP: xp, CreatedAt: at,
+		}))
+	}
+	for i := 0; i < 10; i++ {
+		review(ana, bye, true, 10, now.AddDate(0, 0, -10).Add(time.Duration(i)*time.Minute))
+	}
+	for i := 0; i < 3; i++ {
+		review(ana, hello, true, 10, now.Add(time.Duration(i-60)*time.Minute))
+		review(carla, hello, true, 10, now.Add(time.Duration(i-30)*time.Minute))
+		review(bruno, thanks, false, 0, now.AddDate(0, 0, -2).Add(time.Duration(i)*time.Minute))
+	}
+	for i := 0; i < 9; i++ {
+		review(bruno, thanks, true, 10, now.AddDate(0, 0, -2).Add(time.Duration(i+3)*time.Minute))
+	}
+	// Duarte isn't a member of the classroom
+	review(duarte, hello, true, 100, now.Add(-time.Minute))
+
+	_, err = svc.SetLeaderboardProfile(ctx, ana.ID, LeaderboardProfileInput{DisplayName: "  Ana S.  "})
+	require.NoError(t, err)
+	pseudonym := svc.displayName(carla.ID, "")
+
+	board, err := svc.GetLeaderboard(ctx, classroom.ID, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.LeaderboardXP, board.Metric)
+	assert.Equal(t, models.PeriodWeekly, board.Period)
+	assert.Equal(t, now, board.ComputedAt)
+	assert.Equal(t, []LeaderboardEntry{
+		{Rank: 1, DisplayName: svc.displayName(bruno.ID, ""), Value: 90},
+		{Rank: 2, DisplayName: "Ana S.", Value: 30},
+		{Rank: 2, DisplayName: pseudonym, Value: 30},
+	}, board.Entries)
+
+	board, err = svc.GetLeaderboard(ctx, classroom.ID, models.LeaderboardXP, models.PeriodDaily)
+	require.NoError(t, err)
+	assert.Equal(t, []LeaderboardEntry{
+		{Rank: 1, DisplayName: "Ana S.", Value: 30},
+		{Rank: 1, DisplayName: pseudonym, Value: 30},
+	}, board.Entries)
+
+	// Only Bruno made enough reviews to be ranked by accuracy
+	board, err = svc.GetLeaderboard(ctx, classroom.ID, models.LeaderboardAccuracy, models.PeriodWeekly)
+	require.NoError(t, err)
+	assert.Equal(t, []LeaderboardEntry{
+		{Rank: 1, DisplayName: svc.displayName(bruno.ID, ""), Value: 75},
+	}, board.Entries)
+
+	board, err = svc.GetLeaderboard(ctx, classroom.ID, models.LeaderboardWordsMastered, models.PeriodAllTime)
+	require.NoError(t, err)
+	assert.Equal(t, []LeaderboardEntry{
+		{Rank: 1, DisplayName: "Ana S.", Value: 2},
+		{Rank: 2, DisplayName: svc.displayName(bruno.ID, ""), Value: 1},
+		{Rank: 2, DisplayName: pseudonym, Value: 1},
+	}, board.Entries)
+
+	// Reviews show once the snapshots are refreshed, opting out at once
+	review(carla, bye, true, 50, now)
+	board, err = svc.GetLeaderboard(ctx, classroom.ID, models.LeaderboardXP, models.PeriodDaily)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, board.Entries[1].Value)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, svc.RefreshLeaderboards(ctx))
+	board, err = svc.GetLeaderboard(ctx, classroom.ID, models.LeaderboardXP, models.PeriodDaily)
+	require.NoError(t, err)
+	assert.Equal(t, now, board.ComputedAt)
+	assert.Equal(t, []LeaderboardEntry{
+		{Rank: 1, DisplayName: pseudonym, Value: 80},
+		{Rank: 2, DisplayName: "Ana S.", Value: 30},
+	}, board.Entries)
+
+	_, err = svc.SetLeaderboardProfile(ctx, carla.ID, LeaderboardProfileInput{OptOut: true})
+	require.NoError(t, err)
+	board, err = svc.GetLeaderboard(ctx, classroom.ID, models.LeaderboardXP, models.PeriodDaily)
+	require.NoError(t, err)
+	assert.Equal(t, []LeaderboardEntry{
+		{Rank: 1, DisplayName: "Ana S.", Value: 30},
+	}, board.Entries)
+
+	_, err = svc.GetLeaderboard(ctx, classroom.ID, "streak", models.PeriodDaily)
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = svc.GetLeaderboard(ctx, classroom.ID, models.LeaderboardXP, "monthly")
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = svc.GetLeaderboard(ctx, classroom.ID+1, "", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestLeaderboardProfile tests choosing a display name, falling back to a
+// pseudonym, and opting out
+func TestLeaderboardProfile(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	svc := NewLeaderboardService(store.Leaderboards(), store.Classrooms(), store.Users(), 3, []byte("secret"))
+	user, err := store.Users().CreateUser(ctx, &models.User{Name: "Ana"})
+	require.NoError(t, err)
+
+	profile, err := svc.GetLeaderboardProfile(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &models.LeaderboardProfile{UserID: user.ID, DisplayName: svc.displayName(user.ID, "")}, profile)
+	assert.NotEqual(t, profile.DisplayName, svc.displayName(user.ID+1, ""))
+	other := NewLeaderboardService(store.Leaderboards(), store.Classrooms(), store.Users(), 3, []byte("other secret"))
+	assert.NotEqual(t, profile.DisplayName, other.displayName(user.ID, ""), "pseudonyms depend on the secret")
+
+	profile, err = svc.SetLeaderboardProfile(ctx, user.ID, LeaderboardProfileInput{DisplayName: "Aninha", OptOut: true})
+	require.NoError(t, err)
+	assert.Equal(t, &models.LeaderboardProfile{UserID: user.ID, DisplayName: "Aninha", OptOut: true}, profile)
+
+	profile, err = svc.SetLeaderboardProfile(ctx, user.ID, LeaderboardProfileInput{DisplayName: " "})
+	require.NoError(t, err)
+	assert.Equal(t, &models.LeaderboardProfile{UserID: user.ID, DisplayName: svc.displayName(user.ID, "")}, profile)
+
+	_, err = svc.SetLeaderboardProfile(ctx, user.ID, LeaderboardProfileInput{DisplayName: strings.Repeat("á", maxDisplayNameLength+1)})
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = svc.SetLeaderboardProfile(ctx, user.ID, LeaderboardProfileInput{DisplayName: strings.Repeat("á", maxDisplayNameLength)})
+	assert.NoError(t, err)
+	_, err = svc.GetLeaderboardProfile(ctx, user.ID+1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.SetLeaderboardProfile(ctx, user.ID+1, LeaderboardProfileInput{})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+// The repositories the services depend on. internal/repository implements
+// them on SQLite and internal/repository/memory in memory, for tests; both
+// pass the conformance suite in internal/repository/repotest.
+//
+// Getters return nil and no error when the record doesn't exist. Paginated
+// methods take a 1-based page, the others an offset and limit.
+
+// Repositories holds one of each repository, all bound to the same database
+// or transaction
+type Repositories struct {
+	Words           WordRepository
+	Groups          GroupRepository
+	StudyActivities StudyActivityRepository
+	StudySessions   StudySessionRepository
+	Users           UserRepository
+	Classrooms      ClassroomRepository
+	Gamification    GamificationRepository
+	Leaderboards    LeaderboardRepository
+}
+
+// UnitOfWork runs writes that span several repositories atomically. WithTx
+// calls fn with repositories bound to a new transaction, which is committed
+// if fn returns nil and rolled back if it returns an error or panics. fn's
+// error is returned as is.
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+// WordRepository stores words and reads their review counts. CreateWord and
+// UpdateWord return repository.ErrDuplicate when another word has the same
+// Portuguese and English text.
+type WordRepository interface {
+	GetWord(ctx context.Context, id int64) (*models.Word, error)
+	GetWordWithStats(ctx context.Context, id int64) (*models.WordWithStats, error)
+	ListWords(ctx context.Context) ([]*models.Word, error)
+	ListWordsWithStatsPaginated(ctx context.Context, page, pageSize int) ([]*models.WordWithStats, int, error)
+	CountWords(ctx context.Context) (int, error)
+	FindWord(ctx context.Context, portuguese, english string) (*models.Word, error)
+	CreateWord(ctx context.Context, word *models.Word) (*models.Word, error)
+	UpdateWord(ctx context.Context, word *models.Word) (*models.Word, error)
+	DeleteWord(ctx context.Context, id int64) error
+	GetWordGroups(ctx context.Context, wordID int64) ([]models.WordGroup, error)
+	FindMissingWordIDs(ctx context.Context, wordIDs []int64) ([]int64, error)
+	// ListLeeches returns the leeches of the group with ID groupID, or of
+	// all words if 0, the most often answered wrong first
+	ListLeeches(ctx context.Context, groupID int64, c models.LeechCriteria) ([]*models.WordWithStats, error)
+}
+
+// GroupRepository stores groups and their words. AddWordsToGroup returns
+// repository.ErrDuplicate when a word is already in the group, and
+// CreateGroup and UpdateGroup when another group has the name, regardless of
+// case.
+type GroupRepository interface {
+	GetGroup(ctx context.Context, id int64) (*models.Group, error)
+	FindGroupByName(ctx context.Context, name string) (*models.Group, error)
+	GetGroupWithStats(ctx context.Context, id int64) (*models.GroupWithStats, error)
+	ListGroups(ctx context.Context) ([]*models.Group, error)
+	ListGroupsPaginated(ctx context.Context, page, pageSize int) ([]*models.Group, int, error)
+	CreateGroup(ctx context.Context, group *models.Group) (*models.Group, error)
+	UpdateGroup(ctx context.Context, group *models.Group) (*models.Group, error)
+	DeleteGroup(ctx context.Context, id int64) error
+	GetGroupWords(ctx context.Context, groupID int64) ([]*models.Word, error)
+	GetGroupWordsPaginated(ctx context.Context, groupID int64, page, pageSize int) ([]*models.WordWithStats, int, error)
+	CountGroupWords(ctx context.Context, groupID int64) (int, error)
+	AddWordsToGroup(ctx context.Context, groupID int64, wordIDs []int64) error
+	RemoveWordFromGroup(ctx context.Context, groupID, wordID int64) error
+	// GetGroupProgress counts the words of each of the groups by state as
+	// of now, in the order of groupIDs
+	GetGroupProgress(ctx context.Context, groupIDs []int64, t models.MasteryThresholds, now time.Time) ([]models.GroupProgress, error)
+}
+
+// StudyActivityRepository stores study activities and lists their sessions.
+// CreateStudyActivity returns repository.ErrDuplicate when another activity
+// has the name.
+type StudyActivityRepository interface {
+	GetStudyActivity(ctx context.Context, id int64) (*models.StudyActivity, error)
+	ListStudyActivities(ctx context.Context) ([]models.StudyActivity, error)
+	ListStudyActivitiesPaginated(ctx context.Context, offset, limit int) ([]models.StudyActivity, error)
+	CountStudyActivities(ctx context.Context) (int, error)
+	CreateStudyActivity(ctx context.Context, activity *models.StudyActivity) error
+	GetStudyActivitySessions(ctx context.Context, activityID int64, offset, limit int) ([]models.StudySessionDetail, error)
+	CountStudyActivitySessions(ctx context.Context, activityID int64) (int, error)
+}
+
+// StudySessionRepository stores study sessions and the reviews made in them.
+// CreateStudySession and CreateReview return repository.ErrForeignKey when a
+// record they reference doesn't exist.
+type StudySessionRepository interface {
+	GetStudySession(ctx context.Context, id int64) (*models.StudySessionDetail, error)
+	GetLastStudySession(ctx context.Context) (*models.StudySessionDetail, error)
+	ListStudySessions(ctx context.Context, offset, limit int) ([]models.StudySessionDetail, error)
+	CountStudySessions(ctx context.Context) (int, error)
+	CreateStudySession(ctx context.Context, session *models.StudySession) error
+	CreateReview(ctx context.Context, review *models.WordReviewItem) error
+	// GetSessionCombo counts the answers right in a row at the end of a
+	// session, in the order they were recorded
+	GetSessionCombo(ctx context.Context, sessionID int64) (int, error)
+	// EndStudySession ends a session at at, unless it has already ended
+	EndStudySession(ctx context.Context, id int64, at time.Time) error
+	GetTotalDistinctWordsStudied(ctx context.Context) (int, error)
+	GetWordReviewStats(ctx context.Context) (correct int, total int, err error)
+	GetTotalActiveGroups(ctx context.Context) (int, error)
+	// ListStudyTimes returns the quarter hours in which the learner with ID
+	// userID, the guest if 0, started sessions, oldest first, in UTC
+	ListStudyTimes(ctx context.Context, userID int64) ([]time.Time, error)
+	// ListSessionActivity returns the sessions started from from until
+	// before to, oldest first
+	ListSessionActivity(ctx context.Context, from, to time.Time) ([]models.SessionActivity, error)
+	// GetRecallBuckets counts the reviews of scope by the interval since the
+	// previous review of the same word by the same learner, one bucket per
+	// bound: bucket i holds those made from bounds[i] until before
+	// bounds[i+1] after it
+	GetRecallBuckets(ctx context.Context, scope models.ReviewScope, bounds []time.Duration) ([]models.RecallBucket, error)
+	// ListWordReviews returns the reviews of a word, oldest first, with the
+	// interval since the same learner's previous one
+	ListWordReviews(ctx context.Context, wordID int64) ([]models.WordReviewItem, error)
+}
+
+// GamificationRepository reads the study of each learner and stores their
+// daily goals and achievements. A learner is the user with ID userID, or the
+// guest if 0: the sessions started without a user.
+type GamificationRepository interface {
+	// GetLearnerTotals sums up the reviews of a learner
+	GetLearnerTotals(ctx context.Context, userID int64) (*models.LearnerTotals, error)
+	// CountPerfectSessions counts the sessions of a learner ended with at
+	// least perfectReviews reviews, all correct
+	CountPerfectSessions(ctx context.Context, userID int64, perfectReviews int) (int, error)
+	// ListSessionActivity returns the sessions the learner started from from
+	// until before to, oldest first
+	ListSessionActivity(ctx context.Context, userID int64, from, to time.Time) ([]models.SessionActivity, error)
+	GetDailyGoal(ctx context.Context, userID int64) (*models.DailyGoal, error)
+	SetDailyGoal(ctx context.Context, goal *models.DailyGoal) error
+	// ListUnlockedAchievements returns the achievements of a learner, the
+	// first unlocked first
+	ListUnlockedAchievements(ctx context.Context, userID int64) ([]models.UnlockedAchievement, error)
+	// UnlockAchievement does nothing if the learner already has it
+	UnlockAchievement(ctx context.Context, achievement *models.UnlockedAchievement) error
+}
+
+// UserRepository stores students and teachers
+type UserRepository interface {
+	GetUser(ctx context.Context, id int64) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+}
+
+// ClassroomRepository stores classrooms, their members and assignments.
+// CreateClassroom, AddMembers and CreateAssignment return
+// repository.ErrForeignKey when a record they reference doesn't exist.
+type ClassroomRepository interface {
+	GetClassroom(ctx context.Context, id int64) (*models.Classroom, error)
+	ListClassroomIDs(ctx context.Context) ([]int64, error)
+	CreateClassroom(ctx context.Context, classroom *models.Classroom) (*models.Classroom, error)
+	ListMembers(ctx context.Context, classroomID int64) ([]models.User, error)
+	AddMembers(ctx context.Context, classroomID int64, userIDs []int64) error
+	RemoveMember(ctx context.Context, classroomID, userID int64) error
+	DeleteGroupAssignments(ctx context.Context, groupID int64) error
+	GetAssignment(ctx context.Context, classroomID, id int64) (*models.Assignment, error)
+	CreateAssignment(ctx context.Context, assignment *models.Assignment) (*models.Assignment, error)
+	ListAssignments(ctx context.Context, classroomID int64) ([]*models.Assignment, error)
+	ListUserAssignments(ctx context.Context, userID int64) ([]*models.Assignment, error)
+	GetAssignmentProgress(ctx context.Context, assignment *models.Assignment, userID *int64) ([]models.AssignmentProgress, error)
+}
+
+// LeaderboardRepository computes the leaderboards of classrooms and caches
+// them as snapshots, and stores how learners appear on them
+type LeaderboardRepository interface {
+	// ComputeStandings aggregates the reviews the members of a classroom
+	// made since the given time, or ever if it is zero, in their own
+	// sessions. Members who made none are left out. A word is mastered by
+	// masteryStreak answers right in a row.
+	ComputeStandings(ctx context.Context, classroomID int64, since time.Time, masteryStreak int) ([]models.LeaderboardStanding, error)
+	// SaveSnapshot replaces the snapshot of the classroom and period
+	SaveSnapshot(ctx context.Context, snapshot *models.LeaderboardSnapshot) error
+	// GetSnapshot returns the standings of those still members of the
+	// classroom, with their profiles, by user ID
+	GetSnapshot(ctx context.Context, classroomID int64, period models.LeaderboardPeriod) (*models.LeaderboardSnapshot, error)
+	GetProfile(ctx context.Context, userID int64) (*models.LeaderboardProfile, error)
+	SetProfile(ctx context.Context, profile *models.LeaderboardProfile) error
+}
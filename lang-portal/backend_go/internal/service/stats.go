@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
+
+// maxActivityDays is the longest activity history returned at once, a year
+// and a day so that a heatmap can show a full year in any time zone
+const maxActivityDays = 367
+
+// StatsService reports the study history in the learner's time zone
+type StatsService struct {
+	studySessionRepo StudySessionRepository
+	gamificationRepo GamificationRepository
+	userRepo         UserRepository
+	now              func() time.Time
+}
+
+func NewStatsService(studySessionRepo StudySessionRepository, gamificationRepo GamificationRepository, userRepo UserRepository) *StatsService {
+	return &StatsService{
+		studySessionRepo: studySessionRepo,
+		gamificationRepo: gamificationRepo,
+		userRepo:         userRepo,
+		now:              time.Now,
+	}
+}
+
+// ActivityRange selects the days of an activity history. Empty fields take
+// their defaults.
+type ActivityRange struct {
+	// From is the first day, as YYYY-MM-DD. It defaults to a year before To.
+	From string
+	// To is the last day, as YYYY-MM-DD. It defaults to today.
+	To string
+	// TimeZone is the IANA name of the time zone whose midnights end the
+	// days, such as America/Sao_Paulo. It defaults to UTC.
+	TimeZone string
+}
+
+// ActivityHistory is the study activity of every day of a range
+type ActivityHistory struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	TimeZone string          `json:"time_zone"`
+	Days     []DailyActivity `json:"days"`
+}
+
+// DailyActivity is the study activity of one day. Sessions count on the day
+// they were started, along with their reviews and time.
+type DailyActivity struct {
+	Date         string  `json:"date"`
+	Sessions     int     `json:"sessions"`
+	Reviews      int     `json:"reviews"`
+	CorrectCount int     `json:"correct_count"`
+	Accuracy     float64 `json:"accuracy"`
+	// Minutes is the time from the start of each session to its last review
+	Minutes float64 `json:"minutes"`
+}
+
+// GetActivity returns the study activity of each day of r, including the
+// days without any
+func (s *StatsService) GetActivity(ctx context.Context, r ActivityRange) (*ActivityHistory, error) {
+	loc, from, to, err := s.parseRange(r)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make([]DailyActivity, daysBetween(from, to)+1)
+	index := make(map[string]int, len(days))
+	for i := range days {
+		days[i].Date = addDays(from, i).Format(time.DateOnly)
+		index[days[i].Date] = i
+	}
+
+	sessions, err := s.studySessionRepo.ListSessionActivity(ctx, from, addDays(to, 1))
+	if err != nil {
+		return nil, err
+	}
+	minutes := make([]time.Duration, len(days))
+	for _, session := range sessions {
+		i, ok := index[session.StartedAt.In(loc).Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		days[i].Sessions++
+		days[i].Reviews += session.ReviewCount
+		days[i].CorrectCount += session.CorrectCount
+		if session.EndedAt != nil && session.EndedAt.After(session.StartedAt) {
+			minutes[i] += session.EndedAt.Sub(session.StartedAt)
+		}
+	}
+	for i := range days {
+		days[i].Accuracy = accuracy(days[i].CorrectCount, days[i].Reviews-days[i].CorrectCount)
+		days[i].Minutes = roundTenth(minutes[i].Minutes())
+	}
+
+	return &ActivityHistory{
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		TimeZone: loc.String(),
+		Days:     days,
+	}, nil
+}
+
+// parseRange resolves the time zone of r and the midnights starting its first
+// and last days
+func (s *StatsService) parseRange(r ActivityRange) (loc *time.Location, from, to time.Time, err error) {
+	var v validation.Validator
+	loc = parseTimeZone(&v, r.TimeZone)
+	now := s.now().In(loc)
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if r.To != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, r.To, loc); err != nil {
+			v.Add("to", "must be a date formatted as YYYY-MM-DD")
+		}
+	}
+	from = to.AddDate(-1, 0, 1)
+	if r.From != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, r.From, loc); err != nil {
+			v.Add("from", "must be a date formatted as YYYY-MM-DD")
+		}
+	}
+
+	if v.Err() == nil {
+		switch {
+		case from.After(to):
+			v.Add("from", "must not be after to")
+		case daysBetween(from, to) >= maxActivityDays:
+			v.Add("from", fmt.Sprintf("must be at most %d days before to", maxActivityDays-1))
+		}
+	}
+	if err := v.Err(); err != nil {
+		return nil, time.Time{}, time.Time{}, invalidInput(err)
+	}
+	return loc, from, to, nil
+}
+
+// parseTimeZone returns the time zone called name, or UTC if name is empty.
+// An unknown name is reported to v as the tz parameter, and UTC returned.
+func parseTimeZone(v *validation.Validator, name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		v.Add("tz", "must be an IANA time zone name, such as America/Sao_Paulo")
+		return time.UTC
+	}
+	return loc
+}
+
+// addDays returns the start of the nth day after the one day starts. Days
+// don't all last 24 hours, and may not start at midnight when daylight
+// saving time does.
+func addDays(day time.Time, n int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+n, 0, 0, 0, 0, day.Location())
+}
+
+// daysBetween counts the days from the one from starts to the one to starts,
+// whatever daylight saving time changes are in between
+func daysBetween(from, to time.Time) int {
+	date := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	return int(date(to).Sub(date(from)) / (24 * time.Hour))
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStatsFixture returns a stats service on a memory store, and a function
+// recording a session started at the given time with reviews answered the
+// given durations later
+func newStatsFixture(t *testing.T) (*StatsService, func(start time.Time, answers ...time.Duration)) {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	word, err := store.Words().CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	require.NoError(t, err)
+	group, err := store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+
+	study := func(start time.Time, answers ...time.Duration) {
+		t.Helper()
+		session := &models.StudySession{GroupID: group.ID, StudyActivityID: activity.ID, CreatedAt: start}
+		require.NoError(t, store.StudySessions().CreateStudySession(ctx, session))
+		for i, after := range answers {
+			review := &models.WordReviewItem{StudySessionID: session.ID, WordID: word.ID, Correct: i%2 == 0, CreatedAt: start.Add(after)}
+			require.NoError(t, store.StudySessions().CreateReview(ctx, review))
+		}
+	}
+	return NewStatsService(store.StudySessions(), store.Gamification(), store.Users()), study
+}
+
+// TestGetActivity tests that sessions are counted on the day they started in
+// the requested time zone
+func TestGetActivity(t *testing.T) {
+	ctx := context.Background()
+	svc, study := newStatsFixture(t)
+
+	// 22:30 on the 10th in São Paulo, 01:30 on the 11th in UTC
+	study(time.Date(2025, 3, 11, 1, 30, 0, 0, time.UTC), time.Minute, 2*time.Minute, 12*time.Minute+30*time.Second)
+	study(time.Date(2025, 3, 11, 14, 0, 0, 0, time.UTC), 5*time.Minute)
+	study(time.Date(2025, 3, 11, 18, 0, 0, 0, time.UTC))
+
+	history, err := svc.GetActivity(ctx, ActivityRange{From: "2025-03-10", To: "2025-03-12", TimeZone: "America/Sao_Paulo"})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-10", history.From)
+	assert.Equal(t, "2025-03-12", history.To)
+	assert.Equal(t, "America/Sao_Paulo", history.TimeZone)
+	assert.Equal(t, []DailyActivity{
+		{Date: "2025-03-10", Sessions: 1, Reviews: 3, CorrectCount: 2, Accuracy: 200.0 / 3, Minutes: 12.5},
+		{Date: "2025-03-11", Sessions: 2, Reviews: 1, CorrectCount: 1, Accuracy: 100, Minutes: 5},
+		{Date: "2025-03-12"},
+	}, history.Days)
+
+	history, err = svc.GetActivity(ctx, ActivityRange{From: "2025-03-10", To: "2025-03-11"})
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", history.TimeZone)
+	require.Len(t, history.Days, 2)
+	assert.Zero(t, history.Days[0].Sessions)
+	assert.Equal(t, 3, history.Days[1].Sessions)
+}
+
+// TestGetActivityAcrossDST tests days of 23 and 25 hours
+func TestGetActivityAcrossDST(t *testing.T) {
+	ctx := context.Background()
+	svc, study := newStatsFixture(t)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Clocks went forward on 9 March 2025 and back on 2 November
+	study(time.Date(2025, 3, 8, 23, 30, 0, 0, newYork))
+	study(time.Date(2025, 3, 9, 23, 30, 0, 0, newYork))
+	study(time.Date(2025, 3, 10, 0, 30, 0, 0, newYork))
+	study(time.Date(2025, 11, 2, 23, 59, 0, 0, newYork))
+
+	history, err := svc.GetActivity(ctx, ActivityRange{From: "2025-03-08", To: "2025-03-10", TimeZone: "America/New_York"})
+	require.NoError(t, err)
+	sessions := make([]int, len(history.Days))
+	for i, day := range history.Days {
+		sessions[i] = day.Sessions
+	}
+	assert.Equal(t, []int{1, 1, 1}, sessions)
+
+	history, err = svc.GetActivity(ctx, ActivityRange{From: "2025-11-02", To: "2025-11-02", TimeZone: "America/New_York"})
+	require.NoError(t, err)
+	require.Len(t, history.Days, 1)
+	assert.Equal(t, 1, history.Days[0].Sessions)
+}
+
+// TestGetActivityDefaults tests that the range defaults to the year ending
+// today in the requested time zone
+func TestGetActivityDefaults(t *testing.T) {
+	svc, _ := newStatsFixture(t)
+	// Already the 1st of March in UTC, still the 28th of February in São Paulo
+	svc.now = func() time.Time { return time.Date(2025, 3, 1, 1, 0, 0, 0, time.UTC) }
+
+	history, err := svc.GetActivity(context.Background(), ActivityRange{TimeZone: "America/Sao_Paulo"})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-29", history.From)
+	assert.Equal(t, "2025-02-28", history.To)
+	assert.Len(t, history.Days, 366)
+
+	history, err = svc.GetActivity(context.Background(), ActivityRange{To: "2025-01-31"})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-01", history.From)
+}
+
+func TestGetActivityValidation(t *testing.T) {
+	svc, _ := newStatsFixture(t)
+	tests := []struct {
+		name  string
+		r     ActivityRange
+		field string
+	}{
+		{"unknown time zone", ActivityRange{TimeZone: "Mars/Olympus_Mons"}, "tz"},
+		{"malformed from", ActivityRange{From: "10/03/2025"}, "from"},
+		{"malformed to", ActivityRange{To: "2025-02-30"}, "to"},
+		{"from after to", ActivityRange{From: "2025-03-11", To: "2025-03-10"}, "from"},
+		{"more than a year", ActivityRange{From: "2024-01-01", To: "2025-01-02"}, "from"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetActivity(context.Background(), tt.r)
+			require.ErrorIs(t, err, ErrValidation)
+			var domainErr *Error
+			require.True(t, errors.As(err, &domainErr))
+			require.Len(t, domainErr.Fields, 1)
+			assert.Equal(t, tt.field, domainErr.Fields[0].Field)
+		})
+	}
+
+	_, err := svc.GetActivity(context.Background(), ActivityRange{From: "2024-01-01", To: "2024-12-31"})
+	assert.NoError(t, err, "a leap year fits")
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/validation"
+)
+
+const (
+	// freezeEveryDays is how many days of a streak earn a streak freeze
+	freezeEveryDays = 7
+	// maxStreakFreezes is how many unused streak freezes can be held at once
+	maxStreakFreezes = 2
+)
+
+// StudyStreak counts the consecutive days on which the learner studied, in
+// their time zone. A streak stays alive until the end of the day after the
+// last one studied, and a streak freeze, earned every 7 days of a streak,
+// covers a day missed since. Days covered by a freeze keep the streak alive
+// without lengthening it.
+type StudyStreak struct {
+	TimeZone string `json:"time_zone"`
+	// Current is 0 once a day has been missed without a freeze to cover it
+	Current      int  `json:"current"`
+	Longest      int  `json:"longest"`
+	StudiedToday bool `json:"studied_today"`
+	// FreezesAvailable is how many freezes are left to cover the days to come,
+	// at most 2
+	FreezesAvailable int `json:"freezes_available"`
+	// FrozenDays lists the days of the current streak covered by a freeze,
+	// as YYYY-MM-DD
+	FrozenDays []string `json:"frozen_days"`
+}
+
+// GetStreak returns the study streak of the learner with ID userID, the
+// guest if 0, in the time zone called timeZone, or in that of their daily
+// goal if empty
+func (s *StatsService) GetStreak(ctx context.Context, userID int64, timeZone string) (*StudyStreak, error) {
+	var v validation.Validator
+	var loc *time.Location
+	if timeZone != "" {
+		loc = parseTimeZone(&v, timeZone)
+	}
+	if err := v.Err(); err != nil {
+		return nil, invalidInput(err)
+	}
+	if err := checkLearner(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	return loadStreak(ctx, s.studySessionRepo, s.gamificationRepo, userID, loc, s.now())
+}
+
+// loadStreak reads the study history of a learner and counts their streak
+// as of now in loc, or in the time zone of their daily goal if loc is nil
+func loadStreak(ctx context.Context, sessions StudySessionRepository, gamification GamificationRepository, userID int64, loc *time.Location, now time.Time) (*StudyStreak, error) {
+	if loc == nil {
+		goal, err := dailyGoal(ctx, gamification, userID)
+		if err != nil {
+			return nil, err
+		}
+		if loc, err = time.LoadLocation(goal.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+	times, err := sessions.ListStudyTimes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return studyStreak(times, loc, now), nil
+}
+
+// studyStreak replays the days from the first one studied until today, in
+// loc, given the times sessions were started
+func studyStreak(times []time.Time, loc *time.Location, now time.Time) *StudyStreak {
+	streak := &StudyStreak{TimeZone: loc.String(), FrozenDays: []string{}}
+	if len(times) == 0 {
+		return streak
+	}
+
+	// Days are compared as dates, so that DST changes don't shift them
+	date := func(t time.Time) time.Time {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	studied := make(map[time.Time]bool, len(times))
+	for _, t := range times {
+		studied[date(t)] = true
+	}
+	first := date(times[0])
+	today := date(now)
+	streak.StudiedToday = studied[today]
+
+	// earning counts the days studied towards the next freeze
+	earning := 0
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		switch {
+		case studied[day]:
+			streak.Current++
+			streak.Longest = max(streak.Longest, streak.Current)
+			if earning++; earning == freezeEveryDays {
+				earning = 0
+				streak.FreezesAvailable = min(streak.FreezesAvailable+1, maxStreakFreezes)
+			}
+		case day.Equal(today) || streak.Current == 0:
+			// There is still time to study today
+		case streak.FreezesAvailable > 0:
+			streak.FreezesAvailable--
+			streak.FrozenDays = append(streak.FrozenDays, day.Format(time.DateOnly))
+		default:
+			streak.Current = 0
+			earning = 0
+			streak.FrozenDays = []string{}
+		}
+	}
+	return streak
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+// daily returns n consecutive days from the one first, at the time of day clock
+func daily(first string, n int, clock string) []string {
+	days := make([]string, n)
+	for i := range days {
+		days[i] = day(first).AddDate(0, 0, i).Format(time.DateOnly) + " " + clock
+	}
+	return days
+}
+
+// TestStudyStreak tests counting consecutive study days in the learner's
+// time zone
+func TestStudyStreak(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+	saoPaulo := loadLocation(t, "America/Sao_Paulo")
+	kathmandu := loadLocation(t, "Asia/Kathmandu")
+
+	tests := []struct {
+		name string
+		loc  *time.Location
+		// studied and now are local times, formatted as YYYY-MM-DD HH:MM
+		studied []string
+		now     string
+		want    StudyStreak
+	}{
+		{
+			name: "never studied",
+			now:  "2025-03-10 18:30",
+		},
+		{
+			name:    "only today",
+			studied: []string{"2025-03-10 08:00"},
+			now:     "2025-03-10 18:30",
+			want:    StudyStreak{Current: 1, Longest: 1, StudiedToday: true},
+		},
+		{
+			name:    "three days in a row",
+			studied: daily("2025-03-08", 3, "08:00"),
+			now:     "2025-03-10 18:30",
+			want:    StudyStreak{Current: 3, Longest: 3, StudiedToday: true},
+		},
+		{
+			name:    "alive until the end of the day after the last one studied",
+			studied: daily("2025-03-08", 2, "08:00"),
+			now:     "2025-03-10 23:59",
+			want:    StudyStreak{Current: 2, Longest: 2},
+		},
+		{
+			name:    "a missed day ends the streak",
+			studied: daily("2025-03-07", 2, "08:00"),
+			now:     "2025-03-10 00:01",
+			want:    StudyStreak{Longest: 2},
+		},
+		{
+			name:    "the longest streak outlives the current one",
+			studied: append(daily("2025-02-20", 5, "08:00"), daily("2025-03-09", 2, "08:00")...),
+			now:     "2025-03-10 18:30",
+			want:    StudyStreak{Current: 2, Longest: 5, StudiedToday: true},
+		},
+		{
+			name:    "a week earns a freeze",
+			studied: daily("2025-03-04", 7, "08:00"),
+			now:     "2025-03-10 18:30",
+			want:    StudyStreak{Current: 7, Longest: 7, StudiedToday: true, FreezesAvailable: 1},
+		},
+		{
+			name:    "a freeze covers a missed day without lengthening the streak",
+			studied: append(daily("2025-03-01", 7, "08:00"), "2025-03-09 08:00", "2025-03-10 08:00"),
+			now:     "2025-03-10 18:30",
+			want:    StudyStreak{Current: 9, Longest: 9, StudiedToday: true, FrozenDays: []string{"2025-03-08"}},
+		},
+		{
+			name:    "a freeze covers yesterday",
+			studied: daily("2025-03-02", 7, "08:00"),
+			now:     "2025-03-10 18:30",
+			want:    StudyStreak{Current: 7, Longest: 7, FrozenDays: []string{"2025-03-09"}},
+		},
+		{
+			name:    "two freezes cover two missed days",
+			studied: append(daily("2025-02-20", 14, "08:00"), "2025-03-08 08:00"),
+			now:     "2025-03-08 18:30",
+			want:    StudyStreak{Current: 15, Longest: 15, StudiedToday: true, FrozenDays: []string{"2025-03-06", "2025-03-07"}},
+		},
+		{
+			name:    "no more than two freezes are held",
+			studied: append(daily("2025-02-01", 21, "08:00"), "2025-02-25 08:00"),
+			now:     "2025-02-25 18:30",
+			want:    StudyStreak{Current: 1, Longest: 21, StudiedToday: true},
+		},
+		{
+			name:    "days run from midnight to midnight in the learner's time zone",
+			loc:     saoPaulo,
+			studied: []string{"2025-03-09 22:30", "2025-03-10 22:30"},
+			now:     "2025-03-11 10:00",
+			want:    StudyStreak{Current: 2, Longest: 2},
+		},
+		{
+			name:    "late sessions in UTC days",
+			studied: []string{"2025-03-10 01:30", "2025-03-11 01:30"},
+			now:     "2025-03-11 13:00",
+			want:    StudyStreak{Current: 2, Longest: 2, StudiedToday: true},
+		},
+		{
+			name:    "spring forward",
+			loc:     newYork,
+			studied: []string{"2025-03-08 00:15", "2025-03-09 03:30", "2025-03-10 23:45"},
+			now:     "2025-03-11 00:10",
+			want:    StudyStreak{Current: 3, Longest: 3},
+		},
+		{
+			name:    "fall back",
+			loc:     newYork,
+			studied: []string{"2025-11-01 23:59", "2025-11-02 23:30"},
+			now:     "2025-11-03 00:05",
+			want:    StudyStreak{Current: 2, Longest: 2},
+		},
+		{
+			name:    "a day starting at 01:00",
+			loc:     saoPaulo,
+			studied: []string{"2018-11-03 23:50", "2018-11-04 01:05"},
+			now:     "2018-11-05 12:00",
+			want:    StudyStreak{Current: 2, Longest: 2},
+		},
+		{
+			name:    "an offset of quarter hours",
+			loc:     kathmandu,
+			studied: []string{"2025-03-09 23:50", "2025-03-10 00:05"},
+			now:     "2025-03-10 08:00",
+			want:    StudyStreak{Current: 2, Longest: 2, StudiedToday: true},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+			parse := func(s string) time.Time {
+				parsed, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+				require.NoError(t, err)
+				return parsed
+			}
+			// As ListStudyTimes returns them
+			var times []time.Time
+			for _, s := range tt.studied {
+				times = append(times, parse(s).UTC().Truncate(15*time.Minute))
+			}
+			sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+			want := tt.want
+			want.TimeZone = loc.String()
+			if want.FrozenDays == nil {
+				want.FrozenDays = []string{}
+			}
+			assert.Equal(t, &want, studyStreak(times, loc, parse(tt.now)))
+		})
+	}
+}
+
+// TestGetStreak tests that a learner's streak counts their own sessions, in
+// the time zone asked for or else that of their daily goal
+func TestGetStreak(t *testing.T) {
+	ctx := context.Background()
+	svc, study := newStatsFixture(t)
+	study(time.Date(2025, 3, 10, 1, 30, 0, 0, time.UTC))
+	study(time.Date(2025, 3, 11, 1, 30, 0, 0, time.UTC))
+	svc.now = func() time.Time { return time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC) }
+
+	streak, err := svc.GetStreak(ctx, 0, "America/Sao_Paulo")
+	require.NoError(t, err)
+	assert.Equal(t, "America/Sao_Paulo", streak.TimeZone)
+	assert.Equal(t, 2, streak.Current)
+	assert.False(t, streak.StudiedToday, "both sessions were on the evening before")
+
+	streak, err = svc.GetStreak(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", streak.TimeZone)
+	assert.True(t, streak.StudiedToday)
+
+	require.NoError(t, svc.gamificationRepo.SetDailyGoal(ctx, &models.DailyGoal{Kind: models.GoalReviews, Target: 20, TimeZone: "America/Sao_Paulo"}))
+	streak, err = svc.GetStreak(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, "America/Sao_Paulo", streak.TimeZone, "the daily goal's time zone is the default")
+	assert.False(t, streak.StudiedToday)
+
+	// The guest's sessions aren't Ana's
+	ana, err := svc.userRepo.CreateUser(ctx, &models.User{Name: "Ana"})
+	require.NoError(t, err)
+	streak, err = svc.GetStreak(ctx, ana.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", streak.TimeZone)
+	assert.Zero(t, streak.Longest)
+
+	_, err = svc.GetStreak(ctx, ana.ID+1, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.GetStreak(ctx, 0, "America/Sao Paulo")
+	assert.ErrorIs(t, err, ErrValidation)
+}
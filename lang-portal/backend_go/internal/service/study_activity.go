@@ -1,42 +1,81 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
-	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
 )
 
 type StudyActivityService struct {
-	activityRepo *repository.StudyActivityRepository
-	sessionRepo  *repository.StudySessionRepository
+	activityRepo StudyActivityRepository
+	sessionRepo  StudySessionRepository
+	wordRepo     WordRepository
+	uow          UnitOfWork
+	events       Events
+	now          func() time.Time
 }
 
+// NewStudyActivityService returns the service. events may be nil.
 func NewStudyActivityService(
-	activityRepo *repository.StudyActivityRepository,
-	sessionRepo *repository.StudySessionRepository,
+	activityRepo StudyActivityRepository,
+	sessionRepo StudySessionRepository,
+	wordRepo WordRepository,
+	uow UnitOfWork,
+	events Events,
 ) *StudyActivityService {
+	if events == nil {
+		events = noEvents{}
+	}
 	return &StudyActivityService{
 		activityRepo: activityRepo,
 		sessionRepo:  sessionRepo,
+		wordRepo:     wordRepo,
+		uow:          uow,
+		events:       events,
+		now:          time.Now,
+	}
+}
+
+func (s *StudyActivityService) GetStudyActivity(ctx context.Context, id int64) (*models.StudyActivity, error) {
+	activity, err := s.activityRepo.GetStudyActivity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if activity == nil {
+		return nil, NotFound("study activity")
 	}
+	return activity, nil
 }
 
-func (s *StudyActivityService) GetStudyActivity(id int64) (*models.StudyActivity, error) {
-	return s.activityRepo.GetStudyActivity(id)
+func (s *StudyActivityService) ListStudyActivities(ctx context.Context) ([]models.StudyActivity, error) {
+	return s.activityRepo.ListStudyActivities(ctx)
 }
 
-func (s *StudyActivityService) ListStudyActivities() ([]models.StudyActivity, error) {
-	return s.activityRepo.ListStudyActivities()
+// ListStudyActivitiesPaginated returns a page of study activities and the total count
+func (s *StudyActivityService) ListStudyActivitiesPaginated(ctx context.Context, page, perPage int) ([]models.StudyActivity, int, error) {
+	activities, err := s.activityRepo.ListStudyActivitiesPaginated(ctx, (page-1)*perPage, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.activityRepo.CountStudyActivities(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return activities, total, nil
 }
 
-func (s *StudyActivityService) GetStudyActivitySessions(activityID int64, page, perPage int) ([]models.StudySessionDetail, int, error) {
+func (s *StudyActivityService) GetStudyActivitySessions(ctx context.Context, activityID int64, page, perPage int) ([]models.StudySessionDetail, int, error) {
 	offset := (page - 1) * perPage
 
-	sessions, err := s.activityRepo.GetStudyActivitySessions(activityID, offset, perPage)
+	sessions, err := s.activityRepo.GetStudyActivitySessions(ctx, activityID, offset, perPage)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := s.activityRepo.CountStudyActivitySessions(activityID)
+	total, err := s.activityRepo.CountStudyActivitySessions(ctx, activityID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -44,24 +83,226 @@ func (s *StudyActivityService) GetStudyActivitySessions(activityID int64, page,
 	return sessions, total, nil
 }
 
-func (s *StudyActivityService) CreateStudySession(groupID, activityID int64) (*models.StudySession, error) {
+// CreateStudySession starts a study session of an activity on a group, by
+// userID or, if nil, the guest
+func (s *StudyActivityService) CreateStudySession(ctx context.Context, groupID, activityID int64, userID *int64) (*models.StudySession, error) {
 	session := &models.StudySession{
 		GroupID:         groupID,
 		StudyActivityID: activityID,
+		UserID:          userID,
+	}
+
+	err := s.uow.WithTx(ctx, func(repos Repositories) error {
+		group, err := repos.Groups.GetGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			return NotFound("group")
+		}
+		activity, err := repos.StudyActivities.GetStudyActivity(ctx, activityID)
+		if err != nil {
+			return err
+		}
+		if activity == nil {
+			return NotFound("study activity")
+		}
+		if userID != nil {
+			user, err := repos.Users.GetUser(ctx, *userID)
+			if err != nil {
+				return err
+			}
+			if user == nil {
+				return NotFound("user")
+			}
+		}
+		return missingReference(repos.StudySessions.CreateStudySession(ctx, session))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.SessionStarted()
+	return session, nil
+}
+
+// RecordReview records whether a word was answered correctly during a study
+// session, awards its XP and unlocks the achievements it earns
+func (s *StudyActivityService) RecordReview(ctx context.Context, sessionID, wordID int64, correct bool) (*models.WordReviewItem, error) {
+	var review *models.WordReviewItem
+	err := s.uow.WithTx(ctx, func(repos Repositories) error {
+		session, err := openSession(ctx, repos.StudySessions, sessionID)
+		if err != nil {
+			return err
+		}
+
+		word, err := repos.Words.GetWord(ctx, wordID)
+		if err != nil {
+			return err
+		}
+		if word == nil {
+			return NotFound("word")
+		}
+
+		recorded, err := s.recordReviews(ctx, repos, session, []models.ReviewInput{{WordID: wordID, Correct: correct}})
+		if err != nil {
+			return err
+		}
+		review = recorded[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.ReviewRecorded(correct)
+	return review, nil
+}
+
+// RecordReviews records a batch of answers given during a study session.
+// Either all of them are recorded or, if a word doesn't exist or a write
+// fails, none is. Events are reported once the batch is committed.
+func (s *StudyActivityService) RecordReviews(ctx context.Context, sessionID int64, reviews []models.ReviewInput) ([]*models.WordReviewItem, error) {
+	if err := models.ValidateReviews(reviews); err != nil {
+		return nil, invalidInput(err)
 	}
 
-	err := s.sessionRepo.CreateStudySession(session)
+	var recorded []*models.WordReviewItem
+	err := s.uow.WithTx(ctx, func(repos Repositories) error {
+		session, err := openSession(ctx, repos.StudySessions, sessionID)
+		if err != nil {
+			return err
+		}
+
+		wordIDs := make([]int64, len(reviews))
+		for i, review := range reviews {
+			wordIDs[i] = review.WordID
+		}
+		missing, err := repos.Words.FindMissingWordIDs(ctx, wordIDs)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return missingIDs("reviews[%d].word_id", wordIDs, missing)
+		}
+
+		recorded, err = s.recordReviews(ctx, repos, session, reviews)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	for _, review := range recorded {
+		s.events.ReviewRecorded(review.Correct)
+	}
+	return recorded, nil
+}
+
+// openSession returns a study session that takes reviews: a not found error
+// if it doesn't exist, and a conflict if it has ended
+func openSession(ctx context.Context, repo StudySessionRepository, id int64) (*models.StudySessionDetail, error) {
+	session, err := repo.GetStudySession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, NotFound("study session")
+	}
+	if session.EndedAt != nil {
+		return nil, Conflict("study session has ended", nil)
+	}
 	return session, nil
 }
 
-func (s *StudyActivityService) ListStudySessions(offset, limit int) ([]models.StudySessionDetail, error) {
-	return s.sessionRepo.ListStudySessions(offset, limit)
+// recordReviews records reviews in session in order, awarding each the XP of
+// the answers right in a row it extends, then unlocks the achievements they
+// earn the learner
+func (s *StudyActivityService) recordReviews(ctx context.Context, repos Repositories, session *models.StudySessionDetail, reviews []models.ReviewInput) ([]*models.WordReviewItem, error) {
+	combo, err := repos.StudySessions.GetSessionCombo(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]*models.WordReviewItem, 0, len(reviews))
+	for _, in := range reviews {
+		combo++
+		if !in.Correct {
+			combo = 0
+		}
+		review := &models.WordReviewItem{
+			StudySessionID: session.ID,
+			WordID:         in.WordID,
+			Correct:        in.Correct,
+			XP:             models.ReviewXP(combo),
+		}
+		if err := repos.StudySessions.CreateReview(ctx, review); err != nil {
+			return nil, err
+		}
+		recorded = append(recorded, review)
+	}
+
+	if _, err := evaluateAchievements(ctx, repos, learnerOf(session.UserID), false, s.now()); err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// learnerOf returns the learner ID of a session's user, 0 for the guest
+func learnerOf(userID *int64) int64 {
+	if userID == nil {
+		return 0
+	}
+	return *userID
+}
+
+// SessionEnd is a study session that has ended, and the achievements ending
+// it unlocked
+type SessionEnd struct {
+	StudySessionID int64                `json:"study_session_id"`
+	EndedAt        time.Time            `json:"ended_at"`
+	Unlocked       []models.Achievement `json:"unlocked"`
+}
+
+// EndStudySession ends a study session, after which it takes no more
+// reviews, and unlocks the achievements the learner has earned. Ending a
+// session again changes nothing.
+func (s *StudyActivityService) EndStudySession(ctx context.Context, id int64) (*SessionEnd, error) {
+	end := &SessionEnd{StudySessionID: id, Unlocked: []models.Achievement{}}
+	err := s.uow.WithTx(ctx, func(repos Repositories) error {
+		session, err := repos.StudySessions.GetStudySession(ctx, id)
+		if err != nil {
+			return err
+		}
+		if session == nil {
+			return NotFound("study session")
+		}
+		if session.EndedAt != nil {
+			end.EndedAt = *session.EndedAt
+			return nil
+		}
+
+		end.EndedAt = s.now().UTC()
+		if err := repos.StudySessions.EndStudySession(ctx, id, end.EndedAt); err != nil {
+			return err
+		}
+		unlocked, err := evaluateAchievements(ctx, repos, learnerOf(session.UserID), true, end.EndedAt)
+		if err != nil {
+			return err
+		}
+		end.Unlocked = append(end.Unlocked, unlocked...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return end, nil
+}
+
+func (s *StudyActivityService) ListStudySessions(ctx context.Context, offset, limit int) ([]models.StudySessionDetail, error) {
+	return s.sessionRepo.ListStudySessions(ctx, offset, limit)
 }
 
-func (s *StudyActivityService) CountStudySessions() (int, error) {
-	return s.sessionRepo.CountStudySessions()
+func (s *StudyActivityService) CountStudySessions(ctx context.Context) (int, error) {
+	return s.sessionRepo.CountStudySessions(ctx)
 }
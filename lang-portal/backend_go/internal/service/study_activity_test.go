@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedEvents counts the events reported by a service
+type recordedEvents struct {
+	sessions int
+	correct  int
+	wrong    int
+}
+
+func (e *recordedEvents) SessionStarted() { e.sessions++ }
+
+func (e *recordedEvents) ReviewRecorded(correct bool) {
+	if correct {
+		e.correct++
+	} else {
+		e.wrong++
+	}
+}
+
+// TestRecordReview tests recording reviews and reporting them as events
+func TestRecordReview(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	events := &recordedEvents{}
+	svc := NewStudyActivityService(store.StudyActivities(), store.StudySessions(), store.Words(), &fakeUnitOfWork{store: store}, events)
+
+	word, err := store.Words().CreateWord(ctx, &models.Word{Portuguese: "olá", English: "hello"})
+	require.NoError(t, err)
+	group, err := store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+
+	session, err := svc.CreateStudySession(ctx, group.ID, activity.ID, nil)
+	require.NoError(t, err)
+
+	review, err := svc.RecordReview(ctx, session.ID, word.ID, true)
+	require.NoError(t, err)
+	assert.NotZero(t, review.ID)
+	_, err = svc.RecordReview(ctx, session.ID, word.ID, false)
+	require.NoError(t, err)
+
+	_, err = svc.RecordReview(ctx, session.ID+1, word.ID, true)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.RecordReview(ctx, session.ID, word.ID+1, true)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, recordedEvents{sessions: 1, correct: 1, wrong: 1}, *events, "failed reviews aren't reported")
+
+	detail, err := store.StudySessions().GetStudySession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, detail.ReviewItemsCount)
+}
+
+// TestCreateStudySession tests that sessions referencing a group, activity or
+// user that doesn't exist aren't created
+func TestCreateStudySession(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	events := &recordedEvents{}
+	svc := NewStudyActivityService(store.StudyActivities(), store.StudySessions(), store.Words(), &fakeUnitOfWork{store: store}, events)
+
+	group, err := store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+	user, err := store.Users().CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleStudent})
+	require.NoError(t, err)
+
+	session, err := svc.CreateStudySession(ctx, group.ID, activity.ID, &user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &user.ID, session.UserID)
+
+	missing := int64(999)
+	_, err = svc.CreateStudySession(ctx, missing, activity.ID, nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, "group not found")
+	_, err = svc.CreateStudySession(ctx, group.ID, missing, nil)
+	assert.EqualError(t, err, "study activity not found")
+	_, err = svc.CreateStudySession(ctx, group.ID, activity.ID, &missing)
+	assert.EqualError(t, err, "user not found")
+	assert.Equal(t, 1, events.sessions, "sessions that weren't created aren't reported")
+}
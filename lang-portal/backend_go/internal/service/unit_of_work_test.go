@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixture is a store with two groups, three words in the first one and an
+// assignment on it
+type fixture struct {
+	store      *memory.Store
+	uow        *fakeUnitOfWork
+	greetings  *models.Group
+	food       *models.Group
+	words      []*models.Word
+	assignment *models.Assignment
+	session    *models.StudySession
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	f := &fixture{store: store, uow: &fakeUnitOfWork{store: store}}
+
+	var err error
+	f.greetings, err = store.Groups().CreateGroup(ctx, &models.Group{Name: "Greetings"})
+	require.NoError(t, err)
+	f.food, err = store.Groups().CreateGroup(ctx, &models.Group{Name: "Food"})
+	require.NoError(t, err)
+
+	var ids []int64
+	for _, w := range [][2]string{{"olá", "hello"}, {"adeus", "goodbye"}, {"pão", "bread"}} {
+		word, err := store.Words().CreateWord(ctx, &models.Word{Portuguese: w[0], English: w[1]})
+		require.NoError(t, err)
+		f.words = append(f.words, word)
+		ids = append(ids, word.ID)
+	}
+	require.NoError(t, store.Groups().AddWordsToGroup(ctx, f.greetings.ID, ids))
+
+	teacher, err := store.Users().CreateUser(ctx, &models.User{Name: "Ana", Role: models.RoleTeacher})
+	require.NoError(t, err)
+	classroom, err := store.Classrooms().CreateClassroom(ctx, &models.Classroom{Name: "A1", TeacherID: teacher.ID})
+	require.NoError(t, err)
+	f.assignment, err = store.Classrooms().CreateAssignment(ctx, &models.Assignment{
+		ClassroomID: classroom.ID, GroupID: f.greetings.ID, DueAt: time.Now().Add(24 * time.Hour), TargetAccuracy: 80,
+	})
+	require.NoError(t, err)
+
+	activity := &models.StudyActivity{Name: "flashcards"}
+	require.NoError(t, store.StudyActivities().CreateStudyActivity(ctx, activity))
+	f.session = &models.StudySession{GroupID: f.greetings.ID, StudyActivityID: activity.ID}
+	require.NoError(t, store.StudySessions().CreateStudySession(ctx, f.session))
+	return f
+}
+
+func (f *fixture) groupService() *GroupService {
+	return NewGroupService(f.store.Groups(), f.store.Words(), f.store.StudyActivities(), f.uow, testMastery)
+}
+
+func (f *fixture) groupWordIDs(t *testing.T, groupID int64) []int64 {
+	t.Helper()
+	words, err := f.store.Groups().GetGroupWords(context.Background(), groupID)
+	require.NoError(t, err)
+	ids := []int64{}
+	for _, w := range words {
+		ids = append(ids, w.ID)
+	}
+	return ids
+}
+
+// TestDeleteGroupWithTx tests that a group and its assignments are deleted together
+func TestDeleteGroupWithTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes the assignments with the group", func(t *testing.T) {
+		f := newFixture(t)
+		require.NoError(t, f.groupService().DeleteGroup(ctx, f.greetings.ID))
+
+		group, err := f.store.Groups().GetGroup(ctx, f.greetings.ID)
+		require.NoError(t, err)
+		assert.Nil(t, group)
+		assignment, err := f.store.Classrooms().GetAssignment(ctx, f.assignment.ClassroomID, f.assignment.ID)
+		require.NoError(t, err)
+		assert.Nil(t, assignment)
+		words, err := f.store.Words().CountWords(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, words, "words outlive their group")
+	})
+
+	t.Run("keeps the assignments when the group can't be deleted", func(t *testing.T) {
+		f := newFixture(t)
+		f.uow.wrap = func(repos Repositories) Repositories {
+			repos.Groups = failingGroups{GroupRepository: repos.Groups, failDelete: true}
+			return repos
+		}
+
+		err := f.groupService().DeleteGroup(ctx, f.greetings.ID)
+		assert.ErrorIs(t, err, errInjected)
+
+		// The assignments were deleted first, so they're only back if that was rolled back
+		assignments, err := f.store.Classrooms().ListAssignments(ctx, f.assignment.ClassroomID)
+		require.NoError(t, err)
+		assert.Len(t, assignments, 1)
+		assert.Len(t, f.groupWordIDs(t, f.greetings.ID), 3)
+	})
+}
+
+// TestMoveWords tests moving words between groups, all or none
+func TestMoveWords(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("moves the words", func(t *testing.T) {
+		f := newFixture(t)
+		err := f.groupService().MoveWords(ctx, f.greetings.ID, f.food.ID, []int64{f.words[2].ID})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{f.words[0].ID, f.words[1].ID}, f.groupWordIDs(t, f.greetings.ID))
+		assert.Equal(t, []int64{f.words[2].ID}, f.groupWordIDs(t, f.food.ID))
+	})
+
+	t.Run("rejects words outside the source group", func(t *testing.T) {
+		f := newFixture(t)
+		err := f.groupService().MoveWords(ctx, f.food.ID, f.greetings.ID, []int64{f.words[0].ID})
+		assert.ErrorIs(t, err, ErrValidation)
+		var svcErr *Error
+		require.True(t, errors.As(err, &svcErr))
+		assert.Equal(t, []FieldError{{Field: "word_ids[0]", Message: "is not in the group"}}, svcErr.Fields)
+	})
+
+	t.Run("rejects an unknown or identical target", func(t *testing.T) {
+		f := newFixture(t)
+		err := f.groupService().MoveWords(ctx, f.greetings.ID, f.food.ID+10, []int64{f.words[0].ID})
+		assert.ErrorIs(t, err, ErrValidation)
+		err = f.groupService().MoveWords(ctx, f.greetings.ID, f.greetings.ID, []int64{f.words[0].ID})
+		assert.ErrorIs(t, err, ErrValidation)
+		err = f.groupService().MoveWords(ctx, f.food.ID+10, f.greetings.ID, []int64{f.words[0].ID})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("puts the words back when adding them fails", func(t *testing.T) {
+		f := newFixture(t)
+		f.uow.wrap = func(repos Repositories) Repositories {
+			repos.Groups = failingGroups{GroupRepository: repos.Groups, failAdd: true}
+			return repos
+		}
+
+		err := f.groupService().MoveWords(ctx, f.greetings.ID, f.food.ID, []int64{f.words[0].ID, f.words[1].ID})
+		assert.ErrorIs(t, err, errInjected)
+		assert.Len(t, f.groupWordIDs(t, f.greetings.ID), 3)
+		assert.Empty(t, f.groupWordIDs(t, f.food.ID))
+	})
+
+	t.Run("moves nothing when a word is already in the target", func(t *testing.T) {
+		f := newFixture(t)
+		require.NoError(t, f.store.Groups().AddWordsToGroup(ctx, f.food.ID, []int64{f.words[1].ID}))
+
+		err := f.groupService().MoveWords(ctx, f.greetings.ID, f.food.ID, []int64{f.words[0].ID, f.words[1].ID})
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Len(t, f.groupWordIDs(t, f.greetings.ID), 3)
+		assert.Equal(t, []int64{f.words[1].ID}, f.groupWordIDs(t, f.food.ID))
+	})
+}
+
+// TestRecordReviews tests recording a batch of reviews, all or none
+func TestRecordReviews(t *testing.T) {
+	ctx := context.Background()
+	newService := func(f *fixture, events Events) *StudyActivityService {
+		return NewStudyActivityService(f.store.StudyActivities(), f.store.StudySessions(), f.store.Words(), f.uow, events)
+	}
+	reviewCount := func(t *testing.T, f *fixture) int {
+		_, total, err := f.store.StudySessions().GetWordReviewStats(ctx)
+		require.NoError(t, err)
+		return total
+	}
+
+	t.Run("records every review", func(t *testing.T) {
+		f := newFixture(t)
+		events := &recordedEvents{}
+		reviews, err := newService(f, events).RecordReviews(ctx, f.session.ID, []models.ReviewInput{
+			{WordID: f.words[0].ID, Correct: true},
+			{WordID: f.words[0].ID, Correct: false},
+			{WordID: f.words[1].ID, Correct: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, reviews, 3)
+		assert.NotZero(t, reviews[2].ID)
+		assert.Equal(t, 3, reviewCount(t, f))
+		assert.Equal(t, recordedEvents{correct: 2, wrong: 1}, *events)
+	})
+
+	t.Run("records nothing when a write fails", func(t *testing.T) {
+		f := newFixture(t)
+		f.uow.wrap = func(repos Repositories) Repositories {
+			repos.StudySessions = &failingSessions{StudySessionRepository: repos.StudySessions, succeed: 2}
+			return repos
+		}
+		events := &recordedEvents{}
+
+		_, err := newService(f, events).RecordReviews(ctx, f.session.ID, []models.ReviewInput{
+			{WordID: f.words[0].ID, Correct: true},
+			{WordID: f.words[1].ID, Correct: true},
+			{WordID: f.words[2].ID, Correct: true},
+		})
+		assert.ErrorIs(t, err, errInjected)
+		assert.Zero(t, reviewCount(t, f))
+		assert.Equal(t, recordedEvents{}, *events, "rolled back reviews aren't reported")
+	})
+
+	t.Run("rejects unknown words and sessions", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, nil)
+
+		_, err := svc.RecordReviews(ctx, f.session.ID, []models.ReviewInput{
+			{WordID: f.words[0].ID, Correct: true},
+			{WordID: 999, Correct: true},
+		})
+		var svcErr *Error
+		require.True(t, errors.As(err, &svcErr))
+		assert.Equal(t, []FieldError{{Field: "reviews[1].word_id", Message: "does not exist"}}, svcErr.Fields)
+
+		_, err = svc.RecordReviews(ctx, f.session.ID+1, []models.ReviewInput{{WordID: f.words[0].ID}})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = svc.RecordReviews(ctx, f.session.ID, nil)
+		assert.ErrorIs(t, err, ErrValidation)
+		assert.Zero(t, reviewCount(t, f))
+	})
+}
+
+// TestImportGroup tests creating a group with its words, all or none
+func TestImportGroup(t *testing.T) {
+	ctx := context.Background()
+	input := func() models.GroupImport {
+		return models.GroupImport{Name: " Travel ", Words: []models.WordInput{
+			{Portuguese: "comboio", English: "train"},
+			{Portuguese: "avião", English: "plane"},
+			{Portuguese: "barco", English: "boat"},
+		}}
+	}
+
+	t.Run("creates the group and its words", func(t *testing.T) {
+		f := newFixture(t)
+		group, err := f.groupService().ImportGroup(ctx, input())
+		require.NoError(t, err)
+		assert.Equal(t, "Travel", group.Name)
+		assert.Equal(t, 3, group.WordCount)
+		words, err := f.store.Words().CountWords(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 6, words)
+	})
+
+	t.Run("creates nothing when a word fails", func(t *testing.T) {
+		f := newFixture(t)
+		f.uow.wrap = func(repos Repositories) Repositories {
+			repos.Words = &failingWords{WordRepository: repos.Words, succeed: 2}
+			return repos
+		}
+
+		_, err := f.groupService().ImportGroup(ctx, input())
+		assert.ErrorIs(t, err, errInjected)
+		group, err := f.store.Groups().FindGroupByName(ctx, "Travel")
+		require.NoError(t, err)
+		assert.Nil(t, group)
+		words, err := f.store.Words().CountWords(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, words)
+	})
+
+	t.Run("rejects a taken name and invalid words", func(t *testing.T) {
+		f := newFixture(t)
+		taken := input()
+		taken.Name = "FOOD"
+		_, err := f.groupService().ImportGroup(ctx, taken)
+		assert.ErrorIs(t, err, ErrConflict)
+
+		invalid := input()
+		invalid.Words[1].English = " "
+		_, err = f.groupService().ImportGroup(ctx, invalid)
+		var svcErr *Error
+		require.True(t, errors.As(err, &svcErr))
+		assert.Equal(t, []FieldError{{Field: "words[1].english", Message: "must not be empty"}}, svcErr.Fields)
+	})
+}
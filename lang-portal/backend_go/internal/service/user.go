@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
+)
+
+type UserService struct {
+	userRepo UserRepository
+}
+
+func NewUserService(userRepo UserRepository) *UserService {
+	return &UserService{userRepo: userRepo}
+}
+
+func (s *UserService) GetUser(ctx context.Context, id int64) (*models.User, error) {
+	user, err := s.userRepo.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, NotFound("user")
+	}
+	return user, nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	if user.Role == "" {
+		user.Role = models.RoleStudent
+	}
+	return s.userRepo.CreateUser(ctx, user)
+}
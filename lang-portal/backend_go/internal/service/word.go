@@ -1,60 +1,109 @@
 package service
 
 import (
+	"context"
+	"errors"
+
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/models"
 	"github.com/andlogreg/free-genai-bootcamp-2025/backend_go/internal/repository"
 )
 
 type WordService struct {
-	wordRepo *repository.WordRepository
+	wordRepo WordRepository
 }
 
-func NewWordService(wordRepo *repository.WordRepository) *WordService {
+func NewWordService(wordRepo WordRepository) *WordService {
 	return &WordService{wordRepo: wordRepo}
 }
 
-func (s *WordService) ListWords() ([]*models.Word, error) {
-	return s.wordRepo.ListWords()
+func (s *WordService) ListWords(ctx context.Context) ([]*models.Word, error) {
+	return s.wordRepo.ListWords(ctx)
 }
 
 // ListWordsWithStatsPaginated returns a paginated list of words with their stats
-func (s *WordService) ListWordsWithStatsPaginated(page, pageSize int) ([]*models.WordWithStats, int, error) {
-	return s.wordRepo.ListWordsWithStatsPaginated(page, pageSize)
+func (s *WordService) ListWordsWithStatsPaginated(ctx context.Context, page, pageSize int) ([]*models.WordWithStats, int, error) {
+	return s.wordRepo.ListWordsWithStatsPaginated(ctx, page, pageSize)
+}
+
+func (s *WordService) GetWord(ctx context.Context, id int64) (*models.Word, error) {
+	word, err := s.wordRepo.GetWord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, NotFound("word")
+	}
+	return word, nil
 }
 
-func (s *WordService) GetWord(id int64) (*models.Word, error) {
-	return s.wordRepo.GetWord(id)
+func (s *WordService) GetWordWithStats(ctx context.Context, id int64) (*models.WordWithStats, error) {
+	return s.wordRepo.GetWordWithStats(ctx, id)
 }
 
-func (s *WordService) GetWordWithStats(id int64) (*models.WordWithStats, error) {
-	return s.wordRepo.GetWordWithStats(id)
+// CreateWord creates a word. It returns a conflict if a word with the same
+// Portuguese and English text exists.
+func (s *WordService) CreateWord(ctx context.Context, input models.WordInput) (*models.Word, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalidInput(err)
+	}
+	word, err := s.wordRepo.CreateWord(ctx, &models.Word{
+		Portuguese: input.Portuguese,
+		English:    input.English,
+	})
+	if err != nil {
+		return nil, wordExists(err)
+	}
+	return word, nil
 }
 
-func (s *WordService) CreateWord(word *models.Word) (*models.Word, error) {
-	return s.wordRepo.CreateWord(word)
+func (s *WordService) UpdateWord(ctx context.Context, id int64, input models.WordInput) (*models.Word, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalidInput(err)
+	}
+	updated, err := s.wordRepo.UpdateWord(ctx, &models.Word{
+		ID:         id,
+		Portuguese: input.Portuguese,
+		English:    input.English,
+	})
+	if err != nil {
+		return nil, wordExists(err)
+	}
+	if updated == nil {
+		return nil, NotFound("word")
+	}
+	return updated, nil
 }
 
-func (s *WordService) UpdateWord(word *models.Word) (*models.Word, error) {
-	return s.wordRepo.UpdateWord(word)
+// wordExists converts repository.ErrDuplicate, returned when another word has
+// the same text, into a conflict. Other errors are returned unchanged.
+func wordExists(err error) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return Conflict("word already exists", err)
+	}
+	return err
 }
 
-func (s *WordService) DeleteWord(id int64) error {
-	return s.wordRepo.DeleteWord(id)
+func (s *WordService) DeleteWord(ctx context.Context, id int64) error {
+	err := s.wordRepo.DeleteWord(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return NotFound("word")
+	}
+	return err
 }
 
 // GetWordDetail returns a word with its statistics and groups
-func (s *WordService) GetWordDetail(id int64) (*models.WordDetail, error) {
+func (s *WordService) GetWordDetail(ctx context.Context, id int64) (*models.WordDetail, error) {
 	// Get the word with stats
-	wordWithStats, err := s.wordRepo.GetWordWithStats(id)
+	wordWithStats, err := s.wordRepo.GetWordWithStats(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if wordWithStats == nil {
-		return nil, nil
+		return nil, NotFound("word")
 	}
 
 	// Get the groups for this word
-	groups, err := s.wordRepo.GetWordGroups(id)
+	groups, err := s.wordRepo.GetWordGroups(ctx, id)
 	if err != nil {
 		return nil, err
 	}
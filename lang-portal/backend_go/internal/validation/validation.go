@@ -0,0 +1,80 @@
+// Package validation holds the input rules shared by the HTTP handlers and
+// the seed importer.
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes why a single input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors is a list of field errors. It is returned as an error by Validator.Err.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, fe := range e {
+		parts = append(parts, fe.Field+" "+fe.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validator collects field errors while checking an input
+type Validator struct {
+	errs Errors
+}
+
+// Add records an error for field
+func (v *Validator) Add(field, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: message})
+}
+
+// Text trims value in place and checks that it is non-empty and at most max
+// characters long
+func (v *Validator) Text(field string, value *string, max int) {
+	*value = strings.TrimSpace(*value)
+	switch {
+	case *value == "":
+		v.Add(field, "must not be empty")
+	case utf8.RuneCountInString(*value) > max:
+		v.Add(field, fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+// IDs checks that a list of IDs is non-empty, positive and free of duplicates
+func (v *Validator) IDs(field string, ids []int64, max int) {
+	if len(ids) == 0 {
+		v.Add(field, "must not be empty")
+		return
+	}
+	if len(ids) > max {
+		v.Add(field, fmt.Sprintf("must contain at most %d items", max))
+		return
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	for i, id := range ids {
+		itemField := fmt.Sprintf("%s[%d]", field, i)
+		switch {
+		case id < 1:
+			v.Add(itemField, "must be a positive integer")
+		case seen[id]:
+			v.Add(itemField, "is a duplicate")
+		}
+		seen[id] = true
+	}
+}
+
+// Err returns the collected errors, or nil if the input is valid
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
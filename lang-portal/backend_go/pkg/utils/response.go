@@ -11,14 +11,6 @@ type PaginatedResponse struct {
 	Pagination Pagination  `json:"pagination"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
-func RespondWithError(c *gin.Context, code int, message string) {
-	c.JSON(code, ErrorResponse{Error: message})
-}
-
 func RespondWithJSON(c *gin.Context, code int, payload interface{}) {
 	c.JSON(code, payload)
 }